@@ -0,0 +1,76 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ActionExecutionSpec records a single execution of a ResourceAction.
+type ActionExecutionSpec struct {
+	// ResourceAction is the name of the ResourceAction in the same namespace.
+	ResourceAction string `json:"resourceAction"`
+
+	ExecutionRecord `json:",inline"`
+
+	// Request and Response describe the last HTTP action of the execution.
+	Request  *HTTPRequestRecord  `json:"request,omitempty"`
+	Response *HTTPResponseRecord `json:"response,omitempty"`
+
+	// FinalState is the last known state of the object of a Delete event.
+	// Retriggering the Delete event replays it once the object is gone.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	FinalState *runtime.RawExtension `json:"finalState,omitempty"`
+}
+
+type HTTPRequestRecord struct {
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+type HTTPResponseRecord struct {
+	StatusCode int `json:"statusCode,omitempty"`
+	// Body is truncated to a few KiB; Truncated reports whether it was cut.
+	Body      string `json:"body,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ActionExecution is an audit record of a single ResourceAction execution.
+// It is owned by its ResourceAction and garbage collected with it.
+type ActionExecution struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ActionExecutionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ActionExecutionList contains a list of ActionExecution.
+type ActionExecutionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ActionExecution `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ActionExecution{}, &ActionExecutionList{})
+}
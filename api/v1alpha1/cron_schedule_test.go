@@ -0,0 +1,40 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Matches(t *testing.T) {
+	schedule, err := ParseCronSchedule("30 22 * * 6,7")
+	if err != nil {
+		t.Fatalf("ParseCronSchedule: %v", err)
+	}
+	for at, want := range map[string]bool{
+		"2026-10-17T22:30:00Z": true,  // Saturday
+		"2026-10-18T22:30:00Z": true,  // Sunday
+		"2026-10-19T22:30:00Z": false, // Monday
+		"2026-10-17T22:31:00Z": false,
+	} {
+		tm, _ := time.Parse(time.RFC3339, at)
+		if got := schedule.Matches(tm); got != want {
+			t.Fatalf("Matches(%s) = %v, want %v", at, got, want)
+		}
+	}
+
+	// Both day fields restricted: either matches.
+	schedule, err = ParseCronSchedule("0 0 1 * 1")
+	if err != nil {
+		t.Fatalf("ParseCronSchedule: %v", err)
+	}
+	for at, want := range map[string]bool{
+		"2026-10-01T00:00:00Z": true,  // the 1st, a Thursday
+		"2026-10-19T00:00:00Z": true,  // a Monday
+		"2026-10-20T00:00:00Z": false, // a Tuesday
+	} {
+		tm, _ := time.Parse(time.RFC3339, at)
+		if got := schedule.Matches(tm); got != want {
+			t.Fatalf("Matches(%s) = %v, want %v", at, got, want)
+		}
+	}
+}
@@ -27,10 +27,170 @@ import (
 // ResourceActionSpec defines the desired state of ResourceAction.
 type ResourceActionSpec struct {
 	Selector ResourceSelector `json:"selector"`
-	// +kubebuilder:validation:Items:Enum=Create;Update;Delete
+	// +kubebuilder:validation:Items:Enum=Create;Update;Delete;Webhook
 	Events  []string     `json:"events"`
 	Filters *FilterSpec  `json:"filters,omitempty"`
 	Actions []ActionSpec `json:"actions"`
+
+	// WatchNamespaces limits the informers for the selected resource to these
+	// namespaces instead of watching the whole cluster. It is ignored for
+	// cluster-scoped resources. When the operator itself is restricted with
+	// --watch-namespaces, every entry must be one of those namespaces.
+	// +optional
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// ClusterRef watches the selected resource and runs job actions in a
+	// remote cluster. Status, execution history and HTTP actions stay in the
+	// cluster the operator runs in.
+	// +optional
+	ClusterRef *ClusterRefSpec `json:"clusterRef,omitempty"`
+
+	// ExecutionPolicy controls how often the event-driven actions run for an
+	// object. "OncePerObject" runs them once per object and event,
+	// "OncePerGeneration" again whenever metadata.generation increased, and
+	// "EveryEvent" on every matching event. The default of v1alpha1 is
+	// "OncePerObject"; the API server stores it when the object is created, so
+	// a different default of a later API version does not change existing
+	// ResourceActions.
+	// +kubebuilder:validation:Enum=OncePerObject;OncePerGeneration;EveryEvent
+	// +kubebuilder:default=OncePerObject
+	// +optional
+	ExecutionPolicy string `json:"executionPolicy,omitempty"`
+
+	// NameReuse controls how events of an object that was deleted and
+	// re-created with the same name, and so a new UID, are treated.
+	// +optional
+	NameReuse *NameReuseSpec `json:"nameReuse,omitempty"`
+
+	// Debounce coalesces Update events of the same object that arrive within
+	// this window, for example "30s", into a single execution with the latest
+	// object state. Unset executes every Update event.
+	// +optional
+	Debounce string `json:"debounce,omitempty"`
+
+	// MaxExecutionsPerObject caps how often the event-driven actions run for
+	// the same object within an hour. Further events of the object are
+	// throttled.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxExecutionsPerObject *int32 `json:"maxExecutionsPerObject,omitempty"`
+
+	// MaxExecutionsPerHour caps how often the event-driven actions run within
+	// an hour across all objects. Further events are throttled.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxExecutionsPerHour *int32 `json:"maxExecutionsPerHour,omitempty"`
+
+	// SuppressionWindows are recurring periods, such as planned maintenance,
+	// during which events are still matched but their actions are dropped or
+	// queued until the window ends.
+	// +optional
+	SuppressionWindows []SuppressionWindowSpec `json:"suppressionWindows,omitempty"`
+
+	// Aggregation collects matching events and delivers them together: every
+	// action runs once per batch and its request body is a JSON array with one
+	// element per event.
+	// +optional
+	Aggregation *AggregationSpec `json:"aggregation,omitempty"`
+
+	// Priority orders the ResourceActions that match the same event. Higher
+	// priorities run first; equal priorities run in order of namespace and
+	// name.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// StopOnMatch skips the ResourceActions with a lower position in the
+	// priority order for every event this ResourceAction matches.
+	// +optional
+	StopOnMatch bool `json:"stopOnMatch,omitempty"`
+
+	// Suspend pauses event-driven execution and cron actions for this
+	// ResourceAction without deleting it.
+	// +kubebuilder:default=false
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Backfill runs the Create actions for every matching object that already
+	// exists when the ResourceAction is created or resumed from suspend.
+	// +optional
+	Backfill bool `json:"backfill,omitempty"`
+
+	// DryRun matches events and renders the requests of the HTTP actions
+	// without sending them. The rendered requests are recorded in
+	// status.executions with Secret values masked. Job actions, cron actions
+	// and the teardown do not run.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Record evaluates matching events like dryRun, but stores every
+	// execution as an ActionExecution marked simulated, whatever historyMode
+	// is, to trial a ResourceAction on production events. No requests are
+	// sent. The operator flag --record-mode enables it for every
+	// ResourceAction.
+	// +optional
+	Record bool `json:"record,omitempty"`
+
+	// Teardown runs once when the ResourceAction is deleted, after its
+	// schedules were stopped and its queued events were delivered. The
+	// ResourceAction itself is the object of the action.
+	// +optional
+	Teardown *ActionSpec `json:"teardown,omitempty"`
+
+	// OnSpecChange controls what happens when the spec changes, that is when
+	// metadata.generation moves past status.observedGeneration. Schedules are
+	// always restarted with the new spec.
+	// +optional
+	OnSpecChange *SpecChangeSpec `json:"onSpecChange,omitempty"`
+
+	// Degraded sets when the Degraded condition turns True, so alerting can
+	// key on a single condition instead of the state of every action. Unset
+	// does not report the condition.
+	// +optional
+	Degraded *DegradedSpec `json:"degraded,omitempty"`
+
+	// HistoryLimit is the maximum number of status.executions records kept.
+	// The oldest records are pruned first. Unset keeps all records.
+	// +kubebuilder:validation:Minimum=1
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+
+	// HistoryTTL prunes status.executions records older than this duration,
+	// for example "168h". Unset keeps records regardless of age.
+	HistoryTTL string `json:"historyTTL,omitempty"`
+
+	// HistoryMode selects where execution records are kept. "Status" appends
+	// them to status.executions, "ActionExecution" creates one ActionExecution
+	// object per execution instead. historyLimit and historyTTL apply to both.
+	// +kubebuilder:validation:Enum=Status;ActionExecution
+	// +kubebuilder:default=Status
+	HistoryMode string `json:"historyMode,omitempty"`
+}
+
+// SpecChangeSpec controls what happens when the spec of a ResourceAction
+// changes.
+type SpecChangeSpec struct {
+	// ClearHistory drops the execution history and the deduplication state,
+	// so the actions run again for objects that were already handled.
+	// +optional
+	ClearHistory bool `json:"clearHistory,omitempty"`
+
+	// Backfill runs spec.backfill again for the existing objects.
+	// +optional
+	Backfill bool `json:"backfill,omitempty"`
+}
+
+// DegradedSpec sets when a failing action degrades the ResourceAction. Set at
+// least one of the fields.
+type DegradedSpec struct {
+	// StaleAfter reports Degraded when a failing action has had no
+	// successful execution for this long, for example "6h". An action that
+	// never succeeded counts from its first failure.
+	// +optional
+	StaleAfter string `json:"staleAfter,omitempty"`
+
+	// FailureThreshold reports Degraded when an action failed this many
+	// times in a row.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
 }
 
 type ResourceSelector struct {
@@ -44,6 +204,24 @@ type FilterSpec struct {
 	LabelChanges   []LabelChangeFilter `json:"labelChanges,omitempty"`
 	NameRegex      string              `json:"nameRegex,omitempty"`
 	NamespaceRegex string              `json:"namespaceRegex,omitempty"`
+
+	// Alert matches the alerts of WebhookSources with format Alertmanager.
+	// Other events never match it.
+	// +optional
+	Alert *AlertFilter `json:"alert,omitempty"`
+
+	// GitHub matches the events of WebhookSources with format GitHub.
+	// Other events never match it.
+	// +optional
+	GitHub *GitHubFilter `json:"github,omitempty"`
+
+	// ChangeType matches Update events that changed the spec, the status, the
+	// metadata or the labels of the object. Changes of the resourceVersion and
+	// managedFields are ignored, and Labels changes are Metadata changes as
+	// well. Other events never match it.
+	// +kubebuilder:validation:Enum=Spec;Status;Metadata;Labels
+	// +optional
+	ChangeType string `json:"changeType,omitempty"`
 }
 
 type LabelChangeFilter struct {
@@ -58,8 +236,93 @@ type LabelChangeFilter struct {
 	To string `json:"to,omitempty"`
 }
 
+// AlertFilter matches an Alertmanager alert by its labels and status.
+type AlertFilter struct {
+	// Labels must all be set on the alert with these values.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Status is "firing" or "resolved". Empty matches both.
+	// +kubebuilder:validation:Enum=firing;resolved
+	// +optional
+	Status string `json:"status,omitempty"`
+}
+
+// GitHubFilter matches a GitHub webhook delivery by its event, repository
+// and Git reference.
+type GitHubFilter struct {
+	// Events are the names of the GitHub events to match, as sent in
+	// X-GitHub-Event, for example "push". Empty matches all events.
+	// +optional
+	Events []string `json:"events,omitempty"`
+
+	// Repository is the full name of the repository, for example
+	// "octo-org/shop".
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Ref is the Git reference of the event, for example "refs/heads/main".
+	// Events without a ref, such as issues, never match it.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+}
+
 type ActionSpec struct {
-	// +kubebuilder:validation:Enum=http;job
+	// Name identifies the action in status, events and metrics. It must be
+	// unique within the ResourceAction. Actions without a name are referred
+	// to by their index only.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Enabled set to false skips the action without removing it from the
+	// spec, so the indices of the other actions do not change.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ContinueOnError runs the later actions of the list even when this
+	// action fails. The execution is then recorded as PartiallyFailed.
+	// +optional
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+
+	// DependsOn names actions that must be done before this action runs.
+	// Once an action declares dependencies, the actions run as a graph:
+	// actions whose dependencies are done run in parallel, and the actions
+	// depending on a failed action are skipped unless it has continueOnError.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// OnSuccess names actions with mode handler that run after this action
+	// succeeded.
+	// +optional
+	OnSuccess []string `json:"onSuccess,omitempty"`
+
+	// OnFailure names actions with mode handler that run after this action
+	// failed, for example to notify a fallback channel. They can read the
+	// failure reason.
+	// +optional
+	OnFailure []string `json:"onFailure,omitempty"`
+
+	// Escalation names actions with mode handler that run one after another
+	// once this action failed and its retries are exhausted, until one of
+	// them succeeds, for example to page PagerDuty when the primary webhook
+	// stays down. They can read the failure reason, the attempts and status
+	// of this action, and their escalation level.
+	// +optional
+	Escalation []string `json:"escalation,omitempty"`
+
+	// Approval holds the action in the PendingApproval state until it is
+	// approved, for example before it deletes or scales a resource. Later
+	// actions wait for it.
+	// +optional
+	Approval *ApprovalSpec `json:"approval,omitempty"`
+
+	// Type is http, job, wait, or waitForCondition. A wait action pauses the
+	// execution before the next action; see wait. A waitForCondition action
+	// pauses it until a resource reaches a state; see waitForCondition.
+	// +kubebuilder:validation:Enum=http;job;wait;waitForCondition
 	Type string `json:"type"`
 
 	// +kubebuilder:default=POST
@@ -69,21 +332,424 @@ type ActionSpec struct {
 	Headers   map[string]ValueFrom `json:"headers,omitempty"`
 	Body      *TemplateSpec        `json:"body,omitempty"`
 
+	// FallbackURLs are tried in order when the request to url failed after
+	// all retries. Each one gets the retries of the action again.
+	// +optional
+	FallbackURLs []string `json:"fallbackURLs,omitempty"`
+
+	// Hedging races url against the first fallback URL and takes the first
+	// success. Both receivers may process the request, so it must be
+	// idempotent.
+	// +optional
+	Hedging *HedgingSpec `json:"hedging,omitempty"`
+
+	// ForEach sends one request per item of a templated list instead of a
+	// single request to url, for example one per container of a Pod. The
+	// action fails when a request to any target fails.
+	// +optional
+	ForEach *ForEachSpec `json:"forEach,omitempty"`
+
+	// Auth authenticates HTTP requests to the target.
+	// +optional
+	Auth *AuthSpec `json:"auth,omitempty"`
+
+	// IdempotencyKey adds a header whose value is derived from the
+	// ResourceAction, the action, the object and the event, so receivers
+	// can drop requests they already processed. Retries, fallback URLs and
+	// redeliveries after a restart send the same key.
+	// +optional
+	IdempotencyKey *IdempotencyKeySpec `json:"idempotencyKey,omitempty"`
+
 	ExpectedStatus string `json:"expectedStatus,omitempty"`
 
-	// +kubebuilder:validation:Enum=once;cron
+	// ExpectedResponse checks the body of HTTP responses whose status
+	// matched expectedStatus. A response that fails the check counts as
+	// failed and is retried.
+	// +optional
+	ExpectedResponse *ExpectedResponseSpec `json:"expectedResponse,omitempty"`
+
+	// SuccessCondition is a CEL expression that decides whether an HTTP
+	// response succeeded, instead of expectedStatus, for example
+	// `status == 200 && body.result == "ok"`. It sees status, headers with
+	// lower-case names, and body, the response parsed as JSON or its text.
+	// Failed 2xx responses and those with a status in retry.retryOnStatus
+	// are retried.
+	// +optional
+	SuccessCondition string `json:"successCondition,omitempty"`
+
+	// LogResponseBody adds the response body of HTTP requests to the logs,
+	// capped at 1 KiB and with Secret values and credential fields redacted.
+	// By default only its size is logged.
+	// +optional
+	LogResponseBody bool `json:"logResponseBody,omitempty"`
+
+	// MaxResponseBytes caps how much of a response body is read, including
+	// for expectedResponse, responseCapture and outputs. Longer bodies are
+	// truncated. Defaults to 1 MiB.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxResponseBytes int64 `json:"maxResponseBytes,omitempty"`
+
+	// Mode once runs the action for events, cron on a schedule, and handler
+	// only from onSuccess, onFailure or escalation of another action.
+	// +kubebuilder:validation:Enum=once;cron;handler
 	// +kubebuilder:default=once
 	Mode string `json:"mode,omitempty"`
 
 	Schedule string `json:"schedule,omitempty"`
 
+	// ScheduleScope controls which objects a cron action runs against.
+	// "event" registers one schedule per object after a matching event.
+	// "all" runs on every tick against all objects currently matching the
+	// selector and filters, without waiting for an event.
+	// +kubebuilder:validation:Enum=event;all
+	// +kubebuilder:default=event
+	ScheduleScope string `json:"scheduleScope,omitempty"`
+
+	// MissedRunPolicy controls runs that were due while the schedule was not
+	// registered, for example during operator downtime or leader failover.
+	// "Skip" drops them, "RunOnce" runs the action once on registration.
+	// +kubebuilder:validation:Enum=Skip;RunOnce
+	// +kubebuilder:default=Skip
+	MissedRunPolicy string `json:"missedRunPolicy,omitempty"`
+
+	// StartingDeadline limits how late a missed run may be caught up, for
+	// example "10m". Missed runs older than this are skipped.
+	StartingDeadline string `json:"startingDeadline,omitempty"`
+
+	// ExecutionDeadline bounds the total runtime of a single cron tick,
+	// including retries and backoff, for example "2m". Defaults to the
+	// schedule interval so a tick never overlaps the next one.
+	ExecutionDeadline string `json:"executionDeadline,omitempty"`
+
+	// Timeout caps each attempt of an HTTP request. Superseded by
+	// attemptTimeout, which takes precedence when set.
 	// +kubebuilder:default="10s"
 	Timeout string `json:"timeout,omitempty"`
 
+	// AttemptTimeout caps a single HTTP attempt, from sending the request
+	// to reading the response, for example "5s". Defaults to timeout.
+	// +optional
+	AttemptTimeout string `json:"attemptTimeout,omitempty"`
+
+	// OverallTimeout caps the whole HTTP action, including all attempts,
+	// backoff and fallback URLs, for example "1m". Retries whose backoff
+	// would end past it are not attempted. Unset, only the deadline of the
+	// execution applies.
+	// +optional
+	OverallTimeout string `json:"overallTimeout,omitempty"`
+
 	Retry *RetrySpec `json:"retry,omitempty"`
 	TLS   *TLSSpec   `json:"tls,omitempty"`
 
+	// Proxy sends the requests of this action through a forward proxy
+	// instead of the proxy from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables of the operator.
+	// +optional
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// FollowRedirects follows HTTP redirects. Defaults to true. When false,
+	// the redirect response itself is checked against expectedStatus.
+	// +optional
+	FollowRedirects *bool `json:"followRedirects,omitempty"`
+
+	// MaxRedirects caps the redirects followed for a request. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRedirects int `json:"maxRedirects,omitempty"`
+
+	// CrossOriginRedirects controls what redirects to another scheme, host
+	// or port keep of the request.
+	// +optional
+	CrossOriginRedirects *CrossOriginRedirectSpec `json:"crossOriginRedirects,omitempty"`
+
+	// RateLimit caps the requests this action sends, including retries.
+	RateLimit *RateLimitSpec `json:"rateLimit,omitempty"`
+
+	// ResponseCapture stores values from a successful HTTP response.
+	ResponseCapture *ResponseCaptureSpec `json:"responseCapture,omitempty"`
+
+	// Outputs maps names to JSONPath expressions evaluated against the JSON
+	// body of a successful HTTP response, for example
+	// {"ticketURL": "{.links.self}"}. Body templates of later actions in the
+	// same execution read them with {{ output "ticketURL" }}.
+	// +optional
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// RecordResultOn writes the result back once the action succeeded.
+	// "object" patches resultAnnotations onto the object of a Create or
+	// Update event, so other controllers and users see that the action ran.
+	// +kubebuilder:validation:Enum=object
+	// +optional
+	RecordResultOn string `json:"recordResultOn,omitempty"`
+
+	// ResultAnnotations are the annotations written by recordResultOn. Their
+	// names must have a prefix ending in ".ops.yusaozdemir.de". The values
+	// are templates rendered against the object with the functions of body
+	// templates, the outputs of this action and executedAt. Defaults to
+	// <resourceaction>.ops.yusaozdemir.de/notified-at set to executedAt.
+	// +optional
+	ResultAnnotations map[string]string `json:"resultAnnotations,omitempty"`
+
+	// DeadLetter receives the event and payload when the action fails after
+	// all retries.
+	DeadLetter *DeadLetterSpec `json:"deadLetter,omitempty"`
+
 	Job *JobSpec `json:"job,omitempty"`
+
+	// Wait configures an action of type wait.
+	// +optional
+	Wait *WaitSpec `json:"wait,omitempty"`
+
+	// WaitForCondition configures an action of type waitForCondition.
+	// +optional
+	WaitForCondition *WaitForConditionSpec `json:"waitForCondition,omitempty"`
+}
+
+// AggregationSpec collects the events of a ResourceAction into batches. A
+// batch is delivered when window has passed since its first event or when it
+// holds maxEvents events, whichever comes first. Set at least one of them.
+// With groupBy, every group of events is batched on its own.
+type AggregationSpec struct {
+	// Window is how long events are collected, counted from the first event
+	// of a batch, for example "30s".
+	// +optional
+	Window string `json:"window,omitempty"`
+
+	// MaxEvents delivers a batch as soon as it holds this many events.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	MaxEvents int `json:"maxEvents,omitempty"`
+
+	// GroupBy maps label names to Go templates rendered against the object of
+	// each event, like the group_by of Alertmanager. Events whose labels are
+	// equal share a fingerprint and are collected into a batch of their own.
+	// +optional
+	GroupBy map[string]string `json:"groupBy,omitempty"`
+
+	// ResolveAfter runs the actions once more for the last batch of a group,
+	// with groupStatus "resolved", when no event of the group arrived for this
+	// long, for example "10m". It must be longer than window.
+	// +optional
+	ResolveAfter string `json:"resolveAfter,omitempty"`
+}
+
+// NameReuseSpec configures the handling of objects that are re-created under
+// the name of a deleted object.
+type NameReuseSpec struct {
+	// Policy is "New" to treat a re-created object like any other object or
+	// "Suppress" to skip its events while the same event of an earlier object
+	// with the same namespace and name ran within window.
+	// +kubebuilder:validation:Enum=New;Suppress
+	// +kubebuilder:default=New
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// Window is how long events of a re-created object are suppressed after
+	// the actions last ran for an object of the same name, for example "10m".
+	// +kubebuilder:default="10m"
+	// +optional
+	Window string `json:"window,omitempty"`
+}
+
+// SuppressionWindowSpec is a recurring window during which the event-driven
+// actions of a ResourceAction do not run.
+type SuppressionWindowSpec struct {
+	// Schedule is a cron expression with the fields minute, hour, day of
+	// month, month and day of week for the start of the window, for example
+	// "0 22 * * 6" for Saturdays at 22:00.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window lasts, for example "4h". At most
+	// "168h".
+	Duration string `json:"duration"`
+
+	// TimeZone is the IANA time zone of schedule, for example
+	// "Europe/Berlin". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Mode "Drop" skips the actions of events within the window; "Queue"
+	// runs them once the window has ended.
+	// +kubebuilder:validation:Enum=Drop;Queue
+	// +kubebuilder:default=Drop
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// ApprovalSpec gates an action on a human decision. The execution pauses
+// before the action until the ResourceAction is annotated with
+// resource-action-operator.yusaozdemir.de/approve or
+// resource-action-operator.yusaozdemir.de/reject set to the correlation ID of
+// the execution, which status.actionStates shows.
+type ApprovalSpec struct {
+	// Timeout fails the action when it is neither approved nor rejected in
+	// time. Defaults to 1h.
+	// +kubebuilder:default="1h"
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// AuthSpec configures how an HTTP action authenticates to its target. Set
+// exactly one field.
+type AuthSpec struct {
+	// Basic sends HTTP basic authentication credentials.
+	// +optional
+	Basic *BasicAuth `json:"basic,omitempty"`
+
+	// ServiceAccountToken sends a bound ServiceAccount token in the
+	// Authorization header as bearer token.
+	// +optional
+	ServiceAccountToken *ServiceAccountTokenAuth `json:"serviceAccountToken,omitempty"`
+}
+
+// BasicAuth reads HTTP basic authentication credentials from Secrets in the
+// namespace of the ResourceAction.
+type BasicAuth struct {
+	Username ValueFrom `json:"username"`
+	Password ValueFrom `json:"password"`
+}
+
+// ServiceAccountTokenAuth requests a token for a ServiceAccount in the
+// namespace of the ResourceAction through the TokenRequest API. The token is
+// cached and requested again before it expires.
+type ServiceAccountTokenAuth struct {
+	// ServiceAccountName is the ServiceAccount the token is issued for.
+	// +kubebuilder:default=default
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Audience is the audience the token is issued for, for example the
+	// receiving service. Receivers must reject tokens for other audiences.
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+
+	// ExpirationSeconds is the requested lifetime of the token. The API
+	// server may issue a token with a different lifetime.
+	// +kubebuilder:validation:Minimum=600
+	// +kubebuilder:default=3600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// ClusterRefSpec references a kubeconfig stored in a Secret in the namespace
+// of the ResourceAction.
+type ClusterRefSpec struct {
+	// SecretName is the Secret that holds the kubeconfig.
+	SecretName string `json:"secretName"`
+
+	// Key is the Secret key of the kubeconfig.
+	// +kubebuilder:default=kubeconfig
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// DeadLetterSpec configures where permanently failed executions are
+// published.
+type DeadLetterSpec struct {
+	// Type is HTTP (POST to url), ConfigMap (entry in configMapName) or
+	// ActionExecution (ActionExecution object labeled as dead letter).
+	// +kubebuilder:validation:Enum=HTTP;ConfigMap;ActionExecution
+	Type string `json:"type"`
+
+	URL       string         `json:"url,omitempty"`
+	URLPolicy *URLPolicySpec `json:"urlPolicy,omitempty"`
+
+	// ConfigMapName is the ConfigMap in the ResourceAction namespace that
+	// collects dead letters. It keeps the most recent 100 entries.
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// CrossOriginRedirectSpec controls redirects to another origin. By default the
+// configured headers, including the auth header, are removed from the
+// redirected request, and 307 and 308 redirects, which re-send the body, are
+// not followed.
+type CrossOriginRedirectSpec struct {
+	// KeepHeaders sends the configured headers and the auth header to the
+	// other origin too.
+	// +optional
+	KeepHeaders bool `json:"keepHeaders,omitempty"`
+
+	// ResendBody follows 307 and 308 redirects that re-send the request body
+	// to the other origin.
+	// +optional
+	ResendBody bool `json:"resendBody,omitempty"`
+}
+
+// ForEachSpec fans an HTTP action out to a list of targets. Templates render
+// against the object and can read the current item with {{ item }} and the
+// outputs of earlier actions with {{ output "name" }}.
+type ForEachSpec struct {
+	// Items is a Go template that yields one item per line, for example
+	// "{{ range .spec.containers }}{{ .name }}\n{{ end }}". Empty lines are
+	// skipped; at most 100 items are allowed.
+	// +kubebuilder:validation:MinLength=1
+	Items string `json:"items"`
+
+	// URL is a Go template that yields the URL of an item, for example
+	// "https://{{ item }}.example.com/hooks". Every URL is checked against
+	// the URL policies before it is called.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+}
+
+// HedgingSpec sends a request to two endpoints of the same receiver.
+type HedgingSpec struct {
+	// Delay before the request to the first fallback URL is sent, for
+	// example "200ms", unless url answered successfully before. Defaults to
+	// sending both right away.
+	// +optional
+	Delay string `json:"delay,omitempty"`
+}
+
+// IdempotencyKeySpec configures the idempotency key header of an HTTP action.
+// The key is a SHA-256 hash of the UIDs of the ResourceAction and the
+// object, the action index, the event and the generation of the object.
+// Scheduled actions add the time of the tick.
+type IdempotencyKeySpec struct {
+	// Header is the name of the header. Defaults to Idempotency-Key.
+	// +kubebuilder:default=Idempotency-Key
+	// +optional
+	Header string `json:"header,omitempty"`
+}
+
+// ExpectedResponseSpec checks the body of an HTTP response, for example for
+// receivers that answer 200 with {"status":"error"}. All configured checks
+// must pass.
+type ExpectedResponseSpec struct {
+	// BodyRegex must match the response body.
+	// +optional
+	BodyRegex string `json:"bodyRegex,omitempty"`
+
+	// JSONPath is evaluated against the JSON response body, for example
+	// "{.status}". It must select a value.
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+
+	// Value is compared with the result of jsonPath.
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// ResponseCaptureSpec selects values of an HTTP response and where to store
+// them, for example a ticket ID returned by the receiver.
+type ResponseCaptureSpec struct {
+	// Target is Status (status.capturedResponses) or ConfigMap.
+	// +kubebuilder:validation:Enum=Status;ConfigMap
+	// +kubebuilder:default=Status
+	Target string `json:"target,omitempty"`
+
+	// ConfigMapName is the ConfigMap in the ResourceAction namespace that
+	// receives the values when target is ConfigMap.
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// StatusCode stores the HTTP status code under the key "statusCode".
+	StatusCode bool `json:"statusCode,omitempty"`
+
+	// Fields maps keys to JSONPath expressions evaluated against the JSON
+	// response body, for example {"ticketID": "{.id}"}.
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 type RetrySpec struct {
@@ -94,7 +760,8 @@ type RetrySpec struct {
 	// +kubebuilder:default="500ms"
 	Backoff string `json:"backoff,omitempty"`
 
-	// Max backoff, for example "10s".
+	// Max backoff, for example "10s". It also bounds the delays receivers
+	// ask for with Retry-After.
 	// +kubebuilder:default="10s"
 	MaxBackoff string `json:"maxBackoff,omitempty"`
 
@@ -107,6 +774,19 @@ type RetrySpec struct {
 	RetryOnStatus []int `json:"retryOnStatus,omitempty"`
 }
 
+// RateLimitSpec caps outbound HTTP requests with a token bucket.
+type RateLimitSpec struct {
+	// RequestsPerSecond is the sustained request rate.
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int `json:"requestsPerSecond"`
+
+	// Burst is the number of requests that may be sent at once. Defaults to
+	// requestsPerSecond.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int `json:"burst,omitempty"`
+}
+
 type URLPolicySpec struct {
 	AllowUnsafeLocalTargets bool     `json:"allowUnsafeLocalTargets,omitempty"`
 	AllowedHostRegex        []string `json:"allowedHostRegex,omitempty"`
@@ -126,6 +806,56 @@ type TLSSpec struct {
 
 	// mTLS client cert/key from secret, default keys: tls.crt/tls.key.
 	ClientCertSecretRef *TLSClientCertRef `json:"clientCertSecretRef,omitempty"`
+
+	// MinVersion is the lowest TLS version accepted. Defaults to "1.2".
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// MaxVersion is the highest TLS version offered. Defaults to "1.3".
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +optional
+	MaxVersion string `json:"maxVersion,omitempty"`
+
+	// CipherSuites restricts the TLS 1.2 cipher suites to these IANA names,
+	// for example "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256". The TLS 1.3
+	// cipher suites are not configurable.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+}
+
+// FormFileSpec is a file of a multipart/form-data body.
+type FormFileSpec struct {
+	// Field is the form field of the file.
+	Field string `json:"field"`
+
+	// FileName is the file name sent to the receiver.
+	FileName string `json:"fileName"`
+
+	// ContentType of the file. Defaults to application/octet-stream.
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+
+	// Template is a Go template rendered against the object that yields
+	// the file content.
+	Template string `json:"template"`
+}
+
+// ProxySpec configures the forward proxy of an HTTP action.
+type ProxySpec struct {
+	// URL of the proxy, for example "http://proxy.example.com:3128". It is
+	// used for http and https targets.
+	URL string `json:"url"`
+
+	// NoProxy lists the targets that are reached directly, in the format of
+	// NO_PROXY: host names, domain suffixes such as ".example.com", IP
+	// addresses and CIDR ranges, each optionally with a port.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+
+	// Credentials authenticate to the proxy with basic authentication.
+	// +optional
+	Credentials *BasicAuth `json:"credentials,omitempty"`
 }
 
 type TLSClientCertRef struct {
@@ -138,8 +868,34 @@ type TLSClientCertRef struct {
 	KeyKey string `json:"keyKey,omitempty"`
 }
 
+// TemplateSpec is the request body of an HTTP action: a template, form
+// fields, or form fields and files sent as multipart/form-data.
 type TemplateSpec struct {
-	Template string `json:"template"`
+	// Template is a Go template rendered against the object.
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// Form maps field names to Go templates. The rendered fields are sent
+	// as application/x-www-form-urlencoded, or as multipart/form-data
+	// together with files.
+	// +optional
+	Form map[string]string `json:"form,omitempty"`
+
+	// Files are sent as multipart/form-data together with form.
+	// +optional
+	Files []FormFileSpec `json:"files,omitempty"`
+
+	// ContentType is the Content-Type of the rendered template, for example
+	// application/xml. Defaults to application/json. Form and file bodies
+	// set their own.
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+
+	// Compression encodes the rendered body and sets Content-Encoding, for
+	// example for full objects sent to receivers with a request size limit.
+	// +kubebuilder:validation:Enum=gzip
+	// +optional
+	Compression string `json:"compression,omitempty"`
 }
 
 type JobSpec struct {
@@ -206,6 +962,11 @@ type JobVolumeMount struct {
 
 type ValueFrom struct {
 	SecretKeyRef *SecretKeyRef `json:"secretKeyRef,omitempty"`
+
+	// VaultRef reads the value from HashiCorp Vault on every use instead of
+	// from a Secret. Not supported for job env.
+	// +optional
+	VaultRef *VaultRef `json:"vaultRef,omitempty"`
 }
 
 type SecretKeyRef struct {
@@ -213,14 +974,195 @@ type SecretKeyRef struct {
 	Key  string `json:"key"`
 }
 
+// WaitSpec pauses an execution between two actions, for example to give a
+// downstream system time to index a resource before it is verified. The
+// event is requeued instead of holding a worker.
+type WaitSpec struct {
+	// Duration is a Go template rendered against the object that yields a
+	// duration such as "30s". It can read the outputs of earlier actions
+	// with {{ output "name" }}.
+	// +kubebuilder:validation:MinLength=1
+	Duration string `json:"duration"`
+}
+
+// WaitForConditionSpec polls a resource until one of its status conditions
+// or a JSONPath expression has the expected value, for example to notify
+// once the Job of an earlier action completed. The event is requeued between
+// polls instead of holding a worker.
+type WaitForConditionSpec struct {
+	// Job selects the Job created by the earlier job action with this name.
+	// +optional
+	Job string `json:"job,omitempty"`
+
+	// APIVersion and Kind select the resource to poll, for example "apps/v1"
+	// and "Deployment". Without job and kind, the object of the event is
+	// polled.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is a Go template rendered against the object that yields the name
+	// of the resource. It can read the outputs of earlier actions with
+	// {{ output "name" }}. Required with kind.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is a Go template like name. Defaults to the namespace of the
+	// object.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Condition is the type of a status condition that must have status, for
+	// example "Complete" or "Available".
+	// +optional
+	Condition string `json:"condition,omitempty"`
+
+	// Status is the status condition must have.
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	// +kubebuilder:default=True
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// FailureCondition is the type of a status condition that fails the
+	// action as soon as it is True, for example "Failed".
+	// +optional
+	FailureCondition string `json:"failureCondition,omitempty"`
+
+	// JSONPath is evaluated against the resource, for example
+	// "{.status.phase}". Without value, any result other than "" and
+	// "false" satisfies it.
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+
+	// Value is the result JSONPath must have.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// PollInterval is the time between two polls.
+	// +kubebuilder:default="10s"
+	// +optional
+	PollInterval string `json:"pollInterval,omitempty"`
+
+	// Timeout fails the action when the resource did not reach the state
+	// within it.
+	// +kubebuilder:default="10m"
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// VaultRef selects a key of a Vault secret. The operator logs in to Vault
+// with the Kubernetes auth method, using a token of a ServiceAccount in the
+// namespace of the ResourceAction.
+type VaultRef struct {
+	// Path of the secret, for example "secret/data/team-a/webhook" for a KV
+	// version 2 engine mounted at "secret".
+	Path string `json:"path"`
+
+	// Key within the data of the secret.
+	Key string `json:"key"`
+
+	// Role of the Kubernetes auth method to log in with.
+	Role string `json:"role"`
+
+	// AuthMount is the path the Kubernetes auth method is mounted at.
+	// +kubebuilder:default=kubernetes
+	// +optional
+	AuthMount string `json:"authMount,omitempty"`
+
+	// ServiceAccountName is the ServiceAccount whose token is used to log in.
+	// +kubebuilder:default=default
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Audience of the ServiceAccount token. It must match the audience of
+	// the Vault role.
+	// +kubebuilder:default=vault
+	// +optional
+	Audience string `json:"audience,omitempty"`
+}
+
 type ResourceActionStatus struct {
-	Executions []ExecutionRecord  `json:"executions,omitempty"`
-	LastError  string             `json:"lastError,omitempty"`
-	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	Executions       []ExecutionRecord       `json:"executions,omitempty"`
+	ScheduledActions []ScheduledActionStatus `json:"scheduledActions,omitempty"`
+	// CapturedResponses holds the values captured from the last response of
+	// each HTTP action with responseCapture target Status.
+	CapturedResponses []CapturedResponse `json:"capturedResponses,omitempty"`
+	// ActionStates reports the outcome of the last execution per action.
+	ActionStates []ActionState `json:"actionStates,omitempty"`
+	// WatchedKind is the Kind.group of the watched resource type.
+	WatchedKind string `json:"watchedKind,omitempty"`
+	// ActionCount is the number of actions in the spec.
+	ActionCount int `json:"actionCount,omitempty"`
+	// LastExecutionTime is the time of the most recent event-driven execution.
+	LastExecutionTime *metav1.Time       `json:"lastExecutionTime,omitempty"`
+	LastError         string             `json:"lastError,omitempty"`
+	Conditions        []metav1.Condition `json:"conditions,omitempty"`
+	// ObservedGeneration is the metadata.generation of the spec the operator
+	// last applied.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// CapturedResponse holds values captured from an HTTP response.
+type CapturedResponse struct {
+	ActionIndex  int               `json:"actionIndex"`
+	ActionName   string            `json:"actionName,omitempty"`
+	ResourceUID  string            `json:"resourceUID,omitempty"`
+	ResourceName string            `json:"resourceName,omitempty"`
+	CapturedAt   metav1.Time       `json:"capturedAt"`
+	Values       map[string]string `json:"values,omitempty"`
+}
+
+// ActionState reports the health of a single action of a ResourceAction,
+// keyed by its index in spec.actions.
+type ActionState struct {
+	ActionIndex int    `json:"actionIndex"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	// State is Ready when the last execution of the action succeeded,
+	// Failing when it returned an error, and PendingApproval while an
+	// execution waits for its approval.
+	// +kubebuilder:validation:Enum=Ready;Failing;PendingApproval
+	State   string `json:"state"`
+	Message string `json:"message,omitempty"`
+	// ConsecutiveFailures counts failed executions since the last success.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+
+	LastExecutionTime  *metav1.Time `json:"lastExecutionTime,omitempty"`
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	LastFailureTime    *metav1.Time `json:"lastFailureTime,omitempty"`
+}
+
+// ScheduledActionStatus reports the state of a registered cron action for a
+// single target resource.
+type ScheduledActionStatus struct {
+	ActionIndex int    `json:"actionIndex"`
+	ActionName  string `json:"actionName,omitempty"`
+	// ResourceUID is empty for schedules with scheduleScope "all".
+	ResourceUID       string `json:"resourceUID,omitempty"`
+	ResourceName      string `json:"resourceName,omitempty"`
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+	Event             string `json:"event,omitempty"`
+	Schedule          string `json:"schedule,omitempty"`
+	// MatchedResources is the number of objects the last run of a
+	// scheduleScope "all" action was executed against.
+	MatchedResources int `json:"matchedResources,omitempty"`
+
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+	// LastRunResult is one of Succeeded, Failed or Skipped.
+	LastRunResult string       `json:"lastRunResult,omitempty"`
+	LastRunError  string       `json:"lastRunError,omitempty"`
+	NextRunTime   *metav1.Time `json:"nextRunTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.status.watchedKind`
+// +kubebuilder:printcolumn:name="Actions",type=integer,JSONPath=`.status.actionCount`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Last Execution",type=date,JSONPath=`.status.lastExecutionTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 type ResourceAction struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -243,6 +1185,26 @@ type ExecutionRecord struct {
 	ResourceUID string      `json:"resourceUID"`
 	Event       string      `json:"event"`
 	ExecutedAt  metav1.Time `json:"executedAt"`
+	// CorrelationID identifies this execution in operator logs, the
+	// X-Correlation-ID header of HTTP actions and Job annotations.
+	CorrelationID string `json:"correlationID,omitempty"`
+
+	ResourceName       string `json:"resourceName,omitempty"`
+	ResourceNamespace  string `json:"resourceNamespace,omitempty"`
+	ResourceAPIVersion string `json:"resourceAPIVersion,omitempty"`
+	ResourceKind       string `json:"resourceKind,omitempty"`
+
+	// ActionIndex is the index of the last action executed for this record,
+	// which is the failing action when Result is Failed.
+	ActionIndex *int `json:"actionIndex,omitempty"`
+	// ActionName is spec.actions[].name of that action.
+	ActionName string `json:"actionName,omitempty"`
+	// Result is Succeeded, Failed, or PartiallyFailed when actions with
+	// continueOnError failed and the remaining actions ran.
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// FailedActions are the indices of the actions that failed.
+	FailedActions []int `json:"failedActions,omitempty"`
 
 	ActionCount       int                 `json:"actionCount,omitempty"`
 	Attempts          int                 `json:"attempts,omitempty"`
@@ -253,6 +1215,45 @@ type ExecutionRecord struct {
 	DurationMillis    int64               `json:"durationMillis,omitempty"`
 	LastHTTPStatus    int                 `json:"lastHttpStatus,omitempty"`
 	Job               *JobExecutionRecord `json:"job,omitempty"`
+	// Targets are the results per target of the last forEach action.
+	Targets []TargetRecord `json:"targets,omitempty"`
+	// BatchSize is the number of events spec.aggregation delivered together
+	// in this execution.
+	BatchSize int `json:"batchSize,omitempty"`
+	// DryRun marks an execution of spec.dryRun, which sent no requests. It
+	// does not count as an execution for spec.executionPolicy.
+	DryRun bool `json:"dryRun,omitempty"`
+	// Simulated marks an execution of spec.record or the operator record
+	// mode, which sent no requests. It does not count as an execution for
+	// spec.executionPolicy.
+	Simulated bool `json:"simulated,omitempty"`
+	// RenderedRequests are the requests spec.dryRun or spec.record rendered.
+	RenderedRequests []RenderedRequest `json:"renderedRequests,omitempty"`
+}
+
+// RenderedRequest is a request of an HTTP action that spec.dryRun rendered
+// without sending it.
+type RenderedRequest struct {
+	ActionIndex int    `json:"actionIndex"`
+	ActionName  string `json:"actionName,omitempty"`
+	Method      string `json:"method,omitempty"`
+	URL         string `json:"url,omitempty"`
+	// Headers are the request headers. Values read from Secrets or Vault
+	// and the Authorization header are masked.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is the uncompressed body, truncated to a few KiB; Truncated
+	// reports whether it was cut.
+	Body      string `json:"body,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// TargetRecord is the result of the request to one target of a forEach
+// action.
+type TargetRecord struct {
+	Item       string `json:"item"`
+	URL        string `json:"url,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
 }
 
 type JobExecutionRecord struct {
@@ -16,12 +16,17 @@ import (
 
 var _ admission.CustomValidator = &ResourceActionCustomValidator{}
 
-type ResourceActionCustomValidator struct{}
+// +kubebuilder:object:generate=false
+type ResourceActionCustomValidator struct {
+	// URLPolicy rejects ResourceActions that call hosts the operator does not
+	// allow. Nil allows every host.
+	URLPolicy *OperatorURLPolicy
+}
 
-func (r *ResourceAction) SetupWebhookWithManager(mgr ctrl.Manager) error {
+func (r *ResourceAction) SetupWebhookWithManager(mgr ctrl.Manager, urlPolicy *OperatorURLPolicy) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
-		WithValidator(&ResourceActionCustomValidator{}).
+		WithValidator(&ResourceActionCustomValidator{URLPolicy: urlPolicy}).
 		Complete()
 }
 
@@ -30,7 +35,7 @@ func (v *ResourceActionCustomValidator) ValidateCreate(_ context.Context, obj ru
 	if !ok {
 		return nil, fmt.Errorf("expected a ResourceAction object but got %T", obj)
 	}
-	return nil, validateResourceActionObject(ra)
+	return nil, v.validateResourceActionObject(ra)
 }
 
 func (v *ResourceActionCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
@@ -38,15 +43,24 @@ func (v *ResourceActionCustomValidator) ValidateUpdate(_ context.Context, _, new
 	if !ok {
 		return nil, fmt.Errorf("expected a ResourceAction object but got %T", newObj)
 	}
-	return nil, validateResourceActionObject(ra)
+	return nil, v.validateResourceActionObject(ra)
 }
 
 func (v *ResourceActionCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
 	return nil, nil
 }
 
-func validateResourceActionObject(ra *ResourceAction) error {
-	if err := ValidateResourceActionSpec(ra.Spec); err != nil {
+// ValidateSpec runs the checks of the webhook on spec and returns the first
+// error. raoctl validate uses it to check manifests offline.
+func (v *ResourceActionCustomValidator) ValidateSpec(spec ResourceActionSpec) error {
+	if err := ValidateResourceActionSpec(spec); err != nil {
+		return err
+	}
+	return v.URLPolicy.ValidateSpec(spec)
+}
+
+func (v *ResourceActionCustomValidator) validateResourceActionObject(ra *ResourceAction) error {
+	if err := v.ValidateSpec(ra.Spec); err != nil {
 		return apierrors.NewInvalid(
 			schema.GroupKind{Group: GroupVersion.Group, Kind: "ResourceAction"},
 			ra.Name,
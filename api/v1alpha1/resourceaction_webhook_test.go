@@ -49,3 +49,45 @@ func TestResourceActionValidateCreate_Invalid(t *testing.T) {
 		t.Fatalf("expected validation error, got nil")
 	}
 }
+
+func TestResourceActionValidateCreate_OperatorURLPolicy(t *testing.T) {
+	policy, err := NewOperatorURLPolicy([]string{`.*\.example\.com`}, []string{`internal\.example\.com`}, true)
+	if err != nil {
+		t.Fatalf("NewOperatorURLPolicy() error = %v", err)
+	}
+	v := &ResourceActionCustomValidator{URLPolicy: policy}
+	newRA := func(url string, urlPolicy *URLPolicySpec) *ResourceAction {
+		return &ResourceAction{
+			Spec: ResourceActionSpec{
+				Selector: ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Events:   []string{"Create"},
+				Actions:  []ActionSpec{{Type: "http", URL: url, URLPolicy: urlPolicy}},
+			},
+		}
+	}
+
+	if _, err := v.ValidateCreate(context.Background(), newRA("https://api.example.com/hook", nil)); err != nil {
+		t.Fatalf("expected allowed host to be valid, got %v", err)
+	}
+	for _, ra := range []*ResourceAction{
+		newRA("https://internal.example.com/hook", nil),
+		newRA("https://example.com.attacker.net/hook", nil),
+		newRA("https://api.example.com/hook", &URLPolicySpec{AllowUnsafeLocalTargets: true}),
+	} {
+		if _, err := v.ValidateCreate(context.Background(), ra); err == nil {
+			t.Fatalf("expected %s to be rejected by the operator URL policy", ra.Spec.Actions[0].URL)
+		}
+	}
+
+	withFallback := newRA("https://api.example.com/hook", nil)
+	withFallback.Spec.Actions[0].FallbackURLs = []string{"https://internal.example.com/hook"}
+	if _, err := v.ValidateCreate(context.Background(), withFallback); err == nil {
+		t.Fatalf("expected blocked fallback URL to be rejected by the operator URL policy")
+	}
+
+	withProxy := newRA("https://api.example.com/hook", nil)
+	withProxy.Spec.Actions[0].Proxy = &ProxySpec{URL: "http://internal.example.com:3128"}
+	if _, err := v.ValidateCreate(context.Background(), withProxy); err == nil {
+		t.Fatalf("expected blocked proxy URL to be rejected by the operator URL policy")
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SilenceSpec mutes the event-driven actions of the events it matches
+// between startsAt and endsAt.
+type SilenceSpec struct {
+	// Matchers select the events to silence. An event is silenced when any
+	// of them matches it.
+	// +kubebuilder:validation:MinItems=1
+	Matchers []SilenceMatcher `json:"matchers"`
+
+	// StartsAt is when the silence starts. Defaults to its creation.
+	// +optional
+	StartsAt *metav1.Time `json:"startsAt,omitempty"`
+
+	// EndsAt is when the silence expires.
+	EndsAt metav1.Time `json:"endsAt"`
+
+	// CreatedBy names who created the silence, for example the on-call
+	// engineer.
+	// +optional
+	CreatedBy string `json:"createdBy,omitempty"`
+
+	// Comment explains why the silence was created.
+	// +optional
+	Comment string `json:"comment,omitempty"`
+}
+
+// SilenceMatcher matches events by their object and ResourceAction. Every
+// field that is set must match; an empty field matches anything.
+type SilenceMatcher struct {
+	// Group, Version and Kind match the kind of the object, for example
+	// "apps", "" and "Deployment" for Deployments of every version.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// +optional
+	Version string `json:"version,omitempty"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Namespaces lists the namespaces of the objects.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Labels must all be set on the object with these values.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ResourceActions lists the ResourceActions whose actions are silenced
+	// as "<namespace>/<name>".
+	// +optional
+	ResourceActions []string `json:"resourceActions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Starts",type=string,JSONPath=`.spec.startsAt`
+// +kubebuilder:printcolumn:name="Ends",type=string,JSONPath=`.spec.endsAt`
+// +kubebuilder:printcolumn:name="Created By",type=string,JSONPath=`.spec.createdBy`
+// +kubebuilder:printcolumn:name="Comment",type=string,JSONPath=`.spec.comment`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Silence mutes the actions of matching events in every ResourceAction
+// until it expires, for example to quiet a noisy integration during an
+// incident without editing the ResourceActions.
+type Silence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SilenceSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SilenceList contains a list of Silence.
+type SilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Silence `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Silence{}, &SilenceList{})
+}
@@ -0,0 +1,38 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// successConditionCostLimit bounds the evaluation cost of a success
+// condition, so an expression over a large response body cannot stall the
+// executor.
+const successConditionCostLimit = 1_000_000
+
+// CompileSuccessCondition compiles the CEL expression of
+// spec.actions[].successCondition. The expression sees the variables status
+// (int), headers (map of lower-case header names to values) and body (the
+// response parsed as JSON, or the raw text if it is not JSON), and must
+// return a bool.
+func CompileSuccessCondition(expr string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("status", cel.IntType),
+		cel.Variable("headers", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("body", cel.DynType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	// Fields of body are only known at runtime, so dyn is checked when the
+	// expression is evaluated.
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, fmt.Errorf("must return a bool, got %s", t)
+	}
+	return env.Program(ast, cel.CostLimit(successConditionCostLimit))
+}
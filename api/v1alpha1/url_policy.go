@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// OperatorURLPolicy restricts the hosts that HTTP actions and HTTP dead
+// letters of every ResourceAction may call. Cluster administrators configure
+// it with operator flags; it applies in addition to spec.actions[].urlPolicy,
+// which can only narrow it further.
+// +kubebuilder:object:generate=false
+type OperatorURLPolicy struct {
+	allowed []*regexp.Regexp
+	blocked []*regexp.Regexp
+	// forbidUnsafeLocalTargets ignores urlPolicy.allowUnsafeLocalTargets.
+	forbidUnsafeLocalTargets bool
+}
+
+// NewOperatorURLPolicy compiles the host patterns. Each pattern must match the
+// whole lower-case host name of a URL. Empty patterns are ignored.
+func NewOperatorURLPolicy(allowedHosts, blockedHosts []string, forbidUnsafeLocalTargets bool) (*OperatorURLPolicy, error) {
+	allowed, err := compileHostPatterns(allowedHosts)
+	if err != nil {
+		return nil, fmt.Errorf("allowed hosts: %w", err)
+	}
+	blocked, err := compileHostPatterns(blockedHosts)
+	if err != nil {
+		return nil, fmt.Errorf("blocked hosts: %w", err)
+	}
+	return &OperatorURLPolicy{
+		allowed:                  allowed,
+		blocked:                  blocked,
+		forbidUnsafeLocalTargets: forbidUnsafeLocalTargets,
+	}, nil
+}
+
+func compileHostPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		re, err := regexp.Compile(`^(?:` + p + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// AllowsUnsafeLocalTargets reports whether urlPolicy.allowUnsafeLocalTargets
+// of a ResourceAction is honored. A nil policy allows it.
+func (p *OperatorURLPolicy) AllowsUnsafeLocalTargets() bool {
+	return p == nil || !p.forbidUnsafeLocalTargets
+}
+
+// CheckURL returns an error when the host of rawURL is blocked or not allowed.
+// A nil policy allows every URL.
+func (p *OperatorURLPolicy) CheckURL(rawURL string) error {
+	if p == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, re := range p.blocked {
+		if re.MatchString(host) {
+			return fmt.Errorf("host %q is blocked by the operator URL policy", host)
+		}
+	}
+	if len(p.allowed) == 0 {
+		return nil
+	}
+	for _, re := range p.allowed {
+		if re.MatchString(host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed by the operator URL policy", host)
+}
+
+// ValidateSpec checks the URLs of all HTTP actions, the teardown action,
+// proxies and HTTP dead letters of spec against the policy.
+func (p *OperatorURLPolicy) ValidateSpec(spec ResourceActionSpec) error {
+	if p == nil {
+		return nil
+	}
+	for i, action := range spec.Actions {
+		if err := p.validateAction(fmt.Sprintf("actions[%d]", i), action); err != nil {
+			return err
+		}
+	}
+	if spec.Teardown != nil {
+		return p.validateAction("teardown", *spec.Teardown)
+	}
+	return nil
+}
+
+func (p *OperatorURLPolicy) validateAction(path string, action ActionSpec) error {
+	switch {
+	case action.Type == "http" && action.ForEach != nil:
+		// The URLs are rendered per item and checked when they are called.
+		if policy := action.URLPolicy; policy != nil && policy.AllowUnsafeLocalTargets && !p.AllowsUnsafeLocalTargets() {
+			return fmt.Errorf("%s.urlPolicy.allowUnsafeLocalTargets is forbidden by the operator URL policy", path)
+		}
+	case action.Type == "http":
+		if err := p.validateTarget(path, action.URL, action.URLPolicy); err != nil {
+			return err
+		}
+		for j, fallback := range action.FallbackURLs {
+			if err := p.CheckURL(fallback); err != nil {
+				return fmt.Errorf("%s.fallbackURLs[%d]: %w", path, j, err)
+			}
+		}
+	}
+	if proxy := action.Proxy; proxy != nil {
+		if err := p.CheckURL(proxy.URL); err != nil {
+			return fmt.Errorf("%s.proxy.url: %w", path, err)
+		}
+	}
+	if dl := action.DeadLetter; dl != nil && dl.Type == "HTTP" {
+		if err := p.validateTarget(path+".deadLetter", dl.URL, dl.URLPolicy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *OperatorURLPolicy) validateTarget(path, rawURL string, policy *URLPolicySpec) error {
+	if policy != nil && policy.AllowUnsafeLocalTargets && !p.AllowsUnsafeLocalTargets() {
+		return fmt.Errorf("%s.urlPolicy.allowUnsafeLocalTargets is forbidden by the operator URL policy", path)
+	}
+	if err := p.CheckURL(rawURL); err != nil {
+		return fmt.Errorf("%s.url: %w", path, err)
+	}
+	return nil
+}
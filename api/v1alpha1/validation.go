@@ -1,11 +1,21 @@
 package v1alpha1
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"mime"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+	"text/template"
 	"time"
+
+	"golang.org/x/net/http/httpguts"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 // ValidateResourceActionSpec performs runtime-safe validation for fields that
@@ -17,9 +27,74 @@ func ValidateResourceActionSpec(spec ResourceActionSpec) error {
 	if len(spec.Events) == 0 {
 		return fmt.Errorf("at least one event is required")
 	}
+	if containsSpecEvent(spec.Events, "Webhook") &&
+		(spec.Selector.Group != GroupVersion.Group || spec.Selector.Kind != "WebhookSource") {
+		return fmt.Errorf("event %q requires selector kind WebhookSource of group %s", "Webhook", GroupVersion.Group)
+	}
 	if len(spec.Actions) == 0 {
 		return fmt.Errorf("at least one action is required")
 	}
+	if spec.HistoryLimit != nil && *spec.HistoryLimit < 1 {
+		return fmt.Errorf("historyLimit must be >= 1")
+	}
+	switch spec.HistoryMode {
+	case "", "Status", "ActionExecution":
+	default:
+		return fmt.Errorf("historyMode must be \"Status\" or \"ActionExecution\"")
+	}
+	if spec.HistoryTTL != "" {
+		if _, err := time.ParseDuration(spec.HistoryTTL); err != nil {
+			return fmt.Errorf("invalid historyTTL: %w", err)
+		}
+	}
+
+	if err := validateDegraded(spec.Degraded); err != nil {
+		return err
+	}
+
+	switch spec.ExecutionPolicy {
+	case "", "OncePerObject", "OncePerGeneration", "EveryEvent":
+	default:
+		return fmt.Errorf("executionPolicy must be \"OncePerObject\", \"OncePerGeneration\" or \"EveryEvent\"")
+	}
+	if spec.NameReuse != nil {
+		switch spec.NameReuse.Policy {
+		case "", "New", "Suppress":
+		default:
+			return fmt.Errorf("nameReuse.policy must be \"New\" or \"Suppress\"")
+		}
+		if spec.NameReuse.Window != "" {
+			if d, err := time.ParseDuration(spec.NameReuse.Window); err != nil || d <= 0 {
+				return fmt.Errorf("nameReuse.window must be a positive duration")
+			}
+		}
+	}
+	if spec.MaxExecutionsPerObject != nil && *spec.MaxExecutionsPerObject < 1 {
+		return fmt.Errorf("maxExecutionsPerObject must be >= 1")
+	}
+	if spec.MaxExecutionsPerHour != nil && *spec.MaxExecutionsPerHour < 1 {
+		return fmt.Errorf("maxExecutionsPerHour must be >= 1")
+	}
+	if err := validateSuppressionWindows(spec.SuppressionWindows); err != nil {
+		return err
+	}
+	if spec.Debounce != "" {
+		d, err := time.ParseDuration(spec.Debounce)
+		if err != nil {
+			return fmt.Errorf("invalid debounce: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("debounce must not be negative")
+		}
+	}
+	for i, ns := range spec.WatchNamespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("watchNamespaces[%d] %q is not a valid namespace: %s", i, ns, strings.Join(errs, "; "))
+		}
+	}
+	if spec.ClusterRef != nil && spec.ClusterRef.SecretName == "" {
+		return fmt.Errorf("clusterRef.secretName is required")
+	}
 
 	if spec.Filters != nil {
 		if spec.Filters.NameRegex != "" {
@@ -32,6 +107,19 @@ func ValidateResourceActionSpec(spec ResourceActionSpec) error {
 				return fmt.Errorf("invalid filters.namespaceRegex: %w", err)
 			}
 		}
+		if spec.Filters.Alert != nil {
+			if !containsSpecEvent(spec.Events, "Webhook") {
+				return fmt.Errorf("filters.alert requires event %q", "Webhook")
+			}
+			switch spec.Filters.Alert.Status {
+			case "", "firing", "resolved":
+			default:
+				return fmt.Errorf("filters.alert.status must be \"firing\" or \"resolved\"")
+			}
+		}
+		if spec.Filters.GitHub != nil && !containsSpecEvent(spec.Events, "Webhook") {
+			return fmt.Errorf("filters.github requires event %q", "Webhook")
+		}
 		if len(spec.Filters.LabelChanges) > 0 {
 			if !containsSpecEvent(spec.Events, "Update") {
 				return fmt.Errorf("filters.labelChanges requires event %q", "Update")
@@ -42,9 +130,29 @@ func ValidateResourceActionSpec(spec ResourceActionSpec) error {
 				}
 			}
 		}
+		if spec.Filters.ChangeType != "" {
+			if !containsSpecEvent(spec.Events, "Update") {
+				return fmt.Errorf("filters.changeType requires event %q", "Update")
+			}
+			switch spec.Filters.ChangeType {
+			case "Spec", "Status", "Metadata", "Labels":
+			default:
+				return fmt.Errorf("filters.changeType must be one of Spec, Status, Metadata or Labels")
+			}
+		}
 	}
 
+	names := make(map[string]int, len(spec.Actions))
 	for i, action := range spec.Actions {
+		if action.Name != "" {
+			if errs := validation.IsDNS1123Label(action.Name); len(errs) > 0 {
+				return fmt.Errorf("actions[%d].name %q is invalid: %s", i, action.Name, strings.Join(errs, "; "))
+			}
+			if first, ok := names[action.Name]; ok {
+				return fmt.Errorf("actions[%d].name %q is already used by actions[%d]", i, action.Name, first)
+			}
+			names[action.Name] = i
+		}
 		if action.Mode == "cron" || action.Mode == "schedule" {
 			if action.Schedule == "" {
 				return fmt.Errorf("actions[%d].schedule is required for mode %q", i, action.Mode)
@@ -53,6 +161,21 @@ func ValidateResourceActionSpec(spec ResourceActionSpec) error {
 				return fmt.Errorf("actions[%d].schedule invalid duration: %w", i, err)
 			}
 		}
+		if err := validateScheduleScope(i, action, spec.Filters); err != nil {
+			return err
+		}
+		if err := validateMissedRunPolicy(i, action); err != nil {
+			return err
+		}
+		if err := validateDeadLetter(i, action.DeadLetter); err != nil {
+			return err
+		}
+		if err := validateApproval(i, action); err != nil {
+			return err
+		}
+		if err := validateRecordResult(i, action, spec.Events); err != nil {
+			return err
+		}
 		switch action.Type {
 		case "http":
 			if err := validateHTTPAction(i, action); err != nil {
@@ -62,11 +185,586 @@ func ValidateResourceActionSpec(spec ResourceActionSpec) error {
 			if err := validateJobAction(i, action); err != nil {
 				return err
 			}
+		case "wait":
+			if err := validateWaitAction(i, action); err != nil {
+				return err
+			}
+		case "waitForCondition":
+			if err := validateWaitForConditionAction(i, action, spec.Actions[:i]); err != nil {
+				return err
+			}
 		default:
-			return fmt.Errorf("actions[%d].type must be \"http\" or \"job\"", i)
+			return fmt.Errorf("actions[%d].type must be \"http\", \"job\", \"wait\" or \"waitForCondition\"", i)
+		}
+		if action.Type != "wait" && action.Wait != nil {
+			return fmt.Errorf("actions[%d].wait is only allowed for type %q", i, "wait")
+		}
+		if action.Type != "waitForCondition" && action.WaitForCondition != nil {
+			return fmt.Errorf("actions[%d].waitForCondition is only allowed for type %q", i, "waitForCondition")
+		}
+		if err := validateTemplates(i, action); err != nil {
+			return err
 		}
 	}
+	for i, action := range spec.Actions {
+		if err := validateHandlers(i, action, spec.Actions, names); err != nil {
+			return err
+		}
+	}
+	if err := validateDependencies(spec.Actions, names); err != nil {
+		return err
+	}
+	if err := validateAggregation(spec); err != nil {
+		return err
+	}
+	if spec.Teardown != nil {
+		if err := validateTeardown(*spec.Teardown); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateDegraded checks spec.degraded.
+func validateDegraded(degraded *DegradedSpec) error {
+	if degraded == nil {
+		return nil
+	}
+	if degraded.StaleAfter == "" && degraded.FailureThreshold == nil {
+		return fmt.Errorf("degraded needs staleAfter or failureThreshold")
+	}
+	if degraded.StaleAfter != "" {
+		d, err := time.ParseDuration(degraded.StaleAfter)
+		if err != nil {
+			return fmt.Errorf("invalid degraded.staleAfter: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("degraded.staleAfter must be positive")
+		}
+	}
+	if degraded.FailureThreshold != nil && *degraded.FailureThreshold < 1 {
+		return fmt.Errorf("degraded.failureThreshold must be >= 1")
+	}
+	return nil
+}
+
+// maxSuppressionWindow bounds spec.suppressionWindows[].duration, so the
+// engine only looks back a week for the start of a window.
+const maxSuppressionWindow = 168 * time.Hour
+
+// validateSuppressionWindows checks spec.suppressionWindows.
+func validateSuppressionWindows(windows []SuppressionWindowSpec) error {
+	for i, window := range windows {
+		if _, err := ParseCronSchedule(window.Schedule); err != nil {
+			return fmt.Errorf("invalid suppressionWindows[%d].schedule: %w", i, err)
+		}
+		d, err := time.ParseDuration(window.Duration)
+		if err != nil || d <= 0 || d > maxSuppressionWindow {
+			return fmt.Errorf("suppressionWindows[%d].duration must be a positive duration of at most %s", i, maxSuppressionWindow)
+		}
+		if window.TimeZone != "" {
+			if _, err := time.LoadLocation(window.TimeZone); err != nil {
+				return fmt.Errorf("invalid suppressionWindows[%d].timeZone: %w", i, err)
+			}
+		}
+		switch window.Mode {
+		case "", "Drop", "Queue":
+		default:
+			return fmt.Errorf("suppressionWindows[%d].mode must be \"Drop\" or \"Queue\"", i)
+		}
+	}
+	return nil
+}
 
+// validateAggregation checks spec.aggregation. A batch is delivered as one
+// JSON array per action, so every action that runs for events must be an
+// HTTP action with a single request and a JSON body. Group labels are
+// templates like request bodies.
+func validateAggregation(spec ResourceActionSpec) error {
+	aggregation := spec.Aggregation
+	if aggregation == nil {
+		return nil
+	}
+	if aggregation.Window == "" && aggregation.MaxEvents == 0 {
+		return fmt.Errorf("aggregation requires window or maxEvents")
+	}
+	if aggregation.Window != "" {
+		if d, err := time.ParseDuration(aggregation.Window); err != nil || d <= 0 {
+			return fmt.Errorf("aggregation.window must be a positive duration")
+		}
+	}
+	if aggregation.MaxEvents < 0 || aggregation.MaxEvents > 1000 {
+		return fmt.Errorf("aggregation.maxEvents must be between 1 and 1000")
+	}
+	names := make([]string, 0, len(aggregation.GroupBy))
+	for name := range aggregation.GroupBy {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return fmt.Errorf("aggregation.groupBy label %q is invalid: %s", name, strings.Join(errs, "; "))
+		}
+		if _, err := template.New(name).Funcs(templateFuncs).Parse(aggregation.GroupBy[name]); err != nil {
+			return fmt.Errorf("aggregation.groupBy[%s] is not a valid template: %w", name, err)
+		}
+	}
+	if aggregation.ResolveAfter != "" {
+		d, err := time.ParseDuration(aggregation.ResolveAfter)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("aggregation.resolveAfter must be a positive duration")
+		}
+		if window, err := time.ParseDuration(aggregation.Window); err == nil && d <= window {
+			return fmt.Errorf("aggregation.resolveAfter must be longer than aggregation.window")
+		}
+	}
+	for i, action := range spec.Actions {
+		if action.Mode == "cron" || action.Mode == "schedule" {
+			continue
+		}
+		if action.Type != "http" {
+			return fmt.Errorf("actions[%d] must have type %q with aggregation", i, "http")
+		}
+		if action.ForEach != nil || action.Approval != nil {
+			return fmt.Errorf("actions[%d].forEach and approval are not allowed with aggregation", i)
+		}
+		if action.Body != nil && (len(action.Body.Form) > 0 || len(action.Body.Files) > 0) {
+			return fmt.Errorf("actions[%d].body.form and files are not allowed with aggregation", i)
+		}
+	}
+	return nil
+}
+
+// validateTeardown validates spec.teardown like an action. It runs once and
+// alone, so schedules, response capture and outputs are not allowed.
+func validateTeardown(action ActionSpec) error {
+	if action.Mode != "" && action.Mode != "once" {
+		return fmt.Errorf("teardown.mode must be \"once\"")
+	}
+	if action.Schedule != "" {
+		return fmt.Errorf("teardown.schedule is not allowed")
+	}
+	if action.ResponseCapture != nil {
+		return fmt.Errorf("teardown.responseCapture is not allowed")
+	}
+	if len(action.Outputs) > 0 {
+		return fmt.Errorf("teardown.outputs is not allowed")
+	}
+	if len(action.OnSuccess) > 0 || len(action.OnFailure) > 0 || len(action.Escalation) > 0 {
+		return fmt.Errorf("teardown.onSuccess, teardown.onFailure and teardown.escalation are not allowed")
+	}
+	if action.Wait != nil || action.WaitForCondition != nil {
+		return fmt.Errorf("teardown.wait and teardown.waitForCondition are not allowed")
+	}
+	if action.Approval != nil {
+		return fmt.Errorf("teardown.approval is not allowed")
+	}
+	if len(action.DependsOn) > 0 {
+		return fmt.Errorf("teardown.dependsOn is not allowed")
+	}
+	if action.RecordResultOn != "" || len(action.ResultAnnotations) > 0 {
+		return fmt.Errorf("teardown.recordResultOn and teardown.resultAnnotations are not allowed")
+	}
+	var err error
+	switch action.Type {
+	case "http":
+		err = validateHTTPAction(0, action)
+	case "job":
+		err = validateJobAction(0, action)
+	default:
+		return fmt.Errorf("teardown.type must be \"http\" or \"job\"")
+	}
+	if err == nil {
+		err = validateDeadLetter(0, action.DeadLetter)
+	}
+	if err == nil {
+		err = validateTemplates(0, action)
+	}
+	if err != nil {
+		// The action validators report errors for actions[0].
+		return errors.New(strings.Replace(err.Error(), "actions[0]", "teardown", 1))
+	}
+	return nil
+}
+
+// validateHandlers checks that onSuccess, onFailure and escalation name
+// actions with mode handler. Handlers cannot have handlers themselves, so they
+// never run in a loop. An escalation names every level once.
+func validateHandlers(i int, action ActionSpec, actions []ActionSpec, names map[string]int) error {
+	if action.Mode == "handler" && (len(action.OnSuccess) > 0 || len(action.OnFailure) > 0 || len(action.Escalation) > 0) {
+		return fmt.Errorf("actions[%d] with mode %q cannot have onSuccess, onFailure or escalation", i, "handler")
+	}
+	for j, name := range action.Escalation {
+		if slices.Contains(action.Escalation[:j], name) {
+			return fmt.Errorf("actions[%d].escalation: action %q is named more than once", i, name)
+		}
+	}
+	for _, refs := range []struct {
+		field    string
+		handlers []string
+	}{{"onSuccess", action.OnSuccess}, {"onFailure", action.OnFailure}, {"escalation", action.Escalation}} {
+		for _, name := range refs.handlers {
+			j, ok := names[name]
+			if !ok {
+				return fmt.Errorf("actions[%d].%s: no action is named %q", i, refs.field, name)
+			}
+			if actions[j].Mode != "handler" {
+				return fmt.Errorf("actions[%d].%s: action %q must have mode %q", i, refs.field, name, "handler")
+			}
+		}
+	}
+	return nil
+}
+
+// validateDependencies checks spec.actions[].dependsOn. Dependencies must name
+// other actions that run for events and must not form a cycle. Actions of a
+// graph run in parallel and cannot pause, so wait and waitForCondition actions
+// and approvals are not allowed.
+func validateDependencies(actions []ActionSpec, names map[string]int) error {
+	graph := false
+	for i, action := range actions {
+		if len(action.DependsOn) == 0 {
+			continue
+		}
+		graph = true
+		if action.Mode != "" && action.Mode != "once" {
+			return fmt.Errorf("actions[%d].dependsOn requires mode %q", i, "once")
+		}
+		for _, name := range action.DependsOn {
+			j, ok := names[name]
+			if !ok {
+				return fmt.Errorf("actions[%d].dependsOn: no action is named %q", i, name)
+			}
+			if j == i {
+				return fmt.Errorf("actions[%d].dependsOn must not name the action itself", i)
+			}
+			if mode := actions[j].Mode; mode != "" && mode != "once" {
+				return fmt.Errorf("actions[%d].dependsOn: action %q must have mode %q", i, name, "once")
+			}
+		}
+	}
+	if !graph {
+		return nil
+	}
+	for i, action := range actions {
+		if action.Type == "wait" || action.Type == "waitForCondition" || action.Approval != nil {
+			return fmt.Errorf("actions[%d]: wait and waitForCondition actions and approval cannot be combined with dependsOn", i)
+		}
+	}
+
+	// 0 unvisited, 1 on the current path, 2 done.
+	state := make([]int, len(actions))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case 1:
+			return fmt.Errorf("actions[%d].dependsOn forms a cycle", i)
+		case 2:
+			return nil
+		}
+		state[i] = 1
+		for _, name := range actions[i].DependsOn {
+			if err := visit(names[name]); err != nil {
+				return err
+			}
+		}
+		state[i] = 2
+		return nil
+	}
+	for i := range actions {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateFuncs stubs the functions the operator adds to templates, so
+// templates can be parsed without rendering them.
+var templateFuncs = template.FuncMap{
+	"output":          func(string) (string, error) { return "", nil },
+	"triggeredBy":     func() string { return "" },
+	"failureReason":   func() string { return "" },
+	"failedAttempts":  func() int { return 0 },
+	"failureStatus":   func() int { return 0 },
+	"escalationLevel": func() int { return 0 },
+	"item":            func() (string, error) { return "", nil },
+	"changeTypes":     func() []string { return nil },
+	"changed":         func(string) bool { return false },
+	"configMapValue": func(string, string, string) (string, error) {
+		return "", nil
+	},
+	"secretValue": func(string, string) (string, error) { return "", nil },
+	"executedAt":  func() string { return "" },
+	"groupStatus": func() string { return "" },
+	"groupLabels": func() map[string]string { return nil },
+	"fingerprint": func() string { return "" },
+}
+
+// validateTemplates parses the Go templates of an action, so syntax errors
+// and unknown functions are rejected before an event renders them.
+func validateTemplates(i int, action ActionSpec) error {
+	templates := map[string]string{}
+	if action.Body != nil {
+		templates["body.template"] = action.Body.Template
+		for name, text := range action.Body.Form {
+			templates["body.form."+name] = text
+		}
+		for j, file := range action.Body.Files {
+			templates[fmt.Sprintf("body.files[%d].template", j)] = file.Template
+		}
+	}
+	if action.ForEach != nil {
+		templates["forEach.items"] = action.ForEach.Items
+		templates["forEach.url"] = action.ForEach.URL
+	}
+	if action.Wait != nil {
+		templates["wait.duration"] = action.Wait.Duration
+	}
+	if action.WaitForCondition != nil {
+		templates["waitForCondition.name"] = action.WaitForCondition.Name
+		templates["waitForCondition.namespace"] = action.WaitForCondition.Namespace
+	}
+	fields := make([]string, 0, len(templates))
+	for field := range templates {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	for _, field := range fields {
+		if templates[field] == "" {
+			continue
+		}
+		if _, err := template.New(field).Funcs(templateFuncs).Parse(templates[field]); err != nil {
+			return fmt.Errorf("actions[%d].%s is not a valid template: %w", i, field, err)
+		}
+	}
+	return nil
+}
+
+// validateWaitAction validates an action of type wait. It only pauses the
+// actions that run for an event, so it takes no request, schedule or
+// handlers.
+func validateWaitAction(i int, action ActionSpec) error {
+	if action.Wait == nil || strings.TrimSpace(action.Wait.Duration) == "" {
+		return fmt.Errorf("actions[%d].wait.duration is required for type %q", i, "wait")
+	}
+	if !strings.Contains(action.Wait.Duration, "{{") {
+		// Templated durations are checked when they are rendered.
+		if d, err := time.ParseDuration(action.Wait.Duration); err != nil || d < 0 {
+			return fmt.Errorf("actions[%d].wait.duration must be a non-negative duration", i)
+		}
+	}
+	return validatePausingAction(i, action)
+}
+
+// validateWaitForConditionAction validates an action of type
+// waitForCondition. Like a wait action it only pauses the actions that run for
+// an event. A job must name an earlier job action, whose Job it polls.
+func validateWaitForConditionAction(i int, action ActionSpec, earlier []ActionSpec) error {
+	spec := action.WaitForCondition
+	if spec == nil {
+		return fmt.Errorf("actions[%d].waitForCondition is required for type %q", i, "waitForCondition")
+	}
+	if spec.Condition == "" && spec.JSONPath == "" {
+		return fmt.Errorf("actions[%d].waitForCondition needs condition or jsonPath", i)
+	}
+	if spec.Value != "" && spec.JSONPath == "" {
+		return fmt.Errorf("actions[%d].waitForCondition.value requires jsonPath", i)
+	}
+	if spec.JSONPath != "" {
+		if err := jsonpath.New("waitForCondition").Parse(spec.JSONPath); err != nil {
+			return fmt.Errorf("actions[%d].waitForCondition.jsonPath is invalid: %w", i, err)
+		}
+	}
+	switch {
+	case spec.Job != "" && (spec.Kind != "" || spec.APIVersion != "" || spec.Name != "" || spec.Namespace != ""):
+		return fmt.Errorf("actions[%d].waitForCondition.job cannot be combined with apiVersion, kind, name and namespace", i)
+	case spec.Job != "":
+		j := slices.IndexFunc(earlier, func(a ActionSpec) bool { return a.Name == spec.Job })
+		if j < 0 || earlier[j].Type != "job" || (earlier[j].Mode != "" && earlier[j].Mode != "once") {
+			return fmt.Errorf("actions[%d].waitForCondition.job must name an earlier job action with mode %q", i, "once")
+		}
+	case spec.Kind != "":
+		if spec.APIVersion == "" || spec.Name == "" {
+			return fmt.Errorf("actions[%d].waitForCondition.kind requires apiVersion and name", i)
+		}
+		if _, err := schema.ParseGroupVersion(spec.APIVersion); err != nil {
+			return fmt.Errorf("actions[%d].waitForCondition.apiVersion is invalid: %w", i, err)
+		}
+	case spec.APIVersion != "" || spec.Name != "" || spec.Namespace != "":
+		return fmt.Errorf("actions[%d].waitForCondition.apiVersion, name and namespace require kind", i)
+	}
+	if spec.PollInterval != "" {
+		if d, err := time.ParseDuration(spec.PollInterval); err != nil || d < time.Second {
+			return fmt.Errorf("actions[%d].waitForCondition.pollInterval must be a duration of at least 1s", i)
+		}
+	}
+	if spec.Timeout != "" {
+		if d, err := time.ParseDuration(spec.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("actions[%d].waitForCondition.timeout must be a positive duration", i)
+		}
+	}
+	return validatePausingAction(i, action)
+}
+
+// validatePausingAction rejects the request, schedule and handlers of the
+// wait and waitForCondition actions.
+func validatePausingAction(i int, action ActionSpec) error {
+	if action.Mode != "" && action.Mode != "once" {
+		return fmt.Errorf("actions[%d] of type %q must have mode %q", i, action.Type, "once")
+	}
+	if action.URL != "" || len(action.FallbackURLs) > 0 || action.ForEach != nil || action.Job != nil {
+		return fmt.Errorf("actions[%d].url, fallbackURLs, forEach and job are not allowed for type %q", i, action.Type)
+	}
+	if len(action.Headers) > 0 || action.Body != nil || action.Auth != nil {
+		return fmt.Errorf("actions[%d].headers, body and auth are not allowed for type %q", i, action.Type)
+	}
+	if action.ExpectedResponse != nil || action.SuccessCondition != "" || action.ResponseCapture != nil || len(action.Outputs) > 0 {
+		return fmt.Errorf("actions[%d].expectedResponse, successCondition, responseCapture and outputs are not allowed for type %q", i, action.Type)
+	}
+	if action.Retry != nil || action.DeadLetter != nil {
+		return fmt.Errorf("actions[%d].retry and deadLetter are not allowed for type %q", i, action.Type)
+	}
+	if len(action.OnSuccess) > 0 || len(action.OnFailure) > 0 || len(action.Escalation) > 0 {
+		return fmt.Errorf("actions[%d].onSuccess, onFailure and escalation are not allowed for type %q", i, action.Type)
+	}
+	return nil
+}
+
+// validateRecordResult checks spec.actions[].recordResultOn and
+// resultAnnotations. Results are only written back to the objects of Create
+// and Update events, which still exist.
+func validateRecordResult(i int, action ActionSpec, events []string) error {
+	if action.RecordResultOn == "" {
+		if len(action.ResultAnnotations) > 0 {
+			return fmt.Errorf("actions[%d].resultAnnotations requires recordResultOn", i)
+		}
+		return nil
+	}
+	if action.RecordResultOn != "object" {
+		return fmt.Errorf("actions[%d].recordResultOn must be %q", i, "object")
+	}
+	if action.Type == "wait" || action.Type == "waitForCondition" || (action.Mode != "" && action.Mode != "once" && action.Mode != "handler") {
+		return fmt.Errorf("actions[%d].recordResultOn is only allowed for http and job actions that run for events", i)
+	}
+	if !containsSpecEvent(events, "Create") && !containsSpecEvent(events, "Update") {
+		return fmt.Errorf("actions[%d].recordResultOn requires event %q or %q", i, "Create", "Update")
+	}
+	keys := make([]string, 0, len(action.ResultAnnotations))
+	for key := range action.ResultAnnotations {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("actions[%d].resultAnnotations key %q is invalid: %s", i, key, strings.Join(errs, "; "))
+		}
+		if !IsResultAnnotation(key) {
+			return fmt.Errorf("actions[%d].resultAnnotations key %q must have a prefix ending in %q", i, key, "."+GroupVersion.Group)
+		}
+		if _, err := template.New(key).Funcs(templateFuncs).Parse(action.ResultAnnotations[key]); err != nil {
+			return fmt.Errorf("actions[%d].resultAnnotations[%s] is not a valid template: %w", i, key, err)
+		}
+	}
+	return nil
+}
+
+// IsResultAnnotation reports whether key is in the domain of result
+// annotations, a subdomain of the API group such as
+// "tickets.ops.yusaozdemir.de/url". Updates that only change these
+// annotations are write-backs of results and do not run ResourceActions.
+func IsResultAnnotation(key string) bool {
+	prefix, _, ok := strings.Cut(key, "/")
+	return ok && strings.HasSuffix(prefix, "."+GroupVersion.Group)
+}
+
+// validateApproval checks spec.actions[].approval. Only actions that run for
+// events can pause for an approval.
+func validateApproval(i int, action ActionSpec) error {
+	if action.Approval == nil {
+		return nil
+	}
+	if action.Mode != "" && action.Mode != "once" {
+		return fmt.Errorf("actions[%d].approval requires mode %q", i, "once")
+	}
+	if action.Type == "wait" || action.Type == "waitForCondition" {
+		return fmt.Errorf("actions[%d].approval is not allowed for type %q", i, action.Type)
+	}
+	if action.Approval.Timeout != "" {
+		if d, err := time.ParseDuration(action.Approval.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("actions[%d].approval.timeout must be a positive duration", i)
+		}
+	}
+	return nil
+}
+
+func validateScheduleScope(i int, action ActionSpec, filters *FilterSpec) error {
+	switch action.ScheduleScope {
+	case "", "event":
+		return nil
+	case "all":
+		if action.Mode != "cron" && action.Mode != "schedule" {
+			return fmt.Errorf("actions[%d].scheduleScope %q requires mode %q", i, action.ScheduleScope, "cron")
+		}
+		if filters != nil && len(filters.LabelChanges) > 0 {
+			return fmt.Errorf("actions[%d].scheduleScope %q cannot be combined with filters.labelChanges", i, action.ScheduleScope)
+		}
+		return nil
+	default:
+		return fmt.Errorf("actions[%d].scheduleScope must be \"event\" or \"all\"", i)
+	}
+}
+
+func validateMissedRunPolicy(i int, action ActionSpec) error {
+	switch action.MissedRunPolicy {
+	case "", "Skip", "RunOnce":
+	default:
+		return fmt.Errorf("actions[%d].missedRunPolicy must be \"Skip\" or \"RunOnce\"", i)
+	}
+	if action.StartingDeadline != "" {
+		if _, err := time.ParseDuration(action.StartingDeadline); err != nil {
+			return fmt.Errorf("actions[%d].startingDeadline invalid duration: %w", i, err)
+		}
+	}
+	if action.ExecutionDeadline != "" {
+		d, err := time.ParseDuration(action.ExecutionDeadline)
+		if err != nil {
+			return fmt.Errorf("actions[%d].executionDeadline invalid duration: %w", i, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("actions[%d].executionDeadline must be positive", i)
+		}
+	}
+	return nil
+}
+
+func validateDeadLetter(i int, deadLetter *DeadLetterSpec) error {
+	if deadLetter == nil {
+		return nil
+	}
+	switch deadLetter.Type {
+	case "HTTP":
+		if deadLetter.URL == "" {
+			return fmt.Errorf("actions[%d].deadLetter.url is required for type %q", i, deadLetter.Type)
+		}
+		if err := validateActionURL(deadLetter.URL); err != nil {
+			return fmt.Errorf("actions[%d].deadLetter.url: %w", i, err)
+		}
+	case "ConfigMap":
+		if errs := validation.IsDNS1123Subdomain(deadLetter.ConfigMapName); len(errs) > 0 {
+			return fmt.Errorf("actions[%d].deadLetter.configMapName invalid: %s", i, strings.Join(errs, ", "))
+		}
+	case "ActionExecution":
+	default:
+		return fmt.Errorf("actions[%d].deadLetter.type must be HTTP, ConfigMap or ActionExecution", i)
+	}
+	if deadLetter.Type != "HTTP" && (deadLetter.URL != "" || deadLetter.URLPolicy != nil) {
+		return fmt.Errorf("actions[%d].deadLetter.url is only allowed for type %q", i, "HTTP")
+	}
+	if deadLetter.Type != "ConfigMap" && deadLetter.ConfigMapName != "" {
+		return fmt.Errorf("actions[%d].deadLetter.configMapName is only allowed for type %q", i, "ConfigMap")
+	}
 	return nil
 }
 
@@ -74,17 +772,108 @@ func validateHTTPAction(i int, action ActionSpec) error {
 	if action.Job != nil {
 		return fmt.Errorf("actions[%d].job is only allowed for type %q", i, action.Type)
 	}
-	if action.URL == "" {
-		return fmt.Errorf("actions[%d].url is required", i)
+	if action.ForEach != nil {
+		if err := validateForEach(i, action); err != nil {
+			return err
+		}
+	} else {
+		if action.URL == "" {
+			return fmt.Errorf("actions[%d].url is required", i)
+		}
+		if err := validateActionURL(action.URL); err != nil {
+			return fmt.Errorf("actions[%d].url: %w", i, err)
+		}
+	}
+	for j, fallback := range action.FallbackURLs {
+		if err := validateActionURL(fallback); err != nil {
+			return fmt.Errorf("actions[%d].fallbackURLs[%d]: %w", i, j, err)
+		}
+	}
+	if hedging := action.Hedging; hedging != nil {
+		if len(action.FallbackURLs) == 0 {
+			return fmt.Errorf("actions[%d].hedging requires fallbackURLs", i)
+		}
+		if hedging.Delay != "" {
+			if d, err := time.ParseDuration(hedging.Delay); err != nil || d < 0 {
+				return fmt.Errorf("actions[%d].hedging.delay must be a non-negative duration", i)
+			}
+		}
+	}
+	for field, value := range map[string]string{
+		"attemptTimeout": action.AttemptTimeout,
+		"overallTimeout": action.OverallTimeout,
+	} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("actions[%d].%s must be a positive duration", i, field)
+		}
 	}
-	if err := validateActionURL(action.URL); err != nil {
-		return fmt.Errorf("actions[%d].url: %w", i, err)
+	for name, value := range action.Headers {
+		if value.VaultRef == nil {
+			continue
+		}
+		if err := validateValueFrom(value); err != nil {
+			return fmt.Errorf("actions[%d].headers.%s: %w", i, name, err)
+		}
 	}
 	if action.ExpectedStatus != "" {
 		if _, err := regexp.Compile(action.ExpectedStatus); err != nil {
 			return fmt.Errorf("actions[%d].expectedStatus invalid regex: %w", i, err)
 		}
 	}
+	if err := validateBody(i, action.Body); err != nil {
+		return err
+	}
+	if err := validateExpectedResponse(i, action.ExpectedResponse); err != nil {
+		return err
+	}
+	if action.SuccessCondition != "" {
+		if action.ExpectedStatus != "" || action.ExpectedResponse != nil {
+			return fmt.Errorf("actions[%d].successCondition cannot be combined with expectedStatus or expectedResponse", i)
+		}
+		if _, err := CompileSuccessCondition(action.SuccessCondition); err != nil {
+			return fmt.Errorf("actions[%d].successCondition invalid CEL expression: %w", i, err)
+		}
+	}
+	if err := validateResponseCapture(i, action.ResponseCapture); err != nil {
+		return err
+	}
+	for name, expr := range action.Outputs {
+		if name == "" {
+			return fmt.Errorf("actions[%d].outputs name must not be empty", i)
+		}
+		if err := jsonpath.New(name).Parse(expr); err != nil {
+			return fmt.Errorf("actions[%d].outputs.%s invalid JSONPath: %w", i, name, err)
+		}
+	}
+	if err := validateAuth(i, action); err != nil {
+		return err
+	}
+	if err := validateIdempotencyKey(i, action); err != nil {
+		return err
+	}
+	if err := validateProxy(i, action.Proxy); err != nil {
+		return err
+	}
+	if err := validateRedirects(i, action); err != nil {
+		return err
+	}
+	if action.MaxResponseBytes < 0 {
+		return fmt.Errorf("actions[%d].maxResponseBytes must be >= 1", i)
+	}
+	if err := validateTLS(i, action.TLS); err != nil {
+		return err
+	}
+	if limit := action.RateLimit; limit != nil {
+		if limit.RequestsPerSecond < 1 {
+			return fmt.Errorf("actions[%d].rateLimit.requestsPerSecond must be >= 1", i)
+		}
+		if limit.Burst < 0 {
+			return fmt.Errorf("actions[%d].rateLimit.burst must be >= 1", i)
+		}
+	}
 	if action.URLPolicy != nil {
 		for _, p := range action.URLPolicy.AllowedHostRegex {
 			if _, err := regexp.Compile(p); err != nil {
@@ -100,6 +889,281 @@ func validateHTTPAction(i int, action ActionSpec) error {
 	return nil
 }
 
+func validateBody(i int, body *TemplateSpec) error {
+	if body == nil {
+		return nil
+	}
+	form := len(body.Form) > 0 || len(body.Files) > 0
+	switch {
+	case body.Template != "" && form:
+		return fmt.Errorf("actions[%d].body.template cannot be combined with form or files", i)
+	case body.Template == "" && !form:
+		return fmt.Errorf("actions[%d].body requires template, form or files", i)
+	}
+	if body.ContentType != "" {
+		if form {
+			return fmt.Errorf("actions[%d].body.contentType is only allowed with template", i)
+		}
+		if _, _, err := mime.ParseMediaType(body.ContentType); err != nil {
+			return fmt.Errorf("actions[%d].body.contentType invalid: %w", i, err)
+		}
+	}
+	if body.Compression != "" && body.Compression != "gzip" {
+		return fmt.Errorf("actions[%d].body.compression must be %q", i, "gzip")
+	}
+	for name := range body.Form {
+		if name == "" {
+			return fmt.Errorf("actions[%d].body.form field name must not be empty", i)
+		}
+	}
+	for j, file := range body.Files {
+		if file.Field == "" || file.FileName == "" {
+			return fmt.Errorf("actions[%d].body.files[%d] requires field and fileName", i, j)
+		}
+		if file.ContentType != "" {
+			if _, _, err := mime.ParseMediaType(file.ContentType); err != nil {
+				return fmt.Errorf("actions[%d].body.files[%d].contentType invalid: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRedirects(i int, action ActionSpec) error {
+	if action.MaxRedirects < 0 {
+		return fmt.Errorf("actions[%d].maxRedirects must be >= 1", i)
+	}
+	if action.FollowRedirects == nil || *action.FollowRedirects {
+		return nil
+	}
+	if action.MaxRedirects != 0 {
+		return fmt.Errorf("actions[%d].maxRedirects requires followRedirects", i)
+	}
+	if action.CrossOriginRedirects != nil {
+		return fmt.Errorf("actions[%d].crossOriginRedirects requires followRedirects", i)
+	}
+	return nil
+}
+
+func validateExpectedResponse(i int, expected *ExpectedResponseSpec) error {
+	if expected == nil {
+		return nil
+	}
+	if expected.BodyRegex == "" && expected.JSONPath == "" {
+		return fmt.Errorf("actions[%d].expectedResponse requires bodyRegex or jsonPath", i)
+	}
+	if expected.BodyRegex != "" {
+		if _, err := regexp.Compile(expected.BodyRegex); err != nil {
+			return fmt.Errorf("actions[%d].expectedResponse.bodyRegex invalid regex: %w", i, err)
+		}
+	}
+	if expected.JSONPath != "" {
+		if err := jsonpath.New("expectedResponse").Parse(expected.JSONPath); err != nil {
+			return fmt.Errorf("actions[%d].expectedResponse.jsonPath invalid: %w", i, err)
+		}
+	} else if expected.Value != nil {
+		return fmt.Errorf("actions[%d].expectedResponse.value requires jsonPath", i)
+	}
+	return nil
+}
+
+func validateResponseCapture(i int, capture *ResponseCaptureSpec) error {
+	if capture == nil {
+		return nil
+	}
+	switch capture.Target {
+	case "", "Status":
+		if capture.ConfigMapName != "" {
+			return fmt.Errorf("actions[%d].responseCapture.configMapName is only allowed for target %q", i, "ConfigMap")
+		}
+	case "ConfigMap":
+		if errs := validation.IsDNS1123Subdomain(capture.ConfigMapName); len(errs) > 0 {
+			return fmt.Errorf("actions[%d].responseCapture.configMapName invalid: %s", i, strings.Join(errs, ", "))
+		}
+	default:
+		return fmt.Errorf("actions[%d].responseCapture.target must be Status or ConfigMap", i)
+	}
+	if !capture.StatusCode && len(capture.Fields) == 0 {
+		return fmt.Errorf("actions[%d].responseCapture requires statusCode or fields", i)
+	}
+	for key, expr := range capture.Fields {
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return fmt.Errorf("actions[%d].responseCapture.fields key %q invalid: %s", i, key, strings.Join(errs, ", "))
+		}
+		if capture.StatusCode && key == "statusCode" {
+			return fmt.Errorf("actions[%d].responseCapture.fields key %q is reserved", i, key)
+		}
+		if err := jsonpath.New(key).Parse(expr); err != nil {
+			return fmt.Errorf("actions[%d].responseCapture.fields[%q] invalid JSONPath: %w", i, key, err)
+		}
+	}
+	return nil
+}
+
+// validateAuth validates actions[i].auth. The Authorization header it sets
+// must not also be configured in headers.
+func validateAuth(i int, action ActionSpec) error {
+	if action.Auth == nil {
+		return nil
+	}
+	basic, sa := action.Auth.Basic, action.Auth.ServiceAccountToken
+	switch {
+	case basic == nil && sa == nil:
+		return fmt.Errorf("actions[%d].auth requires basic or serviceAccountToken", i)
+	case basic != nil && sa != nil:
+		return fmt.Errorf("actions[%d].auth allows only one of basic and serviceAccountToken", i)
+	case basic != nil:
+		if err := validateValueFrom(basic.Username); err != nil {
+			return fmt.Errorf("actions[%d].auth.basic.username: %w", i, err)
+		}
+		if err := validateValueFrom(basic.Password); err != nil {
+			return fmt.Errorf("actions[%d].auth.basic.password: %w", i, err)
+		}
+	default:
+		if err := validateServiceAccountTokenAuth(i, sa); err != nil {
+			return err
+		}
+	}
+	for name := range action.Headers {
+		if strings.EqualFold(name, "Authorization") {
+			return fmt.Errorf("actions[%d].headers.%s cannot be combined with auth", i, name)
+		}
+	}
+	return nil
+}
+
+func validateIdempotencyKey(i int, action ActionSpec) error {
+	if action.IdempotencyKey == nil {
+		return nil
+	}
+	header := action.IdempotencyKey.Header
+	if header == "" {
+		header = "Idempotency-Key"
+	}
+	if !httpguts.ValidHeaderFieldName(header) {
+		return fmt.Errorf("actions[%d].idempotencyKey.header %q is not a valid header name", i, header)
+	}
+	for name := range action.Headers {
+		if strings.EqualFold(name, header) {
+			return fmt.Errorf("actions[%d].headers.%s cannot be combined with idempotencyKey", i, name)
+		}
+	}
+	return nil
+}
+
+func validateTLS(i int, spec *TLSSpec) error {
+	if spec == nil {
+		return nil
+	}
+	for _, v := range []string{spec.MinVersion, spec.MaxVersion} {
+		if v != "" && v != "1.2" && v != "1.3" {
+			return fmt.Errorf("actions[%d].tls version %q must be 1.2 or 1.3", i, v)
+		}
+	}
+	if spec.MinVersion == "1.3" && spec.MaxVersion == "1.2" {
+		return fmt.Errorf("actions[%d].tls.minVersion must not be greater than tls.maxVersion", i)
+	}
+	if len(spec.CipherSuites) > 0 && spec.MinVersion == "1.3" {
+		return fmt.Errorf("actions[%d].tls.cipherSuites only apply to TLS 1.2 and cannot be set with minVersion 1.3", i)
+	}
+	for _, name := range spec.CipherSuites {
+		if !slices.ContainsFunc(tls.CipherSuites(), func(s *tls.CipherSuite) bool { return s.Name == name }) {
+			return fmt.Errorf("actions[%d].tls.cipherSuites: unsupported or insecure cipher suite %q", i, name)
+		}
+	}
+	return nil
+}
+
+func validateProxy(i int, proxy *ProxySpec) error {
+	if proxy == nil {
+		return nil
+	}
+	u, err := url.Parse(proxy.URL)
+	if err != nil {
+		return fmt.Errorf("actions[%d].proxy.url: %w", i, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("actions[%d].proxy.url scheme must be http, https or socks5", i)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("actions[%d].proxy.url host is required", i)
+	}
+	if u.User != nil {
+		return fmt.Errorf("actions[%d].proxy.url must not contain credentials; use proxy.credentials", i)
+	}
+	for j, entry := range proxy.NoProxy {
+		if strings.TrimSpace(entry) == "" || strings.Contains(entry, ",") {
+			return fmt.Errorf("actions[%d].proxy.noProxy[%d] must be a single non-empty entry", i, j)
+		}
+	}
+	if creds := proxy.Credentials; creds != nil {
+		if err := validateValueFrom(creds.Username); err != nil {
+			return fmt.Errorf("actions[%d].proxy.credentials.username: %w", i, err)
+		}
+		if err := validateValueFrom(creds.Password); err != nil {
+			return fmt.Errorf("actions[%d].proxy.credentials.password: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateValueFrom(value ValueFrom) error {
+	if value.SecretKeyRef != nil && value.VaultRef != nil {
+		return fmt.Errorf("only one of secretKeyRef and vaultRef can be set")
+	}
+	if vault := value.VaultRef; vault != nil {
+		if strings.TrimSpace(vault.Path) == "" || strings.TrimSpace(vault.Key) == "" || strings.TrimSpace(vault.Role) == "" {
+			return fmt.Errorf("vaultRef.path, vaultRef.key and vaultRef.role are required")
+		}
+		if vault.ServiceAccountName != "" {
+			if errs := validation.IsDNS1123Subdomain(vault.ServiceAccountName); len(errs) > 0 {
+				return fmt.Errorf("vaultRef.serviceAccountName %q is invalid: %s", vault.ServiceAccountName, strings.Join(errs, "; "))
+			}
+		}
+		return nil
+	}
+	if value.SecretKeyRef == nil || value.SecretKeyRef.Name == "" || value.SecretKeyRef.Key == "" {
+		return fmt.Errorf("secretKeyRef.name and secretKeyRef.key are required")
+	}
+	return nil
+}
+
+func validateServiceAccountTokenAuth(i int, sa *ServiceAccountTokenAuth) error {
+	if strings.TrimSpace(sa.Audience) == "" {
+		return fmt.Errorf("actions[%d].auth.serviceAccountToken.audience is required", i)
+	}
+	if sa.ServiceAccountName != "" {
+		if errs := validation.IsDNS1123Subdomain(sa.ServiceAccountName); len(errs) > 0 {
+			return fmt.Errorf("actions[%d].auth.serviceAccountToken.serviceAccountName %q is invalid: %s",
+				i, sa.ServiceAccountName, strings.Join(errs, "; "))
+		}
+	}
+	if sa.ExpirationSeconds != nil && *sa.ExpirationSeconds < 600 {
+		return fmt.Errorf("actions[%d].auth.serviceAccountToken.expirationSeconds must be >= 600", i)
+	}
+	return nil
+}
+
+// validateForEach validates spec.actions[].forEach. The URLs are rendered per
+// item, so they are checked when the requests are sent.
+func validateForEach(i int, action ActionSpec) error {
+	if strings.TrimSpace(action.ForEach.Items) == "" || strings.TrimSpace(action.ForEach.URL) == "" {
+		return fmt.Errorf("actions[%d].forEach.items and forEach.url are required", i)
+	}
+	if action.URL != "" {
+		return fmt.Errorf("actions[%d].url cannot be combined with forEach, use forEach.url", i)
+	}
+	if len(action.FallbackURLs) > 0 || action.Hedging != nil {
+		return fmt.Errorf("actions[%d].fallbackURLs and hedging cannot be combined with forEach", i)
+	}
+	if action.ResponseCapture != nil || len(action.Outputs) > 0 {
+		return fmt.Errorf("actions[%d].responseCapture and outputs cannot be combined with forEach", i)
+	}
+	return nil
+}
+
 func validateJobAction(i int, action ActionSpec) error {
 	if action.Job == nil {
 		return fmt.Errorf("actions[%d].job is required for type %q", i, action.Type)
@@ -107,6 +1171,42 @@ func validateJobAction(i int, action ActionSpec) error {
 	if action.URL != "" {
 		return fmt.Errorf("actions[%d].url is only allowed for type %q", i, action.Type)
 	}
+	if len(action.FallbackURLs) > 0 || action.Hedging != nil || action.ForEach != nil {
+		return fmt.Errorf("actions[%d].fallbackURLs, hedging and forEach are only allowed for type %q", i, "http")
+	}
+	if action.ExpectedResponse != nil {
+		return fmt.Errorf("actions[%d].expectedResponse is only allowed for type %q", i, "http")
+	}
+	if action.SuccessCondition != "" {
+		return fmt.Errorf("actions[%d].successCondition is only allowed for type %q", i, "http")
+	}
+	if action.ResponseCapture != nil {
+		return fmt.Errorf("actions[%d].responseCapture is only allowed for type %q", i, "http")
+	}
+	if len(action.Outputs) > 0 {
+		return fmt.Errorf("actions[%d].outputs is only allowed for type %q", i, "http")
+	}
+	if action.RateLimit != nil {
+		return fmt.Errorf("actions[%d].rateLimit is only allowed for type %q", i, "http")
+	}
+	if action.Auth != nil {
+		return fmt.Errorf("actions[%d].auth is only allowed for type %q", i, "http")
+	}
+	if action.IdempotencyKey != nil {
+		return fmt.Errorf("actions[%d].idempotencyKey is only allowed for type %q", i, "http")
+	}
+	if action.Proxy != nil {
+		return fmt.Errorf("actions[%d].proxy is only allowed for type %q", i, "http")
+	}
+	if action.AttemptTimeout != "" || action.OverallTimeout != "" {
+		return fmt.Errorf("actions[%d].attemptTimeout and overallTimeout are only allowed for type %q, use job.timeout", i, "http")
+	}
+	if action.LogResponseBody || action.MaxResponseBytes != 0 {
+		return fmt.Errorf("actions[%d].logResponseBody and maxResponseBytes are only allowed for type %q", i, "http")
+	}
+	if action.FollowRedirects != nil || action.MaxRedirects != 0 || action.CrossOriginRedirects != nil {
+		return fmt.Errorf("actions[%d].followRedirects, maxRedirects and crossOriginRedirects are only allowed for type %q", i, "http")
+	}
 
 	job := action.Job
 	if strings.TrimSpace(job.Image) == "" {
@@ -165,6 +1265,9 @@ func validateJobEnv(i int, job *JobSpec) error {
 		if hasValue == hasValueFrom {
 			return fmt.Errorf("actions[%d].job.env[%d] must define exactly one of value or valueFrom", i, j)
 		}
+		if hasValueFrom && env.ValueFrom.VaultRef != nil {
+			return fmt.Errorf("actions[%d].job.env[%d].valueFrom.vaultRef is not supported for jobs", i, j)
+		}
 		if hasValueFrom && env.ValueFrom.SecretKeyRef == nil {
 			return fmt.Errorf("actions[%d].job.env[%d].valueFrom.secretKeyRef is required", i, j)
 		}
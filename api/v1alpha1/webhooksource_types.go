@@ -0,0 +1,68 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WebhookSourceSpec configures an endpoint of the webhook receiver.
+type WebhookSourceSpec struct {
+	// SecretRef references the key of a Secret in the namespace of the
+	// WebhookSource that holds the shared secret callers must send.
+	SecretRef SecretKeyRef `json:"secretRef"`
+
+	// Header is the request header carrying the shared secret.
+	// Defaults to X-Webhook-Secret. With Authorization the secret may also
+	// be sent as a bearer token. Not used with format GitHub.
+	// +optional
+	Header string `json:"header,omitempty"`
+
+	// Format is how request bodies are read. "JSON" delivers the body as
+	// one event. "Alertmanager" reads Alertmanager webhook notifications and
+	// delivers one event per alert. "GitHub" reads GitHub webhook deliveries,
+	// which are signed with the shared secret in X-Hub-Signature-256.
+	// +kubebuilder:validation:Enum=JSON;Alertmanager;GitHub
+	// +kubebuilder:default=JSON
+	// +optional
+	Format string `json:"format,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WebhookSource accepts HTTP events from outside the cluster. Every request
+// to its endpoint is delivered to the ResourceActions selecting WebhookSource
+// with the event Webhook.
+type WebhookSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WebhookSourceSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WebhookSourceList contains a list of WebhookSource.
+type WebhookSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WebhookSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WebhookSource{}, &WebhookSourceList{})
+}
@@ -26,9 +26,128 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionExecution) DeepCopyInto(out *ActionExecution) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionExecution.
+func (in *ActionExecution) DeepCopy() *ActionExecution {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionExecution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActionExecution) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionExecutionList) DeepCopyInto(out *ActionExecutionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ActionExecution, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionExecutionList.
+func (in *ActionExecutionList) DeepCopy() *ActionExecutionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionExecutionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActionExecutionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionExecutionSpec) DeepCopyInto(out *ActionExecutionSpec) {
+	*out = *in
+	in.ExecutionRecord.DeepCopyInto(&out.ExecutionRecord)
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(HTTPRequestRecord)
+		**out = **in
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(HTTPResponseRecord)
+		**out = **in
+	}
+	if in.FinalState != nil {
+		in, out := &in.FinalState, &out.FinalState
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionExecutionSpec.
+func (in *ActionExecutionSpec) DeepCopy() *ActionExecutionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionExecutionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActionSpec) DeepCopyInto(out *ActionSpec) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OnSuccess != nil {
+		in, out := &in.OnSuccess, &out.OnSuccess
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OnFailure != nil {
+		in, out := &in.OnFailure, &out.OnFailure
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Escalation != nil {
+		in, out := &in.Escalation, &out.Escalation
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalSpec)
+		**out = **in
+	}
 	if in.URLPolicy != nil {
 		in, out := &in.URLPolicy, &out.URLPolicy
 		*out = new(URLPolicySpec)
@@ -44,8 +163,38 @@ func (in *ActionSpec) DeepCopyInto(out *ActionSpec) {
 	if in.Body != nil {
 		in, out := &in.Body, &out.Body
 		*out = new(TemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FallbackURLs != nil {
+		in, out := &in.FallbackURLs, &out.FallbackURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hedging != nil {
+		in, out := &in.Hedging, &out.Hedging
+		*out = new(HedgingSpec)
+		**out = **in
+	}
+	if in.ForEach != nil {
+		in, out := &in.ForEach, &out.ForEach
+		*out = new(ForEachSpec)
 		**out = **in
 	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(AuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IdempotencyKey != nil {
+		in, out := &in.IdempotencyKey, &out.IdempotencyKey
+		*out = new(IdempotencyKeySpec)
+		**out = **in
+	}
+	if in.ExpectedResponse != nil {
+		in, out := &in.ExpectedResponse, &out.ExpectedResponse
+		*out = new(ExpectedResponseSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Retry != nil {
 		in, out := &in.Retry, &out.Retry
 		*out = new(RetrySpec)
@@ -56,11 +205,65 @@ func (in *ActionSpec) DeepCopyInto(out *ActionSpec) {
 		*out = new(TLSSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FollowRedirects != nil {
+		in, out := &in.FollowRedirects, &out.FollowRedirects
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CrossOriginRedirects != nil {
+		in, out := &in.CrossOriginRedirects, &out.CrossOriginRedirects
+		*out = new(CrossOriginRedirectSpec)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimitSpec)
+		**out = **in
+	}
+	if in.ResponseCapture != nil {
+		in, out := &in.ResponseCapture, &out.ResponseCapture
+		*out = new(ResponseCaptureSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResultAnnotations != nil {
+		in, out := &in.ResultAnnotations, &out.ResultAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DeadLetter != nil {
+		in, out := &in.DeadLetter, &out.DeadLetter
+		*out = new(DeadLetterSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Job != nil {
 		in, out := &in.Job, &out.Job
 		*out = new(JobSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(WaitSpec)
+		**out = **in
+	}
+	if in.WaitForCondition != nil {
+		in, out := &in.WaitForCondition, &out.WaitForCondition
+		*out = new(WaitForConditionSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionSpec.
@@ -74,61 +277,60 @@ func (in *ActionSpec) DeepCopy() *ActionSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExecutionRecord) DeepCopyInto(out *ExecutionRecord) {
+func (in *ActionState) DeepCopyInto(out *ActionState) {
 	*out = *in
-	in.ExecutedAt.DeepCopyInto(&out.ExecutedAt)
-	if in.Job != nil {
-		in, out := &in.Job, &out.Job
-		*out = new(JobExecutionRecord)
-		(*in).DeepCopyInto(*out)
+	if in.LastExecutionTime != nil {
+		in, out := &in.LastExecutionTime, &out.LastExecutionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutionRecord.
-func (in *ExecutionRecord) DeepCopy() *ExecutionRecord {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionState.
+func (in *ActionState) DeepCopy() *ActionState {
 	if in == nil {
 		return nil
 	}
-	out := new(ExecutionRecord)
+	out := new(ActionState)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobExecutionRecord) DeepCopyInto(out *JobExecutionRecord) {
+func (in *AggregationSpec) DeepCopyInto(out *AggregationSpec) {
 	*out = *in
-	if in.ExitCode != nil {
-		in, out := &in.ExitCode, &out.ExitCode
-		*out = new(int32)
-		**out = **in
-	}
-	if in.StartedAt != nil {
-		in, out := &in.StartedAt, &out.StartedAt
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletedAt != nil {
-		in, out := &in.CompletedAt, &out.CompletedAt
-		*out = (*in).DeepCopy()
-	}
-	if in.LogTail != nil {
-		in, out := &in.LogTail, &out.LogTail
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobExecutionRecord.
-func (in *JobExecutionRecord) DeepCopy() *JobExecutionRecord {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AggregationSpec.
+func (in *AggregationSpec) DeepCopy() *AggregationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(JobExecutionRecord)
+	out := new(AggregationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilterSpec) DeepCopyInto(out *FilterSpec) {
+func (in *AlertFilter) DeepCopyInto(out *AlertFilter) {
 	*out = *in
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
@@ -137,235 +339,1020 @@ func (in *FilterSpec) DeepCopyInto(out *FilterSpec) {
 			(*out)[key] = val
 		}
 	}
-	if in.LabelChanges != nil {
-		in, out := &in.LabelChanges, &out.LabelChanges
-		*out = make([]LabelChangeFilter, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertFilter.
+func (in *AlertFilter) DeepCopy() *AlertFilter {
+	if in == nil {
+		return nil
 	}
+	out := new(AlertFilter)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilterSpec.
-func (in *FilterSpec) DeepCopy() *FilterSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalSpec) DeepCopyInto(out *ApprovalSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalSpec.
+func (in *ApprovalSpec) DeepCopy() *ApprovalSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FilterSpec)
+	out := new(ApprovalSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LabelChangeFilter) DeepCopyInto(out *LabelChangeFilter) {
+func (in *AuthSpec) DeepCopyInto(out *AuthSpec) {
 	*out = *in
+	if in.Basic != nil {
+		in, out := &in.Basic, &out.Basic
+		*out = new(BasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccountToken != nil {
+		in, out := &in.ServiceAccountToken, &out.ServiceAccountToken
+		*out = new(ServiceAccountTokenAuth)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelChangeFilter.
-func (in *LabelChangeFilter) DeepCopy() *LabelChangeFilter {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthSpec.
+func (in *AuthSpec) DeepCopy() *AuthSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LabelChangeFilter)
+	out := new(AuthSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobConfigMapVolume) DeepCopyInto(out *JobConfigMapVolume) {
+func (in *BasicAuth) DeepCopyInto(out *BasicAuth) {
 	*out = *in
+	in.Username.DeepCopyInto(&out.Username)
+	in.Password.DeepCopyInto(&out.Password)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobConfigMapVolume.
-func (in *JobConfigMapVolume) DeepCopy() *JobConfigMapVolume {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuth.
+func (in *BasicAuth) DeepCopy() *BasicAuth {
 	if in == nil {
 		return nil
 	}
-	out := new(JobConfigMapVolume)
+	out := new(BasicAuth)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobEnvVar) DeepCopyInto(out *JobEnvVar) {
+func (in *CapturedResponse) DeepCopyInto(out *CapturedResponse) {
 	*out = *in
-	if in.ValueFrom != nil {
-		in, out := &in.ValueFrom, &out.ValueFrom
-		*out = new(ValueFrom)
-		(*in).DeepCopyInto(*out)
+	in.CapturedAt.DeepCopyInto(&out.CapturedAt)
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobEnvVar.
-func (in *JobEnvVar) DeepCopy() *JobEnvVar {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapturedResponse.
+func (in *CapturedResponse) DeepCopy() *CapturedResponse {
 	if in == nil {
 		return nil
 	}
-	out := new(JobEnvVar)
+	out := new(CapturedResponse)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobSecretVolume) DeepCopyInto(out *JobSecretVolume) {
+func (in *ClusterRefSpec) DeepCopyInto(out *ClusterRefSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSecretVolume.
-func (in *JobSecretVolume) DeepCopy() *JobSecretVolume {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRefSpec.
+func (in *ClusterRefSpec) DeepCopy() *ClusterRefSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(JobSecretVolume)
+	out := new(ClusterRefSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobSpec) DeepCopyInto(out *JobSpec) {
+func (in *CrossOriginRedirectSpec) DeepCopyInto(out *CrossOriginRedirectSpec) {
 	*out = *in
-	if in.Command != nil {
-		in, out := &in.Command, &out.Command
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossOriginRedirectSpec.
+func (in *CrossOriginRedirectSpec) DeepCopy() *CrossOriginRedirectSpec {
+	if in == nil {
+		return nil
 	}
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out := new(CrossOriginRedirectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeadLetterSpec) DeepCopyInto(out *DeadLetterSpec) {
+	*out = *in
+	if in.URLPolicy != nil {
+		in, out := &in.URLPolicy, &out.URLPolicy
+		*out = new(URLPolicySpec)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.InterpreterCommand != nil {
-		in, out := &in.InterpreterCommand, &out.InterpreterCommand
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeadLetterSpec.
+func (in *DeadLetterSpec) DeepCopy() *DeadLetterSpec {
+	if in == nil {
+		return nil
 	}
-	if in.Env != nil {
-		in, out := &in.Env, &out.Env
-		*out = make([]JobEnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out := new(DeadLetterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DegradedSpec) DeepCopyInto(out *DegradedSpec) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]JobVolume, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DegradedSpec.
+func (in *DegradedSpec) DeepCopy() *DegradedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DegradedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutionRecord) DeepCopyInto(out *ExecutionRecord) {
+	*out = *in
+	in.ExecutedAt.DeepCopyInto(&out.ExecutedAt)
+	if in.ActionIndex != nil {
+		in, out := &in.ActionIndex, &out.ActionIndex
+		*out = new(int)
+		**out = **in
+	}
+	if in.FailedActions != nil {
+		in, out := &in.FailedActions, &out.FailedActions
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobExecutionRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]TargetRecord, len(*in))
+		copy(*out, *in)
+	}
+	if in.RenderedRequests != nil {
+		in, out := &in.RenderedRequests, &out.RenderedRequests
+		*out = make([]RenderedRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutionRecord.
+func (in *ExecutionRecord) DeepCopy() *ExecutionRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutionRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpectedResponseSpec) DeepCopyInto(out *ExpectedResponseSpec) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpectedResponseSpec.
+func (in *ExpectedResponseSpec) DeepCopy() *ExpectedResponseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpectedResponseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForEachSpec) DeepCopyInto(out *ForEachSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForEachSpec.
+func (in *ForEachSpec) DeepCopy() *ForEachSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ForEachSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FormFileSpec) DeepCopyInto(out *FormFileSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FormFileSpec.
+func (in *FormFileSpec) DeepCopy() *FormFileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FormFileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HedgingSpec) DeepCopyInto(out *HedgingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HedgingSpec.
+func (in *HedgingSpec) DeepCopy() *HedgingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HedgingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdempotencyKeySpec) DeepCopyInto(out *IdempotencyKeySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdempotencyKeySpec.
+func (in *IdempotencyKeySpec) DeepCopy() *IdempotencyKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IdempotencyKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobExecutionRecord) DeepCopyInto(out *JobExecutionRecord) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LogTail != nil {
+		in, out := &in.LogTail, &out.LogTail
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobExecutionRecord.
+func (in *JobExecutionRecord) DeepCopy() *JobExecutionRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(JobExecutionRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilterSpec) DeepCopyInto(out *FilterSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LabelChanges != nil {
+		in, out := &in.LabelChanges, &out.LabelChanges
+		*out = make([]LabelChangeFilter, len(*in))
+		copy(*out, *in)
+	}
+	if in.Alert != nil {
+		in, out := &in.Alert, &out.Alert
+		*out = new(AlertFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitHub != nil {
+		in, out := &in.GitHub, &out.GitHub
+		*out = new(GitHubFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilterSpec.
+func (in *FilterSpec) DeepCopy() *FilterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FilterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelChangeFilter) DeepCopyInto(out *LabelChangeFilter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelChangeFilter.
+func (in *LabelChangeFilter) DeepCopy() *LabelChangeFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelChangeFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubFilter) DeepCopyInto(out *GitHubFilter) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubFilter.
+func (in *GitHubFilter) DeepCopy() *GitHubFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRequestRecord) DeepCopyInto(out *HTTPRequestRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRequestRecord.
+func (in *HTTPRequestRecord) DeepCopy() *HTTPRequestRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRequestRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPResponseRecord) DeepCopyInto(out *HTTPResponseRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPResponseRecord.
+func (in *HTTPResponseRecord) DeepCopy() *HTTPResponseRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPResponseRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobConfigMapVolume) DeepCopyInto(out *JobConfigMapVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobConfigMapVolume.
+func (in *JobConfigMapVolume) DeepCopy() *JobConfigMapVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(JobConfigMapVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobEnvVar) DeepCopyInto(out *JobEnvVar) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ValueFrom)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobEnvVar.
+func (in *JobEnvVar) DeepCopy() *JobEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(JobEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSecretVolume) DeepCopyInto(out *JobSecretVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSecretVolume.
+func (in *JobSecretVolume) DeepCopy() *JobSecretVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSecretVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSpec) DeepCopyInto(out *JobSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InterpreterCommand != nil {
+		in, out := &in.InterpreterCommand, &out.InterpreterCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]JobEnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]JobVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]JobVolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AutomountServiceAccountToken != nil {
+		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowRunAsRoot != nil {
+		in, out := &in.AllowRunAsRoot, &out.AllowRunAsRoot
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogTailLines != nil {
+		in, out := &in.LogTailLines, &out.LogTailLines
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSpec.
+func (in *JobSpec) DeepCopy() *JobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobVolume) DeepCopyInto(out *JobVolume) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(JobSecretVolume)
+		**out = **in
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(JobConfigMapVolume)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobVolume.
+func (in *JobVolume) DeepCopy() *JobVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(JobVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobVolumeMount) DeepCopyInto(out *JobVolumeMount) {
+	*out = *in
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobVolumeMount.
+func (in *JobVolumeMount) DeepCopy() *JobVolumeMount {
+	if in == nil {
+		return nil
+	}
+	out := new(JobVolumeMount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NameReuseSpec) DeepCopyInto(out *NameReuseSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameReuseSpec.
+func (in *NameReuseSpec) DeepCopy() *NameReuseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NameReuseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(BasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
+func (in *ProxySpec) DeepCopy() *ProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitSpec) DeepCopyInto(out *RateLimitSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitSpec.
+func (in *RateLimitSpec) DeepCopy() *RateLimitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenderedRequest) DeepCopyInto(out *RenderedRequest) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenderedRequest.
+func (in *RenderedRequest) DeepCopy() *RenderedRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(RenderedRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceAction) DeepCopyInto(out *ResourceAction) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAction.
+func (in *ResourceAction) DeepCopy() *ResourceAction {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceAction) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceActionList) DeepCopyInto(out *ResourceActionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceAction, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]JobVolumeMount, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionList.
+func (in *ResourceActionList) DeepCopy() *ResourceActionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceActionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceActionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceActionSpec) DeepCopyInto(out *ResourceActionSpec) {
+	*out = *in
+	out.Selector = in.Selector
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(FilterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]ActionSpec, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.AutomountServiceAccountToken != nil {
-		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
-		*out = new(bool)
+	if in.WatchNamespaces != nil {
+		in, out := &in.WatchNamespaces, &out.WatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterRef != nil {
+		in, out := &in.ClusterRef, &out.ClusterRef
+		*out = new(ClusterRefSpec)
 		**out = **in
 	}
-	if in.AllowRunAsRoot != nil {
-		in, out := &in.AllowRunAsRoot, &out.AllowRunAsRoot
-		*out = new(bool)
+	if in.NameReuse != nil {
+		in, out := &in.NameReuse, &out.NameReuse
+		*out = new(NameReuseSpec)
 		**out = **in
 	}
-	if in.LogTailLines != nil {
-		in, out := &in.LogTailLines, &out.LogTailLines
+	if in.MaxExecutionsPerObject != nil {
+		in, out := &in.MaxExecutionsPerObject, &out.MaxExecutionsPerObject
 		*out = new(int32)
 		**out = **in
 	}
-	if in.TTLSecondsAfterFinished != nil {
-		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+	if in.MaxExecutionsPerHour != nil {
+		in, out := &in.MaxExecutionsPerHour, &out.MaxExecutionsPerHour
 		*out = new(int32)
 		**out = **in
 	}
-	if in.BackoffLimit != nil {
-		in, out := &in.BackoffLimit, &out.BackoffLimit
-		*out = new(int32)
+	if in.SuppressionWindows != nil {
+		in, out := &in.SuppressionWindows, &out.SuppressionWindows
+		*out = make([]SuppressionWindowSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Aggregation != nil {
+		in, out := &in.Aggregation, &out.Aggregation
+		*out = new(AggregationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Teardown != nil {
+		in, out := &in.Teardown, &out.Teardown
+		*out = new(ActionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnSpecChange != nil {
+		in, out := &in.OnSpecChange, &out.OnSpecChange
+		*out = new(SpecChangeSpec)
 		**out = **in
 	}
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(corev1.ResourceRequirements)
+	if in.Degraded != nil {
+		in, out := &in.Degraded, &out.Degraded
+		*out = new(DegradedSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionSpec.
+func (in *ResourceActionSpec) DeepCopy() *ResourceActionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceActionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceActionStatus) DeepCopyInto(out *ResourceActionStatus) {
+	*out = *in
+	if in.Executions != nil {
+		in, out := &in.Executions, &out.Executions
+		*out = make([]ExecutionRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScheduledActions != nil {
+		in, out := &in.ScheduledActions, &out.ScheduledActions
+		*out = make([]ScheduledActionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CapturedResponses != nil {
+		in, out := &in.CapturedResponses, &out.CapturedResponses
+		*out = make([]CapturedResponse, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ActionStates != nil {
+		in, out := &in.ActionStates, &out.ActionStates
+		*out = make([]ActionState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastExecutionTime != nil {
+		in, out := &in.LastExecutionTime, &out.LastExecutionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionStatus.
+func (in *ResourceActionStatus) DeepCopy() *ResourceActionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceActionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSelector) DeepCopyInto(out *ResourceSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSelector.
+func (in *ResourceSelector) DeepCopy() *ResourceSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseCaptureSpec) DeepCopyInto(out *ResponseCaptureSpec) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseCaptureSpec.
+func (in *ResponseCaptureSpec) DeepCopy() *ResponseCaptureSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseCaptureSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetrySpec) DeepCopyInto(out *RetrySpec) {
+	*out = *in
+	if in.RetryOnNetworkError != nil {
+		in, out := &in.RetryOnNetworkError, &out.RetryOnNetworkError
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RetryOnStatus != nil {
+		in, out := &in.RetryOnStatus, &out.RetryOnStatus
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSpec.
-func (in *JobSpec) DeepCopy() *JobSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetrySpec.
+func (in *RetrySpec) DeepCopy() *RetrySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(JobSpec)
+	out := new(RetrySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobVolume) DeepCopyInto(out *JobVolume) {
+func (in *ScheduledActionStatus) DeepCopyInto(out *ScheduledActionStatus) {
 	*out = *in
-	if in.Secret != nil {
-		in, out := &in.Secret, &out.Secret
-		*out = new(JobSecretVolume)
-		**out = **in
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
 	}
-	if in.ConfigMap != nil {
-		in, out := &in.ConfigMap, &out.ConfigMap
-		*out = new(JobConfigMapVolume)
-		**out = **in
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobVolume.
-func (in *JobVolume) DeepCopy() *JobVolume {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledActionStatus.
+func (in *ScheduledActionStatus) DeepCopy() *ScheduledActionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(JobVolume)
+	out := new(ScheduledActionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobVolumeMount) DeepCopyInto(out *JobVolumeMount) {
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
 	*out = *in
-	if in.ReadOnly != nil {
-		in, out := &in.ReadOnly, &out.ReadOnly
-		*out = new(bool)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountTokenAuth) DeepCopyInto(out *ServiceAccountTokenAuth) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobVolumeMount.
-func (in *JobVolumeMount) DeepCopy() *JobVolumeMount {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountTokenAuth.
+func (in *ServiceAccountTokenAuth) DeepCopy() *ServiceAccountTokenAuth {
 	if in == nil {
 		return nil
 	}
-	out := new(JobVolumeMount)
+	out := new(ServiceAccountTokenAuth)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceAction) DeepCopyInto(out *ResourceAction) {
+func (in *Silence) DeepCopyInto(out *Silence) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAction.
-func (in *ResourceAction) DeepCopy() *ResourceAction {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Silence.
+func (in *Silence) DeepCopy() *Silence {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceAction)
+	out := new(Silence)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceAction) DeepCopyObject() runtime.Object {
+func (in *Silence) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -373,46 +1360,31 @@ func (in *ResourceAction) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceActionCustomValidator) DeepCopyInto(out *ResourceActionCustomValidator) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionCustomValidator.
-func (in *ResourceActionCustomValidator) DeepCopy() *ResourceActionCustomValidator {
-	if in == nil {
-		return nil
-	}
-	out := new(ResourceActionCustomValidator)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceActionList) DeepCopyInto(out *ResourceActionList) {
+func (in *SilenceList) DeepCopyInto(out *SilenceList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ResourceAction, len(*in))
+		*out = make([]Silence, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionList.
-func (in *ResourceActionList) DeepCopy() *ResourceActionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SilenceList.
+func (in *SilenceList) DeepCopy() *SilenceList {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceActionList)
+	out := new(SilenceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceActionList) DeepCopyObject() runtime.Object {
+func (in *SilenceList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -420,118 +1392,90 @@ func (in *ResourceActionList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceActionSpec) DeepCopyInto(out *ResourceActionSpec) {
+func (in *SilenceMatcher) DeepCopyInto(out *SilenceMatcher) {
 	*out = *in
-	out.Selector = in.Selector
-	if in.Events != nil {
-		in, out := &in.Events, &out.Events
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Filters != nil {
-		in, out := &in.Filters, &out.Filters
-		*out = new(FilterSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Actions != nil {
-		in, out := &in.Actions, &out.Actions
-		*out = make([]ActionSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
+	if in.ResourceActions != nil {
+		in, out := &in.ResourceActions, &out.ResourceActions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionSpec.
-func (in *ResourceActionSpec) DeepCopy() *ResourceActionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SilenceMatcher.
+func (in *SilenceMatcher) DeepCopy() *SilenceMatcher {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceActionSpec)
+	out := new(SilenceMatcher)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceActionStatus) DeepCopyInto(out *ResourceActionStatus) {
+func (in *SilenceSpec) DeepCopyInto(out *SilenceSpec) {
 	*out = *in
-	if in.Executions != nil {
-		in, out := &in.Executions, &out.Executions
-		*out = make([]ExecutionRecord, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+	if in.Matchers != nil {
+		in, out := &in.Matchers, &out.Matchers
+		*out = make([]SilenceMatcher, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionStatus.
-func (in *ResourceActionStatus) DeepCopy() *ResourceActionStatus {
-	if in == nil {
-		return nil
+	if in.StartsAt != nil {
+		in, out := &in.StartsAt, &out.StartsAt
+		*out = (*in).DeepCopy()
 	}
-	out := new(ResourceActionStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSelector) DeepCopyInto(out *ResourceSelector) {
-	*out = *in
+	in.EndsAt.DeepCopyInto(&out.EndsAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSelector.
-func (in *ResourceSelector) DeepCopy() *ResourceSelector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SilenceSpec.
+func (in *SilenceSpec) DeepCopy() *SilenceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSelector)
+	out := new(SilenceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RetrySpec) DeepCopyInto(out *RetrySpec) {
+func (in *SpecChangeSpec) DeepCopyInto(out *SpecChangeSpec) {
 	*out = *in
-	if in.RetryOnNetworkError != nil {
-		in, out := &in.RetryOnNetworkError, &out.RetryOnNetworkError
-		*out = new(bool)
-		**out = **in
-	}
-	if in.RetryOnStatus != nil {
-		in, out := &in.RetryOnStatus, &out.RetryOnStatus
-		*out = make([]int, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetrySpec.
-func (in *RetrySpec) DeepCopy() *RetrySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecChangeSpec.
+func (in *SpecChangeSpec) DeepCopy() *SpecChangeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RetrySpec)
+	out := new(SpecChangeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+func (in *SuppressionWindowSpec) DeepCopyInto(out *SuppressionWindowSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
-func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SuppressionWindowSpec.
+func (in *SuppressionWindowSpec) DeepCopy() *SuppressionWindowSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretKeyRef)
+	out := new(SuppressionWindowSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -564,6 +1508,11 @@ func (in *TLSSpec) DeepCopyInto(out *TLSSpec) {
 		*out = new(TLSClientCertRef)
 		**out = **in
 	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSSpec.
@@ -576,9 +1525,36 @@ func (in *TLSSpec) DeepCopy() *TLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetRecord) DeepCopyInto(out *TargetRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetRecord.
+func (in *TargetRecord) DeepCopy() *TargetRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateSpec) DeepCopyInto(out *TemplateSpec) {
 	*out = *in
+	if in.Form != nil {
+		in, out := &in.Form, &out.Form
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]FormFileSpec, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSpec.
@@ -624,6 +1600,11 @@ func (in *ValueFrom) DeepCopyInto(out *ValueFrom) {
 		*out = new(SecretKeyRef)
 		**out = **in
 	}
+	if in.VaultRef != nil {
+		in, out := &in.VaultRef, &out.VaultRef
+		*out = new(VaultRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueFrom.
@@ -635,3 +1616,122 @@ func (in *ValueFrom) DeepCopy() *ValueFrom {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultRef) DeepCopyInto(out *VaultRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultRef.
+func (in *VaultRef) DeepCopy() *VaultRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitForConditionSpec) DeepCopyInto(out *WaitForConditionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitForConditionSpec.
+func (in *WaitForConditionSpec) DeepCopy() *WaitForConditionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitForConditionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitSpec) DeepCopyInto(out *WaitSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitSpec.
+func (in *WaitSpec) DeepCopy() *WaitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSource) DeepCopyInto(out *WebhookSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSource.
+func (in *WebhookSource) DeepCopy() *WebhookSource {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WebhookSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSourceList) DeepCopyInto(out *WebhookSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WebhookSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSourceList.
+func (in *WebhookSourceList) DeepCopy() *WebhookSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WebhookSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSourceSpec) DeepCopyInto(out *WebhookSourceSpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSourceSpec.
+func (in *WebhookSourceSpec) DeepCopy() *WebhookSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+// Command kubectl-resource_action is the kubectl resource-action plugin. It
+// validates a ResourceAction, evaluates it for a sample object and renders
+// the requests of its HTTP actions locally, without a cluster, and can replay
+// them against a mock.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"de.yusaozdemir.resource-action-operator/internal/engine"
+)
+
+const (
+	exitInvalid  = 1
+	exitNotMatch = 2
+)
+
+const usage = `Render and dry-run a ResourceAction for a sample object.
+
+Usage:
+  kubectl resource-action -f resourceaction.yaml --object object.yaml [flags]
+
+The ResourceAction is validated, its selector, events and filters are
+evaluated for the object, and the requests of its event-driven HTTP actions
+are rendered like in spec.dryRun. With --replay the requests are sent to a
+local mock, so expectedResponse, successCondition, responseCapture and
+outputs can be checked as well. Nothing is read from or written to a cluster.
+
+Exit codes: 0 when the object matches and all requests render and replay,
+1 on invalid input or failed requests, 2 when the object does not match.
+
+Flags:
+`
+
+// replayResult is a replayed action as printed.
+type replayResult struct {
+	ActionIndex int                             `json:"actionIndex"`
+	ActionName  string                          `json:"actionName,omitempty"`
+	Request     *opsv1alpha1.HTTPRequestRecord  `json:"request,omitempty"`
+	Response    *opsv1alpha1.HTTPResponseRecord `json:"response,omitempty"`
+	Captured    map[string]string               `json:"captured,omitempty"`
+	Outputs     map[string]string               `json:"outputs,omitempty"`
+	Error       string                          `json:"error,omitempty"`
+}
+
+// receivedRequest is a request the built-in mock received.
+type receivedRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// mock answers every request with a fixed response and keeps the requests.
+type mock struct {
+	status int
+	body   string
+
+	mu       sync.Mutex
+	received []receivedRequest
+}
+
+func (m *mock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	headers := map[string]string{}
+	for name := range r.Header {
+		headers[name] = r.Header.Get(name)
+	}
+	m.mu.Lock()
+	m.received = append(m.received, receivedRequest{
+		Method:  r.Method,
+		Path:    r.URL.RequestURI(),
+		Headers: headers,
+		Body:    string(body),
+	})
+	m.mu.Unlock()
+	if strings.HasPrefix(strings.TrimSpace(m.body), "{") || strings.HasPrefix(strings.TrimSpace(m.body), "[") {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(m.status)
+	_, _ = io.WriteString(w, m.body)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("kubectl-resource_action", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	var raFile, objectFile, oldObjectFile, event, replayURL, mockBody string
+	var replay bool
+	var mockStatus int
+	var timeout time.Duration
+	fs.StringVar(&raFile, "f", "", "The ResourceAction YAML file.")
+	fs.StringVar(&raFile, "filename", "", "The ResourceAction YAML file.")
+	fs.StringVar(&objectFile, "object", "", "The YAML file of the sample object the event is about.")
+	fs.StringVar(&oldObjectFile, "old-object", "",
+		"The YAML file of the object before an Update event, for filters.labelChanges.")
+	fs.StringVar(&event, "event", string(engine.EventCreate), "The event to evaluate: Create, Update or Delete.")
+	fs.BoolVar(&replay, "replay", false,
+		"Replay the HTTP requests against a built-in mock that answers with --mock-status and --mock-body.")
+	fs.StringVar(&replayURL, "replay-url", "",
+		"Replay the HTTP requests against the mock at this URL instead of the built-in one. "+
+			"The path and query of the action URL are appended.")
+	fs.IntVar(&mockStatus, "mock-status", http.StatusOK, "The status code of the built-in mock.")
+	fs.StringVar(&mockBody, "mock-body", "", "The response body of the built-in mock. Prefix with @ to read it from a file.")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "How long replaying the requests may take.")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitInvalid
+	}
+	if raFile == "" || objectFile == "" {
+		_, _ = fmt.Fprintln(stderr, "error: -f and --object are required")
+		fs.Usage()
+		return exitInvalid
+	}
+
+	fail := func(err error) int {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitInvalid
+	}
+	var ra opsv1alpha1.ResourceAction
+	if err := readYAML(raFile, &ra); err != nil {
+		return fail(err)
+	}
+	if ra.Kind != "" && ra.Kind != "ResourceAction" {
+		return fail(fmt.Errorf("%s holds a %s, not a ResourceAction", raFile, ra.Kind))
+	}
+	input := engine.MatchInput{Event: engine.EventType(event)}
+	var err error
+	if input.Obj, err = readObject(objectFile); err != nil {
+		return fail(err)
+	}
+	input.GVK = input.Obj.GroupVersionKind()
+	if oldObjectFile != "" {
+		if input.OldObj, err = readObject(oldObjectFile); err != nil {
+			return fail(err)
+		}
+	}
+	if strings.HasPrefix(mockBody, "@") {
+		body, err := os.ReadFile(strings.TrimPrefix(mockBody, "@"))
+		if err != nil {
+			return fail(err)
+		}
+		mockBody = string(body)
+	}
+
+	if err := opsv1alpha1.ValidateResourceActionSpec(ra.Spec); err != nil {
+		return fail(fmt.Errorf("ResourceAction %s is invalid: %w", ra.Name, err))
+	}
+	_, _ = fmt.Fprintf(stdout, "ResourceAction %s is valid.\n", ra.Name)
+
+	var m *mock
+	if replay && replayURL == "" {
+		m = &mock{status: mockStatus, body: mockBody}
+		srv := httptest.NewServer(m)
+		defer srv.Close()
+		replayURL = srv.URL
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	sim, simErr := engine.Simulate(ctx, &ra, input, replayURL)
+
+	object := input.Obj.GetName()
+	if ns := input.Obj.GetNamespace(); ns != "" {
+		object = ns + "/" + object
+	}
+	if !sim.Matched {
+		_, _ = fmt.Fprintf(stdout, "%s of %s %s does not match: %s.\n", input.Event, input.GVK.Kind, object, sim.Reason)
+		return exitNotMatch
+	}
+	_, _ = fmt.Fprintf(stdout, "%s of %s %s matches.\n", input.Event, input.GVK.Kind, object)
+
+	if len(sim.Requests) == 0 {
+		_, _ = fmt.Fprintln(stdout, "\nNo event-driven HTTP actions to render.")
+	} else {
+		printYAML(stdout, "Rendered requests", sim.Requests)
+	}
+	if len(sim.Replays) > 0 {
+		results := make([]replayResult, 0, len(sim.Replays))
+		for _, replay := range sim.Replays {
+			result := replayResult{
+				ActionIndex: replay.ActionIndex,
+				ActionName:  replay.ActionName,
+				Request:     replay.Request,
+				Response:    replay.Response,
+				Captured:    replay.Captured,
+				Outputs:     replay.Outputs,
+			}
+			if replay.Err != nil {
+				result.Error = replay.Err.Error()
+			}
+			results = append(results, result)
+		}
+		printYAML(stdout, "Replayed requests", results)
+	}
+	if m != nil && len(m.received) > 0 {
+		printYAML(stdout, "Received by the mock", m.received)
+	}
+	if simErr != nil {
+		return fail(simErr)
+	}
+	return 0
+}
+
+func readYAML(path string, into interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalStrict(data, into); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+func readObject(path string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := readYAML(path, &obj.Object); err != nil {
+		return nil, err
+	}
+	if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+		return nil, fmt.Errorf("%s must set apiVersion and kind", path)
+	}
+	return obj, nil
+}
+
+func printYAML(w io.Writer, title string, value interface{}) {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "\n%s: %v\n", title, err)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "\n%s:\n%s", title, out)
+}
@@ -11,20 +11,31 @@ You may obtain a copy of the License at
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -50,10 +61,36 @@ func init() {
 func main() {
 	var metricsAddr string
 	var probeAddr string
+	var pprofAddr string
 	var enableLeaderElection bool
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var enableWebhook bool
+	var cronMaxConcurrency int
+	var watchNamespaces string
+	var confineNamespaces bool
+	var clusterScopeNamespaces string
+	var configMapLookupNamespaces string
+	var eventWorkers int
+	var cacheStripStatus bool
+	var shutdownGracePeriod time.Duration
+	var statusFlushInterval time.Duration
+	var deferredRetries bool
+	var durableDeliveries bool
+	var shards, shardIndex int
+	var circuitBreakerFailures int
+	var circuitBreakerCooldown time.Duration
+	var httpMaxRPS int
+	var httpMaxRPSPerHost int
+	var allowedURLHosts string
+	var blockedURLHosts string
+	var forbidUnsafeLocalTargets bool
+	var recordMode bool
+	var vaultAddress string
+	var webhookSourceAddr string
+	var cloudEventSink string
+	var auditSink, auditSinkURL string
+	var tracingOpts engine.TracingOptions
 
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -62,6 +99,8 @@ func main() {
 		"The address the metrics endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081",
 		"The address the probe endpoint binds to.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", "0",
+		"The address the net/http/pprof endpoint binds to, for example 127.0.0.1:6060. Use 0 to disable it.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
@@ -70,6 +109,69 @@ func main() {
 		"Enable HTTP/2 for metrics and webhook servers")
 	flag.BoolVar(&enableWebhook, "enable-webhook", false,
 		"Enable admission webhook registration and serving")
+	flag.IntVar(&cronMaxConcurrency, "cron-max-concurrency", 10,
+		"Maximum number of cron action ticks executing concurrently. 0 disables the limit.")
+	flag.IntVar(&eventWorkers, "event-workers", 4,
+		"Number of workers processing informer events concurrently.")
+	flag.IntVar(&circuitBreakerFailures, "circuit-breaker-failures", 5,
+		"Consecutive failures of an HTTP target host after which its circuit opens. 0 disables the circuit breaker.")
+	flag.DurationVar(&circuitBreakerCooldown, "circuit-breaker-cooldown", 30*time.Second,
+		"How long an open circuit short-circuits HTTP attempts before a probe is let through.")
+	flag.IntVar(&httpMaxRPS, "http-max-rps", 0,
+		"Maximum outbound HTTP requests per second across all actions. 0 disables the limit.")
+	flag.IntVar(&httpMaxRPSPerHost, "http-max-rps-per-host", 0,
+		"Maximum outbound HTTP requests per second to a single target host. 0 disables the limit.")
+	flag.StringVar(&allowedURLHosts, "allowed-url-hosts", "",
+		"Comma-separated regular expressions; HTTP actions may only call hosts matching one of them. Empty allows all hosts.")
+	flag.StringVar(&blockedURLHosts, "blocked-url-hosts", "",
+		"Comma-separated regular expressions of hosts HTTP actions may not call.")
+	flag.BoolVar(&forbidUnsafeLocalTargets, "forbid-unsafe-local-targets", false,
+		"Ignore urlPolicy.allowUnsafeLocalTargets, so actions can never call loopback, link-local or private addresses.")
+	flag.BoolVar(&recordMode, "record-mode", false,
+		"Simulate the actions of every ResourceAction like spec.record: executions are stored as simulated ActionExecutions and no requests are sent.")
+	flag.StringVar(&vaultAddress, "vault-address", "",
+		"Address of the HashiCorp Vault server vaultRef values are read from, for example https://vault.example.com:8200.")
+	flag.StringVar(&webhookSourceAddr, "webhook-source-bind-address", "0",
+		"The address the receiver of WebhookSource events binds to. Use 0 to disable it.")
+	flag.StringVar(&cloudEventSink, "cloudevents-sink", os.Getenv("K_SINK"),
+		"URL every matched event is emitted to as a CloudEvent. Defaults to K_SINK, set by a Knative SinkBinding. Empty disables it.")
+	flag.StringVar(&auditSink, "audit-sink", "",
+		"Sink every execution record is exported to: Loki, S3 or Syslog. Empty disables the export.")
+	flag.StringVar(&auditSinkURL, "audit-sink-url", "",
+		"URL of the audit sink: the Loki push API URL, s3://bucket/prefix, or udp://, tcp:// or tls://host:port of a syslog server.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma-separated namespaces the dynamic informers are restricted to. Empty watches all namespaces.")
+	flag.BoolVar(&confineNamespaces, "confine-namespaces", false,
+		"Restrict each ResourceAction to objects in its own namespace.")
+	flag.StringVar(&clusterScopeNamespaces, "cluster-scope-namespaces", "",
+		"Comma-separated namespaces whose ResourceActions are exempt from --confine-namespaces.")
+	flag.StringVar(&configMapLookupNamespaces, "configmap-lookup-namespaces", "",
+		"Comma-separated namespaces whose ConfigMaps configMapValue may read in the templates of every ResourceAction. "+
+			"Empty only allows ConfigMaps in the namespace of the ResourceAction.")
+	flag.BoolVar(&cacheStripStatus, "cache-strip-status", false,
+		"Drop status from objects cached by the informers. Body templates then do not see it. "+
+			"ResourceActions filtering on Status changes keep it.")
+	flag.DurationVar(&shutdownGracePeriod, "shutdown-grace-period", 25*time.Second,
+		"How long shutdown waits for queued events before recording the remaining ones as failed.")
+	flag.DurationVar(&statusFlushInterval, "status-flush-interval", 2*time.Second,
+		"How long execution records are batched before they are written to the status of their ResourceAction "+
+			"with server-side apply. Changes of the Ready condition are written right away. 0 writes every execution right away.")
+	flag.BoolVar(&deferredRetries, "deferred-retries", true,
+		"Retry HTTP actions through the event queue instead of sleeping between attempts, and store the events "+
+			"being retried in the ConfigMap <resourceaction>-pending so they are delivered again after a restart.")
+	flag.BoolVar(&durableDeliveries, "durable-deliveries", false,
+		"Store every event in the ConfigMap <resourceaction>-pending before its actions run, so executions "+
+			"interrupted by a crash or restart are delivered again. Costs two ConfigMap writes per execution.")
+	flag.IntVar(&shards, "shards", 1,
+		"Number of replicas the informer events are sharded across by object UID. Values above 1 require --leader-elect.")
+	flag.IntVar(&shardIndex, "shard-index", -1,
+		"The shard of this replica, from 0 to --shards - 1. -1 takes the ordinal of the StatefulSet pod from POD_NAME or the host name.")
+	flag.StringVar(&tracingOpts.Endpoint, "otlp-endpoint", "",
+		"OTLP gRPC endpoint (host:port) for trace export. Tracing is disabled when empty.")
+	flag.BoolVar(&tracingOpts.Insecure, "otlp-insecure", false,
+		"Disable TLS for the OTLP trace exporter.")
+	flag.Float64Var(&tracingOpts.SampleRatio, "trace-sample-ratio", 1.0,
+		"Fraction of executions that are traced, between 0 and 1.")
 
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "Webhook cert directory")
 	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "Webhook cert name")
@@ -85,6 +187,20 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if shards > 1 {
+		if !enableLeaderElection {
+			setupLog.Error(errors.New("--shards requires --leader-elect"), "invalid sharding")
+			os.Exit(1)
+		}
+		if shardIndex < 0 {
+			var err error
+			if shardIndex, err = podOrdinal(); err != nil {
+				setupLog.Error(err, "unable to determine the shard index, set --shard-index")
+				os.Exit(1)
+			}
+		}
+	}
+
 	disableHTTP2 := func(c *tls.Config) {
 		setupLog.Info("disabling http/2")
 		c.NextProtos = []string{"http/1.1"}
@@ -141,19 +257,52 @@ func main() {
 		})
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		Metrics:                metricsOptions,
-		WebhookServer:          webhookServer,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "4226e2fa.yusaozdemir.de",
+	// Leave time to record undelivered events after the grace period.
+	gracefulShutdownTimeout := shutdownGracePeriod + 15*time.Second
+	restConfig := ctrl.GetConfigOrDie()
+	// The engine resolves selected kinds through the manager's RESTMapper,
+	// so both share one discovery cache.
+	restMapper, err := engine.NewRESTMapper(restConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create REST mapper")
+		os.Exit(1)
+	}
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme: scheme,
+		MapperProvider: func(*rest.Config, *http.Client) (meta.RESTMapper, error) {
+			return restMapper, nil
+		},
+		// The engine caches the Secrets actions read itself, so the manager
+		// does not keep every Secret of the cluster in memory. ConfigMaps are
+		// read from the API server as well: the dedup and pending stores must
+		// see their own writes right away, and the manager would otherwise
+		// cache every ConfigMap of the cluster.
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}}},
+		},
+		Metrics:                 metricsOptions,
+		WebhookServer:           webhookServer,
+		HealthProbeBindAddress:  probeAddr,
+		PprofBindAddress:        pprofAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        "4226e2fa.yusaozdemir.de",
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	urlPolicy, err := opsv1alpha1.NewOperatorURLPolicy(
+		strings.Split(allowedURLHosts, ","),
+		strings.Split(blockedURLHosts, ","),
+		forbidUnsafeLocalTargets,
+	)
+	if err != nil {
+		setupLog.Error(err, "invalid URL policy")
+		os.Exit(1)
+	}
+
 	// =========================
 	// Event Engine initialisieren
 	// =========================
@@ -164,12 +313,58 @@ func main() {
 	}
 
 	exec := engine.NewK8sExecutor(mgr.GetClient(), clientset, mgr.GetEventRecorderFor("resource-action-operator"))
+	exec.SetCircuitBreaker(circuitBreakerFailures, circuitBreakerCooldown)
+	exec.SetHTTPRateLimits(httpMaxRPS, httpMaxRPSPerHost)
+	exec.SetURLPolicy(urlPolicy)
+	exec.SetRecordMode(recordMode)
+	exec.SetStatusFlushInterval(statusFlushInterval)
+	exec.SetDeferredRetries(deferredRetries)
+	exec.SetDurableDeliveries(durableDeliveries)
+	exec.SetVaultAddress(vaultAddress)
+	exec.SetConfigMapLookupNamespaces(strings.Split(configMapLookupNamespaces, ","))
+	if err := exec.SetCloudEventSink(cloudEventSink, os.Getenv("K_CE_OVERRIDES")); err != nil {
+		setupLog.Error(err, "invalid CloudEvent sink")
+		os.Exit(1)
+	}
+	var auditExporter *engine.AuditExporter
+	if auditSink != "" {
+		auditExporter, err = engine.NewAuditExporter(auditSink, auditSinkURL)
+		if err != nil {
+			setupLog.Error(err, "invalid audit sink")
+			os.Exit(1)
+		}
+		exec.SetAuditExporter(auditExporter)
+		auditExporter.Start()
+	}
 
 	eng, err := engine.New(mgr.GetConfig(), exec)
 	if err != nil {
 		setupLog.Error(err, "unable to create event engine")
 		os.Exit(1)
 	}
+	eng.SetRESTMapper(restMapper)
+	eng.SetCronConcurrency(cronMaxConcurrency)
+	eng.SetEventWorkers(eventWorkers)
+	eng.SetCacheStripStatus(cacheStripStatus)
+	eng.SetShutdownGracePeriod(shutdownGracePeriod)
+	if watchNamespaces != "" {
+		eng.SetWatchNamespaces(strings.Split(watchNamespaces, ","))
+	}
+	eng.SetNamespaceConfinement(confineNamespaces, strings.Split(clusterScopeNamespaces, ","))
+	if shards > 1 {
+		if err := eng.SetShard(shardIndex, shards); err != nil {
+			setupLog.Error(err, "invalid sharding")
+			os.Exit(1)
+		}
+		if err := (&controller.ShardReconciler{
+			Client: mgr.GetClient(),
+			Engine: eng,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ResourceActionShard")
+			os.Exit(1)
+		}
+		setupLog.Info("Sharding informer events", "shard", shardIndex, "shards", shards)
+	}
 
 	if err = (&controller.ResourceActionReconciler{
 		Client: mgr.GetClient(),
@@ -180,12 +375,23 @@ func main() {
 		os.Exit(1)
 	}
 	if enableWebhook {
-		if err = (&opsv1alpha1.ResourceAction{}).SetupWebhookWithManager(mgr); err != nil {
+		if err = (&opsv1alpha1.ResourceAction{}).SetupWebhookWithManager(mgr, urlPolicy); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ResourceAction")
 			os.Exit(1)
 		}
 	}
 
+	if err := mgr.Add(eng); err != nil {
+		setupLog.Error(err, "unable to add event engine to manager")
+		os.Exit(1)
+	}
+	if webhookSourceAddr != "0" {
+		if err := mgr.Add(engine.NewWebhookReceiver(webhookSourceAddr, eng, mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to add webhook source receiver to manager")
+			os.Exit(1)
+		}
+	}
+
 	if metricsCertWatcher != nil {
 		_ = mgr.Add(metricsCertWatcher)
 	}
@@ -197,14 +403,51 @@ func main() {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	if err := mgr.AddReadyzCheck("readyz", eng.ReadyCheck(mgr.Elected())); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("informers", eng.InformersHealthy); err != nil {
+		setupLog.Error(err, "unable to set up informer ready check")
+		os.Exit(1)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	shutdownTracing, err := engine.SetupTracing(ctx, tracingOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+	if auditExporter != nil {
+		if err := auditExporter.Shutdown(context.Background()); err != nil {
+			setupLog.Error(err, "failed to flush audit records")
+		}
+	}
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "failed to flush traces")
+	}
+}
+
+// podOrdinal returns the ordinal of the StatefulSet pod the operator runs in,
+// the number after the last dash of its name.
+func podOrdinal() (int, error) {
+	name := os.Getenv("POD_NAME")
+	if name == "" {
+		var err error
+		if name, err = os.Hostname(); err != nil {
+			return 0, err
+		}
+	}
+	i := strings.LastIndex(name, "-")
+	ordinal, err := strconv.Atoi(name[i+1:])
+	if i < 0 || err != nil {
+		return 0, fmt.Errorf("pod name %q has no StatefulSet ordinal", name)
+	}
+	return ordinal, nil
 }
@@ -0,0 +1,289 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+const historyUsage = `Export the execution history of ResourceActions as JSON or CSV.
+
+Usage:
+  raoctl history [flags]
+
+The records are read from status.executions of the ResourceActions and from
+ActionExecution objects, with the credentials of the kubeconfig, so the
+Kubernetes RBAC decides which namespaces can be exported. Records are sorted
+by execution time. Dry runs and simulated executions are left out unless
+--include-simulated is set.
+
+Examples:
+  raoctl history --since 24h --result Failed -o csv > failures.csv
+  raoctl history -n shop --kind Deployment --from 2026-10-01T00:00:00Z
+
+Flags:
+`
+
+// historyEntry is an execution record of a ResourceAction as exported.
+type historyEntry struct {
+	Namespace      string `json:"namespace"`
+	ResourceAction string `json:"resourceAction"`
+	opsv1alpha1.ExecutionRecord
+	Request  *opsv1alpha1.HTTPRequestRecord  `json:"request,omitempty"`
+	Response *opsv1alpha1.HTTPResponseRecord `json:"response,omitempty"`
+}
+
+// historyFilter selects the exported records.
+type historyFilter struct {
+	from, to         time.Time
+	apiVersion, kind string
+	results          []string
+	includeSimulated bool
+}
+
+func (f historyFilter) matches(record opsv1alpha1.ExecutionRecord) bool {
+	executedAt := record.ExecutedAt.Time
+	switch {
+	case !f.from.IsZero() && executedAt.Before(f.from):
+		return false
+	case !f.to.IsZero() && !executedAt.Before(f.to):
+		return false
+	case f.apiVersion != "" && record.ResourceAPIVersion != f.apiVersion:
+		return false
+	case f.kind != "" && !strings.EqualFold(record.ResourceKind, f.kind):
+		return false
+	case len(f.results) > 0 && !slices.Contains(f.results, record.Result):
+		return false
+	case !f.includeSimulated && (record.DryRun || record.Simulated):
+		return false
+	}
+	return true
+}
+
+func runHistory(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("raoctl history", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(stderr, historyUsage)
+		fs.PrintDefaults()
+	}
+	var kubeconfig, kubeContext, namespace, output, results, from, to string
+	var since time.Duration
+	var filter historyFilter
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file. Defaults to KUBECONFIG or ~/.kube/config.")
+	fs.StringVar(&kubeContext, "context", "", "The kubeconfig context to use.")
+	fs.StringVar(&namespace, "n", "", "Only export the ResourceActions of this namespace. Empty exports all namespaces.")
+	fs.StringVar(&namespace, "namespace", "", "Only export the ResourceActions of this namespace. Empty exports all namespaces.")
+	fs.StringVar(&output, "o", "json", "The output format: json or csv.")
+	fs.StringVar(&output, "output", "json", "The output format: json or csv.")
+	fs.DurationVar(&since, "since", 0, "Only export records of the last duration, for example 24h.")
+	fs.StringVar(&from, "from", "", "Only export records executed at or after this RFC 3339 time.")
+	fs.StringVar(&to, "to", "", "Only export records executed before this RFC 3339 time.")
+	fs.StringVar(&filter.apiVersion, "api-version", "", "Only export records of objects of this apiVersion, for example apps/v1.")
+	fs.StringVar(&filter.kind, "kind", "", "Only export records of objects of this kind, for example Deployment.")
+	fs.StringVar(&results, "result", "",
+		"Comma-separated results to export: Succeeded, Failed or PartiallyFailed. Empty exports all results.")
+	fs.BoolVar(&filter.includeSimulated, "include-simulated", false,
+		"Also export dry runs and simulated executions of record mode.")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitUsage
+	}
+
+	usageErr := func(err error) int {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitUsage
+	}
+	if output != "json" && output != "csv" {
+		return usageErr(fmt.Errorf("output must be json or csv, got %q", output))
+	}
+	if since > 0 && from != "" {
+		return usageErr(errors.New("--since and --from are mutually exclusive"))
+	}
+	var err error
+	if since > 0 {
+		filter.from = time.Now().Add(-since)
+	}
+	if from != "" {
+		if filter.from, err = time.Parse(time.RFC3339, from); err != nil {
+			return usageErr(fmt.Errorf("invalid --from: %w", err))
+		}
+	}
+	if to != "" {
+		if filter.to, err = time.Parse(time.RFC3339, to); err != nil {
+			return usageErr(fmt.Errorf("invalid --to: %w", err))
+		}
+	}
+	for _, result := range strings.Split(results, ",") {
+		if result = strings.TrimSpace(result); result != "" {
+			filter.results = append(filter.results, result)
+		}
+	}
+
+	fail := func(err error) int {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitInvalid
+	}
+	c, err := newClient(kubeconfig, kubeContext)
+	if err != nil {
+		return fail(err)
+	}
+	entries, err := listHistory(context.Background(), c, namespace, filter)
+	if err != nil {
+		return fail(err)
+	}
+	if output == "csv" {
+		err = writeHistoryCSV(stdout, entries)
+	} else {
+		err = writeHistoryJSON(stdout, entries)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// newClient returns a client for the cluster of the kubeconfig.
+func newClient(kubeconfig, kubeContext string) (client.Client, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	scheme := runtime.NewScheme()
+	if err := opsv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+// listHistory reads the execution records of the ResourceActions in
+// namespace, all namespaces when empty, and returns those filter matches in
+// order of execution.
+func listHistory(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	filter historyFilter,
+) ([]historyEntry, error) {
+	var opts []client.ListOption
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	var ras opsv1alpha1.ResourceActionList
+	if err := c.List(ctx, &ras, opts...); err != nil {
+		return nil, fmt.Errorf("list ResourceActions: %w", err)
+	}
+	var aes opsv1alpha1.ActionExecutionList
+	if err := c.List(ctx, &aes, opts...); err != nil {
+		return nil, fmt.Errorf("list ActionExecutions: %w", err)
+	}
+
+	var entries []historyEntry
+	for _, ra := range ras.Items {
+		for _, record := range ra.Status.Executions {
+			if filter.matches(record) {
+				entries = append(entries, historyEntry{
+					Namespace:       ra.Namespace,
+					ResourceAction:  ra.Name,
+					ExecutionRecord: record,
+				})
+			}
+		}
+	}
+	for _, ae := range aes.Items {
+		if filter.matches(ae.Spec.ExecutionRecord) {
+			entries = append(entries, historyEntry{
+				Namespace:       ae.Namespace,
+				ResourceAction:  ae.Spec.ResourceAction,
+				ExecutionRecord: ae.Spec.ExecutionRecord,
+				Request:         ae.Spec.Request,
+				Response:        ae.Spec.Response,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ExecutedAt.Before(&entries[j].ExecutedAt)
+	})
+	return entries, nil
+}
+
+func writeHistoryJSON(w io.Writer, entries []historyEntry) error {
+	if entries == nil {
+		entries = []historyEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// historyColumns are the columns of the CSV export.
+var historyColumns = []string{
+	"executedAt", "namespace", "resourceAction", "event",
+	"resourceAPIVersion", "resourceKind", "resourceNamespace", "resourceName",
+	"actionName", "result", "error", "attempts", "durationMillis", "lastHttpStatus",
+	"correlationID", "dryRun", "simulated",
+}
+
+func writeHistoryCSV(w io.Writer, entries []historyEntry) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(historyColumns); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err := out.Write([]string{
+			entry.ExecutedAt.UTC().Format(time.RFC3339),
+			entry.Namespace,
+			entry.ResourceAction,
+			entry.Event,
+			entry.ResourceAPIVersion,
+			entry.ResourceKind,
+			entry.ResourceNamespace,
+			entry.ResourceName,
+			entry.ActionName,
+			entry.Result,
+			entry.Error,
+			strconv.Itoa(entry.Attempts),
+			strconv.FormatInt(entry.DurationMillis, 10),
+			strconv.Itoa(entry.LastHTTPStatus),
+			entry.CorrelationID,
+			strconv.FormatBool(entry.DryRun),
+			strconv.FormatBool(entry.Simulated),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+// Command raoctl works with ResourceActions from outside of the operator.
+// raoctl validate checks manifests against the CRD schema and the validation
+// of the admission webhook, for CI pipelines. raoctl history exports the
+// execution history of a cluster as JSON or CSV.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"de.yusaozdemir.resource-action-operator/config/crd"
+)
+
+const (
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+const usage = `Work with ResourceActions from outside of the operator.
+
+Usage:
+  raoctl validate [flags] FILE|DIR|- ...
+  raoctl history [flags]
+
+Commands:
+  validate  Check ResourceAction manifests against the CRD schema and the
+            validation of the admission webhook.
+  history   Export the execution history of a cluster as JSON or CSV.
+`
+
+const validateUsage = `Check ResourceAction manifests against the CRD schema and the validation of
+the admission webhook: regular expressions, templates, schedules, URLs and
+the operator URL policy set by the flags below.
+
+Usage:
+  raoctl validate [flags] FILE|DIR|- ...
+
+Directories are searched for .yaml, .yml and .json files; - reads standard
+input. Files may hold several documents; documents of other kinds are
+skipped.
+
+Exit codes: 0 when all ResourceActions are valid, 1 when one is invalid or
+a file cannot be read, 2 on wrong usage.
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		_, _ = fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return 0
+	}
+	switch args[0] {
+	case "validate":
+		return runValidate(args[1:], stdin, stdout, stderr)
+	case "history":
+		return runHistory(args[1:], stdout, stderr)
+	default:
+		_, _ = fmt.Fprintf(stderr, "error: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("raoctl validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(stderr, validateUsage)
+		fs.PrintDefaults()
+	}
+	var allowedURLHosts, blockedURLHosts string
+	var forbidUnsafeLocalTargets, quiet bool
+	fs.StringVar(&allowedURLHosts, "allowed-url-hosts", "",
+		"Comma-separated regular expressions; HTTP actions may only call hosts matching one of them. "+
+			"Use the value of the operator flag of the same name.")
+	fs.StringVar(&blockedURLHosts, "blocked-url-hosts", "",
+		"Comma-separated regular expressions of hosts HTTP actions may not call.")
+	fs.BoolVar(&forbidUnsafeLocalTargets, "forbid-unsafe-local-targets", false,
+		"Reject urlPolicy.allowUnsafeLocalTargets like an operator started with this flag.")
+	fs.BoolVar(&quiet, "quiet", false, "Only print invalid ResourceActions.")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		_, _ = fmt.Fprintln(stderr, "error: at least one file or directory is required")
+		fs.Usage()
+		return exitUsage
+	}
+
+	urlPolicy, err := opsv1alpha1.NewOperatorURLPolicy(
+		strings.Split(allowedURLHosts, ","),
+		strings.Split(blockedURLHosts, ","),
+		forbidUnsafeLocalTargets,
+	)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: invalid URL policy: %v\n", err)
+		return exitUsage
+	}
+	schema, err := resourceActionSchema()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitInvalid
+	}
+	v := &validator{
+		schema:  schema,
+		webhook: &opsv1alpha1.ResourceActionCustomValidator{URLPolicy: urlPolicy},
+	}
+
+	files, err := collectFiles(fs.Args())
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitInvalid
+	}
+	var checked, invalid int
+	for _, file := range files {
+		docs, err := readDocuments(file, stdin)
+		if err != nil {
+			_, _ = fmt.Fprintf(stdout, "%s: %v\n", file, err)
+			invalid++
+			continue
+		}
+		for _, doc := range docs {
+			if !doc.isResourceAction() {
+				continue
+			}
+			checked++
+			if errs := v.validate(doc.data); len(errs) > 0 {
+				invalid++
+				_, _ = fmt.Fprintf(stdout, "%s: ResourceAction %s is invalid:\n", doc.source, doc.name())
+				for _, err := range errs {
+					_, _ = fmt.Fprintf(stdout, "  - %v\n", err)
+				}
+				continue
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(stdout, "%s: ResourceAction %s is valid\n", doc.source, doc.name())
+			}
+		}
+	}
+	if !quiet || invalid > 0 {
+		_, _ = fmt.Fprintf(stdout, "\n%d ResourceAction(s) checked, %d invalid\n", checked, invalid)
+	}
+	if invalid > 0 {
+		return exitInvalid
+	}
+	return 0
+}
+
+// validator checks ResourceAction manifests.
+type validator struct {
+	schema  validation.SchemaValidator
+	webhook *opsv1alpha1.ResourceActionCustomValidator
+}
+
+// validate checks a single ResourceAction manifest. The schema is checked
+// first; the webhook validation only runs on manifests that fit it, since
+// decoding fails otherwise.
+func (v *validator) validate(data []byte) []error {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return []error{err}
+	}
+	if errs := validation.ValidateCustomResource(nil, obj, v.schema); len(errs) > 0 {
+		return errs.ToAggregate().Errors()
+	}
+	var ra opsv1alpha1.ResourceAction
+	if err := yaml.UnmarshalStrict(data, &ra); err != nil {
+		return []error{err}
+	}
+	if err := v.webhook.ValidateSpec(ra.Spec); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// resourceActionSchema returns a validator for the schema of the served
+// version of the embedded ResourceAction CRD.
+func resourceActionSchema() (validation.SchemaValidator, error) {
+	var def apiextensionsv1.CustomResourceDefinition
+	if err := yaml.Unmarshal(crd.ResourceAction, &def); err != nil {
+		return nil, fmt.Errorf("parse ResourceAction CRD: %w", err)
+	}
+	for _, version := range def.Spec.Versions {
+		if version.Name != opsv1alpha1.GroupVersion.Version || version.Schema == nil {
+			continue
+		}
+		var props apiextensions.JSONSchemaProps
+		if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+			version.Schema.OpenAPIV3Schema, &props, nil); err != nil {
+			return nil, fmt.Errorf("convert ResourceAction schema: %w", err)
+		}
+		schema, _, err := validation.NewSchemaValidator(&props)
+		if err != nil {
+			return nil, fmt.Errorf("ResourceAction schema: %w", err)
+		}
+		return schema, nil
+	}
+	return nil, fmt.Errorf("ResourceAction CRD has no schema for version %s", opsv1alpha1.GroupVersion.Version)
+}
+
+// document is a single YAML or JSON document of a file.
+type document struct {
+	source string
+	data   []byte
+	meta   *unstructured.Unstructured
+}
+
+func (d document) isResourceAction() bool {
+	return d.meta != nil &&
+		d.meta.GetKind() == "ResourceAction" &&
+		d.meta.GroupVersionKind().Group == opsv1alpha1.GroupVersion.Group
+}
+
+func (d document) name() string {
+	if ns := d.meta.GetNamespace(); ns != "" {
+		return ns + "/" + d.meta.GetName()
+	}
+	return d.meta.GetName()
+}
+
+// collectFiles expands directories in paths to the manifest files in them.
+func collectFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		if path == "-" {
+			files = append(files, path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch filepath.Ext(file) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, file)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// readDocuments splits a file into its documents. Empty documents are
+// dropped; documents that are not objects keep a nil meta.
+func readDocuments(file string, stdin io.Reader) ([]document, error) {
+	var in io.Reader = stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(in))
+	var docs []document
+	for i := 1; ; i++ {
+		data, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		doc := document{source: fmt.Sprintf("%s#%d", file, i), data: data}
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &obj); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(obj) > 0 {
+			doc.meta = &unstructured.Unstructured{Object: obj}
+		}
+		docs = append(docs, doc)
+	}
+}
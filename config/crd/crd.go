@@ -0,0 +1,10 @@
+// Package crd embeds the generated CustomResourceDefinitions, so tools can
+// validate manifests against their schemas without a cluster.
+package crd
+
+import _ "embed"
+
+// ResourceAction is the CustomResourceDefinition of ResourceActions.
+//
+//go:embed bases/ops.yusaozdemir.de_resourceactions.yaml
+var ResourceAction []byte
@@ -2,23 +2,119 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"de.yusaozdemir.resource-action-operator/internal/engine"
+)
+
+const (
+	// watchRecheckInterval is how often the WatchEstablished condition is
+	// refreshed while the informer is not healthy yet.
+	watchRecheckInterval = 15 * time.Second
+	// watchHealthyRecheckInterval is how often a healthy watch is re-checked so
+	// later list/watch failures show up in the status.
+	watchHealthyRecheckInterval = 5 * time.Minute
+	// cleanupRecheckInterval is how often a deleted ResourceAction is
+	// re-checked while its queued events are still being delivered.
+	cleanupRecheckInterval = time.Second
+	// kindRecheckInterval is how often a ResourceAction whose kind is not
+	// served yet is re-checked. Installing a CRD re-checks it right away; the
+	// interval covers remote clusters and aggregated APIs.
+	kindRecheckInterval = time.Minute
+)
+
+const (
+	// reasonWaitingForCRD is the WatchEstablished reason while the selected
+	// kind is not served by the API server.
+	reasonWaitingForCRD = "WaitingForCRD"
+	// reasonNamespaceConfined is the WatchEstablished reason when the
+	// selector reaches outside the namespace of a confined ResourceAction.
+	reasonNamespaceConfined = "NamespaceConfined"
+	// cleanupFinalizer holds a deleted ResourceAction until its schedules,
+	// informers and queued events were cleaned up and its teardown ran.
+	cleanupFinalizer = "ops.yusaozdemir.de/cleanup"
+	// retriggerAnnotation lists comma-separated <uid>/<event> entries to run
+	// again for the ResourceAction regardless of spec.executionPolicy.
+	retriggerAnnotation = "ops.yusaozdemir.de/retrigger"
 )
 
 type WatchEnsurer interface {
 	EnsureWatching(ctx context.Context, gvk schema.GroupVersionKind) error
 }
 
+// ScheduleEnsurer is implemented by engines that support cron actions which
+// run independently of events.
+type ScheduleEnsurer interface {
+	EnsureStandaloneSchedules(ctx context.Context, ra opsv1alpha1.ResourceAction) error
+}
+
+// WatchReleaser is implemented by engines that reference count informers per
+// ResourceAction and stop them once no ResourceAction needs them any more.
+type WatchReleaser interface {
+	EnsureWatchingFor(ctx context.Context, ra *opsv1alpha1.ResourceAction) error
+	ReleaseWatch(ctx context.Context, owner types.NamespacedName)
+}
+
+// WatchHealthReporter is implemented by engines that can report whether the
+// informers of a ResourceAction are synced and able to list and watch.
+type WatchHealthReporter interface {
+	WatchHealth(owner types.NamespacedName) engine.WatchHealth
+}
+
+// Backfiller is implemented by engines that can run the Create actions of a
+// ResourceAction for objects that already exist.
+type Backfiller interface {
+	Backfill(ctx context.Context, ra *opsv1alpha1.ResourceAction) (int, error)
+}
+
+// Retriggerer is implemented by engines that can run an event again for an
+// object that was already handled.
+type Retriggerer interface {
+	Retrigger(ctx context.Context, ra *opsv1alpha1.ResourceAction, uid types.UID, event engine.EventType) error
+}
+
+// ReconcileTracker is implemented by engines whose readiness waits until
+// every ResourceAction was reconciled once.
+type ReconcileTracker interface {
+	Reconciled(owner types.NamespacedName)
+}
+
+// Cleaner is implemented by engines that clean up after a ResourceAction
+// before it is deleted.
+type Cleaner interface {
+	// Cleanup stops the schedules and informers of owner and flushes its
+	// queued events. It returns how many events are still being delivered.
+	Cleanup(ctx context.Context, owner types.NamespacedName) int
+	Teardown(ctx context.Context, ra *opsv1alpha1.ResourceAction) error
+}
+
+// SpecChangeHandler is implemented by engines that apply spec changes to
+// running schedules and the execution history.
+type SpecChangeHandler interface {
+	ResyncSchedules(ctx context.Context, ra opsv1alpha1.ResourceAction) error
+	ClearHistory(ctx context.Context, ra *opsv1alpha1.ResourceAction) error
+}
+
 // ResourceActionReconciler reconciles a ResourceAction object
 type ResourceActionReconciler struct {
 	client.Client
@@ -30,18 +126,41 @@ type ResourceActionReconciler struct {
 // +kubebuilder:rbac:groups=ops.yusaozdemir.de,resources=resourceactions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ops.yusaozdemir.de,resources=resourceactions/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ops.yusaozdemir.de,resources=resourceactions/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ops.yusaozdemir.de,resources=actionexecutions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ops.yusaozdemir.de,resources=webhooksources,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ops.yusaozdemir.de,resources=silences,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 func (r *ResourceActionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	if r.Engine == nil {
 		return ctrl.Result{}, fmt.Errorf("engine is not configured")
 	}
+	if tracker, ok := r.Engine.(ReconcileTracker); ok {
+		defer tracker.Reconciled(req.NamespacedName)
+	}
 
 	var ra opsv1alpha1.ResourceAction
 	if err := r.Get(ctx, req.NamespacedName, &ra); err != nil {
-		// Object deleted: nothing to do.
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		if apierrors.IsNotFound(err) {
+			// Object deleted: release its informer.
+			if cleaner, ok := r.Engine.(Cleaner); ok {
+				cleaner.Cleanup(ctx, req.NamespacedName)
+			} else if releaser, ok := r.Engine.(WatchReleaser); ok {
+				releaser.ReleaseWatch(ctx, req.NamespacedName)
+			}
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !ra.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &ra)
+	}
+	if err := r.ensureFinalizer(ctx, &ra); err != nil {
+		return ctrl.Result{}, err
 	}
 	if err := opsv1alpha1.ValidateResourceActionSpec(ra.Spec); err != nil {
 		logger.Error(err, "invalid ResourceAction spec", "resourceAction", ra.Name)
@@ -61,6 +180,9 @@ func (r *ResourceActionReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		Reason:  "ValidationPassed",
 		Message: "Spec validation passed",
 	})
+	if err := r.setSpecCondition(ctx, ra.Name, ra.Namespace, suspendedCondition(ra.Spec.Suspend)); err != nil {
+		logger.Error(err, "failed to update suspended condition")
+	}
 
 	// Group may be empty for core resources.
 	gvk := schema.GroupVersionKind{
@@ -68,6 +190,9 @@ func (r *ResourceActionReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		Version: ra.Spec.Selector.Version,
 		Kind:    ra.Spec.Selector.Kind,
 	}
+	if err := r.setSummary(ctx, ra.Name, ra.Namespace, gvk.GroupKind().String(), len(ra.Spec.Actions)); err != nil {
+		logger.Error(err, "failed to update status summary")
+	}
 
 	logger.Info("Ensuring watch for resource",
 		"resourceAction", ra.Name,
@@ -75,22 +200,324 @@ func (r *ResourceActionReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	)
 
 	// Ask the engine to ensure this resource type is being watched.
-	if err := r.Engine.EnsureWatching(ctx, gvk); err != nil {
+	if err := r.ensureWatching(ctx, &ra, gvk); err != nil {
+		var notServed *engine.KindNotServedError
+		if errors.As(err, &notServed) {
+			logger.Info("Waiting for the selected kind to be served", "gvk", gvk.String())
+			if updateErr := r.setSpecCondition(ctx, ra.Name, ra.Namespace, metav1.Condition{
+				Type:    "WatchEstablished",
+				Status:  metav1.ConditionFalse,
+				Reason:  reasonWaitingForCRD,
+				Message: fmt.Sprintf("%s is not served by the API server; the watch is established once its CRD is installed", gvk.String()),
+			}); updateErr != nil {
+				logger.Error(updateErr, "failed to update watch condition")
+			}
+			return ctrl.Result{RequeueAfter: kindRecheckInterval}, nil
+		}
+		var confined *engine.NamespaceConfinementError
+		if errors.As(err, &confined) {
+			// Retrying does not help until the spec changes.
+			logger.Info("ResourceAction selects objects outside its namespace", "reason", confined.Reason)
+			if updateErr := r.setSpecCondition(ctx, ra.Name, ra.Namespace, metav1.Condition{
+				Type:    "WatchEstablished",
+				Status:  metav1.ConditionFalse,
+				Reason:  reasonNamespaceConfined,
+				Message: err.Error(),
+			}); updateErr != nil {
+				logger.Error(updateErr, "failed to update watch condition")
+			}
+			return ctrl.Result{}, nil
+		}
 		logger.Error(err, "failed to ensure watching resource", "gvk", gvk.String())
+		if updateErr := r.setSpecCondition(ctx, ra.Name, ra.Namespace, metav1.Condition{
+			Type:    "WatchEstablished",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ResolveFailed",
+			Message: err.Error(),
+		}); updateErr != nil {
+			logger.Error(updateErr, "failed to update watch condition")
+		}
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	if schedules, ok := r.Engine.(ScheduleEnsurer); ok {
+		if err := schedules.EnsureStandaloneSchedules(ctx, ra); err != nil {
+			logger.Error(err, "failed to ensure standalone schedules", "resourceAction", ra.Name)
+			return ctrl.Result{}, err
+		}
+	}
+	if ra.Status.ObservedGeneration != ra.Generation {
+		if err := r.applySpecChange(ctx, &ra); err != nil {
+			logger.Error(err, "failed to apply spec change", "resourceAction", ra.Name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	degradedRecheck, err := r.setDegradedCondition(ctx, &ra)
+	if err != nil {
+		logger.Error(err, "failed to update degraded condition")
+	}
+
+	if reporter, ok := r.Engine.(WatchHealthReporter); ok {
+		health := reporter.WatchHealth(req.NamespacedName)
+		if err := r.setSpecCondition(ctx, ra.Name, ra.Namespace, watchEstablishedCondition(health)); err != nil {
+			logger.Error(err, "failed to update watch condition")
+		}
+		if !health.Healthy() {
+			return ctrl.Result{RequeueAfter: watchRecheckInterval}, nil
+		}
+		if err := r.backfill(ctx, &ra); err != nil {
+			logger.Error(err, "failed to backfill existing objects", "resourceAction", ra.Name)
+			return ctrl.Result{}, err
+		}
+		if err := r.retrigger(ctx, &ra); err != nil {
+			logger.Error(err, "failed to retrigger events", "resourceAction", ra.Name)
+			return ctrl.Result{}, err
+		}
+		if degradedRecheck > 0 && degradedRecheck < watchHealthyRecheckInterval {
+			return ctrl.Result{RequeueAfter: degradedRecheck}, nil
+		}
+		return ctrl.Result{RequeueAfter: watchHealthyRecheckInterval}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: degradedRecheck}, nil
+}
+
+// ensureFinalizer adds the cleanup finalizer when the engine can clean up
+// after the ResourceAction.
+func (r *ResourceActionReconciler) ensureFinalizer(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	if _, ok := r.Engine.(Cleaner); !ok || controllerutil.ContainsFinalizer(ra, cleanupFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(ra, cleanupFinalizer)
+	return r.Update(ctx, ra)
+}
+
+// finalize stops the schedules and informers of a deleted ResourceAction,
+// waits until its queued events were delivered, runs spec.teardown and then
+// releases the object. A failed teardown is reported but does not block the
+// deletion.
+func (r *ResourceActionReconciler) finalize(ctx context.Context, ra *opsv1alpha1.ResourceAction) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	cleaner, ok := r.Engine.(Cleaner)
+	if !ok || !controllerutil.ContainsFinalizer(ra, cleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	if pending := cleaner.Cleanup(ctx, owner); pending > 0 {
+		logger.Info("Waiting for queued events before deletion", "resourceAction", ra.Name, "events", pending)
+		return ctrl.Result{RequeueAfter: cleanupRecheckInterval}, nil
+	}
+	if ra.Spec.Teardown != nil {
+		if err := cleaner.Teardown(ctx, ra); err != nil {
+			logger.Error(err, "teardown action failed", "resourceAction", ra.Name)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(ra, cleanupFinalizer)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Update(ctx, ra))
+}
+
+// ensureWatching registers the watch on behalf of the ResourceAction when the
+// engine reference counts its informers.
+func (r *ResourceActionReconciler) ensureWatching(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	gvk schema.GroupVersionKind,
+) error {
+	if releaser, ok := r.Engine.(WatchReleaser); ok {
+		return releaser.EnsureWatchingFor(ctx, ra)
+	}
+	return r.Engine.EnsureWatching(ctx, gvk)
+}
+
+// applySpecChange restarts the schedules of ra after its spec changed and
+// applies spec.onSpecChange, then records the generation as observed. The
+// first reconcile only records the generation.
+func (r *ResourceActionReconciler) applySpecChange(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	if ra.Status.ObservedGeneration > 0 {
+		onChange := ra.Spec.OnSpecChange
+		if onChange == nil {
+			onChange = &opsv1alpha1.SpecChangeSpec{}
+		}
+		if handler, ok := r.Engine.(SpecChangeHandler); ok {
+			if err := handler.ResyncSchedules(ctx, *ra); err != nil {
+				return err
+			}
+			if onChange.ClearHistory {
+				if err := handler.ClearHistory(ctx, ra); err != nil {
+					return err
+				}
+			}
+		}
+		if onChange.Backfill && ra.Spec.Backfill {
+			meta.RemoveStatusCondition(&ra.Status.Conditions, "Backfilled")
+			if err := r.setSpecCondition(ctx, ra.Name, ra.Namespace, metav1.Condition{
+				Type:    "Backfilled",
+				Status:  metav1.ConditionFalse,
+				Reason:  "SpecChanged",
+				Message: "Existing objects are backfilled again after the spec changed",
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := r.Get(ctx, client.ObjectKeyFromObject(ra), &latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if latest.Status.ObservedGeneration == ra.Generation {
+			return nil
+		}
+		latest.Status.ObservedGeneration = ra.Generation
+		return r.Status().Update(ctx, &latest)
+	})
+}
+
+// backfill runs spec.backfill once the informers have synced. The Backfilled
+// condition is reset while the ResourceAction is suspended, so resuming it
+// backfills again.
+func (r *ResourceActionReconciler) backfill(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	backfiller, ok := r.Engine.(Backfiller)
+	if !ok || !ra.Spec.Backfill {
+		return nil
+	}
+	if ra.Spec.Suspend {
+		return r.setSpecCondition(ctx, ra.Name, ra.Namespace, metav1.Condition{
+			Type:    "Backfilled",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Suspended",
+			Message: "Existing objects are backfilled when the ResourceAction is resumed",
+		})
+	}
+	if meta.IsStatusConditionTrue(ra.Status.Conditions, "Backfilled") {
+		return nil
+	}
+
+	queued, err := backfiller.Backfill(ctx, ra)
+	if err != nil {
+		return err
+	}
+	return r.setSpecCondition(ctx, ra.Name, ra.Namespace, metav1.Condition{
+		Type:    "Backfilled",
+		Status:  metav1.ConditionTrue,
+		Reason:  "Completed",
+		Message: fmt.Sprintf("Queued %d existing objects", queued),
+	})
+}
+
+// retrigger queues the events listed in the retrigger annotation and removes
+// it. The Retriggered condition reports which entries were queued. While the
+// ResourceAction is suspended the annotation is kept, so the events run once
+// it is resumed.
+func (r *ResourceActionReconciler) retrigger(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	retriggerer, ok := r.Engine.(Retriggerer)
+	value, set := ra.GetAnnotations()[retriggerAnnotation]
+	if !ok || !set {
+		return nil
+	}
+	if ra.Spec.Suspend {
+		return r.setSpecCondition(ctx, ra.Name, ra.Namespace, metav1.Condition{
+			Type:    "Retriggered",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Suspended",
+			Message: "The events are retriggered when the ResourceAction is resumed",
+		})
+	}
+
+	queued := 0
+	var failures []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		uid, event, ok := strings.Cut(entry, "/")
+		switch {
+		case !ok || uid == "":
+			failures = append(failures, fmt.Sprintf("%q is not <uid>/<event>", entry))
+		case !slices.Contains(ra.Spec.Events, event):
+			failures = append(failures, fmt.Sprintf("%s: event %q is not in spec.events", uid, event))
+		case event == string(engine.EventWebhook):
+			failures = append(failures, fmt.Sprintf("%s: webhook requests cannot be retriggered", uid))
+		default:
+			if err := retriggerer.Retrigger(ctx, ra, types.UID(uid), engine.EventType(event)); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", uid, err))
+				continue
+			}
+			queued++
+		}
+	}
+
+	cond := metav1.Condition{
+		Type:    "Retriggered",
+		Status:  metav1.ConditionTrue,
+		Reason:  "Queued",
+		Message: fmt.Sprintf("Queued %d events", queued),
+	}
+	if len(failures) > 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "Failed"
+		cond.Message = fmt.Sprintf("Queued %d events; failed: %s", queued, strings.Join(failures, "; "))
+	}
+	if err := r.setSpecCondition(ctx, ra.Name, ra.Namespace, cond); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(ra.DeepCopy())
+	annotations := ra.GetAnnotations()
+	delete(annotations, retriggerAnnotation)
+	ra.SetAnnotations(annotations)
+	return client.IgnoreNotFound(r.Patch(ctx, ra, patch))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ResourceActionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1",
+		Kind:    "CustomResourceDefinition",
+	})
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&opsv1alpha1.ResourceAction{}).
+		WatchesMetadata(crd, handler.EnqueueRequestsFromMapFunc(r.waitingForCRD)).
 		Named("resourceaction").
 		Complete(r)
 }
 
+// waitingForCRD maps a CRD to the ResourceActions waiting for a kind of its
+// group, so their watch is established as soon as the CRD is served. CRD
+// names have the form <plural>.<group>.
+func (r *ResourceActionReconciler) waitingForCRD(ctx context.Context, crd client.Object) []reconcile.Request {
+	_, group, ok := strings.Cut(crd.GetName(), ".")
+	if !ok {
+		return nil
+	}
+	var list opsv1alpha1.ResourceActionList
+	if err := r.List(ctx, &list); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list ResourceActions for CRD", "crd", crd.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, ra := range list.Items {
+		if ra.Spec.Selector.Group != group {
+			continue
+		}
+		cond := meta.FindStatusCondition(ra.Status.Conditions, "WatchEstablished")
+		if cond == nil || cond.Reason != reasonWaitingForCRD {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name},
+		})
+	}
+	return requests
+}
+
 func (r *ResourceActionReconciler) setSpecCondition(
 	ctx context.Context,
 	name string,
@@ -122,3 +549,95 @@ func (r *ResourceActionReconciler) setSpecCondition(
 		return r.Status().Update(ctx, &latest)
 	})
 }
+
+// setDegradedCondition evaluates spec.degraded again, so a failing action
+// turns the ResourceAction Degraded once it is stale even when it does not run
+// again. It returns when to evaluate it next, or 0.
+func (r *ResourceActionReconciler) setDegradedCondition(ctx context.Context, ra *opsv1alpha1.ResourceAction) (time.Duration, error) {
+	var recheck time.Duration
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := r.Get(ctx, client.ObjectKeyFromObject(ra), &latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		before := latest.Status.DeepCopy()
+		recheck = engine.SetDegradedCondition(&latest, time.Now())
+		if equality.Semantic.DeepEqual(before, &latest.Status) {
+			return nil
+		}
+		return r.Status().Update(ctx, &latest)
+	})
+	return recheck, err
+}
+
+// setSummary records the fields shown by the printer columns of
+// kubectl get resourceactions.
+func (r *ResourceActionReconciler) setSummary(
+	ctx context.Context,
+	name string,
+	namespace string,
+	watchedKind string,
+	actionCount int,
+) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if latest.Status.WatchedKind == watchedKind && latest.Status.ActionCount == actionCount {
+			return nil
+		}
+		latest.Status.WatchedKind = watchedKind
+		latest.Status.ActionCount = actionCount
+		return r.Status().Update(ctx, &latest)
+	})
+}
+
+func suspendedCondition(suspend bool) metav1.Condition {
+	if suspend {
+		return metav1.Condition{
+			Type:    "Suspended",
+			Status:  metav1.ConditionTrue,
+			Reason:  "SuspendedBySpec",
+			Message: "Event-driven and cron execution is paused",
+		}
+	}
+	return metav1.Condition{
+		Type:    "Suspended",
+		Status:  metav1.ConditionFalse,
+		Reason:  "Active",
+		Message: "ResourceAction is active",
+	}
+}
+
+func watchEstablishedCondition(health engine.WatchHealth) metav1.Condition {
+	switch {
+	case health.Err != nil:
+		return metav1.Condition{
+			Type:    "WatchEstablished",
+			Status:  metav1.ConditionFalse,
+			Reason:  "WatchFailed",
+			Message: health.Err.Error(),
+		}
+	case !health.Watching:
+		return metav1.Condition{
+			Type:    "WatchEstablished",
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotWatching",
+			Message: "No informer is registered for the selected resource",
+		}
+	case !health.Synced:
+		return metav1.Condition{
+			Type:    "WatchEstablished",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Syncing",
+			Message: "Informer cache has not synced yet",
+		}
+	}
+	return metav1.Condition{
+		Type:    "WatchEstablished",
+		Status:  metav1.ConditionTrue,
+		Reason:  "Synced",
+		Message: "Informer is synced and watching the selected resource",
+	}
+}
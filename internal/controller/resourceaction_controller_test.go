@@ -99,8 +99,11 @@ var _ = Describe("ResourceAction Controller", func() {
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			var got opsv1alpha1.ResourceAction
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &got)).To(Succeed())
+			Expect(got.Status.WatchedKind).To(Equal("Namespace"))
+			Expect(got.Status.ActionCount).To(Equal(1))
 		})
 
 		It("should return an error when engine is not configured", func() {
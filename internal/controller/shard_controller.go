@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"de.yusaozdemir.resource-action-operator/internal/engine"
+)
+
+// ShardReconciler establishes the informers of every ResourceAction on every
+// replica when event processing is sharded across replicas. It does not need
+// leader election and only touches the engine; the ResourceActionReconciler
+// of the leader still writes the status, runs the teardown and removes the
+// finalizer.
+type ShardReconciler struct {
+	client.Client
+	Engine WatchReleaser
+}
+
+func (r *ShardReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var ra opsv1alpha1.ResourceAction
+	if err := r.Get(ctx, req.NamespacedName, &ra); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.release(ctx, req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !ra.DeletionTimestamp.IsZero() {
+		r.release(ctx, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	if err := opsv1alpha1.ValidateResourceActionSpec(ra.Spec); err != nil {
+		// Reported by the leader.
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.Engine.EnsureWatchingFor(ctx, &ra); err != nil {
+		var notServed *engine.KindNotServedError
+		if errors.As(err, &notServed) {
+			return ctrl.Result{RequeueAfter: kindRecheckInterval}, nil
+		}
+		var confined *engine.NamespaceConfinementError
+		if errors.As(err, &confined) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// release stops the informers of a deleted ResourceAction and flushes the
+// events this replica queued for it.
+func (r *ShardReconciler) release(ctx context.Context, owner types.NamespacedName) {
+	if cleaner, ok := r.Engine.(Cleaner); ok {
+		cleaner.Cleanup(ctx, owner)
+		return
+	}
+	r.Engine.ReleaseWatch(ctx, owner)
+}
+
+// SetupWithManager sets up the controller with the Manager. It runs on every
+// replica, not only the leader.
+func (r *ShardReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	needLeaderElection := false
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&opsv1alpha1.ResourceAction{}).
+		WithOptions(controller.Options{NeedLeaderElection: &needLeaderElection}).
+		Named("resourceaction-shard").
+		Complete(r)
+}
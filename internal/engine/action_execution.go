@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	historyModeActionExecution = "ActionExecution"
+
+	labelResourceActionName = "resource-action-operator.yusaozdemir.de/name"
+	labelResourceUID        = "resource-action-operator.yusaozdemir.de/resource-uid"
+	labelEvent              = "resource-action-operator.yusaozdemir.de/event"
+
+	// maxRecordedResponseBody caps the response body stored on an
+	// ActionExecution.
+	maxRecordedResponseBody = 4096
+)
+
+func usesActionExecutions(ra *opsv1alpha1.ResourceAction) bool {
+	return ra.Spec.HistoryMode == historyModeActionExecution
+}
+
+// resourceActionLabel returns the value of labelResourceActionName for the
+// ResourceAction name. Names longer than a label value are truncated and
+// suffixed with a hash of the whole name, so they stay distinct.
+func resourceActionLabel(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:4])
+	return name[:validation.LabelValueMaxLength-len(suffix)-1] + "-" + suffix
+}
+
+func actionExecutionLabels(raName string, uid types.UID, event string) map[string]string {
+	return map[string]string{
+		labelResourceActionName: resourceActionLabel(raName),
+		labelResourceUID:        string(uid),
+		labelEvent:              strings.ToLower(event),
+	}
+}
+
+// executedBefore reports whether the ResourceAction already ran for the given
+// resource and event, looking at ActionExecution objects when the history is
+// kept outside of the status.
+func executedBefore(
+	ctx context.Context,
+	c client.Client,
+	ra *opsv1alpha1.ResourceAction,
+	uid types.UID,
+	event string,
+) (bool, error) {
+	if !usesActionExecutions(ra) {
+		return alreadyExecuted(ra, uid, event), nil
+	}
+
+	var list opsv1alpha1.ActionExecutionList
+	if err := c.List(ctx, &list,
+		client.InNamespace(ra.Namespace),
+		client.MatchingLabels(actionExecutionLabels(ra.Name, uid, event)),
+	); err != nil {
+		return false, err
+	}
+	for _, ae := range list.Items {
+		if !ae.Spec.Simulated {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createActionExecution stores an execution record as an ActionExecution owned
+// by the ResourceAction and prunes older records afterwards. final is the
+// final state of the object of a Delete event, or nil.
+func createActionExecution(
+	ctx context.Context,
+	c client.Client,
+	ra *opsv1alpha1.ResourceAction,
+	record opsv1alpha1.ExecutionRecord,
+	request *opsv1alpha1.HTTPRequestRecord,
+	response *opsv1alpha1.HTTPResponseRecord,
+	final *unstructured.Unstructured,
+) error {
+	ae, err := newActionExecution(c, ra, record)
+	if err != nil {
+		return err
+	}
+	ae.Spec.Request = request
+	ae.Spec.Response = response
+	if err := setFinalState(ae, final); err != nil {
+		return err
+	}
+	if err := c.Create(ctx, ae); err != nil {
+		return err
+	}
+	return pruneActionExecutions(ctx, c, ra, time.Now())
+}
+
+// newActionExecution builds an ActionExecution for record that is owned by
+// the ResourceAction.
+func newActionExecution(
+	c client.Client,
+	ra *opsv1alpha1.ResourceAction,
+	record opsv1alpha1.ExecutionRecord,
+) (*opsv1alpha1.ActionExecution, error) {
+	ae := &opsv1alpha1.ActionExecution{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: ra.Name + "-",
+			Namespace:    ra.Namespace,
+			Labels:       actionExecutionLabels(ra.Name, types.UID(record.ResourceUID), record.Event),
+		},
+		Spec: opsv1alpha1.ActionExecutionSpec{
+			ResourceAction:  ra.Name,
+			ExecutionRecord: record,
+		},
+	}
+	if err := controllerutil.SetOwnerReference(ra, ae, c.Scheme()); err != nil {
+		return nil, err
+	}
+	return ae, nil
+}
+
+// pruneActionExecutions applies spec.historyLimit and spec.historyTTL to the
+// ActionExecution objects of a ResourceAction.
+func pruneActionExecutions(
+	ctx context.Context,
+	c client.Client,
+	ra *opsv1alpha1.ResourceAction,
+	now time.Time,
+) error {
+	if ra.Spec.HistoryLimit == nil && ra.Spec.HistoryTTL == "" {
+		return nil
+	}
+
+	var list opsv1alpha1.ActionExecutionList
+	if err := c.List(ctx, &list,
+		client.InNamespace(ra.Namespace),
+		client.MatchingLabels{labelResourceActionName: resourceActionLabel(ra.Name)},
+	); err != nil {
+		return err
+	}
+
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Spec.ExecutedAt.Before(&items[j].Spec.ExecutedAt)
+	})
+
+	// Reuse the status pruning rules so both history modes behave the same.
+	scratch := opsv1alpha1.ResourceAction{Spec: ra.Spec}
+	for _, item := range items {
+		scratch.Status.Executions = append(scratch.Status.Executions, item.Spec.ExecutionRecord)
+	}
+	pruneExecutions(&scratch, now)
+
+	drop := len(items) - len(scratch.Status.Executions)
+	for i := 0; i < drop; i++ {
+		if err := c.Delete(ctx, &items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateActionExecutionJob refreshes the Job details on the ActionExecution
+// that recorded the given Job. It returns false when no such record exists.
+func updateActionExecutionJob(
+	ctx context.Context,
+	c client.Client,
+	ra opsv1alpha1.ResourceAction,
+	input MatchInput,
+	jobRecord opsv1alpha1.JobExecutionRecord,
+) (bool, error) {
+	var list opsv1alpha1.ActionExecutionList
+	if err := c.List(ctx, &list,
+		client.InNamespace(ra.Namespace),
+		client.MatchingLabels(actionExecutionLabels(ra.Name, input.Obj.GetUID(), string(input.Event))),
+	); err != nil {
+		return false, err
+	}
+
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.Spec.Job == nil || item.Spec.Job.Name != jobRecord.Name {
+			continue
+		}
+		item.Spec.Job = &jobRecord
+		return true, c.Update(ctx, item)
+	}
+	return false, nil
+}
+
+func truncateResponseBody(body []byte) *opsv1alpha1.HTTPResponseRecord {
+	if len(body) <= maxRecordedResponseBody {
+		return &opsv1alpha1.HTTPResponseRecord{Body: string(body)}
+	}
+	return &opsv1alpha1.HTTPResponseRecord{
+		Body:      string(body[:maxRecordedResponseBody]),
+		Truncated: true,
+	}
+}
@@ -0,0 +1,309 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// aggregationFlushTimeout bounds delivering the collected batches on shutdown.
+const aggregationFlushTimeout = 10 * time.Second
+
+// AggregationFlusher is implemented by executors that collect events for
+// spec.aggregation and can deliver the pending batches right away.
+type AggregationFlusher interface {
+	FlushAggregations(ctx context.Context)
+}
+
+// aggregator holds the batches of events collected for spec.aggregation, one
+// per ResourceAction and group of aggregation.groupBy.
+type aggregator struct {
+	mu      sync.Mutex
+	batches map[batchKey]*eventBatch
+	// resolutions holds the groups of aggregation.resolveAfter whose last
+	// batch was delivered and that wait for their events to stop.
+	resolutions map[batchKey]*groupResolution
+}
+
+// batchKey identifies a batch by its ResourceAction and the fingerprint of
+// its group, which is empty without aggregation.groupBy.
+type batchKey struct {
+	ra          types.NamespacedName
+	fingerprint string
+}
+
+type eventBatch struct {
+	inputs []MatchInput
+	group  *eventGroup
+	// ctx carries the logger of the first event and is not cancelled with
+	// it, as the batch is delivered later.
+	ctx          context.Context
+	timer        *time.Timer
+	resolveAfter time.Duration
+}
+
+// groupResolution delivers the last batch of a group again as resolved once
+// its timer fires.
+type groupResolution struct {
+	inputs []MatchInput
+	group  *eventGroup
+	ctx    context.Context
+	timer  *time.Timer
+}
+
+func newAggregator() *aggregator {
+	return &aggregator{
+		batches:     map[batchKey]*eventBatch{},
+		resolutions: map[batchKey]*groupResolution{},
+	}
+}
+
+// aggregate adds input to the batch of ra and its group. The batch is
+// delivered when aggregation.window has passed since its first event or when
+// it holds aggregation.maxEvents events. A newer event of an object replaces
+// the one already in the batch unless every event is executed. An event keeps
+// a group that waits for its resolution firing.
+func (e *K8sExecutor) aggregate(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput) {
+	spec := ra.Spec.Aggregation
+	group, err := groupOf(e.compiled.get(ra), ra, input)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to render aggregation.groupBy, the labels are empty",
+			"resourceAction", ra.Name,
+			"name", input.Obj.GetName(),
+		)
+	}
+	key := batchKey{ra: types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}}
+	if group != nil {
+		key.fingerprint = group.fingerprint
+	}
+	a := e.batches
+
+	a.mu.Lock()
+	if resolution := a.resolutions[key]; resolution != nil {
+		// A resolution that fired already sees the new batch and leaves
+		// the group to its delivery.
+		resolution.timer.Stop()
+	}
+	batch := a.batches[key]
+	if batch == nil {
+		batch = &eventBatch{
+			group:        group,
+			ctx:          context.WithoutCancel(ctx),
+			resolveAfter: parseDurationDefault(spec.ResolveAfter, 0),
+		}
+		a.batches[key] = batch
+		if window := parseDurationDefault(spec.Window, 0); window > 0 {
+			batch.timer = time.AfterFunc(window, func() { e.deliverBatch(key, batch) })
+		}
+	}
+	replaced := false
+	if executionPolicy(ra) != executionPolicyEveryEvent {
+		for i, queued := range batch.inputs {
+			if queued.Event == input.Event && queued.Obj.GetUID() == input.Obj.GetUID() {
+				batch.inputs[i] = input
+				replaced = true
+				break
+			}
+		}
+	}
+	if !replaced {
+		batch.inputs = append(batch.inputs, input)
+	}
+	size := len(batch.inputs)
+	full := spec.MaxEvents > 0 && size >= spec.MaxEvents
+	a.mu.Unlock()
+
+	log.FromContext(ctx).Info("Collected event for aggregation",
+		"resourceAction", ra.Name,
+		"event", input.Event,
+		"name", input.Obj.GetName(),
+		"fingerprint", key.fingerprint,
+		"batchSize", size,
+	)
+	if full {
+		go e.deliverBatch(key, batch)
+	}
+}
+
+// takeBatch removes batch from the pending batches. It returns false when
+// the batch was already taken for delivery.
+func (a *aggregator) takeBatch(key batchKey, batch *eventBatch) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.batches[key] != batch {
+		return false
+	}
+	delete(a.batches, key)
+	if batch.timer != nil {
+		batch.timer.Stop()
+	}
+	return true
+}
+
+// deliverBatch executes the actions of the ResourceAction of key once for the
+// events of batch. With aggregation.resolveAfter, the group of the batch then
+// waits for its resolution.
+func (e *K8sExecutor) deliverBatch(key batchKey, batch *eventBatch) {
+	if !e.batches.takeBatch(key, batch) {
+		return
+	}
+	if batch.group != nil && batch.resolveAfter > 0 {
+		e.batches.awaitResolution(key, batch, func(resolution *groupResolution) {
+			e.resolveGroup(key, resolution)
+		})
+	}
+	e.executeBatch(batch.ctx, key.ra, batch.inputs, batch.group)
+}
+
+// awaitResolution replaces the resolution of the group of key with one for
+// batch, which calls resolve after batch.resolveAfter.
+func (a *aggregator) awaitResolution(key batchKey, batch *eventBatch, resolve func(*groupResolution)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if previous := a.resolutions[key]; previous != nil {
+		previous.timer.Stop()
+	}
+	resolution := &groupResolution{inputs: batch.inputs, group: batch.group, ctx: batch.ctx}
+	resolution.timer = time.AfterFunc(batch.resolveAfter, func() { resolve(resolution) })
+	a.resolutions[key] = resolution
+}
+
+// takeResolution removes resolution from the groups waiting for their
+// resolution. It returns false when it was replaced or a new batch of the
+// group is being collected, whose delivery waits for the resolution again.
+func (a *aggregator) takeResolution(key batchKey, resolution *groupResolution) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.resolutions[key] != resolution || a.batches[key] != nil {
+		return false
+	}
+	delete(a.resolutions, key)
+	return true
+}
+
+// resolveGroup executes the actions of the ResourceAction of key once more
+// for the last batch of a group whose events stopped.
+func (e *K8sExecutor) resolveGroup(key batchKey, resolution *groupResolution) {
+	if !e.batches.takeResolution(key, resolution) {
+		return
+	}
+	group := *resolution.group
+	group.resolved = true
+	e.executeBatch(resolution.ctx, key.ra, resolution.inputs, &group)
+}
+
+func (e *K8sExecutor) executeBatch(ctx context.Context, key types.NamespacedName, inputs []MatchInput, group *eventGroup) {
+	logger := log.FromContext(ctx)
+	var ra opsv1alpha1.ResourceAction
+	if err := e.Client.Get(ctx, key, &ra); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Dropping aggregated events, the ResourceAction was deleted",
+				"resourceAction", key.Name,
+				"events", len(inputs),
+			)
+			return
+		}
+		logger.Error(err, "failed to get resourceaction for aggregated events", "resourceAction", key.Name)
+		return
+	}
+	if group.status() == groupStatusResolved && (ra.Spec.Aggregation == nil || ra.Spec.Aggregation.ResolveAfter == "") {
+		// resolveAfter was removed while the group waited.
+		return
+	}
+
+	// The batch is delivered with the latest event; the others only add
+	// their objects to the request bodies.
+	input := inputs[len(inputs)-1]
+	input.batch = inputs
+	input.group = group
+	if err := e.executeFor(ctx, ra, input); err != nil {
+		logger.Error(err, "aggregated execution failed",
+			"resourceAction", key.Name,
+			"events", len(inputs),
+		)
+	}
+}
+
+// FlushAggregations delivers every pending batch right away. Groups waiting
+// for their resolution are dropped.
+func (e *K8sExecutor) FlushAggregations(ctx context.Context) {
+	a := e.batches
+	a.mu.Lock()
+	pending := make(map[batchKey]*eventBatch, len(a.batches))
+	for key, batch := range a.batches {
+		pending[key] = batch
+	}
+	for key, resolution := range a.resolutions {
+		resolution.timer.Stop()
+		delete(a.resolutions, key)
+	}
+	a.mu.Unlock()
+
+	for key, batch := range pending {
+		if e.batches.takeBatch(key, batch) {
+			e.executeBatch(ctx, key.ra, batch.inputs, batch.group)
+		}
+	}
+}
+
+// events returns the events in delivers: those of an aggregated batch, or
+// in itself.
+func (in MatchInput) events() []MatchInput {
+	if in.batch != nil {
+		return in.batch
+	}
+	return []MatchInput{in}
+}
+
+// batchObjects returns the objects of an aggregated batch, or nil.
+func (in MatchInput) batchObjects() []*unstructured.Unstructured {
+	if in.batch == nil {
+		return nil
+	}
+	objects := make([]*unstructured.Unstructured, 0, len(in.batch))
+	for _, event := range in.batch {
+		objects = append(objects, event.Obj)
+	}
+	return objects
+}
+
+// renderBody renders the body of the request of h for obj. For an aggregated
+// batch it is a JSON array with the body rendered for every object of the
+// batch, or the object itself when the action has no body. Bodies that are
+// not JSON are added as strings.
+func (h *HTTPExecutor) renderBody(spec *opsv1alpha1.TemplateSpec, obj *unstructured.Unstructured) ([]byte, string, error) {
+	if h.batch == nil {
+		return renderBody(h.compiled, spec, obj.Object, h.templateFuncs())
+	}
+
+	elements := make([]json.RawMessage, 0, len(h.batch))
+	for i, item := range h.batch {
+		var element []byte
+		var err error
+		if spec == nil {
+			element, err = json.Marshal(item.Object)
+		} else {
+			element, _, err = renderBody(h.compiled, spec, item.Object, h.templateFuncs())
+			if err == nil && !json.Valid(element) {
+				element, err = json.Marshal(string(element))
+			}
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("event %d of the batch: %w", i, err)
+		}
+		elements = append(elements, element)
+	}
+	body, err := json.Marshal(elements)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, defaultBodyContentType, nil
+}
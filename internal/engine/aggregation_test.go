@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type batchReceiver struct {
+	mu     sync.Mutex
+	bodies []string
+	types  []string
+	keys   []string
+}
+
+func (r *batchReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, string(body))
+	r.types = append(r.types, req.Header.Get("Content-Type"))
+	r.keys = append(r.keys, req.Header.Get(defaultIdempotencyKeyHeader))
+}
+
+func (r *batchReceiver) waitForRequests(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		bodies := append([]string(nil), r.bodies...)
+		r.mu.Unlock()
+		if len(bodies) >= n || time.Now().After(deadline) {
+			return bodies
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func getResourceAction(t *testing.T, cl client.Client, ra *opsv1alpha1.ResourceAction) opsv1alpha1.ResourceAction {
+	t.Helper()
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	return got
+}
+
+func TestExecute_AggregationDeliversFullBatch(t *testing.T) {
+	receiver := &batchReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-aggregation", Namespace: "default", UID: "ra-uid"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:    opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:      []string{"Create"},
+			Aggregation: &opsv1alpha1.AggregationSpec{Window: "1h", MaxEvents: 3},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:           "http",
+				URL:            srv.URL,
+				URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:           &opsv1alpha1.TemplateSpec{Template: `{"pod":"{{ .metadata.name }}"}`},
+				IdempotencyKey: &opsv1alpha1.IdempotencyKeySpec{},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	inputs := []MatchInput{
+		newDeploymentInput("uid-1", "a", "default"),
+		newDeploymentInput("uid-2", "b", "default"),
+		newDeploymentInput("uid-3", "c", "default"),
+	}
+	for _, input := range inputs[:2] {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if bodies := receiver.waitForRequests(t, 0); len(bodies) != 0 {
+		t.Fatalf("bodies = %v, want no request before the batch is full", bodies)
+	}
+	// A repeated event of a collected object does not grow the batch.
+	if err := exec.Execute(context.Background(), inputs[1]); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if err := exec.Execute(context.Background(), inputs[2]); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	bodies := receiver.waitForRequests(t, 1)
+	if len(bodies) != 1 || bodies[0] != `[{"pod":"a"},{"pod":"b"},{"pod":"c"}]` {
+		t.Fatalf("bodies = %v, want one request with the three events", bodies)
+	}
+	if receiver.types[0] != defaultBodyContentType || receiver.keys[0] == "" {
+		t.Fatalf("content type = %q, idempotency key = %q; want JSON with a key", receiver.types[0], receiver.keys[0])
+	}
+
+	var got opsv1alpha1.ResourceAction
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got = getResourceAction(t, cl, ra)
+		if len(got.Status.Executions) > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(got.Status.Executions) != 1 || got.Status.Executions[0].BatchSize != 3 {
+		t.Fatalf("executions = %+v, want one record of the batch", got.Status.Executions)
+	}
+
+	// The delivered events were recorded for deduplication.
+	if err := exec.Execute(context.Background(), inputs[0]); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	exec.FlushAggregations(context.Background())
+	if bodies := receiver.waitForRequests(t, 1); len(bodies) != 1 {
+		t.Fatalf("bodies = %v, want the delivered event to be skipped", bodies)
+	}
+}
+
+func TestExecute_AggregationWindow(t *testing.T) {
+	receiver := &batchReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-aggregation", Namespace: "default", UID: "ra-uid"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:    opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:      []string{"Create"},
+			Aggregation: &opsv1alpha1.AggregationSpec{Window: "50ms"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:           "http",
+				URL:            srv.URL,
+				URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				IdempotencyKey: &opsv1alpha1.IdempotencyKeySpec{},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	for _, input := range []MatchInput{
+		newDeploymentInput("uid-1", "a", "default"),
+		newDeploymentInput("uid-2", "b", "default"),
+	} {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	bodies := receiver.waitForRequests(t, 1)
+	if len(bodies) != 1 {
+		t.Fatalf("bodies = %v, want one request after the window", bodies)
+	}
+	want := `[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"a","namespace":"default","uid":"uid-1"}},` +
+		`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"b","namespace":"default","uid":"uid-2"}}]`
+	if bodies[0] != want {
+		t.Fatalf("body = %s, want the objects without a body template", bodies[0])
+	}
+}
+
+func TestFlushAggregations(t *testing.T) {
+	receiver := &batchReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-aggregation", Namespace: "default", UID: "ra-uid"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:    opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:      []string{"Create"},
+			Aggregation: &opsv1alpha1.AggregationSpec{Window: "1h"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:           "http",
+				URL:            srv.URL,
+				URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:           &opsv1alpha1.TemplateSpec{Template: "{{ .metadata.name }} deleted", ContentType: "text/plain"},
+				IdempotencyKey: &opsv1alpha1.IdempotencyKeySpec{},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-1", "a", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	exec.FlushAggregations(context.Background())
+
+	if len(receiver.bodies) != 1 || receiver.bodies[0] != `["a deleted"]` {
+		t.Fatalf("bodies = %v, want the pending batch with the body as a string", receiver.bodies)
+	}
+	if got := getResourceAction(t, cl, ra); len(got.Status.Executions) != 1 {
+		t.Fatalf("executions = %+v, want the flushed batch to be recorded", got.Status.Executions)
+	}
+}
+
+func TestExecute_AggregationGroupsAndResolves(t *testing.T) {
+	receiver := &batchReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-aggregation", Namespace: "default", UID: "ra-uid"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Aggregation: &opsv1alpha1.AggregationSpec{
+				Window:       "50ms",
+				GroupBy:      map[string]string{"namespace": "{{ .metadata.namespace }}"},
+				ResolveAfter: "200ms",
+			},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:           "http",
+				URL:            srv.URL,
+				URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:           &opsv1alpha1.TemplateSpec{Template: `{"name":"{{ .metadata.name }}","status":"{{ groupStatus }}","namespace":"{{ index groupLabels "namespace" }}"}`},
+				IdempotencyKey: &opsv1alpha1.IdempotencyKeySpec{},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	for _, input := range []MatchInput{
+		newDeploymentInput("uid-1", "a", "team-a"),
+		newDeploymentInput("uid-2", "b", "team-b"),
+		newDeploymentInput("uid-3", "c", "team-a"),
+	} {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	bodies := receiver.waitForRequests(t, 4)
+	slices.Sort(bodies)
+	want := []string{
+		`[{"name":"a","status":"firing","namespace":"team-a"},{"name":"c","status":"firing","namespace":"team-a"}]`,
+		`[{"name":"a","status":"resolved","namespace":"team-a"},{"name":"c","status":"resolved","namespace":"team-a"}]`,
+		`[{"name":"b","status":"firing","namespace":"team-b"}]`,
+		`[{"name":"b","status":"resolved","namespace":"team-b"}]`,
+	}
+	if !slices.Equal(bodies, want) {
+		t.Fatalf("bodies = %v, want a firing and a resolved batch per namespace", bodies)
+	}
+	receiver.mu.Lock()
+	keys := map[string]bool{}
+	for _, key := range receiver.keys {
+		keys[key] = true
+	}
+	receiver.mu.Unlock()
+	if len(keys) != 4 {
+		t.Fatalf("idempotency keys = %v, want the resolution to get a key of its own", receiver.keys)
+	}
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	webhookFormatAlertmanager = "Alertmanager"
+
+	// alertGroupField holds the notification an Alertmanager alert came with.
+	alertGroupField = "alertGroup"
+
+	alertStatusEnv      = "ALERT_STATUS"
+	alertFingerprintEnv = "ALERT_FINGERPRINT"
+	alertAnnotationsEnv = "ALERT_ANNOTATIONS"
+	alertLabelEnvPrefix = "ALERT_LABEL_"
+)
+
+var errNotAlertmanagerNotification = errors.New("request body is not an Alertmanager notification")
+
+// alertLabelEnvName matches the alert labels passed to job actions as
+// environment variables. Prometheus label names always match.
+var alertLabelEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// alertmanagerAlerts splits an Alertmanager webhook notification into its
+// alerts and the rest of the notification, which each alert event gets as
+// .alertGroup.
+func alertmanagerAlerts(payload interface{}) ([]interface{}, map[string]interface{}, error) {
+	notification, ok := payload.(map[string]interface{})
+	if !ok {
+		return nil, nil, errNotAlertmanagerNotification
+	}
+	alerts, ok := notification["alerts"].([]interface{})
+	if !ok {
+		return nil, nil, errNotAlertmanagerNotification
+	}
+	for _, alert := range alerts {
+		if _, ok := alert.(map[string]interface{}); !ok {
+			return nil, nil, errNotAlertmanagerNotification
+		}
+	}
+	group := make(map[string]interface{}, len(notification))
+	for key, value := range notification {
+		if key != "alerts" {
+			group[key] = value
+		}
+	}
+	return alerts, group, nil
+}
+
+// alertEventObjects returns the objects of the Webhook events of an
+// Alertmanager notification: one per alert, with the alert as .payload.
+func alertEventObjects(source *opsv1alpha1.WebhookSource, payload interface{}) ([]*unstructured.Unstructured, error) {
+	alerts, group, err := alertmanagerAlerts(payload)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]*unstructured.Unstructured, 0, len(alerts))
+	for _, alert := range alerts {
+		obj, err := webhookEventObject(source, alert)
+		if err != nil {
+			return nil, err
+		}
+		obj.Object[alertGroupField] = runtime.DeepCopyJSONValue(group)
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// alertOf returns the alert of an event of a WebhookSource with format
+// Alertmanager.
+func alertOf(input MatchInput) (map[string]interface{}, bool) {
+	if input.Event != EventWebhook || input.Obj == nil {
+		return nil, false
+	}
+	if _, ok := input.Obj.Object[alertGroupField]; !ok {
+		return nil, false
+	}
+	alert, ok := input.Obj.Object["payload"].(map[string]interface{})
+	return alert, ok
+}
+
+// matchesAlert reports whether input is an Alertmanager alert matching
+// filter.
+func matchesAlert(filter *opsv1alpha1.AlertFilter, input MatchInput) bool {
+	alert, ok := alertOf(input)
+	if !ok {
+		return false
+	}
+	if filter.Status != "" {
+		if status, _, _ := unstructured.NestedString(alert, "status"); status != filter.Status {
+			return false
+		}
+	}
+	labels, _, _ := unstructured.NestedStringMap(alert, "labels")
+	for key, value := range filter.Labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// alertEnv passes the alert of an Alertmanager event to job actions: its
+// status, fingerprint and annotations as JSON, and every label as
+// ALERT_LABEL_<NAME>.
+func alertEnv(input MatchInput) []corev1.EnvVar {
+	alert, ok := alertOf(input)
+	if !ok {
+		return nil
+	}
+	status, _, _ := unstructured.NestedString(alert, "status")
+	fingerprint, _, _ := unstructured.NestedString(alert, "fingerprint")
+	env := []corev1.EnvVar{
+		{Name: alertStatusEnv, Value: status},
+		{Name: alertFingerprintEnv, Value: fingerprint},
+	}
+	if annotations, ok := alert["annotations"]; ok {
+		if raw, err := json.Marshal(annotations); err == nil {
+			env = append(env, corev1.EnvVar{Name: alertAnnotationsEnv, Value: string(raw)})
+		}
+	}
+
+	labels, _, _ := unstructured.NestedStringMap(alert, "labels")
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if alertLabelEnvName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		env = append(env, corev1.EnvVar{Name: alertLabelEnvPrefix + strings.ToUpper(name), Value: labels[name]})
+	}
+	return env
+}
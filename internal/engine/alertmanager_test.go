@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testAlertmanagerNotification = `{
+  "version": "4",
+  "status": "firing",
+  "receiver": "operator",
+  "groupLabels": {"alertname": "PodCrashLooping"},
+  "alerts": [
+    {"status": "firing", "fingerprint": "a1", "labels": {"alertname": "PodCrashLooping", "namespace": "shop", "deployment": "cart"},
+     "annotations": {"summary": "cart is crash looping"}},
+    {"status": "resolved", "fingerprint": "b2", "labels": {"alertname": "PodCrashLooping", "namespace": "shop", "deployment": "checkout"}}
+  ]
+}`
+
+func alertEventInputs(t *testing.T) []MatchInput {
+	t.Helper()
+	source := &opsv1alpha1.WebhookSource{ObjectMeta: metav1.ObjectMeta{Name: "alerts", Namespace: "ops", UID: "uid-source"}}
+	var payload interface{}
+	if err := json.Unmarshal([]byte(testAlertmanagerNotification), &payload); err != nil {
+		t.Fatalf("decode notification: %v", err)
+	}
+	objects, err := alertEventObjects(source, payload)
+	if err != nil {
+		t.Fatalf("alertEventObjects() error = %v", err)
+	}
+	inputs := make([]MatchInput, 0, len(objects))
+	for _, obj := range objects {
+		inputs = append(inputs, MatchInput{Event: EventWebhook, GVK: webhookSourceGVK, Obj: obj})
+	}
+	return inputs
+}
+
+func TestWebhookReceiver_AlertmanagerNotification(t *testing.T) {
+	r, e := newWebhookTestReceiver(t, webhookFormatAlertmanager, "Authorization")
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/ops/alerts", strings.NewReader(testAlertmanagerNotification))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+
+	// Both alerts are queued for the same object, so the second waits for
+	// the first to be delivered.
+	item, _ := e.queue.Get()
+	first := e.takeInput(item)
+	e.queue.Done(item)
+	e.finish(item)
+	item, _ = e.queue.Get()
+	second := e.takeInput(item)
+	e.queue.Done(item)
+
+	for i, want := range []string{"cart", "checkout"} {
+		input := []MatchInput{first, second}[i]
+		deployment, _, _ := unstructured.NestedString(input.Obj.Object, "payload", "labels", "deployment")
+		receiver, _, _ := unstructured.NestedString(input.Obj.Object, alertGroupField, "receiver")
+		if input.Event != EventWebhook || deployment != want || receiver != "operator" {
+			t.Fatalf("event %d = %v, want the alert of %s with its group", i, input.Obj.Object, want)
+		}
+		if _, ok := input.Obj.Object[alertGroupField].(map[string]interface{})["alerts"]; ok {
+			t.Fatalf("alertGroup of event %d still holds the alerts", i)
+		}
+	}
+
+	if code := postWebhook(r, "/webhooks/ops/alerts", "s3cret", `{"alert":"DiskFull"}`); code != http.StatusUnauthorized {
+		t.Fatalf("status with the default header = %d, want %d", code, http.StatusUnauthorized)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/webhooks/ops/alerts", strings.NewReader(`{"alert":"DiskFull"}`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status of a body without alerts = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestExecute_AlertFilter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if body, _ := io.ReadAll(r.Body); string(body) != "shop/cart" {
+			t.Errorf("body = %s, want the deployment of the matching alert", body)
+		}
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-alerts", Namespace: "ops"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "ops.yusaozdemir.de", Version: "v1alpha1", Kind: "WebhookSource"},
+			Events:   []string{"Webhook"},
+			Filters: &opsv1alpha1.FilterSpec{Alert: &opsv1alpha1.AlertFilter{
+				Labels: map[string]string{"alertname": "PodCrashLooping"},
+				Status: "firing",
+			}},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body: &opsv1alpha1.TemplateSpec{
+					Template:    "{{ .payload.labels.namespace }}/{{ .payload.labels.deployment }}",
+					ContentType: "text/plain",
+				},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	for _, input := range alertEventInputs(t) {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want only the firing alert to run", calls.Load())
+	}
+
+	// Webhook events that are no alerts never match an alert filter.
+	obj, err := webhookEventObject(&opsv1alpha1.WebhookSource{ObjectMeta: metav1.ObjectMeta{Name: "alerts", Namespace: "ops"}},
+		map[string]interface{}{"status": "firing", "labels": map[string]interface{}{"alertname": "PodCrashLooping"}})
+	if err != nil {
+		t.Fatalf("webhookEventObject() error = %v", err)
+	}
+	if matchesFilters(nil, ra, MatchInput{Event: EventWebhook, GVK: webhookSourceGVK, Obj: obj}) {
+		t.Fatalf("expected a plain webhook event not to match the alert filter")
+	}
+}
+
+func TestAlertEnv(t *testing.T) {
+	env := alertEnv(alertEventInputs(t)[0])
+	want := []corev1.EnvVar{
+		{Name: alertStatusEnv, Value: "firing"},
+		{Name: alertFingerprintEnv, Value: "a1"},
+		{Name: alertAnnotationsEnv, Value: `{"summary":"cart is crash looping"}`},
+		{Name: "ALERT_LABEL_ALERTNAME", Value: "PodCrashLooping"},
+		{Name: "ALERT_LABEL_DEPLOYMENT", Value: "cart"},
+		{Name: "ALERT_LABEL_NAMESPACE", Value: "shop"},
+	}
+	if len(env) != len(want) {
+		t.Fatalf("env = %v, want %v", env, want)
+	}
+	for i := range want {
+		if env[i] != want[i] {
+			t.Fatalf("env[%d] = %v, want %v", i, env[i], want[i])
+		}
+	}
+
+	if env := alertEnv(newDeploymentInput("uid-1", "demo", "default")); env != nil {
+		t.Fatalf("env = %v, want none for other events", env)
+	}
+}
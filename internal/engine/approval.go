@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	approveAnnotation = "resource-action-operator.yusaozdemir.de/approve"
+	rejectAnnotation  = "resource-action-operator.yusaozdemir.de/reject"
+
+	actionStatePendingApproval = "PendingApproval"
+
+	defaultApprovalTimeout = time.Hour
+	// approvalPollInterval is how often a paused execution checks the
+	// annotations of its ResourceAction.
+	approvalPollInterval = 10 * time.Second
+)
+
+// awaitApproval checks the approval of the action at actionIndex for the
+// execution of progress. It returns zero once the action was approved, the
+// delay until the next check while it is pending, or an error when it was
+// rejected or timed out.
+func (e *K8sExecutor) awaitApproval(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	actionIndex int,
+	action opsv1alpha1.ActionSpec,
+	progress *executionProgress,
+) (time.Duration, error) {
+	switch {
+	case annotationListsID(ra, approveAnnotation, progress.correlationID):
+		progress.approvalRequestedAt = time.Time{}
+		return 0, nil
+	case annotationListsID(ra, rejectAnnotation, progress.correlationID):
+		progress.approvalRequestedAt = time.Time{}
+		return 0, errors.New("rejected")
+	}
+
+	timeout := parseDurationDefault(action.Approval.Timeout, defaultApprovalTimeout)
+	if progress.approvalRequestedAt.IsZero() {
+		if err := e.requestApproval(ctx, ra, actionIndex, progress.correlationID); err != nil {
+			return 0, err
+		}
+		progress.approvalRequestedAt = time.Now()
+		return min(approvalPollInterval, timeout), nil
+	}
+	remaining := timeout - time.Since(progress.approvalRequestedAt)
+	if remaining <= 0 {
+		progress.approvalRequestedAt = time.Time{}
+		return 0, fmt.Errorf("timed out after %s", timeout)
+	}
+	return min(approvalPollInterval, remaining), nil
+}
+
+// requestApproval marks the action at actionIndex as PendingApproval and
+// reports how to approve it.
+func (e *K8sExecutor) requestApproval(ctx context.Context, ra *opsv1alpha1.ResourceAction, actionIndex int, correlationID string) error {
+	message := fmt.Sprintf("execution %s waits for approval: set annotation %s or %s to %s",
+		correlationID, approveAnnotation, rejectAnnotation, correlationID)
+	log.FromContext(ctx).Info("Action waits for approval",
+		"resourceAction", ra.Name,
+		"actionIndex", actionIndex,
+		"action", ra.Spec.Actions[actionIndex].Name,
+	)
+	if e.Recorder != nil {
+		e.Recorder.Eventf(ra, corev1.EventTypeNormal, "ApprovalRequired", "action %s: %s", actionName(ra, actionIndex), message)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := e.Client.Get(ctx, client.ObjectKeyFromObject(ra), &latest); err != nil {
+			return err
+		}
+		setActionPendingApproval(&latest, actionIndex, message, metav1.Now())
+		return e.Client.Status().Update(ctx, &latest)
+	})
+}
+
+// setActionPendingApproval records in status.actionStates that the action at
+// actionIndex waits for an approval. The times and failures of earlier
+// executions are kept.
+func setActionPendingApproval(ra *opsv1alpha1.ResourceAction, actionIndex int, message string, now metav1.Time) {
+	state := opsv1alpha1.ActionState{
+		ActionIndex:        actionIndex,
+		State:              actionStatePendingApproval,
+		Message:            message,
+		LastTransitionTime: &now,
+	}
+	if actionIndex < len(ra.Spec.Actions) {
+		state.Name = ra.Spec.Actions[actionIndex].Name
+		state.Type = ra.Spec.Actions[actionIndex].Type
+	}
+	states := slices.DeleteFunc(ra.Status.ActionStates, func(existing opsv1alpha1.ActionState) bool {
+		if existing.ActionIndex != actionIndex {
+			return existing.ActionIndex >= len(ra.Spec.Actions)
+		}
+		state.ConsecutiveFailures = existing.ConsecutiveFailures
+		state.LastExecutionTime = existing.LastExecutionTime
+		state.LastSuccessfulTime = existing.LastSuccessfulTime
+		state.LastFailureTime = existing.LastFailureTime
+		if existing.State == state.State {
+			state.LastTransitionTime = existing.LastTransitionTime
+		}
+		return true
+	})
+	states = append(states, state)
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].ActionIndex < states[j].ActionIndex
+	})
+	ra.Status.ActionStates = states
+}
+
+// annotationListsID reports whether the annotation key of ra lists id in its
+// comma-separated value.
+func annotationListsID(ra *opsv1alpha1.ResourceAction, key, id string) bool {
+	for _, value := range strings.Split(ra.GetAnnotations()[key], ",") {
+		if strings.TrimSpace(value) == id {
+			return true
+		}
+	}
+	return false
+}
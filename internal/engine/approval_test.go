@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func executeUntilPaused(t *testing.T, exec *K8sExecutor, input MatchInput) *waitError {
+	t.Helper()
+	err := exec.Execute(context.Background(), input)
+	var wait *waitError
+	if !errors.As(err, &wait) {
+		t.Fatalf("Execute() error = %v, want a pending approval", err)
+	}
+	return wait
+}
+
+func annotateResourceAction(t *testing.T, cl client.Client, ra *opsv1alpha1.ResourceAction, key, value string) {
+	t.Helper()
+	var latest opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(ra), &latest); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	latest.SetAnnotations(map[string]string{key: value})
+	if err := cl.Update(context.Background(), &latest); err != nil {
+		t.Fatalf("annotate resourceaction: %v", err)
+	}
+}
+
+func TestExecute_ApprovalGate(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-approval", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Name:      "scale-down",
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Approval:  &opsv1alpha1.ApprovalSpec{Timeout: "1h"},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-approval", "demo", "default")
+
+	wait := executeUntilPaused(t, exec, input)
+	if wait.delay != approvalPollInterval || wait.progress.next != 0 || calls.Load() != 0 {
+		t.Fatalf("wait = %s before action %d after %d calls, want a poll before action 0 without calls",
+			wait.delay, wait.progress.next, calls.Load())
+	}
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	id := wait.progress.correlationID
+	if state := got.Status.ActionStates[0]; state.State != actionStatePendingApproval || !strings.Contains(state.Message, id) {
+		t.Fatalf("action state = %+v, want PendingApproval naming execution %s", state, id)
+	}
+
+	// Still pending: the execution pauses again without a request.
+	input.resume = &wait.progress
+	wait = executeUntilPaused(t, exec, input)
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want none before the approval", calls.Load())
+	}
+
+	annotateResourceAction(t, cl, ra, approveAnnotation, "other-id, "+id)
+	input.resume = &wait.progress
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("approved Execute() error = %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1 after the approval", calls.Load())
+	}
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if state := got.Status.ActionStates[0]; state.State != actionStateReady {
+		t.Fatalf("action state = %+v, want Ready after the approved execution", state)
+	}
+	if record := got.Status.Executions[0]; record.CorrelationID != id || record.Result != executionResultSucceeded {
+		t.Fatalf("record = %+v, want the approved execution", record)
+	}
+}
+
+func TestExecute_ApprovalRejectedOrTimedOut(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+	approval := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-approval", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Name:      "scale-down",
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Approval:  &opsv1alpha1.ApprovalSpec{Timeout: "1h"},
+			}},
+		},
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		ra := approval.DeepCopy()
+		exec, cl := newTestExecutor(t, ra)
+		input := newDeploymentInput("uid-rejected", "demo", "default")
+		wait := executeUntilPaused(t, exec, input)
+
+		annotateResourceAction(t, cl, ra, rejectAnnotation, wait.progress.correlationID)
+		input.resume = &wait.progress
+		if err := exec.Execute(context.Background(), input); err == nil || !strings.Contains(err.Error(), "approval: rejected") {
+			t.Fatalf("Execute() error = %v, want a rejected approval", err)
+		}
+	})
+
+	t.Run("timed out", func(t *testing.T) {
+		exec, _ := newTestExecutor(t, approval.DeepCopy())
+		input := newDeploymentInput("uid-timeout", "demo", "default")
+		wait := executeUntilPaused(t, exec, input)
+
+		wait.progress.approvalRequestedAt = time.Now().Add(-2 * time.Hour)
+		input.resume = &wait.progress
+		if err := exec.Execute(context.Background(), input); err == nil || !strings.Contains(err.Error(), "approval: timed out after 1h0m0s") {
+			t.Fatalf("Execute() error = %v, want a timed out approval", err)
+		}
+	})
+
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want none without an approval", calls.Load())
+	}
+}
+
+func TestSetActionPendingApproval_KeepsFailures(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Actions: []opsv1alpha1.ActionSpec{{Name: "scale-down", Type: "http"}},
+		},
+	}
+	failedAt := metav1.NewTime(time.Now().Add(-time.Minute))
+	ra.Status.ActionStates = []opsv1alpha1.ActionState{{
+		ActionIndex:         0,
+		State:               actionStateFailing,
+		ConsecutiveFailures: 2,
+		LastFailureTime:     &failedAt,
+	}}
+
+	setActionPendingApproval(ra, 0, "waiting", metav1.Now())
+	if state := ra.Status.ActionStates[0]; state.State != actionStatePendingApproval ||
+		state.ConsecutiveFailures != 2 || state.LastFailureTime == nil {
+		t.Fatalf("state = %+v, want PendingApproval keeping the failures", state)
+	}
+
+	setActionState(ra, 0, errors.New("boom"), metav1.Now())
+	if state := ra.Status.ActionStates[0]; state.ConsecutiveFailures != 3 {
+		t.Fatalf("consecutive failures = %d, want 3", state.ConsecutiveFailures)
+	}
+}
@@ -0,0 +1,361 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	AuditSinkLoki   = "Loki"
+	AuditSinkS3     = "S3"
+	AuditSinkSyslog = "Syslog"
+
+	// auditBufferSize bounds the records waiting to be exported. Records are
+	// dropped when the sink falls this far behind.
+	auditBufferSize    = 1000
+	auditBatchSize     = 100
+	auditFlushInterval = 5 * time.Second
+	auditWriteTimeout  = 10 * time.Second
+	auditWriteAttempts = 3
+	auditWriteBackoff  = time.Second
+
+	auditAppName = "resource-action-operator"
+)
+
+// AuditRecord is an execution record exported to the audit sink, together
+// with the ResourceAction it belongs to and the last HTTP request it sent.
+type AuditRecord struct {
+	ResourceAction string `json:"resourceAction"`
+	Namespace      string `json:"namespace"`
+	opsv1alpha1.ExecutionRecord
+	Request *opsv1alpha1.HTTPRequestRecord `json:"request,omitempty"`
+}
+
+// auditSink writes a batch of records to an external system.
+type auditSink interface {
+	write(ctx context.Context, records []AuditRecord) error
+}
+
+// AuditExporter ships every execution record to an external sink, in
+// batches and independently of the status history, which is capped.
+type AuditExporter struct {
+	sink    auditSink
+	records chan AuditRecord
+	// flushInterval is the longest a record waits for its batch to fill.
+	flushInterval time.Duration
+	backoff       time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewAuditExporter returns an exporter writing to the sink of kind Loki, S3
+// or Syslog at address: the push API URL of Loki, s3://bucket/prefix, or
+// udp://, tcp:// or tls://host:port of a syslog server.
+func NewAuditExporter(kind, address string) (*AuditExporter, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parse audit sink URL: %w", err)
+	}
+	var sink auditSink
+	switch kind {
+	case AuditSinkLoki:
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("loki audit sink URL must be http or https, got %q", address)
+		}
+		sink = &lokiSink{url: address, httpClient: &http.Client{Timeout: auditWriteTimeout}}
+	case AuditSinkS3:
+		sink, err = newS3Sink(u, os.Getenv)
+	case AuditSinkSyslog:
+		sink, err = newSyslogSink(u)
+	default:
+		return nil, fmt.Errorf("unsupported audit sink %q, must be Loki, S3 or Syslog", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newAuditExporter(sink), nil
+}
+
+func newAuditExporter(sink auditSink) *AuditExporter {
+	return &AuditExporter{
+		sink:          sink,
+		records:       make(chan AuditRecord, auditBufferSize),
+		flushInterval: auditFlushInterval,
+		backoff:       auditWriteBackoff,
+		done:          make(chan struct{}),
+	}
+}
+
+// SetAuditExporter exports every execution record with exporter. Nil
+// disables exporting.
+func (e *K8sExecutor) SetAuditExporter(exporter *AuditExporter) {
+	e.audit = exporter
+}
+
+// exportRecord queues record of ra for the audit sink. It never blocks the
+// execution: when the sink falls behind, the record is dropped and counted.
+func (e *K8sExecutor) exportRecord(ctx context.Context, ra *opsv1alpha1.ResourceAction, record opsv1alpha1.ExecutionRecord, request *opsv1alpha1.HTTPRequestRecord) {
+	if e.audit == nil {
+		return
+	}
+	if !e.audit.enqueue(AuditRecord{ResourceAction: ra.Name, Namespace: ra.Namespace, ExecutionRecord: record, Request: request}) {
+		log.FromContext(ctx).Info("Dropping audit record, the audit sink is behind",
+			"resourceAction", ra.Name,
+			"correlationID", record.CorrelationID,
+		)
+		observeAuditRecords("dropped", 1)
+	}
+}
+
+func (a *AuditExporter) enqueue(record AuditRecord) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return false
+	}
+	select {
+	case a.records <- record:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start writes the queued records in batches until Shutdown. It is not a
+// manager runnable: the manager stops those before the engine has drained its
+// queue, and the records of the last executions would be lost.
+func (a *AuditExporter) Start() {
+	go a.run()
+}
+
+// Shutdown stops accepting records and waits until the queued ones are
+// written or ctx is done.
+func (a *AuditExporter) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.records)
+	}
+	a.mu.Unlock()
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *AuditExporter) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.flushInterval)
+	defer ticker.Stop()
+
+	ctx := context.Background()
+	batch := make([]AuditRecord, 0, auditBatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			a.writeBatch(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case record, ok := <-a.records:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) == auditBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (a *AuditExporter) writeBatch(ctx context.Context, records []AuditRecord) {
+	var err error
+	for attempt := 1; attempt <= auditWriteAttempts; attempt++ {
+		writeCtx, cancel := context.WithTimeout(ctx, auditWriteTimeout)
+		err = a.sink.write(writeCtx, records)
+		cancel()
+		if err == nil {
+			observeAuditRecords("success", len(records))
+			return
+		}
+		if attempt < auditWriteAttempts {
+			time.Sleep(time.Duration(attempt) * a.backoff)
+		}
+	}
+	log.FromContext(ctx).Error(err, "failed to export audit records", "records", len(records))
+	observeAuditRecords("failure", len(records))
+}
+
+// lokiSink pushes records to the Loki push API, one stream per namespace,
+// ResourceAction and result.
+type lokiSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) write(ctx context.Context, records []AuditRecord) error {
+	streams := map[[3]string]*lokiStream{}
+	var order [][3]string
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		key := [3]string{record.Namespace, record.ResourceAction, record.Result}
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: map[string]string{
+				"job":             auditAppName,
+				"namespace":       record.Namespace,
+				"resource_action": record.ResourceAction,
+				"result":          record.Result,
+			}}
+			streams[key] = stream
+			order = append(order, key)
+		}
+		ts := strconv.FormatInt(record.ExecutedAt.UnixNano(), 10)
+		stream.Values = append(stream.Values, [2]string{ts, string(line)})
+	}
+	push := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range order {
+		push.Streams = append(push.Streams, streams[key])
+	}
+	payload, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doAuditRequest(s.httpClient, req, "loki")
+}
+
+func doAuditRequest(c *http.Client, req *http.Request, sink string) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", sink, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// syslogSink sends each record as an RFC 5424 message with the record as
+// JSON. Messages over TCP and TLS are framed by octet counting.
+type syslogSink struct {
+	network, address string
+	hostname         string
+	dial             func(ctx context.Context) (net.Conn, error)
+}
+
+const (
+	syslogFacilityLocal0 = 16
+	syslogSeverityWarn   = 4
+	syslogSeverityInfo   = 6
+)
+
+func newSyslogSink(u *url.URL) (*syslogSink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("syslog audit sink URL must have a host, for example udp://syslog:514")
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	s := &syslogSink{network: u.Scheme, address: u.Host, hostname: hostname}
+	dialer := &net.Dialer{Timeout: auditWriteTimeout}
+	switch u.Scheme {
+	case "udp", "tcp":
+		s.dial = func(ctx context.Context) (net.Conn, error) {
+			return dialer.DialContext(ctx, u.Scheme, u.Host)
+		}
+	case "tls":
+		tlsDialer := &tls.Dialer{NetDialer: dialer}
+		s.dial = func(ctx context.Context) (net.Conn, error) {
+			return tlsDialer.DialContext(ctx, "tcp", u.Host)
+		}
+	default:
+		return nil, fmt.Errorf("syslog audit sink URL must be udp, tcp or tls, got %q", u.Scheme)
+	}
+	return s, nil
+}
+
+func (s *syslogSink) write(ctx context.Context, records []AuditRecord) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	for _, record := range records {
+		msg, err := s.message(record)
+		if err != nil {
+			return err
+		}
+		if s.network != "udp" {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		if _, err := conn.Write(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// message formats record as an RFC 5424 message. Failed executions are
+// warnings, all others informational.
+func (s *syslogSink) message(record AuditRecord) ([]byte, error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	severity := syslogSeverityInfo
+	if record.Result != executionResultSucceeded && record.Result != "" {
+		severity = syslogSeverityWarn
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s - execution - ",
+		syslogFacilityLocal0*8+severity,
+		record.ExecutedAt.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		auditAppName,
+	)
+	return append([]byte(header), line...), nil
+}
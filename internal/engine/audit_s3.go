@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	awsSigningAlgo  = "AWS4-HMAC-SHA256"
+)
+
+// s3Sink stores each batch of records as an NDJSON object in an S3 bucket,
+// under <prefix>/<yyyy>/<mm>/<dd>/. It signs requests with Signature Version
+// 4 and addresses the bucket path-style, so S3-compatible stores like MinIO
+// work as well.
+type s3Sink struct {
+	endpoint     string
+	bucket       string
+	prefix       string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	httpClient   *http.Client
+	now          func() time.Time
+}
+
+// newS3Sink returns the sink of an s3://bucket/prefix URL. Credentials, the
+// region and the endpoint are read from the standard AWS environment
+// variables.
+func newS3Sink(u *url.URL, getenv func(string) string) (*s3Sink, error) {
+	if u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("S3 audit sink URL must be s3://bucket/prefix, got %q", u.String())
+	}
+	s := &s3Sink{
+		bucket:       u.Host,
+		prefix:       strings.Trim(u.Path, "/"),
+		region:       getenv("AWS_REGION"),
+		accessKey:    getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: getenv("AWS_SESSION_TOKEN"),
+		httpClient:   &http.Client{Timeout: auditWriteTimeout},
+		now:          time.Now,
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("S3 audit sink needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if s.region == "" {
+		s.region = defaultS3Region
+	}
+	s.endpoint = getenv("AWS_ENDPOINT_URL_S3")
+	if s.endpoint == "" {
+		s.endpoint = getenv("AWS_ENDPOINT_URL")
+	}
+	if s.endpoint == "" {
+		s.endpoint = "https://s3." + s.region + ".amazonaws.com"
+	}
+	s.endpoint = strings.TrimSuffix(s.endpoint, "/")
+	return s, nil
+}
+
+func (s *s3Sink) write(ctx context.Context, records []AuditRecord) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	now := s.now().UTC()
+	key := fmt.Sprintf("%s/%s-%s.ndjson", now.Format("2006/01/02"), now.Format("20060102T150405.000000000Z"), uuid.NewUUID())
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut,
+		s.endpoint+"/"+awsURIEscape(s.bucket+"/"+key), bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	signAWSRequest(req, body.Bytes(), s.accessKey, s.secretKey, s.region, "s3", now)
+	return doAuditRequest(s.httpClient, req, "S3")
+}
+
+// signAWSRequest signs req with AWS Signature Version 4, covering the host
+// and every header set on req.
+func signAWSRequest(req *http.Request, body []byte, accessKey, secretKey, region, service string, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{awsSigningAlgo, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigningAlgo, accessKey, scope, signedHeaders, signature))
+}
+
+// awsURIEscape escapes path the way Signature Version 4 expects: every byte
+// except unreserved characters and the slash.
+func awsURIEscape(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Backfill queues a Create event for every object held by the informers of ra
+// and delivers it to ra only. spec.executionPolicy keeps objects that were
+// already handled from running again. It returns the number of queued objects.
+func (e *Engine) Backfill(ctx context.Context, ra *opsv1alpha1.ResourceAction) (int, error) {
+	owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	gvk := schema.GroupVersionKind{
+		Group:   ra.Spec.Selector.Group,
+		Version: ra.Spec.Selector.Version,
+		Kind:    ra.Spec.Selector.Kind,
+	}
+
+	if health := e.WatchHealth(owner); !health.Watching || !health.Synced {
+		return 0, fmt.Errorf("informers of %s have not synced", owner.String())
+	}
+	objects, err := e.ListCached(owner)
+	if err != nil {
+		return 0, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.draining {
+		return 0, errShutdown
+	}
+	for _, obj := range objects {
+		e.addLocked(&eventItem{
+			input: MatchInput{
+				Event:  EventCreate,
+				GVK:    gvk,
+				Obj:    obj,
+				owners: map[types.NamespacedName]struct{}{owner: {}},
+			},
+			key: objectKey{owner: owner, uid: obj.GetUID()},
+		})
+	}
+
+	log.FromContext(ctx).Info("Queued existing objects for backfill",
+		"resourceAction", ra.Name,
+		"gvk", gvk.String(),
+		"objects", len(objects),
+	)
+	return len(objects), nil
+}
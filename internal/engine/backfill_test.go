@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func newConfigMap(name, uid string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace("default")
+	u.SetName(name)
+	u.SetUID(types.UID(uid))
+	return u
+}
+
+func TestBackfill_QueuesExistingObjectsForOwner(t *testing.T) {
+	e := newWatchTestEngine(t)
+	e.dyn = fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+	}, newConfigMap("first", "uid-1"), newConfigMap("second", "uid-2"))
+	// Run the informers without event workers to inspect the queued events.
+	e.started = true
+
+	ctx := context.Background()
+	ra := newTestResourceAction("backfill", "ConfigMap")
+	owner := types.NamespacedName{Namespace: "default", Name: "backfill"}
+
+	if _, err := e.Backfill(ctx, ra); err == nil {
+		t.Fatalf("Backfill() without informer error = nil, want error")
+	}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !e.WatchHealth(owner).Synced && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Drop the Add events of the initial list.
+	for e.queue.Len() > 0 {
+		item, _ := e.queue.Get()
+		e.queue.Done(item)
+		e.queue.Forget(item)
+		e.finish(item)
+	}
+
+	queued, err := e.Backfill(ctx, ra)
+	if err != nil {
+		t.Fatalf("Backfill() error = %v", err)
+	}
+	if queued != 2 || e.queue.Len() != 2 {
+		t.Fatalf("Backfill() queued %d objects, queue length %d, want 2", queued, e.queue.Len())
+	}
+	other := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}}
+	for i := 0; i < 2; i++ {
+		item, _ := e.queue.Get()
+		input := e.takeInput(item)
+		if input.Event != EventCreate || !input.targets(ra) || input.targets(other) {
+			t.Fatalf("unexpected backfill event %s for owners %v", input.Event, input.owners)
+		}
+		e.queue.Done(item)
+	}
+}
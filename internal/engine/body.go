@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"text/template"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+const (
+	defaultBodyContentType = "application/json"
+	formContentType        = "application/x-www-form-urlencoded"
+	defaultFileContentType = "application/octet-stream"
+)
+
+// renderBody renders the body of an HTTP action against data and returns it
+// with its Content-Type. Form fields are sent form-encoded, or as
+// multipart/form-data when the body has files. The templates are taken from
+// compiled.
+func renderBody(
+	compiled *compiledSpec,
+	spec *opsv1alpha1.TemplateSpec,
+	data interface{},
+	funcs template.FuncMap,
+) ([]byte, string, error) {
+	switch {
+	case spec == nil:
+		return nil, "", nil
+	case len(spec.Files) > 0:
+		return renderMultipartBody(compiled, spec, data, funcs)
+	case len(spec.Form) > 0:
+		values := url.Values{}
+		for _, name := range sortedKeys(spec.Form) {
+			value, err := renderTemplate(compiled, "form."+name, spec.Form[name], data, funcs)
+			if err != nil {
+				return nil, "", err
+			}
+			values.Set(name, value)
+		}
+		return []byte(values.Encode()), formContentType, nil
+	case spec.Template != "":
+		body, err := renderTemplate(compiled, "body", spec.Template, data, funcs)
+		if err != nil {
+			return nil, "", err
+		}
+		contentType := spec.ContentType
+		if contentType == "" {
+			contentType = defaultBodyContentType
+		}
+		return []byte(body), contentType, nil
+	}
+	return nil, "", nil
+}
+
+// bodyTemplateFuncs returns the functions available to body templates.
+// output returns an output of an earlier action in the same execution;
+// triggeredBy, failureReason, failedAttempts and failureStatus describe the
+// action that ran a handler, and escalationLevel the level of an escalation
+// handler; item is the current item of a forEach action; changeTypes and
+// changed describe the parts of the object an Update changed; configMapValue
+// and secretValue read values from the cluster, see HTTPExecutor.templateFuncs.
+// Without a cluster they return placeholders. executedAt returns the current
+// time.
+func bodyTemplateFuncs(outputs map[string]string, trigger *actionTrigger) template.FuncMap {
+	if trigger == nil {
+		trigger = &actionTrigger{}
+	}
+	return template.FuncMap{
+		"output": func(name string) (string, error) {
+			value, ok := outputs[name]
+			if !ok {
+				return "", fmt.Errorf("output %q is not set by an earlier action", name)
+			}
+			return value, nil
+		},
+		"triggeredBy":     func() string { return trigger.action },
+		"failureReason":   func() string { return trigger.failureReason },
+		"failedAttempts":  func() int { return trigger.attempts },
+		"failureStatus":   func() int { return trigger.statusCode },
+		"escalationLevel": func() int { return trigger.escalationLevel },
+		"item": func() (string, error) {
+			return "", errors.New("item is only set in forEach")
+		},
+		"changeTypes": func() []string { return nil },
+		"changed":     func(changeType string) bool { return false },
+		"configMapValue": func(namespace, name, key string) string {
+			return fmt.Sprintf("<configMap %s/%s %s>", namespace, name, key)
+		},
+		"secretValue": func(name, key string) string { return redactedValue },
+		"executedAt":  func() string { return time.Now().UTC().Format(time.RFC3339) },
+		"groupStatus": func() string { return groupStatusFiring },
+		"groupLabels": func() map[string]string { return map[string]string{} },
+		"fingerprint": func() string { return "" },
+	}
+}
+
+func renderMultipartBody(
+	compiled *compiledSpec,
+	spec *opsv1alpha1.TemplateSpec,
+	data interface{},
+	funcs template.FuncMap,
+) ([]byte, string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, name := range sortedKeys(spec.Form) {
+		value, err := renderTemplate(compiled, "form."+name, spec.Form[name], data, funcs)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := mw.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+	for i, file := range spec.Files {
+		content, err := renderTemplate(compiled, fmt.Sprintf("files[%d]", i), file.Template, data, funcs)
+		if err != nil {
+			return nil, "", err
+		}
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = defaultFileContentType
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     file.Field,
+			"filename": file.FileName,
+		}))
+		header.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write([]byte(content)); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), mw.FormDataContentType(), nil
+}
+
+func renderTemplate(compiled *compiledSpec, name, text string, data interface{}, funcs template.FuncMap) (string, error) {
+	tpl, err := compiled.parseTemplate(name, text, funcs)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// bodyTemplates returns the templates of a body, for example to find out
+// which fields of the object they read.
+func bodyTemplates(spec *opsv1alpha1.TemplateSpec) []string {
+	if spec == nil {
+		return nil
+	}
+	var templates []string
+	if spec.Template != "" {
+		templates = append(templates, spec.Template)
+	}
+	for _, name := range sortedKeys(spec.Form) {
+		templates = append(templates, spec.Form[name])
+	}
+	for _, file := range spec.Files {
+		templates = append(templates, file.Template)
+	}
+	return templates
+}
+
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
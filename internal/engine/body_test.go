@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+func TestRenderBody_Form(t *testing.T) {
+	data := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "demo app", "namespace": "default"},
+	}
+	body, contentType, err := renderBody(nil, &opsv1alpha1.TemplateSpec{
+		Form: map[string]string{
+			"name":      "{{ .metadata.name }}",
+			"namespace": "{{ .metadata.namespace }}",
+		},
+	}, data, bodyTemplateFuncs(nil, nil))
+	if err != nil {
+		t.Fatalf("renderBody() error = %v", err)
+	}
+	if contentType != "application/x-www-form-urlencoded" {
+		t.Fatalf("Content-Type = %q, want form-encoded", contentType)
+	}
+	if want := "name=demo+app&namespace=default"; string(body) != want {
+		t.Fatalf("body = %q, want %q", body, want)
+	}
+}
+
+func TestRenderBody_Multipart(t *testing.T) {
+	data := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "demo"},
+	}
+	body, contentType, err := renderBody(nil, &opsv1alpha1.TemplateSpec{
+		Form: map[string]string{"name": "{{ .metadata.name }}"},
+		Files: []opsv1alpha1.FormFileSpec{{
+			Field:       "manifest",
+			FileName:    "demo.json",
+			ContentType: "application/json",
+			Template:    `{"name":"{{ .metadata.name }}"}`,
+		}},
+	}, data, bodyTemplateFuncs(nil, nil))
+	if err != nil {
+		t.Fatalf("renderBody() error = %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Content-Type = %q, want multipart/form-data", contentType)
+	}
+
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	field, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("read form field: %v", err)
+	}
+	if value, _ := io.ReadAll(field); field.FormName() != "name" || string(value) != "demo" {
+		t.Fatalf("form field %s = %q, want name=demo", field.FormName(), value)
+	}
+	file, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	content, _ := io.ReadAll(file)
+	if file.FormName() != "manifest" || file.FileName() != "demo.json" ||
+		file.Header.Get("Content-Type") != "application/json" || string(content) != `{"name":"demo"}` {
+		t.Fatalf("file %s/%s (%s) = %q", file.FormName(), file.FileName(), file.Header.Get("Content-Type"), content)
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Fatalf("expected two parts, got error %v", err)
+	}
+}
+
+func TestRenderBody_TemplateError(t *testing.T) {
+	_, _, err := renderBody(nil, &opsv1alpha1.TemplateSpec{
+		Form: map[string]string{"ticket": `{{ output "ticketURL" }}`},
+	}, map[string]interface{}{}, bodyTemplateFuncs(nil, nil))
+	if err == nil {
+		t.Fatalf("expected error for an output that is not set")
+	}
+}
@@ -0,0 +1,51 @@
+package engine
+
+import (
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// SetCacheStripStatus drops status from the objects cached by full informers.
+// Body templates then no longer see it. ResourceActions that filter on Status
+// changes get informers that keep it, and so does every ResourceAction while
+// a CloudEvent sink is set. It must be called before the first watch is
+// established.
+func (e *Engine) SetCacheStripStatus(strip bool) {
+	e.stripStatus = strip
+}
+
+// needsStatus reports whether ra has to see the status of the watched objects
+// even when the cache strips it.
+func needsStatus(ra *opsv1alpha1.ResourceAction) bool {
+	return ra.Spec.Filters != nil && ra.Spec.Filters.ChangeType == changeStatus
+}
+
+// cacheTransform removes fields that are not needed for matching from objects
+// before the informer caches them.
+func cacheTransform(stripStatus bool) cache.TransformFunc {
+	return func(obj interface{}) (interface{}, error) {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			stripObject(u, stripStatus)
+		}
+		return obj, nil
+	}
+}
+
+// stripObject drops managed fields and the kubectl last-applied annotation,
+// and status when stripStatus is set. Both usually dominate the size of an
+// object.
+func stripObject(u *unstructured.Unstructured, stripStatus bool) {
+	unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+	if annotations := u.GetAnnotations(); annotations != nil {
+		if _, ok := annotations[lastAppliedConfigAnnotation]; ok {
+			delete(annotations, lastAppliedConfigAnnotation)
+			u.SetAnnotations(annotations)
+		}
+	}
+	if stripStatus {
+		unstructured.RemoveNestedField(u.Object, "status")
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newCachedDeployment() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":          "demo",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				lastAppliedConfigAnnotation: `{"kind":"Deployment"}`,
+				"team":                      "a",
+			},
+		},
+		"spec":   map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{"readyReplicas": int64(2)},
+	}}
+}
+
+func TestCacheTransform(t *testing.T) {
+	transformed, err := cacheTransform(false)(newCachedDeployment())
+	if err != nil {
+		t.Fatalf("transform error = %v", err)
+	}
+	u := transformed.(*unstructured.Unstructured)
+	if len(u.GetManagedFields()) != 0 {
+		t.Fatalf("managedFields not stripped: %v", u.GetManagedFields())
+	}
+	annotations := u.GetAnnotations()
+	if _, ok := annotations[lastAppliedConfigAnnotation]; ok || annotations["team"] != "a" {
+		t.Fatalf("unexpected annotations %v", annotations)
+	}
+	if _, ok := u.Object["status"]; !ok {
+		t.Fatalf("status stripped without stripStatus")
+	}
+	if _, ok := u.Object["spec"]; !ok {
+		t.Fatalf("spec must be kept")
+	}
+
+	// The transform is idempotent and strips status on request.
+	transformed, err = cacheTransform(true)(u)
+	if err != nil {
+		t.Fatalf("transform error = %v", err)
+	}
+	if _, ok := transformed.(*unstructured.Unstructured).Object["status"]; ok {
+		t.Fatalf("status not stripped with stripStatus")
+	}
+}
+
+func TestEnsureWatchingFor_KeepsStatusForStatusChanges(t *testing.T) {
+	e := newWatchTestEngine(t)
+	e.SetCacheStripStatus(true)
+	ctx := context.Background()
+
+	plain := newTestResourceAction("plain", "ConfigMap")
+	if err := e.EnsureWatchingFor(ctx, plain); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	status := newTestResourceAction("status", "ConfigMap")
+	status.Spec.Filters = &opsv1alpha1.FilterSpec{ChangeType: "Status"}
+	if err := e.EnsureWatchingFor(ctx, status); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+
+	if keys := e.owners[types.NamespacedName{Namespace: "default", Name: "plain"}]; len(keys) != 1 || keys[0].keepStatus {
+		t.Fatalf("expected an informer without status, got %v", keys)
+	}
+	if keys := e.owners[types.NamespacedName{Namespace: "default", Name: "status"}]; len(keys) != 1 || !keys[0].keepStatus {
+		t.Fatalf("expected an informer keeping status, got %v", keys)
+	}
+	if len(e.informers) != 2 {
+		t.Fatalf("expected 2 informers, got %d", len(e.informers))
+	}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The parts of an object an Update event can change, as matched by
+// spec.filters.changeType.
+const (
+	changeSpec     = "Spec"
+	changeStatus   = "Status"
+	changeMetadata = "Metadata"
+	changeLabels   = "Labels"
+)
+
+// changeTypesOf returns the parts of the object that changed from old to obj,
+// in the order Spec, Status, Metadata, Labels. Every top-level field other
+// than metadata and status counts as spec, so the data of a ConfigMap does as
+// well; objects of metadata-only watches only carry a spec change in their
+// generation. A label change is a metadata change as well.
+func changeTypesOf(old, obj *unstructured.Unstructured) []string {
+	var changes []string
+	if old.GetGeneration() != obj.GetGeneration() || !reflect.DeepEqual(specFields(old), specFields(obj)) {
+		changes = append(changes, changeSpec)
+	}
+	if !reflect.DeepEqual(old.Object["status"], obj.Object["status"]) {
+		changes = append(changes, changeStatus)
+	}
+	labels := !maps.Equal(old.GetLabels(), obj.GetLabels())
+	if labels || !reflect.DeepEqual(comparableMetadata(old), comparableMetadata(obj)) {
+		changes = append(changes, changeMetadata)
+	}
+	if labels {
+		changes = append(changes, changeLabels)
+	}
+	return changes
+}
+
+// specFields returns the top-level fields of obj other than its type,
+// metadata and status.
+func specFields(obj *unstructured.Unstructured) map[string]interface{} {
+	fields := maps.Clone(obj.Object)
+	for _, field := range []string{"apiVersion", "kind", "metadata", "status"} {
+		delete(fields, field)
+	}
+	return fields
+}
+
+// comparableMetadata returns the metadata of obj without the fields every
+// write changes.
+func comparableMetadata(obj *unstructured.Unstructured) map[string]interface{} {
+	metadata, _ := obj.Object["metadata"].(map[string]interface{})
+	metadata = maps.Clone(metadata)
+	for _, field := range []string{"resourceVersion", "managedFields", "generation"} {
+		delete(metadata, field)
+	}
+	return metadata
+}
+
+// changed reports whether an Update event changed the part changeType.
+func (in MatchInput) changed(changeType string) bool {
+	return slices.Contains(in.changes, changeType)
+}
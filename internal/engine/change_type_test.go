@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestChangeTypesOf(t *testing.T) {
+	old := newDeploymentInput("uid-1", "web", "default").Obj
+	old.SetResourceVersion("1")
+	old.SetGeneration(1)
+	_ = unstructured.SetNestedField(old.Object, int64(2), "spec", "replicas")
+	_ = unstructured.SetNestedField(old.Object, int64(2), "status", "readyReplicas")
+
+	for _, tc := range []struct {
+		name   string
+		update func(obj *unstructured.Unstructured)
+		want   []string
+	}{
+		{"resourceVersion only", func(obj *unstructured.Unstructured) {}, nil},
+		{"spec", func(obj *unstructured.Unstructured) {
+			_ = unstructured.SetNestedField(obj.Object, int64(3), "spec", "replicas")
+		}, []string{changeSpec}},
+		{"generation only", func(obj *unstructured.Unstructured) {
+			obj.SetGeneration(2)
+		}, []string{changeSpec}},
+		{"status", func(obj *unstructured.Unstructured) {
+			_ = unstructured.SetNestedField(obj.Object, int64(1), "status", "readyReplicas")
+		}, []string{changeStatus}},
+		{"annotation", func(obj *unstructured.Unstructured) {
+			obj.SetAnnotations(map[string]string{"team": "a"})
+		}, []string{changeMetadata}},
+		{"labels and status", func(obj *unstructured.Unstructured) {
+			obj.SetLabels(map[string]string{"tier": "web"})
+			unstructured.RemoveNestedField(obj.Object, "status")
+		}, []string{changeStatus, changeMetadata, changeLabels}},
+	} {
+		obj := old.DeepCopy()
+		obj.SetResourceVersion("2")
+		tc.update(obj)
+		if got := changeTypesOf(old, obj); !slices.Equal(got, tc.want) {
+			t.Fatalf("%s: changeTypesOf() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestExecute_ChangeTypeFilter(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-status", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:        opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:          []string{"Update"},
+			ExecutionPolicy: "EveryEvent",
+			Filters:         &opsv1alpha1.FilterSpec{ChangeType: "Status"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body: &opsv1alpha1.TemplateSpec{
+					Template: `{{ changeTypes }} {{ changed "Spec" }}`,
+				},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+	ctx := context.Background()
+
+	update := func(field string) MatchInput {
+		input := newDeploymentInput("uid-web", "web", "default")
+		input.Event = EventUpdate
+		input.OldObj = input.Obj.DeepCopy()
+		_ = unstructured.SetNestedField(input.Obj.Object, int64(1), field, "replicas")
+		input.changes = changeTypesOf(input.OldObj, input.Obj)
+		return input
+	}
+	for _, field := range []string{"spec", "status"} {
+		if err := exec.Execute(ctx, update(field)); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	}
+	if len(bodies) != 1 || bodies[0] != "[Status] false" {
+		t.Fatalf("bodies = %q, want only the status change", bodies)
+	}
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitBreakerFailures = 5
+	defaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+// circuitOpenError is returned for HTTP attempts that were short-circuited
+// because the circuit of the target host is open.
+type circuitOpenError struct {
+	host       string
+	retryAfter time.Duration
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for host %q, retrying after %s", e.host, e.retryAfter.Round(time.Second))
+}
+
+// circuitBreakers tracks consecutive failures per URL host. A circuit opens
+// after threshold consecutive failures and short-circuits all attempts to the
+// host for the cool-down. Afterwards a single attempt probes the host: success
+// closes the circuit, failure opens it again.
+type circuitBreakers struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	hosts     map[string]*circuit
+	now       func() time.Time
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreakers(threshold int, cooldown time.Duration) *circuitBreakers {
+	return &circuitBreakers{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hosts:     make(map[string]*circuit),
+		now:       time.Now,
+	}
+}
+
+// SetCircuitBreaker configures the circuit breaker of HTTP actions. A
+// threshold of 0 disables it.
+func (e *K8sExecutor) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	e.breakers.mu.Lock()
+	defer e.breakers.mu.Unlock()
+	e.breakers.threshold = threshold
+	e.breakers.cooldown = cooldown
+}
+
+// circuitHost returns the host the circuit of rawURL is keyed by.
+func circuitHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// allow reports whether an attempt to host may be made. It returns a
+// circuitOpenError while the circuit is open or another attempt probes it.
+func (b *circuitBreakers) allow(host string) error {
+	if b == nil || host == "" {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 {
+		return nil
+	}
+	c, ok := b.hosts[host]
+	if !ok || c.failures < b.threshold {
+		return nil
+	}
+	now := b.now()
+	if now.Before(c.openUntil) {
+		observeCircuitShortCircuit(host)
+		return &circuitOpenError{host: host, retryAfter: c.openUntil.Sub(now)}
+	}
+	if c.probing {
+		observeCircuitShortCircuit(host)
+		return &circuitOpenError{host: host, retryAfter: b.cooldown}
+	}
+	c.probing = true
+	return nil
+}
+
+// abort releases the probe of host after an attempt that was cancelled before
+// it had an outcome.
+func (b *circuitBreakers) abort(host string) {
+	if b == nil || host == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.hosts[host]; ok {
+		c.probing = false
+	}
+}
+
+// circuitFailure reports whether a response status counts as a failure of the
+// receiver. Other statuses show the host is reachable.
+func circuitFailure(status int) bool {
+	return status >= 500 || status == http.StatusTooManyRequests
+}
+
+// record stores the outcome of an attempt to host.
+func (b *circuitBreakers) record(host string, failed bool) {
+	if b == nil || host == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 {
+		return
+	}
+	c, ok := b.hosts[host]
+	if !failed {
+		if ok {
+			delete(b.hosts, host)
+			observeCircuitOpen(host, false)
+		}
+		return
+	}
+	if !ok {
+		c = &circuit{}
+		b.hosts[host] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.failures >= b.threshold {
+		c.openUntil = b.now().Add(b.cooldown)
+		observeCircuitOpen(host, true)
+	}
+}
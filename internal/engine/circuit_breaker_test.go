@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCircuitBreakers_OpenProbeAndClose(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreakers(2, time.Minute)
+	b.now = func() time.Time { return now }
+	host := "receiver.example:8080"
+
+	b.record(host, true)
+	if err := b.allow(host); err != nil {
+		t.Fatalf("allow() after 1 failure = %v, want nil", err)
+	}
+	b.record(host, true)
+
+	var open *circuitOpenError
+	if err := b.allow(host); !errors.As(err, &open) {
+		t.Fatalf("allow() after 2 failures = %v, want circuitOpenError", err)
+	}
+	if err := b.allow("other.example"); err != nil {
+		t.Fatalf("allow() for another host = %v, want nil", err)
+	}
+
+	// After the cool-down a single probe is let through.
+	now = now.Add(time.Minute)
+	if err := b.allow(host); err != nil {
+		t.Fatalf("allow() for probe = %v, want nil", err)
+	}
+	if err := b.allow(host); !errors.As(err, &open) {
+		t.Fatalf("allow() during probe = %v, want circuitOpenError", err)
+	}
+
+	// A failed probe opens the circuit again.
+	b.record(host, true)
+	if err := b.allow(host); !errors.As(err, &open) {
+		t.Fatalf("allow() after failed probe = %v, want circuitOpenError", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.allow(host); err != nil {
+		t.Fatalf("allow() for probe = %v, want nil", err)
+	}
+	b.record(host, false)
+	if err := b.allow(host); err != nil {
+		t.Fatalf("allow() after successful probe = %v, want nil", err)
+	}
+}
+
+func TestCircuitBreakers_Disabled(t *testing.T) {
+	b := newCircuitBreakers(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.record("receiver.example", true)
+	}
+	if err := b.allow("receiver.example"); err != nil {
+		t.Fatalf("allow() with disabled breaker = %v, want nil", err)
+	}
+}
+
+func TestHTTPExecutor_CircuitBreakerShortCircuitsRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	exec.breakers = newCircuitBreakers(2, time.Minute)
+	action := opsv1alpha1.ActionSpec{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		Retry: &opsv1alpha1.RetrySpec{
+			MaxAttempts: 5,
+			Backoff:     "1ms",
+			MaxBackoff:  "2ms",
+		},
+	}
+
+	metrics, err := exec.ExecuteWithMetrics(context.Background(), action, "default", newConfigMap("demo", "uid-1"), nil)
+	var open *circuitOpenError
+	if !errors.As(err, &open) {
+		t.Fatalf("ExecuteWithMetrics() error = %v, want circuitOpenError", err)
+	}
+	if metrics.Attempts != 2 || calls.Load() != 2 {
+		t.Fatalf("attempts = %d, calls = %d, want 2", metrics.Attempts, calls.Load())
+	}
+	if got := failureReason(err); got != "CircuitOpen" {
+		t.Fatalf("failureReason() = %q, want CircuitOpen", got)
+	}
+
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", newConfigMap("demo", "uid-1"), nil); !errors.As(err, &open) {
+		t.Fatalf("second ExecuteWithMetrics() error = %v, want circuitOpenError", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d after the circuit opened, want 2", calls.Load())
+	}
+}
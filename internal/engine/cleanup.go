@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// TeardownExecutor is implemented by executors that can run spec.teardown.
+type TeardownExecutor interface {
+	ExecuteTeardown(ctx context.Context, ra *opsv1alpha1.ResourceAction) error
+}
+
+// errCancelled is recorded for executions that were paused when their
+// ResourceAction was deleted.
+var errCancelled = errors.New("ResourceAction was deleted while the execution was paused")
+
+// Cleanup stops the schedules and informers of the ResourceAction owner and
+// delivers its debounced events right away. Executions paused by a wait
+// action, an approval or a deferred retry are cancelled and recorded as
+// failed. It returns how many events of owner are still queued or being
+// delivered; the caller polls until none are left.
+func (e *Engine) Cleanup(ctx context.Context, owner types.NamespacedName) int {
+	e.cronEngine.Stop(owner)
+	e.ReleaseWatch(ctx, owner)
+	if exec, ok := e.executor.(*K8sExecutor); ok {
+		exec.compiled.forget(owner)
+	}
+	remaining, cancelled := e.flushEvents(owner)
+	e.recordCancelled(ctx, cancelled)
+	return remaining
+}
+
+// flushEvents queues the debounced events of owner without waiting for their
+// window and drops its paused executions, which would otherwise hold up the
+// deletion until they are resumed. It returns the number of events of owner
+// not yet delivered and the dropped executions.
+func (e *Engine) flushEvents(owner types.NamespacedName) (int, []*eventItem) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	remaining := 0
+	var cancelled []*eventItem
+	now := time.Now()
+	for key, chain := range e.chains {
+		if key.owner != owner {
+			continue
+		}
+		if paused := chain[0]; paused.input.resume != nil {
+			// The queued item is skipped once it is due, as it is no
+			// longer the head of its object.
+			delete(e.tracked, paused)
+			cancelled = append(cancelled, paused)
+			chain = chain[1:]
+			if len(chain) == 0 {
+				delete(e.chains, key)
+				continue
+			}
+			e.chains[key] = chain
+			chain[0].readyAt = now
+			e.queue.Add(chain[0])
+		}
+		remaining += len(chain)
+		if head := chain[0]; now.Before(head.readyAt) {
+			head.readyAt = now
+			e.queue.Add(head)
+		}
+	}
+	e.notifyDrainedLocked()
+	return remaining, cancelled
+}
+
+// recordCancelled records the executions dropped by flushEvents as failed.
+func (e *Engine) recordCancelled(ctx context.Context, cancelled []*eventItem) {
+	if len(cancelled) == 0 {
+		return
+	}
+	logger := log.FromContext(ctx)
+	recorder, ok := e.executor.(UndeliveredRecorder)
+	for _, item := range cancelled {
+		logger.Info("Cancelling paused execution of deleted ResourceAction",
+			"event", item.input.Event,
+			"gvk", item.input.GVK.String(),
+			"name", item.input.Obj.GetName(),
+		)
+		if !ok {
+			continue
+		}
+		if err := recorder.RecordUndelivered(ctx, item.input, errCancelled); err != nil {
+			logger.Error(err, "failed to record cancelled execution",
+				"event", item.input.Event,
+				"gvk", item.input.GVK.String(),
+				"name", item.input.Obj.GetName(),
+			)
+		}
+	}
+}
+
+// Teardown runs spec.teardown of ra.
+func (e *Engine) Teardown(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	exec, ok := e.executor.(TeardownExecutor)
+	if !ok {
+		return fmt.Errorf("executor does not support teardown actions")
+	}
+	return exec.ExecuteTeardown(ctx, ra)
+}
+
+// ExecuteTeardown runs spec.teardown of ra with ra itself as the object. The
+// result is reported as a Kubernetes event on ra. It does not run in dry run
+// or record mode.
+func (e *K8sExecutor) ExecuteTeardown(ctx context.Context, ra *opsv1alpha1.ResourceAction) (err error) {
+	ctx, span := tracer.Start(ctx, "Executor.ExecuteTeardown", trace.WithAttributes(
+		attribute.String("resourceaction.namespace", ra.Namespace),
+		attribute.String("resourceaction.name", ra.Name),
+	))
+	defer func() { endSpan(span, err) }()
+	ctx, _ = withCorrelationID(ctx)
+
+	if ra.Spec.Teardown == nil || !actionEnabled(*ra.Spec.Teardown) || ra.Spec.DryRun || e.recording(ra) {
+		return nil
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ra)
+	if err != nil {
+		return err
+	}
+	input := MatchInput{
+		Event: EventTeardown,
+		GVK:   opsv1alpha1.GroupVersion.WithKind("ResourceAction"),
+		Obj:   &unstructured.Unstructured{Object: obj},
+	}
+	input.Obj.SetGroupVersionKind(input.GVK)
+
+	// Run the teardown as an extra action, so rate limits and dead letters
+	// that refer to the action by index find it.
+	withTeardown := ra.DeepCopy()
+	withTeardown.Spec.Actions = append(withTeardown.Spec.Actions, *ra.Spec.Teardown)
+	actionIndex := len(withTeardown.Spec.Actions) - 1
+
+	httpExec := e.httpExecutor()
+	jobExec, err := e.jobExecutorFor(ctx, ra)
+	if err != nil {
+		return err
+	}
+	_, err = e.executeAction(ctx, *withTeardown, actionIndex, *ra.Spec.Teardown, input, httpExec, jobExec)
+	if e.Recorder != nil {
+		if err != nil {
+			e.Recorder.Event(ra, corev1.EventTypeWarning, "TeardownFailed", err.Error())
+		} else {
+			e.Recorder.Event(ra, corev1.EventTypeNormal, "TeardownSucceeded", "Teardown action completed")
+		}
+	}
+	return err
+}
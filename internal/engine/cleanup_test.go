@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCleanup_ReleasesWatchAndFlushesDebouncedEvents(t *testing.T) {
+	e := newWatchTestEngine(t)
+	ctx := context.Background()
+	ra := newTestResourceAction("cleanup", "ConfigMap")
+	owner := types.NamespacedName{Namespace: "default", Name: "cleanup"}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	e.debounces[owner] = time.Hour
+
+	old, updated := newConfigMap("demo", "uid-1"), newConfigMap("demo", "uid-1")
+	old.SetResourceVersion("1")
+	updated.SetResourceVersion("2")
+	e.enqueue(MatchInput{
+		Event:  EventUpdate,
+		Obj:    updated,
+		OldObj: old,
+		owners: map[types.NamespacedName]struct{}{owner: {}},
+	})
+	if e.queue.Len() != 0 {
+		t.Fatalf("debounced event queued before its window, queue length %d", e.queue.Len())
+	}
+
+	if pending := e.Cleanup(ctx, owner); pending != 1 {
+		t.Fatalf("Cleanup() = %d pending events, want 1", pending)
+	}
+	if e.queue.Len() != 1 {
+		t.Fatalf("Cleanup() did not flush the debounced event, queue length %d", e.queue.Len())
+	}
+	if isWatching(e, "configmaps", "") {
+		t.Fatalf("Cleanup() kept the informer of the deleted ResourceAction")
+	}
+
+	item, _ := e.queue.Get()
+	e.queue.Done(item)
+	e.finish(item)
+	if pending := e.Cleanup(ctx, owner); pending != 0 {
+		t.Fatalf("Cleanup() after delivery = %d pending events, want 0", pending)
+	}
+}
+
+func TestCleanup_CancelsPausedExecutions(t *testing.T) {
+	e := newWatchTestEngine(t)
+	exec := &blockingExecutor{}
+	e.executor = exec
+	ctx := context.Background()
+	owner := types.NamespacedName{Namespace: "default", Name: "approval"}
+
+	created, updated := newConfigMap("demo", "uid-1"), newConfigMap("demo", "uid-1")
+	created.SetResourceVersion("1")
+	updated.SetResourceVersion("2")
+	e.enqueue(MatchInput{Event: EventCreate, Obj: created, owners: map[types.NamespacedName]struct{}{owner: {}}})
+	// The execution waits for an approval; the Update waits behind it.
+	paused, _ := e.queue.Get()
+	paused.input.resume = &executionProgress{owner: owner}
+	e.queue.Done(paused)
+	e.queue.AddAfter(paused, time.Hour)
+	e.enqueue(MatchInput{Event: EventUpdate, Obj: updated, OldObj: created, owners: map[types.NamespacedName]struct{}{owner: {}}})
+	if e.queue.Len() != 0 {
+		t.Fatalf("event queued behind a paused execution, queue length %d", e.queue.Len())
+	}
+
+	if pending := e.Cleanup(ctx, owner); pending != 1 {
+		t.Fatalf("Cleanup() = %d pending events, want the Update", pending)
+	}
+	if len(exec.undelivered) != 1 || exec.undelivered[0] != "demo" {
+		t.Fatalf("undelivered = %v, want the paused execution recorded", exec.undelivered)
+	}
+	if _, ok := e.tracked[paused]; ok || e.isHead(paused) {
+		t.Fatalf("Cleanup() kept the paused execution")
+	}
+	item, _ := e.queue.Get()
+	if item.input.Event != EventUpdate {
+		t.Fatalf("queued %s, want the Update behind the paused execution", item.input.Event)
+	}
+	e.queue.Done(item)
+	e.finish(item)
+	if pending := e.Cleanup(ctx, owner); pending != 0 {
+		t.Fatalf("Cleanup() after delivery = %d pending events, want 0", pending)
+	}
+}
+
+func TestCronEngine_StopCancelsSchedulesOfOwner(t *testing.T) {
+	c := NewCronEngine(nil, nil)
+	stopped, stoppedCancel := context.WithCancel(context.Background())
+	kept, keptCancel := context.WithCancel(context.Background())
+	defer keptCancel()
+	c.jobs[cronKey{Namespace: "a", ResourceAction: "demo", ActionIndex: 0}] = cronJob{cancel: stoppedCancel}
+	c.jobs[cronKey{Namespace: "b", ResourceAction: "demo", ActionIndex: 0}] = cronJob{cancel: keptCancel}
+
+	c.Stop(types.NamespacedName{Namespace: "a", Name: "demo"})
+
+	if stopped.Err() == nil {
+		t.Fatalf("schedule of the stopped ResourceAction is still running")
+	}
+	if kept.Err() != nil {
+		t.Fatalf("schedule of a ResourceAction in another namespace was stopped")
+	}
+	if len(c.jobs) != 1 {
+		t.Fatalf("jobs = %d, want 1", len(c.jobs))
+	}
+}
+
+func TestExecuteTeardown_RunsWithResourceActionAsObject(t *testing.T) {
+	bodies := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-teardown", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Version: "v1", Kind: "ConfigMap"},
+			Events:   []string{"Create"},
+			Actions:  []opsv1alpha1.ActionSpec{{Type: "http", URL: "https://receiver.example"}},
+			Teardown: &opsv1alpha1.ActionSpec{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:      &opsv1alpha1.TemplateSpec{Template: `{{ .kind }}/{{ .metadata.name }}`},
+			},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	if err := exec.ExecuteTeardown(context.Background(), ra); err != nil {
+		t.Fatalf("ExecuteTeardown() error = %v", err)
+	}
+	if got := <-bodies; got != "ResourceAction/ra-teardown" {
+		t.Fatalf("teardown body = %q, want ResourceAction/ra-teardown", got)
+	}
+}
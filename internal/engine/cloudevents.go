@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// cloudEventTypePrefix is followed by the lowercased event, for example
+	// de.yusaozdemir.resourceaction.create.
+	cloudEventTypePrefix = "de.yusaozdemir.resourceaction."
+
+	cloudEventTimeout  = 10 * time.Second
+	cloudEventAttempts = 3
+	cloudEventBackoff  = 500 * time.Millisecond
+)
+
+// cloudEventExtensionName matches the extension attribute names the
+// CloudEvents specification allows.
+var cloudEventExtensionName = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
+// cloudEventSink emits the matched events of ResourceActions as CloudEvents
+// in binary content mode, like a Knative event source.
+type cloudEventSink struct {
+	url        string
+	extensions map[string]string
+	httpClient *http.Client
+}
+
+// SetCloudEventSink emits every event a ResourceAction matches as a
+// CloudEvent to sink, the K_SINK of a Knative SinkBinding. overrides is the
+// K_CE_OVERRIDES JSON, whose extensions are added to every CloudEvent. An
+// empty sink disables emitting.
+func (e *K8sExecutor) SetCloudEventSink(sink, overrides string) error {
+	e.cloudEvents = nil
+	if sink == "" {
+		return nil
+	}
+	extensions, err := parseCloudEventOverrides(overrides)
+	if err != nil {
+		return err
+	}
+	e.cloudEvents = &cloudEventSink{
+		url:        sink,
+		extensions: extensions,
+		httpClient: &http.Client{Timeout: cloudEventTimeout},
+	}
+	return nil
+}
+
+// emitsCloudEvents reports whether the executor of e has a CloudEvent sink.
+func (e *Engine) emitsCloudEvents() bool {
+	exec, ok := e.executor.(*K8sExecutor)
+	return ok && exec.cloudEvents != nil
+}
+
+func parseCloudEventOverrides(overrides string) (map[string]string, error) {
+	if overrides == "" {
+		return nil, nil
+	}
+	var parsed struct {
+		Extensions map[string]string `json:"extensions"`
+	}
+	if err := json.Unmarshal([]byte(overrides), &parsed); err != nil {
+		return nil, fmt.Errorf("parse CloudEvent overrides: %w", err)
+	}
+	for name := range parsed.Extensions {
+		if !cloudEventExtensionName.MatchString(name) {
+			return nil, fmt.Errorf("CloudEvent extension %q must be 1-20 lowercase letters or digits", name)
+		}
+	}
+	return parsed.Extensions, nil
+}
+
+// emitCloudEvent sends input as a CloudEvent of ra to the sink. Errors are
+// logged; they never change the execution of ra.
+func (e *K8sExecutor) emitCloudEvent(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput) {
+	if e.cloudEvents == nil {
+		return
+	}
+	err := e.cloudEvents.send(ctx, ra, input)
+	result := "success"
+	if err != nil {
+		result = "failure"
+		log.FromContext(ctx).Error(err, "failed to emit CloudEvent",
+			"resourceAction", ra.Name,
+			"event", input.Event,
+			"name", input.Obj.GetName(),
+		)
+	}
+	observeCloudEvent(result)
+}
+
+func (s *cloudEventSink) send(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput) error {
+	payload, err := json.Marshal(input.Obj.Object)
+	if err != nil {
+		return err
+	}
+	headers := cloudEventHeaders(ra, input, s.extensions)
+
+	for attempt := 1; ; attempt++ {
+		err = s.post(ctx, headers, payload)
+		if err == nil || attempt == cloudEventAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * cloudEventBackoff):
+		}
+	}
+}
+
+func (s *cloudEventSink) post(ctx context.Context, headers http.Header, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header = headers.Clone()
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sink returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// cloudEventHeaders returns the binary content mode headers of the CloudEvent
+// of input. The source is the ResourceAction that matched it, the subject the
+// API path of the object, as with the Knative ApiServerSource.
+func cloudEventHeaders(ra *opsv1alpha1.ResourceAction, input MatchInput, extensions map[string]string) http.Header {
+	h := http.Header{}
+	for name, value := range extensions {
+		h.Set("Ce-"+name, value)
+	}
+	h.Set("Content-Type", "application/json")
+	h.Set("Ce-Specversion", "1.0")
+	h.Set("Ce-Id", string(uuid.NewUUID()))
+	h.Set("Ce-Source", fmt.Sprintf("/apis/%s/namespaces/%s/resourceactions/%s",
+		opsv1alpha1.GroupVersion.String(), ra.Namespace, ra.Name))
+	h.Set("Ce-Type", cloudEventTypePrefix+strings.ToLower(string(input.Event)))
+	h.Set("Ce-Subject", objectPath(input))
+	h.Set("Ce-Time", time.Now().UTC().Format(time.RFC3339Nano))
+	h.Set("Ce-Kind", input.GVK.Kind)
+	h.Set("Ce-Name", input.Obj.GetName())
+	if ns := input.Obj.GetNamespace(); ns != "" {
+		h.Set("Ce-Namespace", ns)
+	}
+	return h
+}
+
+// objectPath returns the API path of the object of input, for example
+// /apis/apps/v1/namespaces/shop/deployments/cart. The resource is guessed
+// from the kind, which holds for the conventionally named kinds.
+func objectPath(input MatchInput) string {
+	prefix := "/apis/" + input.GVK.GroupVersion().String()
+	if input.GVK.Group == "" {
+		prefix = "/api/" + input.GVK.Version
+	}
+	plural, _ := meta.UnsafeGuessKindToResource(input.GVK)
+	resource := plural.Resource
+	if ns := input.Obj.GetNamespace(); ns != "" {
+		return fmt.Sprintf("%s/namespaces/%s/%s/%s", prefix, ns, resource, input.Obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", prefix, resource, input.Obj.GetName())
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakemetadata "k8s.io/client-go/metadata/fake"
+)
+
+func TestExecute_EmitsCloudEvents(t *testing.T) {
+	var mu sync.Mutex
+	var received []http.Header
+	var bodies []map[string]interface{}
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		raw, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("decode data: %v", err)
+		}
+		mu.Lock()
+		received = append(received, r.Header.Clone())
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+	var calls atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer target.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "ops"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       target.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+	if err := exec.SetCloudEventSink(sink.URL, `{"extensions":{"cluster":"prod-eu"}}`); err != nil {
+		t.Fatalf("SetCloudEventSink() error = %v", err)
+	}
+
+	// The second delivery is skipped by the execution policy, but still
+	// emitted.
+	for range 2 {
+		if err := exec.Execute(context.Background(), newDeploymentInput("uid-1", "cart", "shop")); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-2", "other", "shop")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	deleted := newDeploymentInput("uid-3", "cart", "shop")
+	deleted.Event = EventDelete
+	if err := exec.Execute(context.Background(), deleted); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if calls.Load() != 2 {
+		t.Fatalf("action calls = %d, want 2", calls.Load())
+	}
+	if len(received) != 3 {
+		t.Fatalf("CloudEvents = %d, want one per matched event", len(received))
+	}
+	h := received[0]
+	want := map[string]string{
+		"Ce-Specversion": "1.0",
+		"Ce-Type":        "de.yusaozdemir.resourceaction.create",
+		"Ce-Source":      "/apis/ops.yusaozdemir.de/v1alpha1/namespaces/ops/resourceactions/notify",
+		"Ce-Subject":     "/apis/apps/v1/namespaces/shop/deployments/cart",
+		"Ce-Kind":        "Deployment",
+		"Ce-Name":        "cart",
+		"Ce-Namespace":   "shop",
+		"Ce-Cluster":     "prod-eu",
+		"Content-Type":   "application/json",
+	}
+	for name, value := range want {
+		if got := h.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if h.Get("Ce-Id") == "" || h.Get("Ce-Id") == received[1].Get("Ce-Id") {
+		t.Errorf("Ce-Id = %q, want a unique ID", h.Get("Ce-Id"))
+	}
+	if h.Get("Ce-Time") == "" {
+		t.Errorf("Ce-Time is missing")
+	}
+	if meta, _ := bodies[0]["metadata"].(map[string]interface{}); meta["uid"] != "uid-1" {
+		t.Errorf("data = %v, want the object", bodies[0])
+	}
+}
+
+func TestCloudEventSink_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer sink.Close()
+
+	ra := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "ops"}}
+	exec, _ := newTestExecutor(t)
+	if err := exec.SetCloudEventSink(sink.URL, ""); err != nil {
+		t.Fatalf("SetCloudEventSink() error = %v", err)
+	}
+	if err := exec.cloudEvents.send(context.Background(), ra, newDeploymentInput("uid-1", "cart", "shop")); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if attempts.Load() != 2 {
+		t.Fatalf("attempts = %d, want the failed attempt to be retried", attempts.Load())
+	}
+}
+
+func TestParseCloudEventOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides string
+		want      map[string]string
+		wantErr   bool
+	}{
+		{name: "empty"},
+		{name: "extensions", overrides: `{"extensions":{"cluster":"prod"}}`, want: map[string]string{"cluster": "prod"}},
+		{name: "invalid JSON", overrides: `{`, wantErr: true},
+		{name: "invalid name", overrides: `{"extensions":{"Cluster-Name":"prod"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCloudEventOverrides(tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("extensions = %v, want %v", got, tt.want)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Fatalf("extensions = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectPath(t *testing.T) {
+	input := newDeploymentInput("uid-1", "cart", "shop")
+	if got := objectPath(input); got != "/apis/apps/v1/namespaces/shop/deployments/cart" {
+		t.Fatalf("objectPath() = %q", got)
+	}
+	input.GVK.Group, input.GVK.Kind = "", "Node"
+	input.Obj.SetNamespace("")
+	if got := objectPath(input); got != "/api/v1/nodes/cart" {
+		t.Fatalf("objectPath() = %q", got)
+	}
+	input.GVK.Kind = "NetworkPolicy"
+	if got := objectPath(input); got != "/api/v1/networkpolicies/cart" {
+		t.Fatalf("objectPath() = %q", got)
+	}
+}
+
+func TestEnsureWatchingFor_CloudEventSinkNeedsFullObjects(t *testing.T) {
+	e := newWatchTestEngine(t)
+	e.meta = fakemetadata.NewSimpleMetadataClient(fakemetadata.NewTestScheme())
+	e.SetCacheStripStatus(true)
+	exec, _ := newTestExecutor(t)
+	e.executor = exec
+	ctx := context.Background()
+
+	ra := newTestResourceAction("sink", "ConfigMap")
+	owner := types.NamespacedName{Namespace: "default", Name: "sink"}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if keys := e.owners[owner]; len(keys) != 1 || !keys[0].metadataOnly || keys[0].keepStatus {
+		t.Fatalf("expected a metadata-only informer without a sink, got %v", keys)
+	}
+
+	if err := exec.SetCloudEventSink("http://sink.example", ""); err != nil {
+		t.Fatalf("SetCloudEventSink() error = %v", err)
+	}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if keys := e.owners[owner]; len(keys) != 1 || keys[0].metadataOnly || !keys[0].keepStatus {
+		t.Fatalf("expected a full informer keeping status with a sink, got %v", keys)
+	}
+}
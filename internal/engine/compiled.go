@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"regexp"
+	"sync"
+	"text/template"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// compiledSpec caches the regular expressions, templates and CEL programs of
+// one generation of a ResourceAction, so they are compiled once instead of on
+// every event and retry. A nil compiledSpec compiles on every call.
+type compiledSpec struct {
+	uid        types.UID
+	generation int64
+
+	mu        sync.Mutex
+	regexps   map[compiledKey]*regexp.Regexp
+	templates map[compiledKey]*template.Template
+	programs  map[compiledKey]cel.Program
+}
+
+// compiledKey is a field of the spec and its text. The text is part of the
+// key, since the fields of different actions, for example of handlers and
+// the teardown, share their names.
+type compiledKey struct {
+	field string
+	text  string
+}
+
+// compiledCache holds the compiledSpec of every ResourceAction. An entry is
+// replaced once the UID or generation of its ResourceAction changed and
+// dropped by forget. A nil compiledCache compiles on every call.
+type compiledCache struct {
+	mu      sync.Mutex
+	byOwner map[types.NamespacedName]*compiledSpec
+}
+
+func newCompiledCache() *compiledCache {
+	return &compiledCache{byOwner: map[types.NamespacedName]*compiledSpec{}}
+}
+
+// get returns the cache of the current generation of ra, or nil when c or ra
+// is nil.
+func (c *compiledCache) get(ra *opsv1alpha1.ResourceAction) *compiledSpec {
+	if c == nil || ra == nil {
+		return nil
+	}
+	owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spec := c.byOwner[owner]
+	if spec == nil || spec.uid != ra.UID || spec.generation != ra.Generation {
+		spec = &compiledSpec{
+			uid:        ra.UID,
+			generation: ra.Generation,
+			regexps:    map[compiledKey]*regexp.Regexp{},
+			templates:  map[compiledKey]*template.Template{},
+			programs:   map[compiledKey]cel.Program{},
+		}
+		c.byOwner[owner] = spec
+	}
+	return spec
+}
+
+// forget drops the cache of the ResourceAction owner.
+func (c *compiledCache) forget(owner types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.byOwner, owner)
+}
+
+// compileRegexp returns the compiled pattern of field. Invalid patterns are
+// not cached; validation rejects them before they reach the engine.
+func (c *compiledSpec) compileRegexp(field, pattern string) (*regexp.Regexp, error) {
+	if c == nil {
+		return regexp.Compile(pattern)
+	}
+	key := compiledKey{field: field, text: pattern}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if re, ok := c.regexps[key]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.regexps[key] = re
+	return re, nil
+}
+
+// parseTemplate returns the parsed template text named name with funcs.
+// Templates are parsed once with the functions of bodyTemplateFuncs and every
+// call gets a clone with funcs bound, since the functions close over the
+// outputs and the item of an execution.
+func (c *compiledSpec) parseTemplate(name, text string, funcs template.FuncMap) (*template.Template, error) {
+	if c == nil {
+		return template.New(name).Funcs(funcs).Parse(text)
+	}
+	key := compiledKey{field: name, text: text}
+	c.mu.Lock()
+	tpl, ok := c.templates[key]
+	if !ok {
+		var err error
+		tpl, err = template.New(name).Funcs(bodyTemplateFuncs(nil, nil)).Parse(text)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+		c.templates[key] = tpl
+	}
+	c.mu.Unlock()
+	clone, err := tpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.Funcs(funcs), nil
+}
+
+// compileSuccessCondition returns the program of the successCondition expr.
+// Programs are safe for concurrent use.
+func (c *compiledSpec) compileSuccessCondition(expr string) (cel.Program, error) {
+	if c == nil {
+		return opsv1alpha1.CompileSuccessCondition(expr)
+	}
+	key := compiledKey{field: "successCondition", text: expr}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if program, ok := c.programs[key]; ok {
+		return program, nil
+	}
+	program, err := opsv1alpha1.CompileSuccessCondition(expr)
+	if err != nil {
+		return nil, err
+	}
+	c.programs[key] = program
+	return program, nil
+}
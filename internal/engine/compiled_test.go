@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCompiledCache_CachesPerGeneration(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-compiled", Namespace: "default", UID: "uid-1", Generation: 1},
+	}
+	owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	cache := newCompiledCache()
+
+	first, err := cache.get(ra).compileRegexp("expectedStatus", "^2..$")
+	if err != nil {
+		t.Fatalf("compileRegexp() error = %v", err)
+	}
+	second, _ := cache.get(ra).compileRegexp("expectedStatus", "^2..$")
+	if first != second {
+		t.Fatalf("expected the regexp to be compiled once per generation")
+	}
+
+	ra.Generation = 2
+	if third, _ := cache.get(ra).compileRegexp("expectedStatus", "^2..$"); third == first {
+		t.Fatalf("expected a new generation to compile the regexp again")
+	}
+	cache.forget(owner)
+	if _, ok := cache.byOwner[owner]; ok {
+		t.Fatalf("expected forget to drop the cache")
+	}
+	if other := newCompiledCache(); other.get(ra) == cache.get(ra) {
+		t.Fatalf("expected caches of different executors to be independent")
+	}
+
+	if _, err := cache.get(ra).compileRegexp("filters.nameRegex", "["); err == nil {
+		t.Fatalf("expected an invalid regexp to fail")
+	}
+}
+
+func TestCompiledSpec_TemplatesBindFuncsPerCall(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-compiled-templates", Namespace: "default", UID: "uid-2", Generation: 1},
+	}
+	compiled := newCompiledCache().get(ra)
+	data := map[string]interface{}{"metadata": map[string]interface{}{"name": "demo"}}
+
+	for _, id := range []string{"1", "2"} {
+		got, err := renderTemplate(compiled, "body", `{{ .metadata.name }}-{{ output "id" }}`, data,
+			bodyTemplateFuncs(map[string]string{"id": id}, nil))
+		if err != nil {
+			t.Fatalf("renderTemplate() error = %v", err)
+		}
+		if want := "demo-" + id; got != want {
+			t.Fatalf("renderTemplate() = %q, want %q", got, want)
+		}
+	}
+	if len(compiled.templates) != 1 {
+		t.Fatalf("templates = %d, want the template parsed once", len(compiled.templates))
+	}
+	if _, err := renderTemplate(compiled, "body", "{{ nosuchfunc }}", data, bodyTemplateFuncs(nil, nil)); err == nil {
+		t.Fatalf("expected an unknown function to fail")
+	}
+}
+
+func TestValidateTargetURL_CompilesURLPolicyOnce(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-compiled-policy", Namespace: "default", UID: "uid-3", Generation: 1},
+	}
+	compiled := newCompiledCache().get(ra)
+	policy := &opsv1alpha1.URLPolicySpec{
+		AllowedHostRegex: []string{`^api\.example\.com$`},
+		BlockedHostRegex: []string{`^blocked\.`},
+	}
+	for i := 0; i < 2; i++ {
+		if err := validateTargetURL(compiled, "https://api.example.com/hook", policy, nil); err != nil {
+			t.Fatalf("validateTargetURL() error = %v", err)
+		}
+	}
+	if len(compiled.regexps) != 2 {
+		t.Fatalf("regexps = %d, want each urlPolicy pattern compiled once", len(compiled.regexps))
+	}
+}
+
+func TestCheckSuccessCondition_CompilesOncePerGeneration(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-compiled-condition", Namespace: "default", UID: "uid-4", Generation: 1},
+	}
+	cache := newCompiledCache()
+	for i := 0; i < 2; i++ {
+		if err := checkSuccessCondition(cache.get(ra), "status == 200", 200, nil, nil); err != nil {
+			t.Fatalf("checkSuccessCondition() error = %v", err)
+		}
+	}
+	if len(cache.get(ra).programs) != 1 {
+		t.Fatalf("programs = %d, want the condition compiled once", len(cache.get(ra).programs))
+	}
+
+	ra.Generation = 2
+	if len(cache.get(ra).programs) != 0 {
+		t.Fatalf("expected a new generation to drop the compiled conditions")
+	}
+}
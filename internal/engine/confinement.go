@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+// namespaceConfinement restricts ResourceActions to objects in their own
+// namespace, so teams that manage their own ResourceActions cannot act on
+// other tenants' objects through the operator.
+type namespaceConfinement struct {
+	enabled bool
+	// clusterScope lists the namespaces whose ResourceActions may still match
+	// objects in every namespace and cluster-scoped objects.
+	clusterScope []string
+}
+
+// NamespaceConfinementError is returned for a ResourceAction that selects
+// objects outside of its namespace while namespace confinement is enabled.
+type NamespaceConfinementError struct {
+	Reason string
+}
+
+func (e *NamespaceConfinementError) Error() string {
+	return "namespace confinement: " + e.Reason
+}
+
+// SetNamespaceConfinement confines every ResourceAction to objects in its own
+// namespace, except those in clusterScope. Only ResourceActions in
+// clusterScope may set spec.clusterRef, since a kubeconfig can point at any
+// cluster, including the local one. It must be called before the first watch
+// is established.
+func (e *Engine) SetNamespaceConfinement(enabled bool, clusterScope []string) {
+	c := namespaceConfinement{enabled: enabled, clusterScope: normalizeNamespaces(clusterScope)}
+	e.confinement = c
+	if exec, ok := e.executor.(*K8sExecutor); ok {
+		exec.confinement = c
+	}
+}
+
+// confines reports whether ra may only match objects in its own namespace.
+func (c namespaceConfinement) confines(ra *opsv1alpha1.ResourceAction) bool {
+	return c.enabled && !slices.Contains(c.clusterScope, ra.Namespace)
+}
+
+// checkClusterRef rejects spec.clusterRef for a confined ra.
+func (c namespaceConfinement) checkClusterRef(ra *opsv1alpha1.ResourceAction) error {
+	if ra.Spec.ClusterRef != nil && c.confines(ra) {
+		return &NamespaceConfinementError{Reason: "clusterRef is only allowed in the cluster-scope namespaces"}
+	}
+	return nil
+}
+
+// watchNamespaces returns the namespaces the informers of ra are restricted to.
+func (c namespaceConfinement) watchNamespaces(ra *opsv1alpha1.ResourceAction) ([]string, error) {
+	if !c.confines(ra) {
+		return ra.Spec.WatchNamespaces, nil
+	}
+	if err := c.checkClusterRef(ra); err != nil {
+		return nil, err
+	}
+	for _, ns := range normalizeNamespaces(ra.Spec.WatchNamespaces) {
+		if ns != ra.Namespace {
+			return nil, &NamespaceConfinementError{
+				Reason: fmt.Sprintf("watchNamespaces may only contain the namespace %q of the ResourceAction", ra.Namespace),
+			}
+		}
+	}
+	return []string{ra.Namespace}, nil
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	correlationIDHeader     = "X-Correlation-ID"
+	correlationIDAnnotation = "resource-action-operator.yusaozdemir.de/correlation-id"
+)
+
+type correlationIDKey struct{}
+
+// withCorrelationID starts a new execution scope: it stores a fresh
+// correlation ID in ctx and adds it to the context logger.
+func withCorrelationID(ctx context.Context) (context.Context, string) {
+	id := string(uuid.NewUUID())
+	return contextWithCorrelationID(ctx, id), id
+}
+
+// contextWithCorrelationID continues the execution scope with the
+// correlation ID id, e.g. when an execution resumes after a wait action.
+func contextWithCorrelationID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, correlationIDKey{}, id)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("resourceaction.correlation_id", id))
+	return log.IntoContext(ctx, log.FromContext(ctx).WithValues("correlationID", id))
+}
+
+// correlationIDFrom returns the correlation ID of the current execution, or
+// an empty string outside of an execution.
+func correlationIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
@@ -2,113 +2,496 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"sync"
 	"time"
 
 	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type cronKey struct {
+	Namespace      string
 	ResourceAction string
 	ResourceUID    types.UID
 	ActionIndex    int
 	Event          EventType
 }
 
+// cronJob is a running schedule. Event-scoped schedules keep the event they
+// were registered for, so they can be registered again when the spec changes.
+type cronJob struct {
+	cancel context.CancelFunc
+	input  MatchInput
+}
+
+const (
+	scheduledResultSucceeded = "Succeeded"
+	scheduledResultFailed    = "Failed"
+	scheduledResultSkipped   = "Skipped"
+)
+
+// ScheduledExecutor runs a single cron action. Executors that do not
+// implement it fall back to Executor.Execute on every tick.
+type ScheduledExecutor interface {
+	ExecuteScheduled(ctx context.Context, ra opsv1alpha1.ResourceAction, actionIndex int, input MatchInput) error
+}
+
+// ObjectLister enumerates the cached objects watched for a ResourceAction.
+type ObjectLister interface {
+	ListCached(owner types.NamespacedName) ([]*unstructured.Unstructured, error)
+}
+
 type CronEngine struct {
 	client   client.Client
 	executor Executor
+	lister   ObjectLister
+	// compiled caches the regular expressions of the filters, shared with a
+	// K8sExecutor.
+	compiled *compiledCache
 
 	mu      sync.Mutex
-	jobs    map[cronKey]context.CancelFunc
+	jobs    map[cronKey]cronJob
 	started bool
+	// runCtx is the context passed to Start. Schedules stop when it is done.
+	runCtx context.Context
+	// deferred holds the standalone schedules requested before Start.
+	deferred map[types.NamespacedName]opsv1alpha1.ResourceAction
+	// restored is set once Start ran the deferred schedules.
+	restored bool
+
+	// slots bounds the number of cron ticks executing at the same time.
+	// A nil channel means no limit.
+	slots chan struct{}
 }
 
 func NewCronEngine(c client.Client, exec Executor) *CronEngine {
-	return &CronEngine{
+	cron := &CronEngine{
 		client:   c,
 		executor: exec,
-		jobs:     make(map[cronKey]context.CancelFunc),
+		jobs:     make(map[cronKey]cronJob),
+		runCtx:   context.Background(),
+		deferred: make(map[types.NamespacedName]opsv1alpha1.ResourceAction),
+	}
+	if k8sExec, ok := exec.(*K8sExecutor); ok {
+		cron.compiled = k8sExec.compiled
 	}
+	return cron
 }
 
-func (c *CronEngine) Start(ctx context.Context) {
+// SetMaxConcurrency limits how many cron ticks may execute concurrently
+// across all registered schedules. Values <= 0 disable the limit.
+func (c *CronEngine) SetMaxConcurrency(n int) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.jobs == nil {
-		c.jobs = make(map[cronKey]context.CancelFunc)
+	if n <= 0 {
+		c.slots = nil
+		return
 	}
+	c.slots = make(chan struct{}, n)
+}
+
+func (c *CronEngine) acquireSlot(ctx context.Context) (func(), bool) {
+	c.mu.Lock()
+	slots := c.slots
+	c.mu.Unlock()
+
+	if slots == nil {
+		return func() {}, true
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// scheduleJitter returns a deterministic offset in [0, period) derived from
+// seed, so that schedules registered at the same time fire spread out.
+func scheduleJitter(seed string, period time.Duration) time.Duration {
+	if period <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(seed))
+	return time.Duration(h.Sum64() % uint64(period))
+}
+
+// startTicker waits for the jitter offset and then starts a ticker with the
+// given period. It returns nil when ctx is cancelled while waiting.
+func startTicker(ctx context.Context, offset, period time.Duration) *time.Ticker {
+	if offset > 0 {
+		timer := time.NewTimer(offset)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+	return time.NewTicker(period)
+}
 
+// Start runs the schedules with ctx, including the standalone schedules that
+// were requested before.
+func (c *CronEngine) Start(ctx context.Context) {
+	c.mu.Lock()
+	if c.jobs == nil {
+		c.jobs = make(map[cronKey]cronJob)
+	}
+	c.runCtx = ctx
 	c.started = true
+	deferred := c.deferred
+	c.deferred = nil
+	c.mu.Unlock()
+
+	for _, ra := range deferred {
+		if err := c.EnsureStandalone(ctx, ra); err != nil {
+			log.FromContext(ctx).Error(err, "failed to start standalone schedules", "resourceAction", ra.Name)
+		}
+	}
+	c.mu.Lock()
+	c.restored = true
+	c.mu.Unlock()
+}
+
+// isStarted reports whether Start ran and restored the standalone schedules
+// requested before it.
+func (c *CronEngine) isStarted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restored
 }
 
 // EnsureForMatch is called on every event,
 // but registers cron jobs only once.
 func (c *CronEngine) EnsureForMatch(ctx context.Context, input MatchInput) error {
-	logger := log.FromContext(ctx)
-
 	var list opsv1alpha1.ResourceActionList
 	if err := c.client.List(ctx, &list); err != nil {
 		return err
 	}
 
 	for _, ra := range list.Items {
-		// Selector / Event match
-		if !matchesSelector(ra.Spec.Selector, input.GVK) {
+		c.ensureEventSchedules(ctx, ra, input)
+	}
+
+	return nil
+}
+
+// ensureEventSchedules registers the event-scoped cron actions of ra for the
+// object of input if the event matches ra.
+func (c *CronEngine) ensureEventSchedules(ctx context.Context, ra opsv1alpha1.ResourceAction, input MatchInput) {
+	logger := log.FromContext(ctx)
+
+	// Selector / Event match
+	if !matchesSelector(ra.Spec.Selector, input.GVK) {
+		return
+	}
+	if !input.targets(&ra) || !watchesNamespace(ra.Spec.WatchNamespaces, input.Obj) {
+		return
+	}
+	if !containsEvent(ra.Spec.Events, string(input.Event)) {
+		return
+	}
+	if !matchesFilters(c.compiled.get(&ra), &ra, input) {
+		return
+	}
+
+	for i, action := range ra.Spec.Actions {
+		if action.Mode != "cron" && action.Mode != "schedule" {
 			continue
 		}
-		if !containsEvent(ra.Spec.Events, string(input.Event)) {
+		if action.Schedule == "" || isStandaloneSchedule(action) || !actionEnabled(action) {
 			continue
 		}
-		if !matchesFilters(ra.Spec.Filters, input) {
+
+		key := cronKey{
+			Namespace:      ra.Namespace,
+			ResourceAction: ra.Name,
+			ResourceUID:    input.Obj.GetUID(),
+			ActionIndex:    i,
+			Event:          input.Event,
+		}
+
+		c.mu.Lock()
+		if _, exists := c.jobs[key]; exists {
+			c.mu.Unlock()
 			continue
 		}
 
-		for i, action := range ra.Spec.Actions {
-			if action.Mode != "cron" && action.Mode != "schedule" {
-				continue
-			}
-			if action.Schedule == "" {
-				continue
-			}
+		jobCtx, cancel := context.WithCancel(c.runCtx)
+		c.jobs[key] = cronJob{cancel: cancel, input: input}
+		c.mu.Unlock()
 
-			key := cronKey{
-				ResourceAction: ra.Name,
-				ResourceUID:    input.Obj.GetUID(),
-				ActionIndex:    i,
-				Event:          input.Event,
-			}
+		logger.Info("Starting cron action",
+			"resourceAction", ra.Name,
+			"schedule", action.Schedule,
+			"name", input.Obj.GetName(),
+		)
 
-			c.mu.Lock()
-			if _, exists := c.jobs[key]; exists {
-				c.mu.Unlock()
-				continue
-			}
+		go c.runCron(jobCtx, ra, i, action, input)
+	}
+}
 
-			jobCtx, cancel := context.WithCancel(context.Background())
-			c.jobs[key] = cancel
-			c.mu.Unlock()
+// EnsureStandalone registers cron actions with scheduleScope "all" for the
+// given ResourceAction. They run independently of events.
+func (c *CronEngine) EnsureStandalone(ctx context.Context, ra opsv1alpha1.ResourceAction) error {
+	logger := log.FromContext(ctx)
 
-			logger.Info("Starting cron action",
-				"resourceAction", ra.Name,
-				"schedule", action.Schedule,
-				"name", input.Obj.GetName(),
-			)
+	for i, action := range ra.Spec.Actions {
+		if !isStandaloneSchedule(action) || action.Schedule == "" || !actionEnabled(action) {
+			continue
+		}
 
-			go c.runCron(jobCtx, ra, action, input)
+		key := cronKey{
+			Namespace:      ra.Namespace,
+			ResourceAction: ra.Name,
+			ActionIndex:    i,
+		}
+
+		c.mu.Lock()
+		if !c.started {
+			c.deferred[types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}] = ra
+			c.mu.Unlock()
+			return nil
+		}
+		if _, exists := c.jobs[key]; exists {
+			c.mu.Unlock()
+			continue
 		}
+
+		jobCtx, cancel := context.WithCancel(c.runCtx)
+		c.jobs[key] = cronJob{cancel: cancel}
+		c.mu.Unlock()
+
+		logger.Info("Starting standalone cron action",
+			"resourceAction", ra.Name,
+			"actionIndex", i,
+			"schedule", action.Schedule,
+		)
+
+		go c.runStandaloneCron(jobCtx, ra, i, action)
 	}
 
 	return nil
 }
 
+// Stop cancels all schedules of the ResourceAction owner.
+func (c *CronEngine) Stop(owner types.NamespacedName) {
+	c.stop(owner)
+}
+
+// stop cancels all schedules of owner and returns the events its
+// event-scoped schedules were registered for, one per object and event.
+func (c *CronEngine) stop(owner types.NamespacedName) []MatchInput {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.deferred, owner)
+	var inputs []MatchInput
+	seen := make(map[cronKey]struct{})
+	for key, job := range c.jobs {
+		if key.Namespace != owner.Namespace || key.ResourceAction != owner.Name {
+			continue
+		}
+		job.cancel()
+		delete(c.jobs, key)
+		if key.ResourceUID == "" {
+			continue
+		}
+		object := cronKey{ResourceUID: key.ResourceUID, Event: key.Event}
+		if _, ok := seen[object]; !ok {
+			seen[object] = struct{}{}
+			inputs = append(inputs, job.input)
+		}
+	}
+	return inputs
+}
+
+// Resync restarts the schedules of ra with its current spec. Event-scoped
+// schedules are registered again for the objects they ran for.
+func (c *CronEngine) Resync(ctx context.Context, ra opsv1alpha1.ResourceAction) error {
+	inputs := c.stop(types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name})
+	for _, input := range inputs {
+		c.ensureEventSchedules(ctx, ra, input)
+	}
+	return c.EnsureStandalone(ctx, ra)
+}
+
+func isStandaloneSchedule(action opsv1alpha1.ActionSpec) bool {
+	return (action.Mode == "cron" || action.Mode == "schedule") && action.ScheduleScope == "all"
+}
+
+func (c *CronEngine) runStandaloneCron(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	action opsv1alpha1.ActionSpec,
+) {
+	logger := log.FromContext(ctx)
+
+	dur, err := time.ParseDuration(action.Schedule)
+	if err != nil {
+		logger.Error(err, "invalid cron duration", "schedule", action.Schedule)
+		return
+	}
+
+	offset := scheduleJitter(fmt.Sprintf("%s/%s/%d", ra.Namespace, ra.Name, actionIndex), dur)
+	entry := opsv1alpha1.ScheduledActionStatus{
+		ActionIndex: actionIndex,
+		ActionName:  action.Name,
+		Schedule:    action.Schedule,
+	}
+	previous := c.previousScheduledStatus(ctx, ra, entry)
+	carryOverScheduledStatus(&entry, previous)
+	entry.NextRunTime = ptrTo(metav1.NewTime(time.Now().Add(offset + dur)))
+	c.updateScheduledActionStatus(ctx, ra, entry)
+
+	if missedRunDue(previous, action, time.Now()) {
+		logger.Info("Catching up missed standalone cron run", "resourceAction", ra.Name, "actionIndex", actionIndex)
+		if !c.standaloneTick(ctx, ra, actionIndex, &entry, time.Now(), offset+dur) {
+			return
+		}
+	}
+
+	ticker := startTicker(ctx, offset, dur)
+	if ticker == nil {
+		return
+	}
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopping standalone cron action", "resourceAction", ra.Name)
+			return
+
+		case tick := <-ticker.C:
+			if !c.standaloneTick(ctx, ra, actionIndex, &entry, tick, dur) {
+				return
+			}
+		}
+	}
+}
+
+// standaloneTick runs one tick of a standalone schedule and records the
+// result. It returns false when the schedule should stop.
+func (c *CronEngine) standaloneTick(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	entry *opsv1alpha1.ScheduledActionStatus,
+	tick time.Time,
+	untilNext time.Duration,
+) bool {
+	logger := log.FromContext(ctx)
+
+	entry.LastRunTime = ptrTo(metav1.NewTime(tick))
+	entry.NextRunTime = ptrTo(metav1.NewTime(tick.Add(untilNext)))
+	entry.LastRunError = ""
+
+	var current opsv1alpha1.ResourceAction
+	if err := c.client.Get(ctx, client.ObjectKey{
+		Name:      ra.Name,
+		Namespace: ra.Namespace,
+	}, &current); err != nil {
+		logger.Info("Stopping cron, ResourceAction gone", "resourceAction", ra.Name)
+		return false
+	}
+	if current.Spec.Suspend {
+		entry.LastRunResult = scheduledResultSkipped
+		c.updateScheduledActionStatus(ctx, ra, *entry)
+		return true
+	}
+
+	release, ok := c.acquireSlot(ctx)
+	if !ok {
+		return false
+	}
+	tickCtx, cancel := context.WithTimeout(ctx, executionDeadline(current, actionIndex))
+	matched, execErr := c.runStandaloneTick(tickCtx, current, actionIndex, tick)
+	cancel()
+	release()
+	entry.MatchedResources = matched
+	if execErr != nil {
+		logger.Error(execErr, "standalone cron action failed",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+		)
+		entry.LastRunResult = scheduledResultFailed
+		entry.LastRunError = execErr.Error()
+	} else {
+		entry.LastRunResult = scheduledResultSucceeded
+	}
+	c.updateScheduledActionStatus(ctx, ra, *entry)
+	return true
+}
+
+// runStandaloneTick executes the action against every cached object that
+// currently matches the ResourceAction. The first error is returned after
+// all objects were attempted.
+func (c *CronEngine) runStandaloneTick(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	tick time.Time,
+) (int, error) {
+	if c.lister == nil {
+		return 0, fmt.Errorf("no object lister configured for standalone schedules")
+	}
+	scheduled, ok := c.executor.(ScheduledExecutor)
+	if !ok {
+		return 0, fmt.Errorf("executor does not support scheduled actions")
+	}
+	if actionIndex >= len(ra.Spec.Actions) {
+		return 0, fmt.Errorf("action index %d out of range", actionIndex)
+	}
+
+	gvk := schema.GroupVersionKind{
+		Group:   ra.Spec.Selector.Group,
+		Version: ra.Spec.Selector.Version,
+		Kind:    ra.Spec.Selector.Kind,
+	}
+	objects, err := c.lister.ListCached(types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name})
+	if err != nil {
+		return 0, err
+	}
+
+	matched := 0
+	var firstErr error
+	for _, obj := range objects {
+		if err := ctx.Err(); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("execution deadline exceeded after %d objects: %w", matched, err)
+			}
+			break
+		}
+		input := MatchInput{GVK: gvk, Obj: obj, ScheduledAt: tick}
+		if !watchesNamespace(ra.Spec.WatchNamespaces, obj) || !matchesFilters(c.compiled.get(&ra), &ra, input) {
+			continue
+		}
+		matched++
+		if err := scheduled.ExecuteScheduled(ctx, ra, actionIndex, input); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return matched, firstErr
+}
+
 func (c *CronEngine) runCron(
 	ctx context.Context,
 	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
 	action opsv1alpha1.ActionSpec,
 	input MatchInput,
 ) {
@@ -120,7 +503,36 @@ func (c *CronEngine) runCron(
 		return
 	}
 
-	ticker := time.NewTicker(dur)
+	offset := scheduleJitter(fmt.Sprintf("%s/%s/%d/%s/%s", ra.Namespace, ra.Name, actionIndex, input.Obj.GetUID(), input.Event), dur)
+	entry := opsv1alpha1.ScheduledActionStatus{
+		ActionIndex:       actionIndex,
+		ActionName:        action.Name,
+		ResourceUID:       string(input.Obj.GetUID()),
+		ResourceName:      input.Obj.GetName(),
+		ResourceNamespace: input.Obj.GetNamespace(),
+		Event:             string(input.Event),
+		Schedule:          action.Schedule,
+	}
+	previous := c.previousScheduledStatus(ctx, ra, entry)
+	carryOverScheduledStatus(&entry, previous)
+	entry.NextRunTime = ptrTo(metav1.NewTime(time.Now().Add(offset + dur)))
+	c.updateScheduledActionStatus(ctx, ra, entry)
+
+	if missedRunDue(previous, action, time.Now()) {
+		logger.Info("Catching up missed cron run",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+			"name", input.Obj.GetName(),
+		)
+		if !c.eventTick(ctx, ra, actionIndex, input, &entry, time.Now(), offset+dur) {
+			return
+		}
+	}
+
+	ticker := startTicker(ctx, offset, dur)
+	if ticker == nil {
+		return
+	}
 	defer ticker.Stop()
 
 	for {
@@ -132,27 +544,205 @@ func (c *CronEngine) runCron(
 			)
 			return
 
-		case <-ticker.C:
-			// Verify the ResourceAction still exists.
-			if input.Event != EventDelete {
-				exists := &opsv1alpha1.ResourceAction{}
-				err := c.client.Get(context.Background(), client.ObjectKey{
-					Name:      ra.Name,
-					Namespace: ra.Namespace,
-				}, exists)
-				if err != nil {
-					logger.Info("Stopping cron, ResourceAction gone",
-						"resourceAction", ra.Name)
-					return
-				}
+		case tick := <-ticker.C:
+			if !c.eventTick(ctx, ra, actionIndex, input, &entry, tick, dur) {
+				return
 			}
+		}
+	}
+}
 
-			logger.Info("Executing cron action",
+// eventTick runs one tick of an event-scoped schedule and records the
+// result. It returns false when the schedule should stop.
+func (c *CronEngine) eventTick(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	input MatchInput,
+	entry *opsv1alpha1.ScheduledActionStatus,
+	tick time.Time,
+	untilNext time.Duration,
+) bool {
+	logger := log.FromContext(ctx)
+
+	entry.LastRunTime = ptrTo(metav1.NewTime(tick))
+	entry.NextRunTime = ptrTo(metav1.NewTime(tick.Add(untilNext)))
+	entry.LastRunError = ""
+
+	// Verify the ResourceAction still exists.
+	current := ra
+	if input.Event != EventDelete {
+		exists := &opsv1alpha1.ResourceAction{}
+		err := c.client.Get(ctx, client.ObjectKey{
+			Name:      ra.Name,
+			Namespace: ra.Namespace,
+		}, exists)
+		if err != nil {
+			logger.Info("Stopping cron, ResourceAction gone",
+				"resourceAction", ra.Name)
+			return false
+		}
+		// Suspended ResourceActions keep their cron registered
+		// but skip ticks until they are resumed.
+		if exists.Spec.Suspend {
+			logger.Info("Skipping cron action, ResourceAction suspended",
 				"resourceAction", ra.Name,
 				"name", input.Obj.GetName(),
 			)
+			entry.LastRunResult = scheduledResultSkipped
+			c.updateScheduledActionStatus(ctx, ra, *entry)
+			return true
+		}
+		current = *exists
+	}
+
+	logger.Info("Executing cron action",
+		"resourceAction", ra.Name,
+		"actionIndex", actionIndex,
+		"name", input.Obj.GetName(),
+	)
 
-			_ = c.executor.Execute(context.Background(), input)
+	release, ok := c.acquireSlot(ctx)
+	if !ok {
+		return false
+	}
+	tickCtx, cancel := context.WithTimeout(ctx, executionDeadline(current, actionIndex))
+	input.ScheduledAt = tick
+	var execErr error
+	if scheduled, ok := c.executor.(ScheduledExecutor); ok && actionIndex < len(current.Spec.Actions) {
+		execErr = scheduled.ExecuteScheduled(tickCtx, current, actionIndex, input)
+	} else {
+		execErr = c.executor.Execute(tickCtx, input)
+	}
+	cancel()
+	release()
+	if execErr != nil {
+		logger.Error(execErr, "cron action failed",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+		)
+		entry.LastRunResult = scheduledResultFailed
+		entry.LastRunError = execErr.Error()
+	} else {
+		entry.LastRunResult = scheduledResultSucceeded
+	}
+	c.updateScheduledActionStatus(ctx, ra, *entry)
+	return true
+}
+
+// executionDeadline returns the runtime budget of a single tick for the
+// action: executionDeadline when set, otherwise the schedule interval.
+func executionDeadline(ra opsv1alpha1.ResourceAction, actionIndex int) time.Duration {
+	if actionIndex < 0 || actionIndex >= len(ra.Spec.Actions) {
+		return time.Minute
+	}
+	action := ra.Spec.Actions[actionIndex]
+	interval := parseDurationDefault(action.Schedule, time.Minute)
+	return parseDurationDefault(action.ExecutionDeadline, interval)
+}
+
+// previousScheduledStatus returns the status entry recorded for the same
+// schedule before this registration, for example by a previous operator
+// process.
+func (c *CronEngine) previousScheduledStatus(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	entry opsv1alpha1.ScheduledActionStatus,
+) *opsv1alpha1.ScheduledActionStatus {
+	var latest opsv1alpha1.ResourceAction
+	if err := c.client.Get(ctx, client.ObjectKey{Name: ra.Name, Namespace: ra.Namespace}, &latest); err != nil {
+		return nil
+	}
+	for i := range latest.Status.ScheduledActions {
+		existing := latest.Status.ScheduledActions[i]
+		if sameScheduledEntry(existing, entry) {
+			return existing.DeepCopy()
 		}
 	}
+	return nil
+}
+
+func carryOverScheduledStatus(entry, previous *opsv1alpha1.ScheduledActionStatus) {
+	if previous == nil {
+		return
+	}
+	entry.LastRunTime = previous.LastRunTime
+	entry.LastRunResult = previous.LastRunResult
+	entry.LastRunError = previous.LastRunError
+	entry.MatchedResources = previous.MatchedResources
+}
+
+// missedRunDue reports whether a run that was due while no schedule was
+// registered (for example during operator downtime) should be caught up.
+func missedRunDue(previous *opsv1alpha1.ScheduledActionStatus, action opsv1alpha1.ActionSpec, now time.Time) bool {
+	if action.MissedRunPolicy != "RunOnce" {
+		return false
+	}
+	if previous == nil || previous.NextRunTime == nil {
+		return false
+	}
+	missedAt := previous.NextRunTime.Time
+	if !missedAt.Before(now) {
+		return false
+	}
+	if action.StartingDeadline != "" {
+		deadline, err := time.ParseDuration(action.StartingDeadline)
+		if err == nil && now.Sub(missedAt) > deadline {
+			return false
+		}
+	}
+	return true
+}
+
+func sameScheduledEntry(a, b opsv1alpha1.ScheduledActionStatus) bool {
+	return a.ActionIndex == b.ActionIndex &&
+		a.ResourceUID == b.ResourceUID &&
+		a.Event == b.Event
+}
+
+// updateScheduledActionStatus upserts the status entry for a single cron
+// registration, keyed by action index, resource UID and event.
+func (c *CronEngine) updateScheduledActionStatus(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	entry opsv1alpha1.ScheduledActionStatus,
+) {
+	logger := log.FromContext(ctx)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := c.client.Get(ctx, client.ObjectKey{Name: ra.Name, Namespace: ra.Namespace}, &latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		var previous *opsv1alpha1.ScheduledActionStatus
+		for i := range latest.Status.ScheduledActions {
+			if sameScheduledEntry(latest.Status.ScheduledActions[i], entry) {
+				previous = &latest.Status.ScheduledActions[i]
+				break
+			}
+		}
+
+		// Only a new run changes the action state, not a re-registration that
+		// carries over the previous result.
+		if entry.LastRunTime != nil && (previous == nil || !entry.LastRunTime.Equal(previous.LastRunTime)) {
+			switch entry.LastRunResult {
+			case scheduledResultSucceeded:
+				setActionState(&latest, entry.ActionIndex, nil, *entry.LastRunTime)
+			case scheduledResultFailed:
+				setActionState(&latest, entry.ActionIndex, errors.New(entry.LastRunError), *entry.LastRunTime)
+			}
+			SetDegradedCondition(&latest, time.Now())
+		}
+
+		if previous != nil {
+			*previous = *entry.DeepCopy()
+		} else {
+			latest.Status.ScheduledActions = append(latest.Status.ScheduledActions, *entry.DeepCopy())
+		}
+		return c.client.Status().Update(ctx, &latest)
+	})
+	if err != nil {
+		logger.Error(err, "failed to update scheduled action status", "resourceAction", ra.Name)
+	}
 }
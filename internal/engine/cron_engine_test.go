@@ -0,0 +1,404 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type recordingScheduledExecutor struct {
+	calls chan int
+	names []string
+}
+
+func (r *recordingScheduledExecutor) Execute(_ context.Context, _ MatchInput) error {
+	return nil
+}
+
+func (r *recordingScheduledExecutor) ExecuteScheduled(
+	_ context.Context,
+	_ opsv1alpha1.ResourceAction,
+	actionIndex int,
+	input MatchInput,
+) error {
+	r.names = append(r.names, input.Obj.GetName())
+	select {
+	case r.calls <- actionIndex:
+	default:
+	}
+	return nil
+}
+
+func stopCronJobs(c *CronEngine) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, job := range c.jobs {
+		job.cancel()
+		delete(c.jobs, key)
+	}
+}
+
+func TestCronEngine_RecordsScheduledActionStatus(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-cron-status",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:     "http",
+					Mode:     "cron",
+					Schedule: "20ms",
+					URL:      "http://example.invalid",
+				},
+			},
+		},
+	}
+
+	_, cl := newTestExecutor(t, ra)
+	exec := &recordingScheduledExecutor{calls: make(chan int, 1)}
+	cron := NewCronEngine(cl, exec)
+	defer stopCronJobs(cron)
+
+	input := newDeploymentInput("uid-cron-1", "demo-cron", "default")
+	if err := cron.EnsureForMatch(context.Background(), input); err != nil {
+		t.Fatalf("ensure for match: %v", err)
+	}
+
+	select {
+	case idx := <-exec.calls:
+		if idx != 0 {
+			t.Fatalf("expected action index 0, got %d", idx)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("cron action was not executed")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var got opsv1alpha1.ResourceAction
+		if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+			t.Fatalf("get resourceaction: %v", err)
+		}
+		if len(got.Status.ScheduledActions) == 1 && got.Status.ScheduledActions[0].LastRunResult == scheduledResultSucceeded {
+			entry := got.Status.ScheduledActions[0]
+			if entry.ResourceUID != "uid-cron-1" || entry.ResourceName != "demo-cron" {
+				t.Fatalf("unexpected target resource: %+v", entry)
+			}
+			if entry.LastRunTime == nil || entry.NextRunTime == nil {
+				t.Fatalf("expected lastRunTime and nextRunTime to be set: %+v", entry)
+			}
+			if entry.NextRunTime.Before(entry.LastRunTime) {
+				t.Fatalf("expected nextRunTime not before lastRunTime: %+v", entry)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected scheduled action status, got %+v", got.Status.ScheduledActions)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type staticLister struct {
+	objects []*unstructured.Unstructured
+}
+
+func (s staticLister) ListCached(_ types.NamespacedName) ([]*unstructured.Unstructured, error) {
+	return s.objects, nil
+}
+
+func TestCronEngine_StandaloneTickRunsAgainstMatchingObjects(t *testing.T) {
+	ra := opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-standalone",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Filters: &opsv1alpha1.FilterSpec{
+				NameRegex: "^web-",
+			},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:          "http",
+					Mode:          "cron",
+					Schedule:      "1m",
+					ScheduleScope: "all",
+					URL:           "http://example.invalid",
+				},
+			},
+		},
+	}
+
+	_, cl := newTestExecutor(t, &ra)
+	exec := &recordingScheduledExecutor{calls: make(chan int, 1)}
+	cron := NewCronEngine(cl, exec)
+	cron.lister = staticLister{objects: []*unstructured.Unstructured{
+		newDeploymentInput("uid-a", "web-a", "default").Obj,
+		newDeploymentInput("uid-b", "worker-b", "default").Obj,
+		newDeploymentInput("uid-c", "web-c", "default").Obj,
+	}}
+
+	matched, err := cron.runStandaloneTick(context.Background(), ra, 0, time.Now())
+	if err != nil {
+		t.Fatalf("standalone tick: %v", err)
+	}
+	if matched != 2 {
+		t.Fatalf("expected 2 matched objects, got %d", matched)
+	}
+	if len(exec.names) != 2 || exec.names[0] != "web-a" || exec.names[1] != "web-c" {
+		t.Fatalf("unexpected executed objects: %v", exec.names)
+	}
+}
+
+func TestCronEngine_EnsureForMatchSkipsStandaloneSchedules(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-standalone-skip",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:          "http",
+					Mode:          "cron",
+					Schedule:      "1m",
+					ScheduleScope: "all",
+					URL:           "http://example.invalid",
+				},
+			},
+		},
+	}
+
+	_, cl := newTestExecutor(t, ra)
+	cron := NewCronEngine(cl, &recordingScheduledExecutor{calls: make(chan int, 1)})
+	defer stopCronJobs(cron)
+
+	if err := cron.EnsureForMatch(context.Background(), newDeploymentInput("uid-d", "web-d", "default")); err != nil {
+		t.Fatalf("ensure for match: %v", err)
+	}
+
+	cron.mu.Lock()
+	defer cron.mu.Unlock()
+	if len(cron.jobs) != 0 {
+		t.Fatalf("expected no event-scoped cron registrations, got %d", len(cron.jobs))
+	}
+}
+
+func TestCronEngine_DefersStandaloneSchedulesUntilStart(t *testing.T) {
+	ra := opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-standalone-deferred",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:          "http",
+					Mode:          "cron",
+					Schedule:      "1h",
+					ScheduleScope: "all",
+					URL:           "http://example.invalid",
+				},
+			},
+		},
+	}
+
+	_, cl := newTestExecutor(t, &ra)
+	cron := NewCronEngine(cl, &recordingScheduledExecutor{calls: make(chan int, 1)})
+	defer stopCronJobs(cron)
+
+	if err := cron.EnsureStandalone(context.Background(), ra); err != nil {
+		t.Fatalf("ensure standalone: %v", err)
+	}
+	cron.mu.Lock()
+	registered := len(cron.jobs)
+	cron.mu.Unlock()
+	if registered != 0 {
+		t.Fatalf("expected no schedules before Start, got %d", registered)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cron.Start(ctx)
+
+	cron.mu.Lock()
+	defer cron.mu.Unlock()
+	if len(cron.jobs) != 1 {
+		t.Fatalf("expected the deferred schedule to start, got %d", len(cron.jobs))
+	}
+}
+
+func TestCronEngine_ResyncRestartsEventSchedulesWithNewSpec(t *testing.T) {
+	cronAction := func(schedule string) opsv1alpha1.ActionSpec {
+		return opsv1alpha1.ActionSpec{Type: "http", Mode: "cron", Schedule: schedule, URL: "http://example.invalid"}
+	}
+	ra := opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-resync", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions:  []opsv1alpha1.ActionSpec{cronAction("1h")},
+		},
+	}
+
+	_, cl := newTestExecutor(t, &ra)
+	cron := NewCronEngine(cl, &recordingScheduledExecutor{calls: make(chan int, 1)})
+	defer stopCronJobs(cron)
+
+	if err := cron.EnsureForMatch(context.Background(), newDeploymentInput("uid-r", "web-r", "default")); err != nil {
+		t.Fatalf("ensure for match: %v", err)
+	}
+	jobCount := func() int {
+		cron.mu.Lock()
+		defer cron.mu.Unlock()
+		return len(cron.jobs)
+	}
+	if got := jobCount(); got != 1 {
+		t.Fatalf("expected 1 schedule, got %d", got)
+	}
+
+	changed := *ra.DeepCopy()
+	changed.Spec.Actions = append(changed.Spec.Actions, cronAction("2h"))
+	if err := cron.Resync(context.Background(), changed); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if got := jobCount(); got != 2 {
+		t.Fatalf("expected 2 schedules after adding a cron action, got %d", got)
+	}
+
+	changed.Spec.Actions = []opsv1alpha1.ActionSpec{{Type: "http", URL: "http://example.invalid"}}
+	if err := cron.Resync(context.Background(), changed); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if got := jobCount(); got != 0 {
+		t.Fatalf("expected no schedules after removing the cron actions, got %d", got)
+	}
+}
+
+func TestScheduleJitter_DeterministicWithinPeriod(t *testing.T) {
+	period := time.Minute
+	first := scheduleJitter("default/ra/0/uid-1/Create", period)
+	second := scheduleJitter("default/ra/0/uid-1/Create", period)
+	if first != second {
+		t.Fatalf("expected deterministic jitter, got %s and %s", first, second)
+	}
+	if first < 0 || first >= period {
+		t.Fatalf("expected jitter within [0, %s), got %s", period, first)
+	}
+	if other := scheduleJitter("default/ra/0/uid-2/Create", period); other == first {
+		t.Fatalf("expected different seeds to spread, both got %s", first)
+	}
+	if got := scheduleJitter("seed", 0); got != 0 {
+		t.Fatalf("expected zero jitter for zero period, got %s", got)
+	}
+}
+
+func TestCronEngine_AcquireSlotRespectsLimit(t *testing.T) {
+	cron := NewCronEngine(nil, nil)
+	cron.SetMaxConcurrency(1)
+
+	release, ok := cron.acquireSlot(context.Background())
+	if !ok {
+		t.Fatalf("expected first slot to be acquired")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, ok := cron.acquireSlot(ctx); ok {
+		t.Fatalf("expected second slot to block until context timeout")
+	}
+
+	release()
+	if _, ok := cron.acquireSlot(context.Background()); !ok {
+		t.Fatalf("expected slot to be available after release")
+	}
+}
+
+func TestMissedRunDue(t *testing.T) {
+	now := time.Now()
+	missed := &opsv1alpha1.ScheduledActionStatus{
+		NextRunTime: ptrTo(metav1.NewTime(now.Add(-5 * time.Minute))),
+	}
+	upcoming := &opsv1alpha1.ScheduledActionStatus{
+		NextRunTime: ptrTo(metav1.NewTime(now.Add(time.Minute))),
+	}
+
+	tests := []struct {
+		name     string
+		previous *opsv1alpha1.ScheduledActionStatus
+		action   opsv1alpha1.ActionSpec
+		want     bool
+	}{
+		{name: "skip policy", previous: missed, action: opsv1alpha1.ActionSpec{MissedRunPolicy: "Skip"}, want: false},
+		{name: "default policy", previous: missed, action: opsv1alpha1.ActionSpec{}, want: false},
+		{name: "run once", previous: missed, action: opsv1alpha1.ActionSpec{MissedRunPolicy: "RunOnce"}, want: true},
+		{name: "no previous run", previous: nil, action: opsv1alpha1.ActionSpec{MissedRunPolicy: "RunOnce"}, want: false},
+		{name: "not yet due", previous: upcoming, action: opsv1alpha1.ActionSpec{MissedRunPolicy: "RunOnce"}, want: false},
+		{
+			name:     "within starting deadline",
+			previous: missed,
+			action:   opsv1alpha1.ActionSpec{MissedRunPolicy: "RunOnce", StartingDeadline: "10m"},
+			want:     true,
+		},
+		{
+			name:     "past starting deadline",
+			previous: missed,
+			action:   opsv1alpha1.ActionSpec{MissedRunPolicy: "RunOnce", StartingDeadline: "1m"},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := missedRunDue(tt.previous, tt.action, now); got != tt.want {
+			t.Fatalf("%s: missedRunDue() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExecutionDeadline(t *testing.T) {
+	ra := opsv1alpha1.ResourceAction{
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Actions: []opsv1alpha1.ActionSpec{
+				{Mode: "cron", Schedule: "5m"},
+				{Mode: "cron", Schedule: "5m", ExecutionDeadline: "30s"},
+			},
+		},
+	}
+
+	if got := executionDeadline(ra, 0); got != 5*time.Minute {
+		t.Fatalf("expected schedule interval as default deadline, got %s", got)
+	}
+	if got := executionDeadline(ra, 1); got != 30*time.Second {
+		t.Fatalf("expected explicit execution deadline, got %s", got)
+	}
+}
@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	deadLetterLabel = "resource-action-operator.yusaozdemir.de/dead-letter"
+
+	// maxDeadLetterEntries bounds the number of entries kept in a dead-letter
+	// ConfigMap so it stays below the object size limit.
+	maxDeadLetterEntries = 100
+	deadLetterTimeout    = 10 * time.Second
+)
+
+// DeadLetter is the payload published for a permanently failed action.
+type DeadLetter struct {
+	ResourceAction string             `json:"resourceAction"`
+	Namespace      string             `json:"namespace"`
+	ActionIndex    int                `json:"actionIndex"`
+	ActionName     string             `json:"actionName,omitempty"`
+	ActionType     string             `json:"actionType"`
+	Event          string             `json:"event"`
+	CorrelationID  string             `json:"correlationID,omitempty"`
+	Error          string             `json:"error"`
+	FailedAt       metav1.Time        `json:"failedAt"`
+	Resource       DeadLetterResource `json:"resource"`
+}
+
+// DeadLetterResource identifies the resource that triggered the execution.
+// The object itself is not included, so dead letters stay small and do not
+// copy Secret data to the destination.
+type DeadLetterResource struct {
+	APIVersion      string `json:"apiVersion"`
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	UID             string `json:"uid"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// publishDeadLetter sends a failed action to its dead-letter destination.
+// Errors are logged; they never change the outcome of the action.
+func (e *K8sExecutor) publishDeadLetter(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	input MatchInput,
+	execErr error,
+) {
+	action := ra.Spec.Actions[actionIndex]
+	if action.DeadLetter == nil {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	letter := DeadLetter{
+		ResourceAction: ra.Name,
+		Namespace:      ra.Namespace,
+		ActionIndex:    actionIndex,
+		ActionName:     action.Name,
+		ActionType:     action.Type,
+		Event:          string(input.Event),
+		CorrelationID:  correlationIDFrom(ctx),
+		Error:          execErr.Error(),
+		FailedAt:       metav1.Now(),
+		Resource: DeadLetterResource{
+			APIVersion:      input.GVK.GroupVersion().String(),
+			Kind:            input.GVK.Kind,
+			Name:            input.Obj.GetName(),
+			Namespace:       input.Obj.GetNamespace(),
+			UID:             string(input.Obj.GetUID()),
+			ResourceVersion: input.Obj.GetResourceVersion(),
+		},
+	}
+
+	var err error
+	switch action.DeadLetter.Type {
+	case "HTTP":
+		err = e.httpExecutor().forAction(&ra, actionIndex).postDeadLetter(ctx, ra.Namespace, action, letter)
+	case "ConfigMap":
+		err = e.appendDeadLetterConfigMap(ctx, ra, action.DeadLetter.ConfigMapName, letter)
+	case "ActionExecution":
+		err = e.createDeadLetterExecution(ctx, ra, input, letter)
+	default:
+		err = fmt.Errorf("unsupported dead-letter type %q", action.DeadLetter.Type)
+	}
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+		logger.Error(err, "failed to publish dead letter",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+			"type", action.DeadLetter.Type,
+		)
+	} else {
+		logger.Info("Published dead letter",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+			"type", action.DeadLetter.Type,
+		)
+	}
+	observeDeadLetter(action.DeadLetter.Type, result)
+}
+
+// postDeadLetter sends letter to the dead-letter URL of action with the TLS
+// and proxy settings of the action. The urlPolicy of the dead letter applies
+// to the URL and to redirects.
+func (h *HTTPExecutor) postDeadLetter(
+	ctx context.Context,
+	raNamespace string,
+	action opsv1alpha1.ActionSpec,
+	letter DeadLetter,
+) error {
+	spec := action.DeadLetter
+	if err := validateTargetURL(h.compiled, spec.URL, spec.URLPolicy, h.urlPolicy); err != nil {
+		return err
+	}
+	if err := validateProxyURL(h.compiled, action.Proxy, action.URLPolicy, h.urlPolicy); err != nil {
+		return err
+	}
+	transport, err := h.buildTransport(ctx, raNamespace, action.TLS, action.Proxy)
+	if err != nil {
+		return err
+	}
+	redirects := action
+	redirects.URLPolicy = spec.URLPolicy
+	httpClient := &http.Client{
+		Transport:     transport,
+		CheckRedirect: h.checkRedirect(redirects, nil),
+	}
+	payload, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, deadLetterTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, spec.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if letter.CorrelationID != "" {
+		req.Header.Set(correlationIDHeader, letter.CorrelationID)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("dead-letter endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// appendDeadLetterConfigMap stores the letter as a JSON entry keyed by time,
+// dropping the oldest entries beyond maxDeadLetterEntries.
+func (e *K8sExecutor) appendDeadLetterConfigMap(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	name string,
+	letter DeadLetter,
+) error {
+	payload, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%019d-a%d.json", letter.FailedAt.UnixNano(), letter.ActionIndex)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ra.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, e.Client, cm, func() error {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[key] = string(payload)
+
+		if len(cm.Data) > maxDeadLetterEntries {
+			keys := make([]string, 0, len(cm.Data))
+			for k := range cm.Data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys[:len(keys)-maxDeadLetterEntries] {
+				delete(cm.Data, k)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (e *K8sExecutor) createDeadLetterExecution(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	input MatchInput,
+	letter DeadLetter,
+) error {
+	record := opsv1alpha1.ExecutionRecord{
+		ResourceUID:   letter.Resource.UID,
+		Event:         letter.Event,
+		ExecutedAt:    letter.FailedAt,
+		CorrelationID: letter.CorrelationID,
+		ActionCount:   1,
+	}
+	fillExecutionRecord(&record, input, letter.ActionIndex, errors.New(letter.Error))
+	record.ActionName = letter.ActionName
+
+	ae, err := newActionExecution(e.Client, &ra, record)
+	if err != nil {
+		return err
+	}
+	ae.Labels[deadLetterLabel] = "true"
+	if err := setFinalState(ae, finalState(input)); err != nil {
+		return err
+	}
+	return e.Client.Create(ctx, ae)
+}
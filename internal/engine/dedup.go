@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	executionPolicyOncePerObject     = "OncePerObject"
+	executionPolicyOncePerGeneration = "OncePerGeneration"
+	executionPolicyEveryEvent        = "EveryEvent"
+
+	dedupConfigMapSuffix = "-dedup"
+
+	// maxDedupEntries is the size of the dedup store above which the
+	// entries of objects that no longer exist are pruned.
+	maxDedupEntries = 5000
+	// dedupPruneInterval bounds how often the objects of a ResourceAction
+	// are listed to prune its dedup store.
+	dedupPruneInterval = 10 * time.Minute
+)
+
+// The dedup store is a ConfigMap owned by the ResourceAction. Each key is
+// "<uid>.<event>" and holds the metadata.generation of the object when the
+// actions last ran for it. The entries of an object are removed once the
+// object is deleted. Entries of objects whose Delete event was missed, for
+// example while the operator was down, are pruned once the store holds more
+// than maxDedupEntries. The manager does not cache ConfigMaps, so back-to-back
+// events of an object read the entry the previous one just wrote.
+
+func dedupConfigMapName(ra *opsv1alpha1.ResourceAction) string {
+	return ra.Name + dedupConfigMapSuffix
+}
+
+func dedupKey(uid types.UID, event EventType) string {
+	return string(uid) + "." + strings.ToLower(string(event))
+}
+
+// executionPolicy returns spec.executionPolicy of ra. The API server defaults
+// it on create; objects stored before the field existed get the default of
+// v1alpha1.
+func executionPolicy(ra *opsv1alpha1.ResourceAction) string {
+	if ra.Spec.ExecutionPolicy == "" {
+		return executionPolicyOncePerObject
+	}
+	return ra.Spec.ExecutionPolicy
+}
+
+// executionDue reports whether the actions of ra should run for input
+// according to spec.executionPolicy. Retriggered events are always due, as
+// are Webhook events, which each carry their own payload.
+func (e *K8sExecutor) executionDue(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	input MatchInput,
+) (bool, error) {
+	policy := executionPolicy(ra)
+	if policy == executionPolicyEveryEvent || input.retrigger || input.Event == EventWebhook {
+		return true, nil
+	}
+
+	var cm corev1.ConfigMap
+	err := e.Client.Get(ctx, client.ObjectKey{Name: dedupConfigMapName(ra), Namespace: ra.Namespace}, &cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	recorded, ok := cm.Data[dedupKey(input.Obj.GetUID(), input.Event)]
+
+	if policy == executionPolicyOncePerGeneration {
+		if !ok {
+			return true, nil
+		}
+		generation, err := strconv.ParseInt(recorded, 10, 64)
+		return err != nil || input.Obj.GetGeneration() > generation, nil
+	}
+
+	if ok {
+		return false, nil
+	}
+	// Records written before the dedup store existed.
+	executed, err := executedBefore(ctx, e.Client, ra, input.Obj.GetUID(), string(input.Event))
+	return !executed, err
+}
+
+// recordExecution stores that the actions of ra ran for input.
+func (e *K8sExecutor) recordExecution(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	input MatchInput,
+) error {
+	// Entries of deleted objects are removed by forgetObject right away.
+	dedup := executionPolicy(ra) != executionPolicyEveryEvent && input.Event != EventDelete && input.Event != EventWebhook
+	fingerprint := fingerprints(ra, input)
+	if !dedup && !fingerprint {
+		return nil
+	}
+
+	// Workers record the executions of different objects concurrently; a
+	// lost entry would run once-actions again.
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dedupConfigMapName(ra),
+				Namespace: ra.Namespace,
+			},
+		}
+		_, err := controllerutil.CreateOrUpdate(ctx, e.Client, cm, func() error {
+			if cm.Labels == nil {
+				cm.Labels = map[string]string{}
+			}
+			cm.Labels[labelResourceActionName] = resourceActionLabel(ra.Name)
+			if cm.Data == nil {
+				cm.Data = map[string]string{}
+			}
+			if dedup {
+				cm.Data[dedupKey(input.Obj.GetUID(), input.Event)] = strconv.FormatInt(input.Obj.GetGeneration(), 10)
+				if len(cm.Data) > maxDedupEntries {
+					e.pruneDedup(ctx, ra, cm.Data)
+				}
+			}
+			if fingerprint {
+				recordName(cm.Data, input, nameReuseWindow(ra), time.Now())
+			}
+			return controllerutil.SetOwnerReference(ra, cm, e.Client.Scheme())
+		})
+		return err
+	})
+}
+
+// dedupPrunes tracks when the dedup stores were last pruned.
+type dedupPrunes struct {
+	mu   sync.Mutex
+	last map[types.UID]time.Time
+}
+
+// due reports whether the dedup store of the ResourceAction with uid may be
+// pruned at now, and records the prune if so.
+func (p *dedupPrunes) due(uid types.UID, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if last, ok := p.last[uid]; ok && now.Sub(last) < dedupPruneInterval {
+		return false
+	}
+	if p.last == nil {
+		p.last = make(map[types.UID]time.Time)
+	}
+	p.last[uid] = now
+	return true
+}
+
+// pruneDedup removes the entries of objects that no longer exist from data,
+// the dedup store of ra. The selected objects are listed from the API server,
+// in pages, at most once per dedupPruneInterval. When they cannot be listed,
+// nothing is pruned.
+func (e *K8sExecutor) pruneDedup(ctx context.Context, ra *opsv1alpha1.ResourceAction, data map[string]string) {
+	if !e.dedupPrunes.due(ra.UID, time.Now()) {
+		return
+	}
+	logger := log.FromContext(ctx)
+	target := e.Client
+	cluster, err := e.clusters.clusterFor(ctx, ra)
+	if err != nil {
+		logger.Error(err, "failed to prune dedup store", "resourceAction", ra.Name)
+		return
+	}
+	if cluster != nil {
+		target = cluster.client
+	}
+	namespaces := normalizeNamespaces(ra.Spec.WatchNamespaces)
+	if e.confinement.confines(ra) {
+		namespaces = []string{ra.Namespace}
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	live := map[string]bool{}
+	for _, ns := range namespaces {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   ra.Spec.Selector.Group,
+			Version: ra.Spec.Selector.Version,
+			Kind:    ra.Spec.Selector.Kind + "List",
+		})
+		for {
+			// Unstructured lists are read from the API server, not the
+			// cache of the manager.
+			if err := target.List(ctx, &list, client.InNamespace(ns), client.Limit(500), client.Continue(list.GetContinue())); err != nil {
+				logger.Error(err, "failed to list objects to prune the dedup store", "resourceAction", ra.Name)
+				return
+			}
+			for _, item := range list.Items {
+				live[string(item.GetUID())] = true
+			}
+			if list.GetContinue() == "" {
+				break
+			}
+		}
+	}
+	for key := range data {
+		if strings.HasPrefix(key, nameKeyPrefix) {
+			continue
+		}
+		if uid, _, _ := strings.Cut(key, "."); !live[uid] {
+			delete(data, key)
+		}
+	}
+}
+
+// forgetObject removes all dedup entries of a deleted object. Name
+// fingerprints are kept.
+func (e *K8sExecutor) forgetObject(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	uid types.UID,
+) error {
+	prefix := string(uid) + "."
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cm corev1.ConfigMap
+		if err := e.Client.Get(ctx, client.ObjectKey{Name: dedupConfigMapName(ra), Namespace: ra.Namespace}, &cm); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		changed := false
+		for key := range cm.Data {
+			if strings.HasPrefix(key, prefix) {
+				delete(cm.Data, key)
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		return e.Client.Update(ctx, &cm)
+	})
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	conditionDegraded = "Degraded"
+
+	reasonConsecutiveFailures = "ConsecutiveFailures"
+	reasonNoRecentSuccess     = "NoRecentSuccess"
+)
+
+// SetDegradedCondition evaluates spec.degraded against status.actionStates at
+// now and sets the Degraded condition of ra. It returns how long until a
+// failing action becomes stale, or 0 when none will, so callers can evaluate
+// the condition again in time. Without spec.degraded the condition is
+// removed.
+func SetDegradedCondition(ra *opsv1alpha1.ResourceAction, now time.Time) time.Duration {
+	policy := ra.Spec.Degraded
+	if policy == nil {
+		meta.RemoveStatusCondition(&ra.Status.Conditions, conditionDegraded)
+		return 0
+	}
+	staleAfter, _ := time.ParseDuration(policy.StaleAfter)
+
+	var reason string
+	var messages []string
+	var recheck time.Duration
+	for _, state := range ra.Status.ActionStates {
+		if state.State != actionStateFailing {
+			continue
+		}
+		action := fmt.Sprintf("action %d", state.ActionIndex)
+		if state.Name != "" {
+			action = fmt.Sprintf("action %q", state.Name)
+		}
+		if policy.FailureThreshold != nil && state.ConsecutiveFailures >= int(*policy.FailureThreshold) {
+			reason = reasonConsecutiveFailures
+			messages = append(messages, fmt.Sprintf("%s failed %d times in a row", action, state.ConsecutiveFailures))
+			continue
+		}
+		if staleAfter <= 0 {
+			continue
+		}
+		since := state.LastSuccessfulTime
+		if since == nil {
+			since = state.LastTransitionTime
+		}
+		if since == nil {
+			continue
+		}
+		if left := since.Add(staleAfter).Sub(now); left > 0 {
+			if recheck == 0 || left < recheck {
+				recheck = left
+			}
+			continue
+		}
+		if reason == "" {
+			reason = reasonNoRecentSuccess
+		}
+		if state.LastSuccessfulTime != nil {
+			messages = append(messages, fmt.Sprintf("%s has not succeeded since %s",
+				action, state.LastSuccessfulTime.UTC().Format(time.RFC3339)))
+		} else {
+			messages = append(messages, fmt.Sprintf("%s has not succeeded since it started failing at %s",
+				action, since.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	if len(messages) == 0 {
+		setCondition(ra, metav1.Condition{
+			Type:    conditionDegraded,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ActionsHealthy",
+			Message: "No action exceeds the degraded thresholds",
+		})
+		return recheck
+	}
+	setCondition(ra, metav1.Condition{
+		Type:    conditionDegraded,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: strings.Join(messages, "; "),
+	})
+	return recheck
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetDegradedCondition(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) *metav1.Time {
+		ts := metav1.NewTime(now.Add(-ago))
+		return &ts
+	}
+	threshold := int32(3)
+	ra := &opsv1alpha1.ResourceAction{
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Actions:  []opsv1alpha1.ActionSpec{{Name: "notify"}, {}},
+			Degraded: &opsv1alpha1.DegradedSpec{StaleAfter: "6h", FailureThreshold: &threshold},
+		},
+		Status: opsv1alpha1.ResourceActionStatus{ActionStates: []opsv1alpha1.ActionState{
+			{ActionIndex: 0, Name: "notify", State: actionStateFailing, ConsecutiveFailures: 2, LastSuccessfulTime: at(5 * time.Hour)},
+			{ActionIndex: 1, State: actionStateReady, LastSuccessfulTime: at(48 * time.Hour)},
+		}},
+	}
+
+	if recheck := SetDegradedCondition(ra, now); recheck != time.Hour {
+		t.Fatalf("recheck = %v, want the time until the failing action is stale", recheck)
+	}
+	if cond := meta.FindStatusCondition(ra.Status.Conditions, conditionDegraded); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Fatalf("condition = %+v, want False", cond)
+	}
+
+	if recheck := SetDegradedCondition(ra, now.Add(time.Hour)); recheck != 0 {
+		t.Fatalf("recheck = %v, want 0 once stale", recheck)
+	}
+	cond := meta.FindStatusCondition(ra.Status.Conditions, conditionDegraded)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != reasonNoRecentSuccess ||
+		!strings.Contains(cond.Message, `action "notify" has not succeeded since 2026-05-04T07:00:00Z`) {
+		t.Fatalf("condition = %+v, want stale", cond)
+	}
+
+	ra.Status.ActionStates[1] = opsv1alpha1.ActionState{
+		ActionIndex: 1, State: actionStateFailing, ConsecutiveFailures: 3, LastTransitionTime: at(time.Minute),
+	}
+	SetDegradedCondition(ra, now)
+	cond = meta.FindStatusCondition(ra.Status.Conditions, conditionDegraded)
+	if cond.Reason != reasonConsecutiveFailures || !strings.Contains(cond.Message, "action 1 failed 3 times in a row") {
+		t.Fatalf("condition = %+v, want the failure threshold", cond)
+	}
+
+	ra.Spec.Degraded = nil
+	SetDegradedCondition(ra, now)
+	if cond := meta.FindStatusCondition(ra.Status.Conditions, conditionDegraded); cond != nil {
+		t.Fatalf("condition = %+v, want it removed without spec.degraded", cond)
+	}
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// maxRenderedBody caps the body of a rendered request in the status.
+const maxRenderedBody = 4096
+
+// dryRun renders the requests of the event-driven HTTP actions of ra for input
+// without sending them and records them in status.executions. Dry runs are
+// recorded in the status for every history mode and do not touch the
+// deduplication state, the action states or the Ready condition. In record
+// mode the rendered requests are stored as a simulated ActionExecution
+// instead.
+func (e *K8sExecutor) dryRun(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput) error {
+	ctx, correlationID := withCorrelationID(ctx)
+	logger := log.FromContext(ctx)
+
+	requests, rendered, lastIndex, renderErr := e.httpExecutor().renderActions(ra, input, correlationID)
+	if rendered == 0 {
+		return nil
+	}
+
+	record := opsv1alpha1.ExecutionRecord{
+		ResourceUID:      string(input.Obj.GetUID()),
+		Event:            string(input.Event),
+		ExecutedAt:       metav1.Now(),
+		CorrelationID:    correlationID,
+		ActionCount:      rendered,
+		RenderedRequests: requests,
+	}
+	if e.recording(ra) {
+		record.Simulated = true
+	} else {
+		record.DryRun = true
+	}
+	fillExecutionRecord(&record, input, lastIndex, renderErr)
+	record.ActionName = actionName(ra, lastIndex)
+
+	if record.Simulated {
+		logger.Info("Recorded simulated execution",
+			"resourceAction", ra.Name,
+			"event", input.Event,
+			"name", input.Obj.GetName(),
+			"requests", len(requests),
+		)
+		request, err := e.storeSimulation(ctx, ra, record)
+		if err != nil {
+			logger.Error(err, "failed to record simulated execution", "resourceAction", ra.Name)
+			return err
+		}
+		e.exportRecord(ctx, ra, record, request)
+		return nil
+	}
+
+	logger.Info("Rendered requests in dry run",
+		"resourceAction", ra.Name,
+		"event", input.Event,
+		"name", input.Obj.GetName(),
+		"requests", len(requests),
+	)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := e.Client.Get(ctx, client.ObjectKeyFromObject(ra), &latest); err != nil {
+			return err
+		}
+		latest.Status.Executions = append(latest.Status.Executions, record)
+		pruneExecutions(&latest, time.Now())
+		return e.Client.Status().Update(ctx, &latest)
+	})
+	if err != nil {
+		logger.Error(err, "failed to record dry run", "resourceAction", ra.Name)
+		return err
+	}
+	e.exportRecord(ctx, ra, record, nil)
+	return nil
+}
+
+// renderActions renders the requests of the event-driven HTTP actions of ra
+// for input. It returns how many actions were rendered and the index of the
+// last of them; render errors of the actions are joined.
+func (h *HTTPExecutor) renderActions(
+	ra *opsv1alpha1.ResourceAction,
+	input MatchInput,
+	correlationID string,
+) ([]opsv1alpha1.RenderedRequest, int, int, error) {
+	// No action ran, so later actions see placeholders for the outputs.
+	outputs := map[string]string{}
+	h.outputs = outputs
+	h.changes = input.changes
+
+	var requests []opsv1alpha1.RenderedRequest
+	var errs []error
+	rendered := 0
+	lastIndex := -1
+	for i, action := range ra.Spec.Actions {
+		if !renderable(action) {
+			continue
+		}
+		actionRequests, err := h.forAction(ra, i).renderRequests(ra, i, action, input, correlationID)
+		for j := range actionRequests {
+			actionRequests[j].ActionIndex = i
+			actionRequests[j].ActionName = action.Name
+		}
+		requests = append(requests, actionRequests...)
+		rendered++
+		lastIndex = i
+		if err != nil {
+			errs = append(errs, fmt.Errorf("action %s: %w", actionName(ra, i), err))
+		}
+		for name := range action.Outputs {
+			outputs[name] = "<output " + name + ">"
+		}
+	}
+	return requests, rendered, lastIndex, errors.Join(errs...)
+}
+
+// renderable reports whether action is an enabled, event-driven HTTP action,
+// the actions a dry run renders.
+func renderable(action opsv1alpha1.ActionSpec) bool {
+	return action.Mode != "cron" && action.Mode != "schedule" && action.Mode != actionModeHandler &&
+		actionEnabled(action) && action.Type == "http"
+}
+
+// renderRequests renders the requests the action at actionIndex would send
+// for input: one, or one per item of a forEach action. Headers read from
+// Secrets or Vault and the Authorization header are masked instead of
+// resolved, so a dry run neither reads credentials nor requests tokens.
+func (h *HTTPExecutor) renderRequests(
+	ra *opsv1alpha1.ResourceAction,
+	actionIndex int,
+	action opsv1alpha1.ActionSpec,
+	input MatchInput,
+	correlationID string,
+) ([]opsv1alpha1.RenderedRequest, error) {
+	headers := map[string]string{correlationIDHeader: correlationID}
+	for name := range action.Headers {
+		headers[name] = redactedValue
+	}
+	if action.Auth != nil {
+		headers["Authorization"] = redactedValue
+	}
+	addIdempotencyKey(headers, action.IdempotencyKey, ra, actionIndex, input)
+
+	if action.ForEach == nil {
+		request, err := h.renderRequest(action, input.Obj, headers)
+		return []opsv1alpha1.RenderedRequest{request}, err
+	}
+	items, err := forEachItems(h.compiled, action.ForEach, input.Obj, h.templateFuncs())
+	if err != nil {
+		return nil, err
+	}
+	var requests []opsv1alpha1.RenderedRequest
+	for _, item := range items {
+		itemExec := *h
+		itemExec.item = &item
+		url, err := renderTemplate(h.compiled, "forEach.url", action.ForEach.URL, input.Obj.Object, itemExec.templateFuncs())
+		if err != nil {
+			return requests, fmt.Errorf("target %s: %w", item, err)
+		}
+		target := action
+		target.ForEach = nil
+		target.URL = strings.TrimSpace(url)
+		request, err := itemExec.renderRequest(target, input.Obj, forEachHeaders(headers, action.IdempotencyKey, item))
+		requests = append(requests, request)
+		if err != nil {
+			return requests, fmt.Errorf("target %s: %w", item, err)
+		}
+	}
+	return requests, nil
+}
+
+// renderRequest renders the request of action for obj. The URL is checked
+// against the URL policies like before a real request.
+func (h *HTTPExecutor) renderRequest(
+	action opsv1alpha1.ActionSpec,
+	obj *unstructured.Unstructured,
+	headers map[string]string,
+) (opsv1alpha1.RenderedRequest, error) {
+	method := action.Method
+	if method == "" {
+		method = "POST"
+	}
+	request := opsv1alpha1.RenderedRequest{Method: method, URL: action.URL, Headers: maps.Clone(headers)}
+	if err := validateTargetURL(h.compiled, action.URL, action.URLPolicy, h.urlPolicy); err != nil {
+		return request, err
+	}
+	body, contentType, err := h.renderBody(action.Body, obj)
+	if err != nil {
+		return request, err
+	}
+	if len(body) > 0 {
+		request.Headers["Content-Type"] = contentType
+		if action.Body != nil && action.Body.Compression != "" {
+			request.Headers["Content-Encoding"] = action.Body.Compression
+		}
+	}
+	if len(body) > maxRenderedBody {
+		body = body[:maxRenderedBody]
+		request.Truncated = true
+	}
+	request.Body = redactor{}.redact(string(body))
+	return request, nil
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExecute_DryRunRendersWithoutSending(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	secret := opsv1alpha1.ValueFrom{SecretKeyRef: &opsv1alpha1.SecretKeyRef{Name: "webhook", Key: "token"}}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-dry-run", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			DryRun:   true,
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Name:           "notify",
+					Type:           "http",
+					Method:         "PUT",
+					URL:            srv.URL + "/hooks",
+					URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Headers:        map[string]opsv1alpha1.ValueFrom{"X-Token": secret},
+					Auth:           &opsv1alpha1.AuthSpec{Basic: &opsv1alpha1.BasicAuth{Username: secret, Password: secret}},
+					IdempotencyKey: &opsv1alpha1.IdempotencyKeySpec{},
+					Outputs:        map[string]string{"id": "{.id}"},
+					Body:           &opsv1alpha1.TemplateSpec{Template: `{"name":"{{ .metadata.name }}","password":"hunter2"}`},
+				},
+				{
+					Name:      "follow-up",
+					Type:      "http",
+					URL:       srv.URL + "/follow-up",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Body:      &opsv1alpha1.TemplateSpec{Template: `{{ output "id" }}`, ContentType: "text/plain"},
+				},
+				{
+					Type: "job",
+					Job:  &opsv1alpha1.JobSpec{Image: "bash:5.2", Command: []string{"true"}},
+				},
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-dry-run", "demo", "default")
+
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want no request in dry run", calls.Load())
+	}
+
+	got := getResourceAction(t, cl, ra)
+	if len(got.Status.Executions) != 1 || len(got.Status.ActionStates) != 0 {
+		t.Fatalf("status = %+v, want one dry run record without action states", got.Status)
+	}
+	record := got.Status.Executions[0]
+	if !record.DryRun || record.Result != executionResultSucceeded || record.ActionCount != 2 || len(record.RenderedRequests) != 2 {
+		t.Fatalf("record = %+v, want a succeeded dry run of both HTTP actions", record)
+	}
+	request := record.RenderedRequests[0]
+	if request.ActionName != "notify" || request.Method != "PUT" || request.URL != srv.URL+"/hooks" {
+		t.Fatalf("request = %+v, want the PUT of notify", request)
+	}
+	if request.Headers["X-Token"] != redactedValue || request.Headers["Authorization"] != redactedValue ||
+		request.Headers[defaultIdempotencyKeyHeader] == "" || request.Headers[correlationIDHeader] != record.CorrelationID {
+		t.Fatalf("headers = %v, want masked credentials and the generated headers", request.Headers)
+	}
+	if request.Body != `{"name":"demo","password":"[REDACTED]"}` {
+		t.Fatalf("body = %s, want the rendered body with the credential field masked", request.Body)
+	}
+	if body := record.RenderedRequests[1].Body; body != "<output id>" {
+		t.Fatalf("follow-up body = %q, want the output placeholder", body)
+	}
+
+	// The dry run does not count as an execution once it is turned off.
+	var latest opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(ra), &latest); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	latest.Spec.DryRun = false
+	latest.Spec.Actions = latest.Spec.Actions[1:2]
+	latest.Spec.Actions[0].Body = nil
+	if err := cl.Update(context.Background(), &latest); err != nil {
+		t.Fatalf("update resourceaction: %v", err)
+	}
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want the event to run after the dry run", calls.Load())
+	}
+}
+
+func TestExecute_DryRunRecordsRenderErrors(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-dry-run-error", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			DryRun:   true,
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type: "http",
+				URL:  "https://hooks.example.com",
+				Body: &opsv1alpha1.TemplateSpec{Template: `{{ .metadata.name | nosuchfunc }}`},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-dry-run", "demo", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	record := getResourceAction(t, cl, ra).Status.Executions[0]
+	if !record.DryRun || record.Result != executionResultFailed || !strings.Contains(record.Error, "nosuchfunc") {
+		t.Fatalf("record = %+v, want a failed dry run with the template error", record)
+	}
+}
@@ -2,18 +2,33 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
-
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/discovery"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 type EventType string
@@ -22,6 +37,10 @@ const (
 	EventCreate EventType = "Create"
 	EventUpdate EventType = "Update"
 	EventDelete EventType = "Delete"
+	// EventWebhook is a request to the endpoint of a WebhookSource.
+	EventWebhook EventType = "Webhook"
+	// EventTeardown runs spec.teardown before a ResourceAction is deleted.
+	EventTeardown EventType = "Teardown"
 )
 
 type MatchInput struct {
@@ -29,6 +48,59 @@ type MatchInput struct {
 	GVK    schema.GroupVersionKind
 	Obj    *unstructured.Unstructured
 	OldObj *unstructured.Unstructured
+
+	// ScheduledAt is the tick of a cron action, or zero for events.
+	ScheduledAt time.Time
+
+	// changes holds the parts of the object an Update changed, see
+	// changeTypesOf.
+	changes []string
+
+	// trigger is set for handler actions and describes the action that ran
+	// them.
+	trigger *actionTrigger
+
+	// resume is set when a wait action or a pending approval paused the
+	// execution of the event.
+	resume *executionProgress
+
+	// batch holds the events delivered together by spec.aggregation. The
+	// input itself is the latest of them.
+	batch []MatchInput
+
+	// group is the group of aggregation.groupBy the batch was collected
+	// for.
+	group *eventGroup
+
+	// retrigger runs the event even when spec.executionPolicy recorded it as
+	// already executed.
+	retrigger bool
+
+	// queued is set when a suppression window queued the event, which was
+	// counted when it arrived.
+	queued bool
+
+	// pending is set for an event read back from the pending store after a
+	// restart.
+	pending bool
+
+	// selectorKey is set for Delete events of a label-selected informer, which
+	// may only mean that the object lost the labels. The worker confirms the
+	// deletion, so the informer does not block on it.
+	selectorKey *watchKey
+
+	// owners limits delivery to the ResourceActions that own the informer the
+	// event came from. Nil delivers to every ResourceAction.
+	owners map[types.NamespacedName]struct{}
+}
+
+// targets reports whether ra should handle the event.
+func (in MatchInput) targets(ra *opsv1alpha1.ResourceAction) bool {
+	if in.owners == nil {
+		return true
+	}
+	_, ok := in.owners[types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}]
+	return ok
 }
 
 type Executor interface {
@@ -36,33 +108,98 @@ type Executor interface {
 }
 
 type Engine struct {
-	cfg   *rest.Config
-	dyn   dynamic.Interface
-	disco discovery.DiscoveryInterface
+	cfg  *rest.Config
+	dyn  dynamic.Interface
+	meta metadata.Interface
+	// kinds resolves selected kinds of the local cluster.
+	kinds *kindResolver
 
-	factory dynamicinformer.DynamicSharedInformerFactory
-	runCtx  context.Context
+	// runCtx is the context passed to Start. Informers run until it is done.
+	runCtx context.Context
 
-	mu        sync.Mutex
+	mu sync.Mutex
+	// started is set by Start; informers registered before only run then.
 	started   bool
-	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+	informers map[watchKey]cache.SharedIndexInformer
+	// stops cancels a running informer.
+	stops map[watchKey]context.CancelFunc
+	// owners maps each ResourceAction to the informers it needs. An informer
+	// runs as long as at least one owner references it.
+	owners map[types.NamespacedName][]watchKey
+	// watchFailures holds the last list/watch error per informer.
+	watchFailures map[watchKey]watchFailure
+	// watchNamespaces restricts informers to these namespaces when set.
+	watchNamespaces []string
+	// confinement restricts ResourceActions to their own namespace.
+	confinement namespaceConfinement
+	// stripStatus drops status from objects cached by full informers.
+	stripStatus bool
+	// shard limits the informer events to those of this replica, see
+	// SetShard.
+	shard shard
+
+	// queue decouples informer handlers from action execution.
+	queue        workqueue.TypedRateLimitingInterface[*eventItem]
+	eventWorkers int
+	// debounces holds spec.debounce per ResourceAction and pending the
+	// debounced Updates that have not been processed yet.
+	debounces map[types.NamespacedName]time.Duration
+	pending   map[objectKey]*eventItem
+	// priorities holds spec.priority per ResourceAction, so the events of an
+	// object are queued for its ResourceActions in priority order.
+	priorities map[types.NamespacedName]int32
+	// chains holds the undelivered events per object in arrival order.
+	chains map[objectKey][]*eventItem
+	// tracked holds the queued events that were not delivered yet.
+	tracked map[*eventItem]struct{}
+	// draining is set on shutdown; new events are no longer accepted.
+	// drained is closed once no deliverable events are left.
+	draining    bool
+	drained     chan struct{}
+	workers     sync.WaitGroup
+	stopWorkers context.CancelFunc
+	// shutdownGracePeriod bounds how long Shutdown drains the queue.
+	shutdownGracePeriod time.Duration
+	// reconciled holds the ResourceActions the controller reconciled since
+	// the process started, until every ResourceAction has been; restored is
+	// set from then on.
+	reconciled map[types.NamespacedName]struct{}
+	restored   bool
 
 	client     client.Client
 	executor   Executor
 	cronEngine *CronEngine
+	// clusters resolves spec.clusterRef to the clients of remote clusters.
+	clusters *clusterRegistry
 }
 
 func NewEngine(c client.Client) *Engine {
 	exec := NewK8sExecutor(c, nil)
 	cron := NewCronEngine(c, exec)
 
-	return &Engine{
+	e := &Engine{
 		client:     c,
 		executor:   exec, // Interface
 		cronEngine: cron,
+		clusters:   exec.clusters,
 		runCtx:     context.Background(),
-		informers:  make(map[schema.GroupVersionResource]cache.SharedIndexInformer),
+		informers:  make(map[watchKey]cache.SharedIndexInformer),
+
+		stops:         make(map[watchKey]context.CancelFunc),
+		owners:        make(map[types.NamespacedName][]watchKey),
+		watchFailures: make(map[watchKey]watchFailure),
+		queue:         newEventQueue(),
+		eventWorkers:  defaultEventWorkers,
+		debounces:     make(map[types.NamespacedName]time.Duration),
+		priorities:    make(map[types.NamespacedName]int32),
+		pending:       make(map[objectKey]*eventItem),
+		chains:        make(map[objectKey][]*eventItem),
+		tracked:       make(map[*eventItem]struct{}),
+
+		shutdownGracePeriod: defaultShutdownGracePeriod,
 	}
+	cron.lister = e
+	return e
 }
 
 func New(cfg *rest.Config, executor Executor) (*Engine, error) {
@@ -70,12 +207,14 @@ func New(cfg *rest.Config, executor Executor) (*Engine, error) {
 	if err != nil {
 		return nil, err
 	}
-	disco, err := discovery.NewDiscoveryClientForConfig(cfg)
+	meta, err := metadata.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := NewRESTMapper(cfg)
 	if err != nil {
 		return nil, err
 	}
-
-	factory := dynamicinformer.NewDynamicSharedInformerFactory(dyn, 0)
 
 	// Executor MUST be backed by client-based executor for cron
 	k8sExec, ok := executor.(*K8sExecutor)
@@ -85,55 +224,430 @@ func New(cfg *rest.Config, executor Executor) (*Engine, error) {
 
 	cron := NewCronEngine(k8sExec.Client, executor)
 
-	return &Engine{
+	e := &Engine{
 		cfg:        cfg,
 		dyn:        dyn,
-		disco:      disco,
+		meta:       meta,
+		kinds:      newKindResolver(mapper),
 		executor:   executor,
 		cronEngine: cron,
-		factory:    factory,
+		clusters:   k8sExec.clusters,
 		runCtx:     context.Background(),
-		informers:  make(map[schema.GroupVersionResource]cache.SharedIndexInformer),
-	}, nil
+		informers:  make(map[watchKey]cache.SharedIndexInformer),
+
+		stops:         make(map[watchKey]context.CancelFunc),
+		owners:        make(map[types.NamespacedName][]watchKey),
+		watchFailures: make(map[watchKey]watchFailure),
+		queue:         newEventQueue(),
+		eventWorkers:  defaultEventWorkers,
+		debounces:     make(map[types.NamespacedName]time.Duration),
+		priorities:    make(map[types.NamespacedName]int32),
+		pending:       make(map[objectKey]*eventItem),
+		chains:        make(map[objectKey][]*eventItem),
+		tracked:       make(map[*eventItem]struct{}),
+
+		shutdownGracePeriod: defaultShutdownGracePeriod,
+	}
+	cron.lister = e
+	if err := ctrlmetrics.Registry.Register(informerCollector{engine: e}); err != nil {
+		return nil, fmt.Errorf("register informer metrics: %w", err)
+	}
+	return e, nil
+}
+
+// watchKey identifies an informer: a resource type in the local or a remote
+// cluster, optionally narrowed to a single namespace and a label selector. An
+// empty namespace watches all namespaces. Metadata-only informers cache just
+// the object metadata; keepStatus informers keep status when the cache strips
+// it otherwise.
+type watchKey struct {
+	gvr           schema.GroupVersionResource
+	namespace     string
+	labelSelector string
+	cluster       clusterID
+	metadataOnly  bool
+	keepStatus    bool
+}
+
+func (k watchKey) String() string {
+	s := k.gvr.String()
+	if k.namespace != "" {
+		s += " in " + k.namespace
+	}
+	if k.labelSelector != "" {
+		s += " with labels " + k.labelSelector
+	}
+	if k.cluster != (clusterID{}) {
+		s += " in cluster " + k.cluster.String()
+	}
+	if k.metadataOnly {
+		s += " (metadata only)"
+	}
+	if k.keepStatus {
+		s += " (with status)"
+	}
+	return s
+}
+
+// informerLabelSelector returns the selector pushed down to the informer of
+// ra. filters.labelChanges needs to see objects before and after they match,
+// so the selector is only used without label change filters. A selected
+// informer reports an object that gains the labels as added and one that
+// loses them as deleted, so it is not used for Create and Delete actions
+// either.
+func informerLabelSelector(ra *opsv1alpha1.ResourceAction) string {
+	if ra.Spec.Filters == nil || len(ra.Spec.Filters.Labels) == 0 || len(ra.Spec.Filters.LabelChanges) > 0 {
+		return ""
+	}
+	if containsEvent(ra.Spec.Events, string(EventCreate)) || containsEvent(ra.Spec.Events, string(EventDelete)) {
+		return ""
+	}
+	return labels.SelectorFromSet(ra.Spec.Filters.Labels).String()
+}
+
+// allResourceActions owns informers started through EnsureWatching. Their
+// events are delivered to every ResourceAction and they are never released.
+var allResourceActions = types.NamespacedName{}
+
+// SetWatchNamespaces restricts all informers to the given namespaces. An empty
+// list watches the whole cluster. It must be called before the first watch is
+// established.
+func (e *Engine) SetWatchNamespaces(namespaces []string) {
+	e.watchNamespaces = normalizeNamespaces(namespaces)
+}
+
+// SetRESTMapper makes the engine resolve kinds through mapper, typically the
+// one of the manager. It must be called before the first watch is
+// established.
+func (e *Engine) SetRESTMapper(mapper meta.ResettableRESTMapper) {
+	e.kinds = newKindResolver(mapper)
 }
 
-// Resolve GVK -> GVR via discovery REST mapping.
+// Resolve GVK -> GVR via the cached REST mapping.
 func (e *Engine) ResolveGVR(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
-	gr, err := restMapping(e.disco, gvk)
+	gr, _, err := e.kinds.resolve(gvk)
 	if err != nil {
 		return schema.GroupVersionResource{}, err
 	}
 	return gr, nil
 }
 
+// kindsFor returns the kind resolver of cluster, nil being the local cluster.
+func (e *Engine) kindsFor(cluster *remoteCluster) *kindResolver {
+	if cluster == nil {
+		return e.kinds
+	}
+	return cluster.kinds
+}
+
+// resolveWatchKeys returns the informers needed to watch gvk in the requested
+// namespaces, narrowed to labelSelector. Cluster-scoped resources are always
+// watched cluster-wide. A nil cluster is the local cluster; --watch-namespaces
+// does not apply to remote clusters.
+func (e *Engine) resolveWatchKeys(
+	gvk schema.GroupVersionKind,
+	requested []string,
+	labelSelector string,
+	cluster *remoteCluster,
+) ([]watchKey, error) {
+	allowed, id := e.watchNamespaces, clusterID{}
+	if cluster != nil {
+		allowed, id = nil, cluster.id
+	}
+	gvr, namespaced, err := e.kindsFor(cluster).resolve(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("resolve GVR for %s: %w", gvk.String(), err)
+	}
+	if !namespaced {
+		return []watchKey{{gvr: gvr, labelSelector: labelSelector, cluster: id}}, nil
+	}
+
+	namespaces := normalizeNamespaces(requested)
+	if len(namespaces) == 0 {
+		namespaces = allowed
+	} else if len(allowed) > 0 {
+		for _, ns := range namespaces {
+			if !slices.Contains(allowed, ns) {
+				return nil, fmt.Errorf("namespace %q is not watched by the operator", ns)
+			}
+		}
+	}
+	if len(namespaces) == 0 {
+		return []watchKey{{gvr: gvr, labelSelector: labelSelector, cluster: id}}, nil
+	}
+
+	keys := make([]watchKey, 0, len(namespaces))
+	for _, ns := range namespaces {
+		keys = append(keys, watchKey{gvr: gvr, namespace: ns, labelSelector: labelSelector, cluster: id})
+	}
+	return keys, nil
+}
+
+// kindsForKey returns the kind resolver of the cluster key belongs to, or nil
+// when the remote cluster is unknown.
+func (e *Engine) kindsForKey(key watchKey) *kindResolver {
+	if key.cluster == (clusterID{}) {
+		return e.kinds
+	}
+	if cluster := e.clusters.cached(key.cluster.secret); cluster != nil {
+		return cluster.kinds
+	}
+	return nil
+}
+
+// metadataFor returns the metadata client of cluster, nil being the local
+// cluster.
+func (e *Engine) metadataFor(cluster *remoteCluster) metadata.Interface {
+	if cluster == nil {
+		return e.meta
+	}
+	return cluster.meta
+}
+
+// dynamicFor returns the dynamic client of the cluster key belongs to, or nil
+// when the remote cluster is unknown.
+func (e *Engine) dynamicFor(key watchKey) dynamic.Interface {
+	if key.cluster == (clusterID{}) {
+		return e.dyn
+	}
+	if cluster := e.clusters.cached(key.cluster.secret); cluster != nil {
+		return cluster.dyn
+	}
+	return nil
+}
+
 // EnsureWatching makes sure an informer for this resource is running.
 func (e *Engine) EnsureWatching(ctx context.Context, gvk schema.GroupVersionKind) error {
+	keys, err := e.resolveWatchKeys(gvk, nil, "", nil)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, key := range keys {
+		if err := e.ensureInformerLocked(ctx, gvk, key); err != nil {
+			return err
+		}
+	}
+	e.owners[allResourceActions] = appendMissingKeys(e.owners[allResourceActions], keys)
+	return nil
+}
+
+// EnsureWatchingFor is EnsureWatching on behalf of a ResourceAction. Informers
+// are reference counted per ResourceAction; switching its selector or
+// namespaces releases informers it no longer needs.
+func (e *Engine) EnsureWatchingFor(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	gvk := schema.GroupVersionKind{
+		Group:   ra.Spec.Selector.Group,
+		Version: ra.Spec.Selector.Version,
+		Kind:    ra.Spec.Selector.Kind,
+	}
+	namespaces, err := e.confinement.watchNamespaces(ra)
+	if err != nil {
+		return err
+	}
+	cluster, err := e.clusters.clusterFor(ctx, ra)
+	if err != nil {
+		return err
+	}
+	keys, err := e.resolveWatchKeys(gvk, namespaces, informerLabelSelector(ra), cluster)
+	if err != nil {
+		return err
+	}
+	if e.confinement.confines(ra) && keys[0].namespace == "" {
+		return &NamespaceConfinementError{Reason: fmt.Sprintf("cluster-scoped kind %s cannot be selected", gvk.GroupKind())}
+	}
+	// CloudEvents carry the whole object, so a sink needs full informers
+	// that keep status.
+	emitsCloudEvents := e.emitsCloudEvents()
+	if !emitsCloudEvents && !needsFullObject(ra) && e.metadataFor(cluster) != nil {
+		for i := range keys {
+			keys[i].metadataOnly = true
+		}
+	}
+	if e.stripStatus && (emitsCloudEvents || needsStatus(ra)) {
+		for i := range keys {
+			keys[i].keepStatus = true
+		}
+	}
+	owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+
+	// The pending deliveries are queued once the lock is released, the first
+	// time the ResourceAction is watched.
+	replay := false
+	defer func() {
+		if replay {
+			e.replayPending(ctx, ra)
+		}
+	}()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	previous := e.owners[owner]
+	e.owners[owner] = keys
+	e.debounces[owner] = parseDurationDefault(ra.Spec.Debounce, 0)
+	e.priorities[owner] = ra.Spec.Priority
+	for _, key := range keys {
+		if err := e.ensureInformerLocked(ctx, gvk, key); err != nil {
+			e.owners[owner] = previous
+			for _, started := range keys {
+				e.stopUnusedLocked(ctx, started)
+			}
+			return err
+		}
+	}
+	for _, key := range previous {
+		e.stopUnusedLocked(ctx, key)
+	}
+	replay = previous == nil
+	return nil
+}
+
+// replayPending queues the pending deliveries the executor stored for ra
+// before a restart.
+func (e *Engine) replayPending(ctx context.Context, ra *opsv1alpha1.ResourceAction) {
+	store, ok := e.executor.(PendingDeliveries)
+	if !ok {
+		return
+	}
 	logger := log.FromContext(ctx)
+	inputs, err := store.PendingDeliveries(ctx, ra)
+	if err != nil {
+		logger.Error(err, "failed to read pending deliveries", "resourceAction", ra.Name)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.draining {
+		return
+	}
+	for _, input := range inputs {
+		if !e.shard.owns(input.Obj) {
+			continue
+		}
+		owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+		e.addLocked(&eventItem{input: input, key: objectKey{owner: owner, uid: input.Obj.GetUID()}})
+		logger.Info("Queued pending delivery",
+			"resourceAction", ra.Name,
+			"event", input.Event,
+			"name", input.Obj.GetName(),
+		)
+	}
+}
+
+// ReleaseWatch drops the references owner holds on its informers and stops
+// every informer no other ResourceAction needs.
+func (e *Engine) ReleaseWatch(ctx context.Context, owner types.NamespacedName) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	keys, ok := e.owners[owner]
+	if !ok {
+		return
+	}
+	delete(e.owners, owner)
+	delete(e.debounces, owner)
+	delete(e.priorities, owner)
+	for _, key := range keys {
+		e.stopUnusedLocked(ctx, key)
+	}
+}
+
+func (e *Engine) stopUnusedLocked(ctx context.Context, key watchKey) {
+	for _, keys := range e.owners {
+		if slices.Contains(keys, key) {
+			return
+		}
+	}
+	if _, ok := e.informers[key]; !ok {
+		return
+	}
+	if stop, ok := e.stops[key]; ok {
+		stop()
+	}
+	delete(e.stops, key)
+	delete(e.informers, key)
+	delete(e.watchFailures, key)
+	log.FromContext(ctx).Info("Stopped watching resource",
+		"gvr", key.gvr.String(),
+		"namespace", key.namespace,
+		"labelSelector", key.labelSelector,
+	)
+}
+
+// leftSelector reports whether a delete from a label-selected informer only
+// means the object stopped matching the selector while it still exists.
+func (e *Engine) leftSelector(ctx context.Context, key watchKey, obj *unstructured.Unstructured) bool {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
 
-	gvr, err := e.ResolveGVR(gvk)
+	dyn := e.dynamicFor(key)
+	if dyn == nil {
+		return false
+	}
+	current, err := dyn.Resource(key.gvr).Namespace(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("resolve GVR for %s: %w", gvk.String(), err)
+		if !apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "failed to confirm deletion", "gvr", key.gvr.String(), "name", obj.GetName())
+		}
+		return false
 	}
+	return current.GetUID() == obj.GetUID()
+}
 
+// ownersOf returns the ResourceActions that receive events from the informer
+// for key. A nil result delivers the events to every ResourceAction.
+func (e *Engine) ownersOf(key watchKey) map[types.NamespacedName]struct{} {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if _, ok := e.informers[gvr]; ok {
-		return nil // already running
+	owners := map[types.NamespacedName]struct{}{}
+	for owner, keys := range e.owners {
+		if !slices.Contains(keys, key) {
+			continue
+		}
+		if owner == allResourceActions {
+			return nil
+		}
+		owners[owner] = struct{}{}
+	}
+	if len(owners) == 0 {
+		return nil
 	}
+	return owners
+}
+
+func (e *Engine) ensureInformerLocked(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	key watchKey,
+) error {
+	logger := log.FromContext(ctx)
 
-	inf := e.factory.ForResource(gvr).Informer()
+	if _, ok := e.informers[key]; ok {
+		return nil // already running
+	}
+	inf, err := e.newInformer(gvk, key)
+	if err != nil {
+		return err
+	}
+	if err := inf.SetWatchErrorHandlerWithContext(e.watchErrorHandler(key)); err != nil {
+		return fmt.Errorf("set watch error handler for %s: %w", key.String(), err)
+	}
 
 	if _, err := inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			u, ok := obj.(*unstructured.Unstructured)
-			if !ok {
+			if !ok || !e.shard.owns(u) {
 				return
 			}
-			e.onEvent(context.Background(), MatchInput{
-				Event: EventCreate,
-				GVK:   gvk,
-				Obj:   u,
+			e.enqueue(MatchInput{
+				Event:  EventCreate,
+				GVK:    gvk,
+				Obj:    u,
+				owners: e.ownersOf(key),
 			})
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
@@ -142,14 +656,16 @@ func (e *Engine) EnsureWatching(ctx context.Context, gvk schema.GroupVersionKind
 				return
 			}
 			newU, ok := newObj.(*unstructured.Unstructured)
-			if !ok {
+			if !ok || !e.shard.owns(newU) {
 				return
 			}
-			e.onEvent(context.Background(), MatchInput{
-				Event:  EventUpdate,
-				GVK:    gvk,
-				Obj:    newU,
-				OldObj: oldU,
+			e.enqueue(MatchInput{
+				Event:   EventUpdate,
+				GVK:     gvk,
+				Obj:     newU,
+				OldObj:  oldU,
+				changes: changeTypesOf(oldU, newU),
+				owners:  e.ownersOf(key),
 			})
 		},
 		DeleteFunc: func(obj interface{}) {
@@ -165,34 +681,153 @@ func (e *Engine) EnsureWatching(ctx context.Context, gvk schema.GroupVersionKind
 			default:
 				return
 			}
-			e.onEvent(context.Background(), MatchInput{
-				Event: EventDelete,
-				GVK:   gvk,
-				Obj:   u,
-			})
+			// The object is the final state of a Delete event; without it
+			// there is nothing to deliver.
+			if u == nil || !e.shard.owns(u) {
+				return
+			}
+			input := MatchInput{
+				Event:  EventDelete,
+				GVK:    gvk,
+				Obj:    u,
+				owners: e.ownersOf(key),
+			}
+			if key.labelSelector != "" {
+				input.selectorKey = &key
+			}
+			e.enqueue(input)
 		},
 	}); err != nil {
-		return fmt.Errorf("add event handler for %s: %w", gvr.String(), err)
+		return fmt.Errorf("add event handler for %s: %w", key.String(), err)
 	}
 
-	e.informers[gvr] = inf
-	logger.Info("Started watching resource", "gvk", gvk.String(), "gvr", gvr.String())
+	e.informers[key] = inf
+	logger.Info("Started watching resource",
+		"gvk", gvk.String(),
+		"gvr", key.gvr.String(),
+		"namespace", key.namespace,
+		"labelSelector", key.labelSelector,
+		"metadataOnly", key.metadataOnly,
+		"keepStatus", key.keepStatus,
+	)
+
+	// Informers registered before Start are run by Start.
+	if e.started {
+		e.runInformerLocked(key, inf)
+	}
+	return nil
+}
+
+// runInformerLocked runs inf until it is stopped or the context passed to
+// Start is done.
+func (e *Engine) runInformerLocked(key watchKey, inf cache.SharedIndexInformer) {
+	runCtx, stop := context.WithCancel(e.runCtx)
+	e.stops[key] = stop
+	go inf.RunWithContext(runCtx)
+}
+
+// newInformer creates the informer for key. Informers are created outside of
+// a shared factory so each one can be stopped on its own once it is no longer
+// referenced.
+func (e *Engine) newInformer(gvk schema.GroupVersionKind, key watchKey) (cache.SharedIndexInformer, error) {
+	var tweakListOptions func(*metav1.ListOptions)
+	if key.labelSelector != "" {
+		tweakListOptions = func(opts *metav1.ListOptions) {
+			opts.LabelSelector = key.labelSelector
+		}
+	}
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+
+	if key.metadataOnly {
+		var meta metadata.Interface
+		if key.cluster == (clusterID{}) {
+			meta = e.meta
+		} else if cluster := e.clusters.cached(key.cluster.secret); cluster != nil {
+			meta = cluster.meta
+		}
+		if meta == nil {
+			return nil, fmt.Errorf("no metadata client for %s", key.String())
+		}
+		inf := metadatainformer.NewFilteredMetadataInformer(
+			meta, key.gvr, key.namespace, 0, indexers, tweakListOptions,
+		).Informer()
+		if err := inf.SetTransform(metadataTransform(gvk)); err != nil {
+			return nil, fmt.Errorf("set metadata transform for %s: %w", key.String(), err)
+		}
+		return inf, nil
+	}
 
-	// Start factory once.
-	if !e.started {
-		e.started = true
-		e.cronEngine.Start(e.runCtx)
-		go e.factory.Start(e.runCtx.Done())
-	} else {
-		go inf.Run(e.runCtx.Done())
+	dyn := e.dynamicFor(key)
+	if dyn == nil {
+		return nil, fmt.Errorf("no client for cluster %s", key.cluster.String())
 	}
+	inf := dynamicinformer.NewFilteredDynamicInformer(
+		dyn, key.gvr, key.namespace, 0, indexers, tweakListOptions,
+	).Informer()
+	if err := inf.SetTransform(cacheTransform(e.stripStatus && !key.keepStatus)); err != nil {
+		return nil, fmt.Errorf("set cache transform for %s: %w", key.String(), err)
+	}
+	return inf, nil
+}
 
-	return nil
+// SetCronConcurrency limits how many cron ticks execute at the same time.
+// Values <= 0 disable the limit.
+func (e *Engine) SetCronConcurrency(n int) {
+	e.cronEngine.SetMaxConcurrency(n)
 }
 
-func (e *Engine) onEvent(ctx context.Context, input MatchInput) {
+// EnsureStandaloneSchedules registers cron actions of the ResourceAction that
+// run against all matching objects instead of being gated on an event.
+func (e *Engine) EnsureStandaloneSchedules(ctx context.Context, ra opsv1alpha1.ResourceAction) error {
+	return e.cronEngine.EnsureStandalone(ctx, ra)
+}
+
+// ListCached returns the objects currently held by the informers of owner,
+// which may watch a remote cluster. Objects cached by more than one informer
+// are returned once.
+func (e *Engine) ListCached(owner types.NamespacedName) ([]*unstructured.Unstructured, error) {
+	e.mu.Lock()
+	var informers []cache.SharedIndexInformer
+	for _, key := range e.owners[owner] {
+		if inf, ok := e.informers[key]; ok {
+			informers = append(informers, inf)
+		}
+	}
+	e.mu.Unlock()
+	if len(informers) == 0 {
+		return nil, fmt.Errorf("no informer is registered for %s", owner.String())
+	}
+
+	seen := map[types.UID]struct{}{}
+	var objects []*unstructured.Unstructured
+	for _, inf := range informers {
+		for _, item := range inf.GetStore().List() {
+			u, ok := item.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			if _, dup := seen[u.GetUID()]; dup {
+				continue
+			}
+			seen[u.GetUID()] = struct{}{}
+			objects = append(objects, u)
+		}
+	}
+	return objects, nil
+}
+
+// onEvent processes one informer event. The returned error makes the worker
+// requeue the event.
+func (e *Engine) onEvent(ctx context.Context, input MatchInput) error {
+	ctx, span := tracer.Start(ctx, "Engine.onEvent", trace.WithAttributes(inputAttributes(input)...))
+	defer span.End()
 	logger := log.FromContext(ctx)
 
+	if input.selectorKey != nil && e.leftSelector(ctx, *input.selectorKey, input.Obj) {
+		// The object lost the labels of the informer and still exists.
+		return nil
+	}
+
 	// 1) Ensure cron jobs are registered (once).
 	err := e.cronEngine.EnsureForMatch(ctx, input)
 	if err != nil {
@@ -201,26 +836,37 @@ func (e *Engine) onEvent(ctx context.Context, input MatchInput) {
 
 	// 2) Execute event-based actions (once mode).
 	if err := e.executor.Execute(ctx, input); err != nil {
+		var wait *waitError
+		if errors.As(err, &wait) {
+			logger.Info("Pausing execution", "reason", wait.Error())
+			return err
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		logger.Error(err, "executor failed")
+		return err
 	}
+	return nil
 }
 
-func restMapping(d discovery.DiscoveryInterface, gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
-	// Discovery: list all resources for this group/version.
-	resources, err := d.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
-	if err != nil {
-		return schema.GroupVersionResource{}, err
+// normalizeNamespaces drops empty entries and duplicates and sorts the result.
+func normalizeNamespaces(namespaces []string) []string {
+	var out []string
+	for _, ns := range namespaces {
+		ns = strings.TrimSpace(ns)
+		if ns != "" && !slices.Contains(out, ns) {
+			out = append(out, ns)
+		}
 	}
+	slices.Sort(out)
+	return out
+}
 
-	for _, r := range resources.APIResources {
-		if r.Kind == gvk.Kind {
-			return schema.GroupVersionResource{
-				Group:    gvk.Group,
-				Version:  gvk.Version,
-				Resource: r.Name, // plural
-			}, nil
+func appendMissingKeys(keys []watchKey, add []watchKey) []watchKey {
+	for _, key := range add {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
 		}
 	}
-
-	return schema.GroupVersionResource{}, fmt.Errorf("kind %q not found in %s", gvk.Kind, gvk.GroupVersion().String())
+	return keys
 }
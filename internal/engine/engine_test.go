@@ -0,0 +1,530 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func newWatchTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	fakeClient := &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+				{Name: "secrets", Kind: "Secret", Namespaced: true},
+				{Name: "nodes", Kind: "Node", Namespaced: false},
+			},
+		},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	e := NewEngine(nil)
+	e.kinds = newKindResolver(newDiscoveryRESTMapper(&fakediscovery.FakeDiscovery{Fake: fakeClient}))
+	e.dyn = fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+		{Version: "v1", Resource: "secrets"}:    "SecretList",
+		{Version: "v1", Resource: "nodes"}:      "NodeList",
+	})
+	e.runCtx = ctx
+	return e
+}
+
+func isWatching(e *Engine, resource, namespace string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.informers[watchKey{
+		gvr:       schema.GroupVersionResource{Version: "v1", Resource: resource},
+		namespace: namespace,
+	}]
+	return ok
+}
+
+func TestEnsureWatchingFor_StopsUnusedInformers(t *testing.T) {
+	e := newWatchTestEngine(t)
+	ctx := context.Background()
+	first := newTestResourceAction("first", "ConfigMap")
+	second := newTestResourceAction("second", "ConfigMap")
+
+	if err := e.EnsureWatchingFor(ctx, first); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if err := e.EnsureWatchingFor(ctx, second); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+
+	e.ReleaseWatch(ctx, types.NamespacedName{Namespace: "default", Name: "first"})
+	if !isWatching(e, "configmaps", "") {
+		t.Fatalf("configmaps informer stopped while still referenced")
+	}
+
+	// Switching the selector releases the previous informer.
+	second.Spec.Selector.Kind = "Secret"
+	if err := e.EnsureWatchingFor(ctx, second); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if isWatching(e, "configmaps", "") {
+		t.Fatalf("configmaps informer still running without references")
+	}
+	if !isWatching(e, "secrets", "") {
+		t.Fatalf("secrets informer not started")
+	}
+
+	e.ReleaseWatch(ctx, types.NamespacedName{Namespace: "default", Name: "second"})
+	if isWatching(e, "secrets", "") {
+		t.Fatalf("secrets informer still running after last release")
+	}
+
+	// Releasing an unknown owner is a no-op.
+	e.ReleaseWatch(ctx, types.NamespacedName{Namespace: "default", Name: "first"})
+}
+
+func TestEnsureWatchingFor_NamespaceScoped(t *testing.T) {
+	e := newWatchTestEngine(t)
+	e.SetWatchNamespaces([]string{"team-b", " team-a", ""})
+	ctx := context.Background()
+
+	if err := e.EnsureWatchingFor(ctx, newTestResourceAction("all", "ConfigMap")); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if isWatching(e, "configmaps", "") || !isWatching(e, "configmaps", "team-a") || !isWatching(e, "configmaps", "team-b") {
+		t.Fatalf("expected one informer per operator namespace")
+	}
+
+	if err := e.EnsureWatchingFor(ctx, newTestResourceAction("narrow", "Secret", "team-a")); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if !isWatching(e, "secrets", "team-a") || isWatching(e, "secrets", "team-b") {
+		t.Fatalf("expected secrets to be watched in team-a only")
+	}
+
+	if err := e.EnsureWatchingFor(ctx, newTestResourceAction("outside", "Secret", "team-c")); err == nil {
+		t.Fatalf("expected error for namespace outside of the operator scope")
+	}
+
+	// Cluster-scoped resources ignore namespaces.
+	if err := e.EnsureWatchingFor(ctx, newTestResourceAction("nodes", "Node", "team-a")); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if !isWatching(e, "nodes", "") {
+		t.Fatalf("expected cluster-wide informer for nodes")
+	}
+}
+
+func TestEnsureWatchingFor_KindNotServed(t *testing.T) {
+	e := newWatchTestEngine(t)
+	ctx := context.Background()
+
+	for _, selector := range []opsv1alpha1.ResourceSelector{
+		{Version: "v1", Kind: "Widget"},
+		{Group: "example.com", Version: "v1", Kind: "Widget"},
+	} {
+		ra := newTestResourceAction("widgets", selector.Kind)
+		ra.Spec.Selector = selector
+		var notServed *KindNotServedError
+		if err := e.EnsureWatchingFor(ctx, ra); !errors.As(err, &notServed) {
+			t.Fatalf("EnsureWatchingFor(%s) error = %v, want KindNotServedError", selector.Group, err)
+		}
+	}
+}
+
+func TestEnsureWatchingFor_NamespaceConfinement(t *testing.T) {
+	e := newWatchTestEngine(t)
+	e.SetNamespaceConfinement(true, []string{"platform"})
+	ctx := context.Background()
+
+	if err := e.EnsureWatchingFor(ctx, newTestResourceAction("own", "ConfigMap")); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if isWatching(e, "configmaps", "") || !isWatching(e, "configmaps", "default") {
+		t.Fatalf("expected a confined ResourceAction to watch its own namespace only")
+	}
+
+	var confined *NamespaceConfinementError
+	if err := e.EnsureWatchingFor(ctx, newTestResourceAction("other", "Secret", "team-a")); !errors.As(err, &confined) {
+		t.Fatalf("EnsureWatchingFor() error = %v, want NamespaceConfinementError", err)
+	}
+	if err := e.EnsureWatchingFor(ctx, newTestResourceAction("nodes", "Node")); !errors.As(err, &confined) {
+		t.Fatalf("EnsureWatchingFor() error = %v, want NamespaceConfinementError", err)
+	}
+
+	// A kubeconfig could point at the local cluster.
+	remote := newTestResourceAction("remote", "ConfigMap")
+	remote.Spec.ClusterRef = &opsv1alpha1.ClusterRefSpec{SecretName: "remote"}
+	if err := e.EnsureWatchingFor(ctx, remote); !errors.As(err, &confined) {
+		t.Fatalf("EnsureWatchingFor() with clusterRef error = %v, want NamespaceConfinementError", err)
+	}
+
+	// ResourceActions in a cluster-scope namespace are not confined.
+	platform := newTestResourceAction("nodes", "Node")
+	platform.Namespace = "platform"
+	if err := e.EnsureWatchingFor(ctx, platform); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if !isWatching(e, "nodes", "") {
+		t.Fatalf("expected cluster-wide informer for nodes")
+	}
+}
+
+func TestOwnersOf_DeliversToOwningResourceActions(t *testing.T) {
+	e := newWatchTestEngine(t)
+	ctx := context.Background()
+	wide := newTestResourceAction("wide", "ConfigMap")
+	narrow := newTestResourceAction("narrow", "ConfigMap", "team-a")
+
+	for _, ra := range []*opsv1alpha1.ResourceAction{wide, narrow} {
+		if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+			t.Fatalf("EnsureWatchingFor() error = %v", err)
+		}
+	}
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	fromWide := MatchInput{owners: e.ownersOf(watchKey{gvr: gvr})}
+	fromNarrow := MatchInput{owners: e.ownersOf(watchKey{gvr: gvr, namespace: "team-a"})}
+
+	if !fromWide.targets(wide) || fromWide.targets(narrow) {
+		t.Fatalf("cluster-wide informer events must only reach the cluster-wide ResourceAction")
+	}
+	if !fromNarrow.targets(narrow) || fromNarrow.targets(wide) {
+		t.Fatalf("namespaced informer events must only reach the namespaced ResourceAction")
+	}
+	if !(MatchInput{}).targets(wide) {
+		t.Fatalf("events without owners must reach every ResourceAction")
+	}
+}
+
+func TestEnsureWatchingFor_PushesLabelSelectorDown(t *testing.T) {
+	e := newWatchTestEngine(t)
+	ctx := context.Background()
+
+	selected := newTestResourceAction("selected", "ConfigMap")
+	selected.Spec.Filters = &opsv1alpha1.FilterSpec{Labels: map[string]string{"team": "a", "app": "web"}}
+	if err := e.EnsureWatchingFor(ctx, selected); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+
+	transitions := newTestResourceAction("transitions", "ConfigMap")
+	transitions.Spec.Filters = &opsv1alpha1.FilterSpec{
+		Labels:       map[string]string{"team": "a"},
+		LabelChanges: []opsv1alpha1.LabelChangeFilter{{Key: "team", To: "a"}},
+	}
+	if err := e.EnsureWatchingFor(ctx, transitions); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+
+	// A selected informer would report relabeled objects as created.
+	created := newTestResourceAction("created", "Secret")
+	created.Spec.Events = []string{"Create", "Update"}
+	created.Spec.Filters = &opsv1alpha1.FilterSpec{Labels: map[string]string{"team": "a"}}
+	if err := e.EnsureWatchingFor(ctx, created); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if !isWatching(e, "secrets", "") {
+		t.Fatalf("expected unfiltered informer for Create actions")
+	}
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	e.mu.Lock()
+	_, selectedWatched := e.informers[watchKey{gvr: gvr, labelSelector: "app=web,team=a"}]
+	_, unfilteredWatched := e.informers[watchKey{gvr: gvr}]
+	e.mu.Unlock()
+	if !selectedWatched {
+		t.Fatalf("expected informer with label selector app=web,team=a")
+	}
+	if !unfilteredWatched {
+		t.Fatalf("expected unfiltered informer for labelChanges filters")
+	}
+}
+
+func TestLeftSelector(t *testing.T) {
+	e := newWatchTestEngine(t)
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	key := watchKey{gvr: gvr, labelSelector: "team=a"}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion("v1")
+	existing.SetKind("ConfigMap")
+	existing.SetNamespace("default")
+	existing.SetName("relabeled")
+	existing.SetUID("uid-1")
+	if _, err := e.dyn.Resource(gvr).Namespace("default").Create(context.Background(), existing, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if !e.leftSelector(context.Background(), key, existing) {
+		t.Fatalf("expected existing object to be treated as leaving the selector")
+	}
+
+	deleted := existing.DeepCopy()
+	deleted.SetName("deleted")
+	if e.leftSelector(context.Background(), key, deleted) {
+		t.Fatalf("expected missing object to be treated as deleted")
+	}
+
+	_, cl := newTestExecutor(t)
+	exec := &flakyExecutor{}
+	e.executor = exec
+	e.cronEngine = NewCronEngine(cl, exec)
+	input := MatchInput{Event: EventDelete, GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Obj: existing, selectorKey: &key}
+	if err := e.onEvent(context.Background(), input); err != nil || exec.callCount() != 0 {
+		t.Fatalf("onEvent() = %v with %d executions, want the relabeled object to be dropped", err, exec.callCount())
+	}
+	input.Obj = deleted
+	if err := e.onEvent(context.Background(), input); err != nil || exec.callCount() != 1 {
+		t.Fatalf("onEvent() = %v with %d executions, want the deletion to be delivered", err, exec.callCount())
+	}
+}
+
+type flakyExecutor struct {
+	mu       sync.Mutex
+	calls    int
+	failures int
+	// actionsFailed fails like an execution whose actions failed.
+	actionsFailed bool
+}
+
+func (f *flakyExecutor) Execute(_ context.Context, _ MatchInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		if f.actionsFailed {
+			return errors.Join(fmt.Errorf("resourceAction default/demo: %w", &actionsFailedError{err: errors.New("http call failed")}))
+		}
+		return errors.New("transient failure")
+	}
+	return nil
+}
+
+func (f *flakyExecutor) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestEventWorkers_RequeueFailedEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		actionsFailed bool
+		wantCalls     int
+	}{
+		{name: "succeeds after retries", failures: 2, wantCalls: 3},
+		{name: "dropped after max retries", failures: 100, wantCalls: maxEventRetries + 1},
+		{name: "failed actions are not requeued", failures: 100, actionsFailed: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cl := newTestExecutor(t)
+			exec := &flakyExecutor{failures: tt.failures, actionsFailed: tt.actionsFailed}
+			e := NewEngine(cl)
+			e.executor = exec
+			e.queue = workqueue.NewTypedRateLimitingQueue(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[*eventItem](time.Millisecond, 10*time.Millisecond),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			e.startWorkers(ctx)
+			e.enqueue(newDeploymentInput("uid-1", "demo", "default"))
+
+			deadline := time.Now().Add(5 * time.Second)
+			for exec.callCount() < tt.wantCalls && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			// Give the workers a chance to run calls that should not happen.
+			time.Sleep(50 * time.Millisecond)
+
+			if got := exec.callCount(); got != tt.wantCalls {
+				t.Fatalf("executor calls = %d, want %d", got, tt.wantCalls)
+			}
+			if e.queue.Len() != 0 {
+				t.Fatalf("queue length = %d, want 0", e.queue.Len())
+			}
+		})
+	}
+}
+
+func newUpdateInput(uid, oldRV, newRV string, owners ...types.NamespacedName) MatchInput {
+	input := newDeploymentInput(uid, "demo", "default")
+	input.Event = EventUpdate
+	input.OldObj = input.Obj.DeepCopy()
+	input.OldObj.SetResourceVersion(oldRV)
+	input.Obj.SetResourceVersion(newRV)
+	input.owners = map[types.NamespacedName]struct{}{}
+	for _, owner := range owners {
+		input.owners[owner] = struct{}{}
+	}
+	return input
+}
+
+func TestEnqueue_DedupAndDebounce(t *testing.T) {
+	e := NewEngine(nil)
+	debounced := types.NamespacedName{Namespace: "default", Name: "debounced"}
+	direct := types.NamespacedName{Namespace: "default", Name: "direct"}
+	e.debounces[debounced] = 50 * time.Millisecond
+
+	// Updates without a resourceVersion change are dropped.
+	e.enqueue(newUpdateInput("uid-1", "1", "1", direct))
+	if e.queue.Len() != 0 {
+		t.Fatalf("queue length = %d, want 0 for unchanged resourceVersion", e.queue.Len())
+	}
+
+	e.enqueue(newUpdateInput("uid-1", "1", "2", debounced, direct))
+	e.enqueue(newUpdateInput("uid-1", "2", "3", debounced, direct))
+	e.enqueue(newUpdateInput("uid-1", "3", "4", debounced, direct))
+	// Immediate items of one object are queued one after the other.
+	for i := 0; i < 3; i++ {
+		if e.queue.Len() != 1 {
+			t.Fatalf("queue length = %d, want 1 immediate item", e.queue.Len())
+		}
+		item, _ := e.queue.Get()
+		input := e.takeInput(item)
+		if input.targets(&opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "debounced"}}) {
+			t.Fatalf("immediate item must not target the debounced ResourceAction")
+		}
+		e.queue.Done(item)
+		e.finish(item)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for e.queue.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if e.queue.Len() != 1 {
+		t.Fatalf("queue length = %d, want 1 coalesced item", e.queue.Len())
+	}
+	item, _ := e.queue.Get()
+	defer e.queue.Done(item)
+	input := e.takeInput(item)
+	if input.OldObj.GetResourceVersion() != "1" || input.Obj.GetResourceVersion() != "4" {
+		t.Fatalf("coalesced versions = %s -> %s, want 1 -> 4",
+			input.OldObj.GetResourceVersion(), input.Obj.GetResourceVersion())
+	}
+	if len(e.pending) != 0 {
+		t.Fatalf("pending debounced items = %d, want 0", len(e.pending))
+	}
+}
+
+func TestEnqueue_QueuesOwnersInPriorityOrder(t *testing.T) {
+	e := NewEngine(nil)
+	low := types.NamespacedName{Namespace: "default", Name: "low"}
+	b := types.NamespacedName{Namespace: "default", Name: "b"}
+	a := types.NamespacedName{Namespace: "default", Name: "a"}
+	e.priorities[b] = 10
+	e.priorities[a] = 10
+
+	e.enqueue(newUpdateInput("uid-1", "1", "2", low, b, a))
+	var got []string
+	for e.queue.Len() > 0 {
+		item, _ := e.queue.Get()
+		got = append(got, item.key.owner.Name)
+		e.queue.Done(item)
+	}
+	if want := []string{"a", "b", "low"}; !slices.Equal(got, want) {
+		t.Fatalf("queued owners = %v, want %v", got, want)
+	}
+}
+
+// orderedExecutor records delivered events and fails the first attempt of the
+// Create of uid-1.
+type orderedExecutor struct {
+	mu     sync.Mutex
+	failed bool
+	events []string
+}
+
+func (o *orderedExecutor) Execute(_ context.Context, input MatchInput) error {
+	if input.Obj.GetUID() == "uid-1" && input.Event == EventCreate {
+		o.mu.Lock()
+		first := !o.failed
+		o.failed = true
+		o.mu.Unlock()
+		if first {
+			return errors.New("transient failure")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, string(input.Obj.GetUID())+"/"+string(input.Event))
+	return nil
+}
+
+func (o *orderedExecutor) delivered() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.events...)
+}
+
+func TestEventWorkers_DeliverInOrderPerObject(t *testing.T) {
+	_, cl := newTestExecutor(t)
+	exec := &orderedExecutor{}
+	e := NewEngine(cl)
+	e.executor = exec
+	e.queue = workqueue.NewTypedRateLimitingQueue(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[*eventItem](time.Millisecond, 10*time.Millisecond),
+	)
+	direct := types.NamespacedName{Namespace: "default", Name: "direct"}
+	debounced := types.NamespacedName{Namespace: "default", Name: "debounced"}
+	e.debounces[debounced] = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e.startWorkers(ctx)
+
+	input := func(uid string, event EventType, owner types.NamespacedName) MatchInput {
+		in := newDeploymentInput(uid, "demo-"+uid, "default")
+		in.Event = event
+		in.owners = map[types.NamespacedName]struct{}{owner: {}}
+		return in
+	}
+	e.enqueue(input("uid-1", EventCreate, direct))
+	e.enqueue(newUpdateInput("uid-1", "1", "2", direct))
+	e.enqueue(input("uid-1", EventDelete, direct))
+	e.enqueue(input("uid-2", EventCreate, direct))
+	// The Delete must not wait for the debounce window of the Update.
+	e.enqueue(newUpdateInput("uid-3", "1", "2", debounced))
+	e.enqueue(input("uid-3", EventDelete, debounced))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(exec.delivered()) < 6 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	got := exec.delivered()
+	if len(got) != 6 {
+		t.Fatalf("delivered = %v, want 6 events", got)
+	}
+	index := map[string]int{}
+	for i, event := range got {
+		index[event] = i
+	}
+	if !(index["uid-1/Create"] < index["uid-1/Update"] && index["uid-1/Update"] < index["uid-1/Delete"]) {
+		t.Fatalf("events of uid-1 out of order: %v", got)
+	}
+	if index["uid-3/Update"] > index["uid-3/Delete"] {
+		t.Fatalf("events of uid-3 out of order: %v", got)
+	}
+	if index["uid-2/Create"] > index["uid-1/Create"] {
+		t.Fatalf("uid-2 waited for the retry of uid-1: %v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// escalate runs the escalation of the action at actionIndex, which failed
+// with execErr after its retries. The levels run in order until one succeeds;
+// a level that fails escalates to the next. Like handler failures, escalation
+// failures are logged and reported as events and do not change the outcome.
+func (e *K8sExecutor) escalate(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	action opsv1alpha1.ActionSpec,
+	input MatchInput,
+	metrics HTTPExecutionMetrics,
+	execErr error,
+	httpExec *HTTPExecutor,
+	jobExec *JobExecutor,
+) {
+	if len(action.Escalation) == 0 {
+		return
+	}
+	// The action may have failed because ctx expired; the escalation must
+	// still run.
+	ctx = context.WithoutCancel(ctx)
+	logger := log.FromContext(ctx)
+	trigger := newActionTrigger(actionIndex, action, metrics, execErr)
+
+	for i, name := range action.Escalation {
+		handlerIndex := actionIndexByName(&ra, name)
+		if handlerIndex < 0 || !actionEnabled(ra.Spec.Actions[handlerIndex]) {
+			continue
+		}
+		level := *trigger
+		level.escalationLevel = i + 1
+		input.trigger = &level
+		logger.Info("Escalating failed action",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+			"handler", name,
+			"level", level.escalationLevel,
+		)
+		_, err := e.executeAction(ctx, ra, handlerIndex, ra.Spec.Actions[handlerIndex], input, httpExec, jobExec)
+		if err == nil {
+			observeEscalation(&ra, trigger.action, level.escalationLevel, "success")
+			if e.Recorder != nil {
+				e.Recorder.Eventf(&ra, corev1.EventTypeNormal, "Escalated", "action %s escalated to %s at level %d", trigger.action, name, level.escalationLevel)
+			}
+			return
+		}
+		observeEscalation(&ra, trigger.action, level.escalationLevel, "failure")
+		logger.Error(err, "escalation level failed", "resourceAction", ra.Name, "handler", name, "level", level.escalationLevel)
+		if e.Recorder != nil {
+			e.Recorder.Eventf(&ra, corev1.EventTypeWarning, "EscalationFailed", "escalation level %d (%s) of action %s failed: %v", level.escalationLevel, name, trigger.action, err)
+		}
+	}
+	if e.Recorder != nil {
+		e.Recorder.Eventf(&ra, corev1.EventTypeWarning, "EscalationExhausted", "no escalation level of action %s succeeded", trigger.action)
+	}
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExecute_Escalation(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	bodies := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		bodies[r.URL.Path] = string(body)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/webhook":
+			http.Error(w, "receiver down", http.StatusServiceUnavailable)
+		case "/slack":
+			http.Error(w, "invalid token", http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	newAction := func(name, path, mode string) opsv1alpha1.ActionSpec {
+		return opsv1alpha1.ActionSpec{
+			Name:      name,
+			Type:      "http",
+			Mode:      mode,
+			URL:       srv.URL + path,
+			URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		}
+	}
+	webhook := newAction("webhook", "/webhook", "")
+	webhook.Retry = &opsv1alpha1.RetrySpec{MaxAttempts: 2, Backoff: "1ms", RetryOnStatus: []int{503}}
+	webhook.Escalation = []string{"slack", "pagerduty", "phone"}
+	escalationBody := &opsv1alpha1.TemplateSpec{
+		Template: `{{ escalationLevel }} {{ triggeredBy }} {{ failedAttempts }} {{ failureStatus }}`,
+	}
+	slack := newAction("slack", "/slack", "handler")
+	slack.Body = escalationBody
+	pagerduty := newAction("pagerduty", "/pagerduty", "handler")
+	pagerduty.Body = escalationBody
+	phone := newAction("phone", "/phone", "handler")
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-escalation", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions:  []opsv1alpha1.ActionSpec{webhook, slack, pagerduty, phone},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-escalation", "demo", "default")); err == nil {
+		t.Fatalf("expected the webhook failure to fail the execution")
+	}
+	// The escalation starts once the retries of the webhook are exhausted and
+	// stops at the first level that succeeds.
+	if want := []string{"/webhook", "/webhook", "/slack", "/pagerduty"}; !slices.Equal(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	if got := bodies["/slack"]; got != "1 webhook 2 503" {
+		t.Fatalf("slack body = %q, want level 1 with the failure of the webhook", got)
+	}
+	if got := bodies["/pagerduty"]; got != "2 webhook 2 503" {
+		t.Fatalf("pagerduty body = %q, want level 2 with the failure of the webhook", got)
+	}
+}
+
+func TestExecute_NoEscalationOnSuccess(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-no-escalation", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Name:       "webhook",
+					Type:       "http",
+					URL:        srv.URL + "/webhook",
+					URLPolicy:  &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Escalation: []string{"pagerduty"},
+				},
+				{
+					Name:      "pagerduty",
+					Type:      "http",
+					Mode:      actionModeHandler,
+					URL:       srv.URL + "/pagerduty",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				},
+			},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-no-escalation", "demo", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !slices.Equal(calls, []string{"/webhook"}) {
+		t.Fatalf("calls = %q, want no escalation after a success", calls)
+	}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+// The states of a group of aggregation.groupBy, as returned by the
+// groupStatus template function.
+const (
+	groupStatusFiring   = "firing"
+	groupStatusResolved = "resolved"
+)
+
+// eventGroup is the group of aggregation.groupBy a batch was collected for.
+type eventGroup struct {
+	labels      map[string]string
+	fingerprint string
+	// resolved is set for the delivery of aggregation.resolveAfter, after
+	// the events of the group stopped.
+	resolved bool
+}
+
+func (g *eventGroup) status() string {
+	if g != nil && g.resolved {
+		return groupStatusResolved
+	}
+	return groupStatusFiring
+}
+
+// groupOf renders the aggregation.groupBy labels of ra for input. It returns
+// nil when ra neither groups events nor resolves groups. A label whose
+// template fails is empty, like a missing label in Alertmanager, and the
+// failure is returned along with the group.
+func groupOf(compiled *compiledSpec, ra *opsv1alpha1.ResourceAction, input MatchInput) (*eventGroup, error) {
+	spec := ra.Spec.Aggregation
+	if spec == nil || (len(spec.GroupBy) == 0 && spec.ResolveAfter == "") {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(spec.GroupBy))
+	var errs []error
+	funcs := bodyTemplateFuncs(nil, input.trigger)
+	for _, name := range sortedKeys(spec.GroupBy) {
+		value, err := renderTemplate(compiled, "aggregation.groupBy."+name, spec.GroupBy[name], input.Obj.Object, funcs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("groupBy[%s]: %w", name, err))
+		}
+		labels[name] = value
+	}
+	return &eventGroup{labels: labels, fingerprint: fingerprintOf(labels)}, errors.Join(errs...)
+}
+
+// fingerprintOf hashes labels like Alertmanager fingerprints alerts: FNV-1a
+// over the sorted label names and values, separated by 0xff.
+func fingerprintOf(labels map[string]string) string {
+	h := fnv.New64a()
+	for _, name := range sortedKeys(labels) {
+		h.Write([]byte(name))
+		h.Write([]byte{0xff})
+		h.Write([]byte(labels[name]))
+		h.Write([]byte{0xff})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+func TestGroupOf(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{Spec: opsv1alpha1.ResourceActionSpec{
+		Aggregation: &opsv1alpha1.AggregationSpec{
+			Window: "1m",
+			GroupBy: map[string]string{
+				"namespace": "{{ .metadata.namespace }}",
+				"team":      `{{ output "team" }}`,
+			},
+		},
+	}}
+
+	a, err := groupOf(nil, ra, newDeploymentInput("uid-1", "a", "default"))
+	if err == nil {
+		t.Fatalf("groupOf() error = nil, want the failed team label")
+	}
+	if a.labels["namespace"] != "default" || a.labels["team"] != "" {
+		t.Fatalf("labels = %v, want the namespace and an empty team", a.labels)
+	}
+	b, _ := groupOf(nil, ra, newDeploymentInput("uid-2", "b", "default"))
+	c, _ := groupOf(nil, ra, newDeploymentInput("uid-3", "c", "other"))
+	if a.fingerprint != b.fingerprint || a.fingerprint == c.fingerprint {
+		t.Fatalf("fingerprints = %s, %s, %s; want equal labels to share one", a.fingerprint, b.fingerprint, c.fingerprint)
+	}
+
+	ra.Spec.Aggregation.GroupBy = nil
+	if group, err := groupOf(nil, ra, newDeploymentInput("uid-1", "a", "default")); group != nil || err != nil {
+		t.Fatalf("groupOf() = %+v, %v; want no group without groupBy and resolveAfter", group, err)
+	}
+}
+
+func TestFingerprintOf(t *testing.T) {
+	// Label values must not run into the next label name.
+	if fingerprintOf(map[string]string{"a": "bc"}) == fingerprintOf(map[string]string{"ab": "c"}) {
+		t.Fatalf("fingerprints of different labels are equal")
+	}
+	if got := fingerprintOf(map[string]string{}); len(got) != 16 {
+		t.Fatalf("fingerprint = %q, want 16 hex digits", got)
+	}
+}
@@ -0,0 +1,282 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultEventWorkers = 4
+	// maxEventRetries bounds how often a failed event is requeued before it
+	// is dropped.
+	maxEventRetries = 5
+)
+
+// eventItem is a queued informer event for one ResourceAction. Items are
+// queued by pointer so every event is processed, even when an object changes
+// several times in a row. Debounced items stay pending until they are
+// processed and absorb later Update events of the same object.
+type eventItem struct {
+	input MatchInput
+	key   objectKey
+	// debounced items are due at readyAt.
+	debounced bool
+	readyAt   time.Time
+}
+
+// objectKey identifies one object for one ResourceAction. Its events are
+// delivered in order and its Updates are debounced together.
+type objectKey struct {
+	owner types.NamespacedName
+	uid   types.UID
+}
+
+func newEventQueue() workqueue.TypedRateLimitingInterface[*eventItem] {
+	return workqueue.NewTypedRateLimitingQueueWithConfig(
+		workqueue.DefaultTypedControllerRateLimiter[*eventItem](),
+		workqueue.TypedRateLimitingQueueConfig[*eventItem]{Name: "resource_action_events"},
+	)
+}
+
+// SetEventWorkers sets how many informer events are processed concurrently.
+// It must be called before the first watch is established.
+func (e *Engine) SetEventWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	e.eventWorkers = n
+}
+
+// enqueue hands an informer event to the workers so informer handlers never
+// block on action execution. The event is split per ResourceAction. Updates
+// that do not change the resourceVersion are dropped, and Updates for
+// ResourceActions with spec.debounce are coalesced per object.
+func (e *Engine) enqueue(input MatchInput) {
+	if input.Event == EventUpdate && input.OldObj != nil &&
+		input.OldObj.GetResourceVersion() == input.Obj.GetResourceVersion() {
+		return
+	}
+	observeEventReceived(input)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.draining {
+		return
+	}
+	if input.owners == nil {
+		e.addLocked(&eventItem{input: input, key: objectKey{owner: allResourceActions, uid: input.Obj.GetUID()}})
+		return
+	}
+
+	for _, owner := range e.byPriorityLocked(input.owners) {
+		item := &eventItem{input: input, key: objectKey{owner: owner, uid: input.Obj.GetUID()}}
+		item.input.owners = map[types.NamespacedName]struct{}{owner: {}}
+
+		window := e.debounces[owner]
+		if input.Event != EventUpdate || window <= 0 {
+			e.addLocked(item)
+			continue
+		}
+		if pending, ok := e.pending[item.key]; ok {
+			// Keep the state before the burst so label transitions and
+			// change types still compare against it.
+			pending.input.Obj = input.Obj
+			if pending.input.OldObj != nil {
+				pending.input.changes = changeTypesOf(pending.input.OldObj, input.Obj)
+			}
+			continue
+		}
+		item.debounced = true
+		item.readyAt = time.Now().Add(window)
+		e.pending[item.key] = item
+		e.addLocked(item)
+	}
+}
+
+// byPriorityLocked returns owners in the order their ResourceActions run:
+// by descending spec.priority, then by namespace and name.
+func (e *Engine) byPriorityLocked(owners map[types.NamespacedName]struct{}) []types.NamespacedName {
+	sorted := slices.Collect(maps.Keys(owners))
+	slices.SortFunc(sorted, func(a, b types.NamespacedName) int {
+		return comparePriority(e.priorities[a], e.priorities[b], a, b)
+	})
+	return sorted
+}
+
+// addLocked appends item to the events of its object and tracks it until it
+// was delivered, so shutdown can report the events it could not deliver. Only
+// the first event of an object is queued; the next one follows once it was
+// processed.
+func (e *Engine) addLocked(item *eventItem) {
+	e.tracked[item] = struct{}{}
+	chain := append(e.chains[item.key], item)
+	e.chains[item.key] = chain
+	switch {
+	case len(chain) == 1:
+		e.queueHeadLocked(chain)
+	case len(chain) == 2 && time.Now().Before(chain[0].readyAt):
+		// Deliver a debounced Update early instead of holding back the
+		// events behind it.
+		e.queue.Add(chain[0])
+	}
+}
+
+// queueHeadLocked queues the first event of an object. A debounced Update
+// waits for its window unless other events wait behind it.
+func (e *Engine) queueHeadLocked(chain []*eventItem) {
+	if wait := time.Until(chain[0].readyAt); wait > 0 && len(chain) == 1 {
+		e.queue.AddAfter(chain[0], wait)
+		return
+	}
+	e.queue.Add(chain[0])
+}
+
+// isHead reports whether item is the next event to deliver for its object.
+func (e *Engine) isHead(item *eventItem) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	chain := e.chains[item.key]
+	return len(chain) > 0 && chain[0] == item
+}
+
+// finish marks item as delivered or dropped and queues the next event of its
+// object.
+func (e *Engine) finish(item *eventItem) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.tracked, item)
+	chain := e.chains[item.key]
+	if len(chain) == 0 || chain[0] != item {
+		return
+	}
+	if len(chain) == 1 {
+		delete(e.chains, item.key)
+	} else {
+		e.chains[item.key] = chain[1:]
+		e.queueHeadLocked(chain[1:])
+	}
+	e.notifyDrainedLocked()
+}
+
+// stall stops delivering the events of item's object during shutdown after
+// item failed. They stay tracked and are recorded as undelivered.
+func (e *Engine) stall(item *eventItem) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.chains, item.key)
+	e.notifyDrainedLocked()
+}
+
+// takeInput returns the input of item. A debounced item stops absorbing
+// further Updates once it is taken.
+func (e *Engine) takeInput(item *eventItem) MatchInput {
+	if !item.debounced {
+		return item.input
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pending[item.key] == item {
+		delete(e.pending, item.key)
+	}
+	return item.input
+}
+
+// startWorkers runs the event workers until ctx is done.
+func (e *Engine) startWorkers(ctx context.Context) {
+	for i := 0; i < e.eventWorkers; i++ {
+		e.workers.Add(1)
+		go func() {
+			defer e.workers.Done()
+			for e.processNextEvent(ctx) {
+			}
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		e.queue.ShutDown()
+	}()
+}
+
+// processNextEvent handles one queued event. Events whose processing failed,
+// other than by failed actions, are requeued with rate-limited backoff up to
+// maxEventRetries times; later events of the same object wait for them. Events paused by a wait action are requeued after the
+// wait and resume where they stopped.
+func (e *Engine) processNextEvent(ctx context.Context) bool {
+	item, shutdown := e.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer e.queue.Done(item)
+
+	if !e.isHead(item) {
+		// A debounced Update that was delivered early and whose window
+		// expired afterwards.
+		e.queue.Forget(item)
+		return true
+	}
+	if ctx.Err() != nil {
+		// Shutdown cancelled the workers; it records the event as
+		// undelivered.
+		e.queue.Forget(item)
+		e.stall(item)
+		return true
+	}
+	err := e.onEvent(ctx, e.takeInput(item))
+	if err == nil {
+		e.queue.Forget(item)
+		e.finish(item)
+		return true
+	}
+
+	logger := log.FromContext(ctx)
+	if e.isDraining() {
+		// Requeued items would be lost; shutdown records the event as
+		// undelivered instead.
+		e.queue.Forget(item)
+		e.stall(item)
+		return true
+	}
+	var wait *waitError
+	if errors.As(err, &wait) {
+		// The item stays at the head of its object, so later events wait
+		// for the paused execution.
+		item.input.resume = &wait.progress
+		item.input.owners = map[types.NamespacedName]struct{}{wait.progress.owner: {}}
+		e.queue.Forget(item)
+		e.queue.AddAfter(item, wait.delay)
+		return true
+	}
+	if !requeueable(err) {
+		// The failure is recorded in the status of the ResourceActions.
+		e.queue.Forget(item)
+		e.finish(item)
+		return true
+	}
+	if e.queue.NumRequeues(item) < maxEventRetries {
+		logger.Info("Requeueing failed event",
+			"event", item.input.Event,
+			"gvk", item.input.GVK.String(),
+			"name", item.input.Obj.GetName(),
+			"retries", e.queue.NumRequeues(item),
+			"error", err.Error(),
+		)
+		e.queue.AddRateLimited(item)
+		return true
+	}
+
+	logger.Error(err, "dropping event after retries",
+		"event", item.input.Event,
+		"gvk", item.input.GVK.String(),
+		"name", item.input.Obj.GetName(),
+	)
+	e.queue.Forget(item)
+	e.finish(item)
+	return true
+}
@@ -1,196 +1,700 @@
 package engine
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"regexp"
+	"maps"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	executionResultSucceeded       = "Succeeded"
+	executionResultFailed          = "Failed"
+	executionResultPartiallyFailed = "PartiallyFailed"
+
+	actionStateReady   = "Ready"
+	actionStateFailing = "Failing"
+)
+
+// actionOutcome is the result of a single action within one execution.
+type actionOutcome struct {
+	index int
+	err   error
+}
+
 type K8sExecutor struct {
 	Client    client.Client
 	Clientset kubernetes.Interface
 	Recorder  record.EventRecorder
+
+	// clusters resolves spec.clusterRef for job actions.
+	clusters *clusterRegistry
+	// breakers and limiter are shared by all HTTP actions.
+	breakers *circuitBreakers
+	limiter  *outboundLimiter
+	// tokens caches the ServiceAccount tokens of auth.serviceAccountToken.
+	tokens *serviceAccountTokens
+	// secrets caches the Secrets read by actions.
+	secrets *secretCache
+	// vault reads vaultRef values. Nil when no Vault is configured.
+	vault *vaultClient
+	// transports is shared by all HTTP actions.
+	transports *transportCache
+	// confinement skips objects outside the namespace of a confined
+	// ResourceAction.
+	confinement namespaceConfinement
+	// urlPolicy is the operator URL policy of HTTP actions and dead letters.
+	urlPolicy *opsv1alpha1.OperatorURLPolicy
+	// compiled caches the regular expressions and templates of every
+	// ResourceAction.
+	compiled *compiledCache
+	// configMapLookupNamespaces are the namespaces whose ConfigMaps templates
+	// of every ResourceAction may read.
+	configMapLookupNamespaces []string
+	// batches collects the events of ResourceActions with spec.aggregation.
+	batches *aggregator
+	// suppressed holds the events queued by spec.suppressionWindows.
+	suppressed *suppressionQueue
+	// quotas counts executions for spec.maxExecutionsPerObject and
+	// spec.maxExecutionsPerHour.
+	quotas *executionQuotas
+	// statusBatches collects the status updates of executions, see
+	// SetStatusFlushInterval.
+	statusBatches *statusBatcher
+	// cloudEvents emits matched events. Nil when no sink is configured.
+	cloudEvents *cloudEventSink
+	// audit exports execution records. Nil when no audit sink is configured.
+	audit *AuditExporter
+	// recordMode simulates the actions of every ResourceAction, see
+	// SetRecordMode.
+	recordMode bool
+	// deferRetries retries HTTP actions through the event queue, see
+	// SetDeferredRetries.
+	deferRetries bool
+	// durableDeliveries stores every execution as a pending delivery before
+	// its actions run, see SetDurableDeliveries.
+	durableDeliveries bool
+	// dedupPrunes limits how often the dedup stores are pruned.
+	dedupPrunes dedupPrunes
 }
 
 func NewK8sExecutor(c client.Client, clientset kubernetes.Interface, recorder ...record.EventRecorder) *K8sExecutor {
-	exec := &K8sExecutor{Client: c, Clientset: clientset}
+	exec := &K8sExecutor{
+		Client:     c,
+		Clientset:  clientset,
+		clusters:   newClusterRegistry(c),
+		breakers:   newCircuitBreakers(defaultCircuitBreakerFailures, defaultCircuitBreakerCooldown),
+		limiter:    newOutboundLimiter(),
+		tokens:     newServiceAccountTokens(c),
+		secrets:    newSecretCache(c),
+		transports: newTransportCache(),
+		compiled:   newCompiledCache(),
+		batches:    newAggregator(),
+		suppressed: newSuppressionQueue(),
+		quotas:     newExecutionQuotas(),
+
+		statusBatches: newStatusBatcher(),
+	}
+	exec.clusters.secrets = exec.secrets
 	if len(recorder) > 0 {
 		exec.Recorder = recorder[0]
 	}
 	return exec
 }
 
-func (e *K8sExecutor) Execute(ctx context.Context, input MatchInput) error {
-	logger := log.FromContext(ctx)
+// SetURLPolicy restricts the hosts HTTP actions and HTTP dead letters may
+// call. Nil allows every host.
+func (e *K8sExecutor) SetURLPolicy(policy *opsv1alpha1.OperatorURLPolicy) {
+	e.urlPolicy = policy
+}
+
+func (e *K8sExecutor) Execute(ctx context.Context, input MatchInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Executor.Execute", trace.WithAttributes(inputAttributes(input)...))
+	defer func() { endSpan(span, err) }()
 
 	var list opsv1alpha1.ResourceActionList
 	if err := e.Client.List(ctx, &list); err != nil {
 		return err
 	}
+	if input.Event == EventDelete {
+		defer e.forgetDeletedObject(ctx, list.Items, input)
+	}
 
-	for _, ra := range list.Items {
-		var execErr error
-		executedAny := false
-		executedActions := 0
-		totalAttempts := 0
-		totalNetworkRetries := 0
-		totalStatusRetries := 0
-		totalBackoffMillis := int64(0)
-		totalDurationMillis := int64(0)
-		lastHTTPStatus := 0
-		var lastJobDetails *opsv1alpha1.JobExecutionRecord
-
-		if !matchesSelector(ra.Spec.Selector, input.GVK) {
-			continue
+	// Every ResourceAction is executed even when another one fails, so one
+	// broken receiver does not hold back the others.
+	sortByPriority(list.Items)
+	var errs []error
+	for i := range list.Items {
+		ra := &list.Items[i]
+		if err := e.executeFor(ctx, *ra, input); err != nil {
+			errs = append(errs, fmt.Errorf("resourceAction %s/%s: %w", ra.Namespace, ra.Name, err))
 		}
-		if !containsEvent(ra.Spec.Events, string(input.Event)) {
-			continue
+		// Events are delivered to each ResourceAction separately, so this
+		// also holds back ResourceActions that input does not target.
+		if ra.Spec.StopOnMatch && !ra.Spec.Suspend && e.matches(ra, input) {
+			break
 		}
-		if !matchesFilters(ra.Spec.Filters, input) {
-			continue
+	}
+	return errors.Join(errs...)
+}
+
+// sortByPriority orders ResourceActions by descending spec.priority, then by
+// namespace and name.
+func sortByPriority(items []opsv1alpha1.ResourceAction) {
+	slices.SortFunc(items, func(a, b opsv1alpha1.ResourceAction) int {
+		return comparePriority(a.Spec.Priority, b.Spec.Priority,
+			types.NamespacedName{Namespace: a.Namespace, Name: a.Name},
+			types.NamespacedName{Namespace: b.Namespace, Name: b.Name})
+	})
+}
+
+func comparePriority(aPriority, bPriority int32, a, b types.NamespacedName) int {
+	if c := cmp.Compare(bPriority, aPriority); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Name, b.Name)
+}
+
+// matches reports whether the selector, namespaces, events and filters of ra
+// match input.
+func (e *K8sExecutor) matches(ra *opsv1alpha1.ResourceAction, input MatchInput) bool {
+	return e.selects(ra, input) && matchesFilters(e.compiled.get(ra), ra, input)
+}
+
+// selects reports whether the selector, namespaces and events of ra match
+// input, before spec.filters are applied.
+func (e *K8sExecutor) selects(ra *opsv1alpha1.ResourceAction, input MatchInput) bool {
+	if !matchesSelector(ra.Spec.Selector, input.GVK) || !watchesNamespace(ra.Spec.WatchNamespaces, input.Obj) {
+		return false
+	}
+	if e.confinement.confines(ra) && input.Obj.GetNamespace() != ra.Namespace {
+		return false
+	}
+	return containsEvent(ra.Spec.Events, string(input.Event))
+}
+
+// actionsFailedError is returned when actions of a recorded execution failed.
+// The event queue does not requeue it: the actions already ran with their own
+// retries, and running the execution again would repeat the actions that
+// succeeded.
+type actionsFailedError struct {
+	err error
+}
+
+func (e *actionsFailedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *actionsFailedError) Unwrap() error {
+	return e.err
+}
+
+// requeueable reports whether processing an event failed for a reason other
+// than failed actions, for example a status update that failed, so that
+// processing it again can succeed.
+func requeueable(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return slices.ContainsFunc(joined.Unwrap(), requeueable)
+	}
+	var failed *actionsFailedError
+	return !errors.As(err, &failed)
+}
+
+// executeFor runs the event-driven actions of ra for input and records the
+// execution in its status.
+func (e *K8sExecutor) executeFor(ctx context.Context, ra opsv1alpha1.ResourceAction, input MatchInput) error {
+	logger := log.FromContext(ctx)
+
+	if !input.targets(&ra) || !e.selects(&ra, input) {
+		return nil
+	}
+	// Resumed executions, aggregated batches and queued events were counted
+	// when their events arrived.
+	fresh := input.resume == nil && input.batch == nil && !input.queued
+	if !matchesFilters(e.compiled.get(&ra), &ra, input) {
+		if fresh {
+			observeEventFiltered(&ra)
 		}
-		if alreadyExecuted(&ra, input.Obj.GetUID(), string(input.Event)) {
-			logger.Info("Skipping already executed action",
+		return nil
+	}
+	if fresh {
+		observeEventMatched(&ra, input)
+	}
+	if ra.Spec.Suspend {
+		logger.Info("Skipping suspended ResourceAction",
+			"resourceAction", ra.Name,
+			"event", input.Event,
+			"name", input.Obj.GetName(),
+		)
+		return nil
+	}
+	if ra.Spec.DryRun || e.recording(&ra) {
+		return e.dryRun(ctx, &ra, input)
+	}
+
+	var progress executionProgress
+	var raCtx context.Context
+	if input.resume != nil {
+		// A wait action paused the execution, which already passed the
+		// deduplication check.
+		if ra.Generation != input.resume.generation {
+			logger.Info("Abandoning paused execution, the spec changed",
 				"resourceAction", ra.Name,
 				"event", input.Event,
 				"name", input.Obj.GetName(),
 			)
-			continue
+			if input.resume.persisted {
+				return e.clearPending(ctx, &ra, input)
+			}
+			return nil
 		}
+		progress = input.resume.clone()
+		raCtx = contextWithCorrelationID(ctx, progress.correlationID)
+	} else {
+		if fresh && input.trigger == nil {
+			e.emitCloudEvent(ctx, &ra, input)
+		}
+		// The events of an aggregated batch passed the deduplication check
+		// when they were collected.
+		if input.batch == nil {
+			reused, err := e.nameReused(ctx, &ra, input)
+			if err != nil {
+				return err
+			}
+			if reused {
+				logger.Info("Suppressing event of re-created object",
+					"resourceAction", ra.Name,
+					"event", input.Event,
+					"name", input.Obj.GetName(),
+				)
+				return nil
+			}
+			due, err := e.executionDue(ctx, &ra, input)
+			if err != nil {
+				return err
+			}
+			if !due {
+				logger.Info("Skipping already executed action",
+					"resourceAction", ra.Name,
+					"event", input.Event,
+					"name", input.Obj.GetName(),
+				)
+				return nil
+			}
+			if end, queue, ok := activeSuppressionWindow(&ra, time.Now()); ok && !input.retrigger {
+				if queue {
+					e.suppress(ctx, &ra, input, end)
+					return nil
+				}
+				logger.Info("Skipping event in suppression window",
+					"resourceAction", ra.Name,
+					"event", input.Event,
+					"name", input.Obj.GetName(),
+					"windowEnd", end,
+				)
+				return nil
+			}
+			if !input.retrigger {
+				if silence := e.activeSilence(ctx, &ra, input, time.Now()); silence != "" {
+					logger.Info("Skipping silenced event",
+						"resourceAction", ra.Name,
+						"event", input.Event,
+						"name", input.Obj.GetName(),
+						"silence", silence,
+					)
+					observeEventSilenced(&ra, silence)
+					return nil
+				}
+			}
+			if reason := e.quotas.allow(&ra, input.Obj.GetUID(), time.Now()); reason != "" {
+				logger.Info("Throttling event, execution quota exceeded",
+					"resourceAction", ra.Name,
+					"event", input.Event,
+					"name", input.Obj.GetName(),
+					"reason", reason,
+				)
+				return e.setThrottled(ctx, &ra, reason)
+			}
+			if ra.Spec.Aggregation != nil {
+				e.aggregate(ctx, &ra, input)
+				return nil
+			}
+		}
+		progress = newExecutionProgress(&ra)
+		raCtx, progress.correlationID = withCorrelationID(ctx)
+		if e.durableDeliveries && input.batch == nil {
+			if err := e.storePending(ctx, &ra, input, &progress); err != nil {
+				return err
+			}
+		}
+	}
+
+	httpExec := e.httpExecutor()
+	jobExec, err := e.jobExecutorFor(ctx, &ra)
+	if err != nil {
+		return err
+	}
+	raLogger := log.FromContext(raCtx)
+	httpExec.outputs = progress.outputs
 
-		httpExec := NewHTTPExecutor(e.Client)
-		jobExec := NewJobExecutor(e.Client, e.Clientset)
+	stopped := false
+	graph := hasDependencies(&ra)
+	// Only events delivered by the event queue can be requeued.
+	httpExec.deferRetries = e.deferRetries && !graph && input.batch == nil && !input.queued
+	if graph {
+		stopped = e.executeGraph(raCtx, ra, input, httpExec, jobExec, &progress)
+	}
+	for i := progress.next; !graph && i < len(ra.Spec.Actions); i++ {
+		action := ra.Spec.Actions[i]
+		if action.Mode == "cron" || action.Mode == "schedule" || action.Mode == actionModeHandler || !actionEnabled(action) {
+			continue
+		}
 
-		for i, action := range ra.Spec.Actions {
-			if action.Mode == "cron" || action.Mode == "schedule" {
+		if action.Approval != nil {
+			delay, err := e.awaitApproval(raCtx, &ra, i, action, &progress)
+			if err == nil && delay > 0 {
+				progress.next = i
+				return &waitError{progress: progress, delay: delay}
+			}
+			if err != nil {
+				err = fmt.Errorf("approval: %w", err)
+				progress.record(i, HTTPExecutionMetrics{}, err)
+				if !action.ContinueOnError {
+					stopped = true
+					break
+				}
 				continue
 			}
-			executedAny = true
+		}
 
-			logger.Info("Executing action",
-				"resourceAction", ra.Name,
-				"actionIndex", i,
-				"type", action.Type,
-				"event", input.Event,
-				"name", input.Obj.GetName(),
-			)
+		raLogger.Info("Executing action",
+			"resourceAction", ra.Name,
+			"actionIndex", i,
+			"action", action.Name,
+			"type", action.Type,
+			"event", input.Event,
+			"name", input.Obj.GetName(),
+		)
 
-			actionMetrics, err := e.executeAction(ctx, ra, i, action, input, httpExec, jobExec)
-			totalAttempts += actionMetrics.Attempts
-			totalNetworkRetries += actionMetrics.NetworkRetryCount
-			totalStatusRetries += actionMetrics.StatusRetryCount
-			totalBackoffMillis += actionMetrics.BackoffMillis
-			totalDurationMillis += actionMetrics.DurationMillis
-			if actionMetrics.StatusCode > 0 {
-				lastHTTPStatus = actionMetrics.StatusCode
+		if action.Type == actionTypeWait {
+			delay, err := renderWaitDuration(e.compiled.get(&ra), action.Wait, input.Obj, progress.outputs)
+			if err == nil {
+				progress.record(i, HTTPExecutionMetrics{}, nil)
+				progress.next = i + 1
+				return &waitError{progress: progress, delay: delay}
 			}
-			if actionMetrics.Job != nil {
-				lastJobDetails = actionMetrics.Job.DeepCopy()
+			err = fmt.Errorf("wait: %w", err)
+			progress.record(i, HTTPExecutionMetrics{}, err)
+			if !action.ContinueOnError {
+				stopped = true
+				break
+			}
+			continue
+		}
+		if action.Type == actionTypeWaitForCondition {
+			delay, err := e.awaitCondition(raCtx, &ra, action, input, jobExec.target, &progress)
+			if err == nil && delay > 0 {
+				progress.next = i
+				return &waitError{progress: progress, delay: delay}
 			}
-			executedActions++
 			if err != nil {
-				execErr = err
+				err = fmt.Errorf("waitForCondition: %w", err)
+			}
+			progress.record(i, HTTPExecutionMetrics{}, err)
+			if err != nil && !action.ContinueOnError {
+				stopped = true
 				break
 			}
+			continue
+		}
+
+		httpExec.firstAttempt = progress.takeRetryAttempt(i)
+		actionMetrics, err := e.executeAction(raCtx, ra, i, action, input, httpExec, jobExec)
+		var later *retryLaterError
+		if errors.As(err, &later) {
+			if err := e.storePending(ctx, &ra, input, &progress); err != nil {
+				return err
+			}
+			progress.deferRetry(i, actionMetrics, later.attempt)
+			raLogger.Info("Deferring action retry",
+				"resourceAction", ra.Name,
+				"actionIndex", i,
+				"action", action.Name,
+				"attempt", later.attempt,
+				"delay", later.delay.String(),
+			)
+			return &waitError{progress: progress, delay: later.delay}
 		}
-		if !executedAny {
+		progress.record(i, actionMetrics, err)
+		if err != nil {
+			if !action.ContinueOnError {
+				stopped = true
+				break
+			}
+			raLogger.Info("Action failed, continuing with the next action",
+				"resourceAction", ra.Name,
+				"actionIndex", i,
+				"action", action.Name,
+				"error", err.Error(),
+			)
 			continue
 		}
+		for name, value := range actionMetrics.Outputs {
+			progress.outputs[name] = value
+		}
+	}
+	if progress.executedActions == 0 {
+		e.finishPending(ctx, &ra, input, progress)
+		return nil
+	}
+	execErr := errors.Join(progress.errs...)
+	totals := progress.totals
+	executedActions := progress.executedActions
+	totalAttempts := totals.Attempts
+	totalNetworkRetries := totals.NetworkRetryCount
+	totalStatusRetries := totals.StatusRetryCount
+	totalBackoffMillis := totals.BackoffMillis
+	totalDurationMillis := totals.DurationMillis
+	lastHTTPStatus := totals.StatusCode
+
+	// ---- Status Update (CONFLICT-SAFE, BATCHED) ----
+	execRecord := opsv1alpha1.ExecutionRecord{
+		ResourceUID:       string(input.Obj.GetUID()),
+		Event:             string(input.Event),
+		ExecutedAt:        metav1.Now(),
+		CorrelationID:     progress.correlationID,
+		ActionCount:       executedActions,
+		Attempts:          totalAttempts,
+		RetryCount:        totalNetworkRetries + totalStatusRetries,
+		NetworkRetryCount: totalNetworkRetries,
+		StatusRetryCount:  totalStatusRetries,
+		BackoffMillis:     totalBackoffMillis,
+		DurationMillis:    totalDurationMillis,
+		LastHTTPStatus:    lastHTTPStatus,
+		Job:               totals.Job,
+	}
+	fillExecutionRecord(&execRecord, input, progress.lastActionIndex, execErr)
+	execRecord.ActionName = actionName(&ra, progress.lastActionIndex)
+	execRecord.FailedActions = progress.failedActions
+	execRecord.Targets = totals.Targets
+	execRecord.BatchSize = len(input.batch)
+	if execErr != nil && !stopped {
+		execRecord.Result = executionResultPartiallyFailed
+	}
 
-		// ---- Status Update (CONFLICT-SAFE) ----
-		execRecord := opsv1alpha1.ExecutionRecord{
-			ResourceUID:       string(input.Obj.GetUID()),
-			Event:             string(input.Event),
-			ExecutedAt:        metav1.Now(),
+	if usesActionExecutions(&ra) {
+		if err := createActionExecution(ctx, e.Client, &ra, execRecord, totals.Request, totals.Response, finalState(input)); err != nil {
+			logger.Error(err, "failed to record action execution", "resourceAction", ra.Name)
+			return err
+		}
+	}
+
+	err = e.updateStatus(ctx, &ra, statusUpdate{
+		record:    execRecord,
+		outcomes:  progress.outcomes,
+		err:       execErr,
+		throttled: e.quotas.throttledWithin(&ra, time.Now()),
+	})
+
+	if err != nil {
+		logger.Error(err, "failed to update status", "resourceAction", ra.Name)
+		return err
+	}
+	e.exportRecord(ctx, &ra, execRecord, totals.Request)
+	e.finishPending(ctx, &ra, input, progress)
+	for _, event := range input.events() {
+		if err := e.recordExecution(ctx, &ra, event); err != nil {
+			logger.Error(err, "failed to record execution for deduplication", "resourceAction", ra.Name)
+		}
+	}
+
+	if execErr != nil && executedActions > 0 {
+		observeHTTPExecution("failure", HTTPExecutionRecordMetrics{
 			ActionCount:       executedActions,
 			Attempts:          totalAttempts,
-			RetryCount:        totalNetworkRetries + totalStatusRetries,
 			NetworkRetryCount: totalNetworkRetries,
 			StatusRetryCount:  totalStatusRetries,
 			BackoffMillis:     totalBackoffMillis,
 			DurationMillis:    totalDurationMillis,
 			LastHTTPStatus:    lastHTTPStatus,
-			Job:               lastJobDetails,
-		}
+		})
+		e.emitEvent(&ra, corev1.EventTypeWarning, "ActionFailed", execRecord, execErr)
+		return &actionsFailedError{err: execErr}
+	}
 
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			var latest opsv1alpha1.ResourceAction
-			if err := e.Client.Get(ctx, client.ObjectKey{
-				Name:      ra.Name,
-				Namespace: ra.Namespace,
-			}, &latest); err != nil {
-				return err
-			}
+	if totalAttempts > 0 || lastHTTPStatus > 0 || totalDurationMillis > 0 {
+		observeHTTPExecution("success", HTTPExecutionRecordMetrics{
+			ActionCount:       executedActions,
+			Attempts:          totalAttempts,
+			NetworkRetryCount: totalNetworkRetries,
+			StatusRetryCount:  totalStatusRetries,
+			BackoffMillis:     totalBackoffMillis,
+			DurationMillis:    totalDurationMillis,
+			LastHTTPStatus:    lastHTTPStatus,
+		})
+	}
+	e.emitEvent(&ra, corev1.EventTypeNormal, "ActionSucceeded", execRecord, nil)
+	return nil
+}
 
-			latest.Status.Executions = append(latest.Status.Executions, execRecord)
+// storePending stores input as a pending delivery of ra unless it already
+// is one.
+func (e *K8sExecutor) storePending(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	input MatchInput,
+	progress *executionProgress,
+) error {
+	if progress.persisted || input.pending {
+		return nil
+	}
+	if err := e.persistPending(ctx, ra, input); err != nil {
+		return err
+	}
+	progress.persisted = true
+	return nil
+}
 
-			if execErr != nil {
-				latest.Status.LastError = execErr.Error()
-				setCondition(&latest, metav1.Condition{
-					Type:    "Ready",
-					Status:  metav1.ConditionFalse,
-					Reason:  "ActionFailed",
-					Message: execErr.Error(),
-				})
-			} else {
-				latest.Status.LastError = ""
-				setCondition(&latest, metav1.Condition{
-					Type:    "Ready",
-					Status:  metav1.ConditionTrue,
-					Reason:  "ActionSucceeded",
-					Message: "All actions executed successfully",
-				})
-			}
+// finishPending removes the pending delivery of a finished execution.
+func (e *K8sExecutor) finishPending(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	input MatchInput,
+	progress executionProgress,
+) {
+	if !progress.persisted && !input.pending {
+		return
+	}
+	if err := e.clearPending(ctx, ra, input); err != nil {
+		log.FromContext(ctx).Error(err, "failed to clear pending delivery", "resourceAction", ra.Name)
+	}
+}
 
-			return e.Client.Status().Update(ctx, &latest)
-		})
+// deferrable reports whether the retries of action can be deferred. Fallback
+// URLs, hedging, forEach and an overall timeout span all attempts, so those
+// actions retry in place.
+func deferrable(action opsv1alpha1.ActionSpec) bool {
+	return len(action.FallbackURLs) == 0 && action.Hedging == nil && action.ForEach == nil && action.OverallTimeout == ""
+}
 
-		if err != nil {
-			logger.Error(err, "failed to update status", "resourceAction", ra.Name)
-			return err
-		}
+// failureReason returns the Ready condition reason for a failed execution.
+func failureReason(err error) string {
+	var open *circuitOpenError
+	if errors.As(err, &open) {
+		return "CircuitOpen"
+	}
+	return "ActionFailed"
+}
 
-		if execErr != nil && executedActions > 0 {
-			observeHTTPExecution("failure", HTTPExecutionRecordMetrics{
-				ActionCount:       executedActions,
-				Attempts:          totalAttempts,
-				NetworkRetryCount: totalNetworkRetries,
-				StatusRetryCount:  totalStatusRetries,
-				BackoffMillis:     totalBackoffMillis,
-				DurationMillis:    totalDurationMillis,
-				LastHTTPStatus:    lastHTTPStatus,
-			})
-			e.emitEvent(&ra, corev1.EventTypeWarning, "ActionFailed", execRecord, execErr)
-			return execErr
+// forgetDeletedObject drops the dedup entries of a deleted object from every
+// ResourceAction that selects its kind.
+func (e *K8sExecutor) forgetDeletedObject(
+	ctx context.Context,
+	items []opsv1alpha1.ResourceAction,
+	input MatchInput,
+) {
+	for i := range items {
+		ra := &items[i]
+		if !matchesSelector(ra.Spec.Selector, input.GVK) || !input.targets(ra) {
+			continue
 		}
-
-		if totalAttempts > 0 || lastHTTPStatus > 0 || totalDurationMillis > 0 {
-			observeHTTPExecution("success", HTTPExecutionRecordMetrics{
-				ActionCount:       executedActions,
-				Attempts:          totalAttempts,
-				NetworkRetryCount: totalNetworkRetries,
-				StatusRetryCount:  totalStatusRetries,
-				BackoffMillis:     totalBackoffMillis,
-				DurationMillis:    totalDurationMillis,
-				LastHTTPStatus:    lastHTTPStatus,
-			})
+		if err := e.forgetObject(ctx, ra, input.Obj.GetUID()); err != nil {
+			log.FromContext(ctx).Error(err, "failed to clean up dedup entries", "resourceAction", ra.Name)
 		}
-		e.emitEvent(&ra, corev1.EventTypeNormal, "ActionSucceeded", execRecord, nil)
 	}
+}
 
-	return nil
+// ExecuteScheduled runs a single cron action of a ResourceAction against the
+// resource captured in input.
+func (e *K8sExecutor) ExecuteScheduled(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	input MatchInput,
+) (err error) {
+	ctx, span := tracer.Start(ctx, "Executor.ExecuteScheduled", trace.WithAttributes(
+		append(inputAttributes(input),
+			attribute.String("resourceaction.name", ra.Name),
+			attribute.Int("resourceaction.action_index", actionIndex),
+		)...,
+	))
+	defer func() { endSpan(span, err) }()
+	ctx, _ = withCorrelationID(ctx)
+
+	if actionIndex < 0 || actionIndex >= len(ra.Spec.Actions) {
+		return fmt.Errorf("action index %d out of range", actionIndex)
+	}
+	if !actionEnabled(ra.Spec.Actions[actionIndex]) {
+		return nil
+	}
+	if ra.Spec.DryRun || e.recording(&ra) {
+		log.FromContext(ctx).Info("Skipping scheduled action in dry run",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+		)
+		return nil
+	}
+	httpExec := e.httpExecutor()
+	jobExec, err := e.jobExecutorFor(ctx, &ra)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.executeAction(ctx, ra, actionIndex, ra.Spec.Actions[actionIndex], input, httpExec, jobExec)
+	return err
+}
+
+// httpExecutor returns an HTTPExecutor for one execution. It shares the
+// transports, Secret cache, circuit breakers and rate limits of e, so
+// connections are reused across events and ResourceActions.
+func (e *K8sExecutor) httpExecutor() *HTTPExecutor {
+	httpExec := NewHTTPExecutor(e.Client)
+	httpExec.breakers = e.breakers
+	httpExec.limiter = e.limiter
+	httpExec.urlPolicy = e.urlPolicy
+	httpExec.secrets = e.secrets
+	httpExec.vault = e.vault
+	httpExec.transports = e.transports
+	httpExec.specs = e.compiled
+	return httpExec
+}
+
+// jobExecutorFor returns a JobExecutor that creates the Jobs of ra in the
+// cluster selected by spec.clusterRef.
+func (e *K8sExecutor) jobExecutorFor(ctx context.Context, ra *opsv1alpha1.ResourceAction) (*JobExecutor, error) {
+	jobExec := NewJobExecutor(e.Client, e.Clientset)
+	if err := e.confinement.checkClusterRef(ra); err != nil {
+		return jobExec, err
+	}
+	cluster, err := e.clusters.clusterFor(ctx, ra)
+	if err != nil || cluster == nil {
+		return jobExec, err
+	}
+	jobExec.target = cluster.client
+	jobExec.clientset = cluster.clientset
+	return jobExec, nil
 }
 
 func (e *K8sExecutor) executeAction(
@@ -201,6 +705,46 @@ func (e *K8sExecutor) executeAction(
 	input MatchInput,
 	httpExec *HTTPExecutor,
 	jobExec *JobExecutor,
+) (HTTPExecutionMetrics, error) {
+	metrics, err := e.runAction(ctx, ra, actionIndex, action, input, httpExec, jobExec)
+	var later *retryLaterError
+	if errors.As(err, &later) {
+		// The action is not done yet.
+		return metrics, err
+	}
+	if err != nil && action.DeadLetter != nil {
+		// The action may have failed because ctx expired; publishing must
+		// still go through.
+		e.publishDeadLetter(context.WithoutCancel(ctx), ra, actionIndex, input, err)
+	}
+	if err == nil && action.RecordResultOn != "" {
+		outputs := maps.Clone(httpExec.outputs)
+		if outputs == nil {
+			outputs = map[string]string{}
+		}
+		maps.Copy(outputs, metrics.Outputs)
+		if recordErr := e.recordResult(ctx, &ra, action, input, outputs); recordErr != nil {
+			log.FromContext(ctx).Error(recordErr, "failed to record the result on the object",
+				"resourceAction", ra.Name,
+				"actionIndex", actionIndex,
+			)
+		}
+	}
+	e.runHandlers(ctx, ra, actionIndex, action, input, metrics, err, httpExec, jobExec)
+	if err != nil {
+		e.escalate(ctx, ra, actionIndex, action, input, metrics, err, httpExec, jobExec)
+	}
+	return metrics, err
+}
+
+func (e *K8sExecutor) runAction(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	action opsv1alpha1.ActionSpec,
+	input MatchInput,
+	httpExec *HTTPExecutor,
+	jobExec *JobExecutor,
 ) (HTTPExecutionMetrics, error) {
 	switch action.Type {
 	case "http":
@@ -208,8 +752,31 @@ func (e *K8sExecutor) executeAction(
 		if err != nil {
 			return HTTPExecutionMetrics{}, err
 		}
+		if err := e.addAuthHeader(ctx, headersResolved, action.Auth, ra.Namespace); err != nil {
+			return HTTPExecutionMetrics{}, err
+		}
+		addIdempotencyKey(headersResolved, action.IdempotencyKey, &ra, actionIndex, input)
 
-		return httpExec.ExecuteWithMetrics(ctx, action, ra.Namespace, input.Obj, headersResolved)
+		actionExec := httpExec.forAction(&ra, actionIndex)
+		actionExec.trigger = input.trigger
+		actionExec.changes = input.changes
+		actionExec.lookups = e.templateLookups(ctx, ra.Namespace)
+		actionExec.deferRetries = httpExec.deferRetries && input.trigger == nil && deferrable(action)
+		if !actionExec.deferRetries {
+			actionExec.firstAttempt = 0
+		}
+		actionExec.batch = input.batchObjects()
+		actionExec.group = input.group
+		if action.ForEach != nil {
+			return actionExec.executeForEach(ctx, action, ra.Namespace, input.Obj, headersResolved)
+		}
+		metrics, err := actionExec.ExecuteWithMetrics(ctx, action, ra.Namespace, input.Obj, headersResolved)
+		if err == nil && metrics.Captured != nil {
+			if storeErr := e.storeCapturedResponse(ctx, ra, actionIndex, input, metrics.Captured); storeErr != nil {
+				return metrics, fmt.Errorf("store captured response: %w", storeErr)
+			}
+		}
+		return metrics, err
 	case "job":
 		jobMetrics, err := jobExec.Execute(ctx, ra, actionIndex, action, input)
 		return HTTPExecutionMetrics{
@@ -234,8 +801,9 @@ func (e *K8sExecutor) emitEvent(
 	}
 
 	msg := fmt.Sprintf(
-		"event=%s actions=%d attempts=%d retries=%d networkRetries=%d statusRetries=%d backoffMs=%d durationMs=%d status=%d",
+		"event=%s correlationID=%s actions=%d attempts=%d retries=%d networkRetries=%d statusRetries=%d backoffMs=%d durationMs=%d status=%d",
 		execRecord.Event,
+		execRecord.CorrelationID,
 		execRecord.ActionCount,
 		execRecord.Attempts,
 		execRecord.RetryCount,
@@ -261,29 +829,207 @@ func (e *K8sExecutor) resolveHeaders(
 	resolved := make(map[string]string)
 
 	for key, val := range headers {
-		if val.SecretKeyRef != nil {
-			var secret corev1.Secret
-			if err := e.Client.Get(ctx, client.ObjectKey{
-				Name:      val.SecretKeyRef.Name,
-				Namespace: namespace,
-			}, &secret); err != nil {
+		if val.SecretKeyRef != nil || val.VaultRef != nil {
+			value, err := e.resolveValue(ctx, val, namespace)
+			if err != nil {
 				return nil, err
 			}
-
-			resolved[key] = string(secret.Data[val.SecretKeyRef.Key])
+			resolved[key] = value
 		}
 	}
 
 	return resolved, nil
 }
 
+// resolveValue reads the Secret or Vault key val refers to.
+func (e *K8sExecutor) resolveValue(ctx context.Context, val opsv1alpha1.ValueFrom, namespace string) (string, error) {
+	return resolveValueFrom(ctx, e.secrets, e.vault, val, namespace)
+}
+
+// resolveValueFrom reads the Secret or Vault key val refers to in namespace.
+func resolveValueFrom(
+	ctx context.Context,
+	secrets *secretCache,
+	vault *vaultClient,
+	val opsv1alpha1.ValueFrom,
+	namespace string,
+) (string, error) {
+	if val.VaultRef != nil {
+		return vault.read(ctx, namespace, val.VaultRef)
+	}
+	if val.SecretKeyRef == nil {
+		return "", nil
+	}
+	secret, err := secrets.get(ctx, client.ObjectKey{
+		Name:      val.SecretKeyRef.Name,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[val.SecretKeyRef.Key]), nil
+}
+
+// addAuthHeader sets the Authorization header configured by auth.
+func (e *K8sExecutor) addAuthHeader(
+	ctx context.Context,
+	headers map[string]string,
+	auth *opsv1alpha1.AuthSpec,
+	namespace string,
+) error {
+	switch {
+	case auth == nil:
+		return nil
+	case auth.Basic != nil:
+		username, err := e.resolveValue(ctx, auth.Basic.Username, namespace)
+		if err != nil {
+			return err
+		}
+		password, err := e.resolveValue(ctx, auth.Basic.Password, namespace)
+		if err != nil {
+			return err
+		}
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	case auth.ServiceAccountToken != nil:
+		token, err := e.tokens.token(ctx, namespace, auth.ServiceAccountToken)
+		if err != nil {
+			return err
+		}
+		headers["Authorization"] = "Bearer " + token
+	}
+	return nil
+}
+
+// actionEnabled reports whether action runs; actions are enabled unless
+// spec.actions[].enabled is false.
+func actionEnabled(action opsv1alpha1.ActionSpec) bool {
+	return action.Enabled == nil || *action.Enabled
+}
+
+// actionName returns the name of the action at actionIndex, or "" when it has
+// none.
+func actionName(ra *opsv1alpha1.ResourceAction, actionIndex int) string {
+	if actionIndex < 0 || actionIndex >= len(ra.Spec.Actions) {
+		return ""
+	}
+	return ra.Spec.Actions[actionIndex].Name
+}
+
+// fillExecutionRecord adds the target resource and outcome details to an
+// execution record.
+func fillExecutionRecord(
+	record *opsv1alpha1.ExecutionRecord,
+	input MatchInput,
+	actionIndex int,
+	execErr error,
+) {
+	record.ResourceName = input.Obj.GetName()
+	record.ResourceNamespace = input.Obj.GetNamespace()
+	record.ResourceAPIVersion = input.GVK.GroupVersion().String()
+	record.ResourceKind = input.GVK.Kind
+	if actionIndex >= 0 {
+		record.ActionIndex = ptrTo(actionIndex)
+	}
+	if execErr != nil {
+		record.Result = executionResultFailed
+		record.Error = execErr.Error()
+	} else {
+		record.Result = executionResultSucceeded
+	}
+}
+
+// pruneExecutions drops execution records older than spec.historyTTL and
+// keeps at most spec.historyLimit of the most recent records.
+func pruneExecutions(ra *opsv1alpha1.ResourceAction, now time.Time) {
+	records := ra.Status.Executions
+
+	if ra.Spec.HistoryTTL != "" {
+		if ttl, err := time.ParseDuration(ra.Spec.HistoryTTL); err == nil && ttl > 0 {
+			cutoff := now.Add(-ttl)
+			kept := records[:0]
+			for _, record := range records {
+				if record.ExecutedAt.Time.Before(cutoff) {
+					continue
+				}
+				kept = append(kept, record)
+			}
+			records = kept
+		}
+	}
+
+	if ra.Spec.HistoryLimit != nil && *ra.Spec.HistoryLimit > 0 {
+		limit := int(*ra.Spec.HistoryLimit)
+		if len(records) > limit {
+			records = records[len(records)-limit:]
+		}
+	}
+
+	ra.Status.Executions = records
+}
+
+// setActionState records the outcome of an action execution in
+// status.actionStates, keeping the list ordered by action index.
+func setActionState(ra *opsv1alpha1.ResourceAction, actionIndex int, execErr error, now metav1.Time) {
+	state := opsv1alpha1.ActionState{
+		ActionIndex:       actionIndex,
+		State:             actionStateReady,
+		LastExecutionTime: &now,
+	}
+	if actionIndex < len(ra.Spec.Actions) {
+		state.Name = ra.Spec.Actions[actionIndex].Name
+		state.Type = ra.Spec.Actions[actionIndex].Type
+	}
+	if execErr != nil {
+		state.State = actionStateFailing
+		state.Message = execErr.Error()
+		state.ConsecutiveFailures = 1
+		state.LastFailureTime = &now
+	} else {
+		state.LastSuccessfulTime = &now
+	}
+
+	states := make([]opsv1alpha1.ActionState, 0, len(ra.Status.ActionStates)+1)
+	for _, existing := range ra.Status.ActionStates {
+		// Drop states of actions that were removed from the spec.
+		if existing.ActionIndex >= len(ra.Spec.Actions) {
+			continue
+		}
+		if existing.ActionIndex != actionIndex {
+			states = append(states, existing)
+			continue
+		}
+		if existing.State == state.State {
+			state.LastTransitionTime = existing.LastTransitionTime
+		}
+		if execErr != nil {
+			// Failures before a PendingApproval state count as well.
+			state.ConsecutiveFailures = existing.ConsecutiveFailures + 1
+		}
+		if state.LastSuccessfulTime == nil {
+			state.LastSuccessfulTime = existing.LastSuccessfulTime
+		}
+		if state.LastFailureTime == nil {
+			state.LastFailureTime = existing.LastFailureTime
+		}
+	}
+	if state.LastTransitionTime == nil {
+		state.LastTransitionTime = &now
+	}
+	states = append(states, state)
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].ActionIndex < states[j].ActionIndex
+	})
+	ra.Status.ActionStates = states
+	observeActionState(ra.Namespace, ra.Name, state)
+}
+
 func alreadyExecuted(
 	ra *opsv1alpha1.ResourceAction,
 	uid types.UID,
 	event string,
 ) bool {
 	for _, exec := range ra.Status.Executions {
-		if exec.ResourceUID == string(uid) && exec.Event == event {
+		if exec.ResourceUID == string(uid) && exec.Event == event && !exec.DryRun {
 			return true
 		}
 	}
@@ -296,21 +1042,35 @@ func matchesSelector(sel opsv1alpha1.ResourceSelector, gvk schema.GroupVersionKi
 		sel.Kind == gvk.Kind
 }
 
-func matchesFilters(filter *opsv1alpha1.FilterSpec, input MatchInput) bool {
+// watchesNamespace reports whether obj lies in one of the namespaces the
+// ResourceAction watches. Cluster-scoped objects always match.
+func watchesNamespace(namespaces []string, obj *unstructured.Unstructured) bool {
+	if len(namespaces) == 0 || obj.GetNamespace() == "" {
+		return true
+	}
+	return slices.Contains(namespaces, obj.GetNamespace())
+}
+
+// matchesFilters reports whether spec.filters of ra match input.
+func matchesFilters(compiled *compiledSpec, ra *opsv1alpha1.ResourceAction, input MatchInput) bool {
+	if resultWriteBack(input) {
+		return false
+	}
+	filter := ra.Spec.Filters
 	if filter == nil {
 		return true
 	}
 	obj := input.Obj
 
 	if filter.NameRegex != "" {
-		re, err := regexp.Compile(filter.NameRegex)
+		re, err := compiled.compileRegexp("filters.nameRegex", filter.NameRegex)
 		if err != nil || !re.MatchString(obj.GetName()) {
 			return false
 		}
 	}
 
 	if filter.NamespaceRegex != "" {
-		re, err := regexp.Compile(filter.NamespaceRegex)
+		re, err := compiled.compileRegexp("filters.namespaceRegex", filter.NamespaceRegex)
 		if err != nil || !re.MatchString(obj.GetNamespace()) {
 			return false
 		}
@@ -325,6 +1085,17 @@ func matchesFilters(filter *opsv1alpha1.FilterSpec, input MatchInput) bool {
 		}
 	}
 
+	if filter.Alert != nil && !matchesAlert(filter.Alert, input) {
+		return false
+	}
+	if filter.GitHub != nil && !matchesGitHub(filter.GitHub, input) {
+		return false
+	}
+
+	if filter.ChangeType != "" && (input.Event != EventUpdate || !input.changed(filter.ChangeType)) {
+		return false
+	}
+
 	if len(filter.LabelChanges) > 0 {
 		if input.Event != EventUpdate || input.OldObj == nil {
 			return false
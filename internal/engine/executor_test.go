@@ -2,15 +2,30 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -25,6 +40,9 @@ func newTestExecutor(t *testing.T, objects ...client.Object) (*K8sExecutor, clie
 	if err := batchv1.AddToScheme(scheme); err != nil {
 		t.Fatalf("add batch scheme: %v", err)
 	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
 
 	cl := fake.NewClientBuilder().
 		WithScheme(scheme).
@@ -34,6 +52,16 @@ func newTestExecutor(t *testing.T, objects ...client.Object) (*K8sExecutor, clie
 	return NewK8sExecutor(cl, nil), cl
 }
 
+func newTestResourceAction(name, kind string, namespaces ...string) *opsv1alpha1.ResourceAction {
+	return &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:        opsv1alpha1.ResourceSelector{Version: "v1", Kind: kind},
+			WatchNamespaces: namespaces,
+		},
+	}
+}
+
 func newDeploymentInput(uid, name, namespace string) MatchInput {
 	return MatchInput{
 		Event: EventCreate,
@@ -286,6 +314,9 @@ func TestExecute_JobAction_CreatesBatchJob(t *testing.T) {
 	if !container.VolumeMounts[0].ReadOnly || !container.VolumeMounts[1].ReadOnly {
 		t.Fatalf("expected all volume mounts to be read-only by default")
 	}
+	if job.Annotations["resource-action-operator.yusaozdemir.de/correlation-id"] == "" {
+		t.Fatalf("expected correlation ID annotation on job")
+	}
 }
 
 func TestExecute_LabelChangeFilter_MatchesAbsentToTrue(t *testing.T) {
@@ -397,3 +428,1164 @@ func TestExecute_LabelChangeFilter_DoesNotMatchUnchangedLabel(t *testing.T) {
 		t.Fatalf("expected 0 jobs, got %d", len(jobs.Items))
 	}
 }
+
+func TestExecute_SuspendedResourceAction_DoesNotExecute(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-suspended",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events:  []string{"Create"},
+			Suspend: true,
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type: "job",
+					Job: &opsv1alpha1.JobSpec{
+						Image:  "bash:5.2",
+						Script: "echo hello",
+					},
+				},
+			},
+		},
+	}
+
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-suspended", "demo-suspended", "default")
+
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var jobs batchv1.JobList
+	if err := cl.List(context.Background(), &jobs); err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Fatalf("expected 0 jobs for suspended ResourceAction, got %d", len(jobs.Items))
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 0 {
+		t.Fatalf("expected no execution records, got %d", len(got.Status.Executions))
+	}
+}
+
+func TestExecute_NamespaceConfinement_SkipsOtherNamespaces(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-confined",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type: "job",
+					Job: &opsv1alpha1.JobSpec{
+						Image:  "bash:5.2",
+						Script: "echo hello",
+					},
+				},
+			},
+		},
+	}
+
+	exec, cl := newTestExecutor(t, ra)
+	exec.confinement = namespaceConfinement{enabled: true}
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-other", "demo-other", "team-a")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	var jobs batchv1.JobList
+	if err := cl.List(context.Background(), &jobs); err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Fatalf("expected 0 jobs for an object in another namespace, got %d", len(jobs.Items))
+	}
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-own", "demo-own", "default")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if err := cl.List(context.Background(), &jobs); err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	if len(jobs.Items) != 1 {
+		t.Fatalf("expected 1 job for an object in the own namespace, got %d", len(jobs.Items))
+	}
+}
+
+func TestExecute_RecordsOutcomeDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-outcome",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       srv.URL,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				},
+			},
+		},
+	}
+
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-outcome", "demo-outcome", "team-a")
+
+	if err := exec.Execute(context.Background(), input); err == nil {
+		t.Fatalf("expected execution error, got nil")
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 1 {
+		t.Fatalf("expected 1 execution record, got %d", len(got.Status.Executions))
+	}
+	record := got.Status.Executions[0]
+	if record.ResourceName != "demo-outcome" || record.ResourceNamespace != "team-a" {
+		t.Fatalf("unexpected resource name/namespace: %+v", record)
+	}
+	if record.ResourceAPIVersion != "apps/v1" || record.ResourceKind != "Deployment" {
+		t.Fatalf("unexpected resource GVK: %+v", record)
+	}
+	if record.ActionIndex == nil || *record.ActionIndex != 0 {
+		t.Fatalf("expected actionIndex 0, got %v", record.ActionIndex)
+	}
+	if record.Result != "Failed" || record.Error == "" {
+		t.Fatalf("expected failed result with error, got %+v", record)
+	}
+	if record.LastHTTPStatus != http.StatusBadRequest {
+		t.Fatalf("expected last HTTP status 400, got %d", record.LastHTTPStatus)
+	}
+	if got.Status.LastExecutionTime == nil || !got.Status.LastExecutionTime.Equal(&record.ExecutedAt) {
+		t.Fatalf("expected lastExecutionTime %v, got %v", record.ExecutedAt, got.Status.LastExecutionTime)
+	}
+}
+
+func TestExecute_IsolatesFailingResourceActions(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "broken", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	var delivered atomic.Int32
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer healthy.Close()
+
+	newRA := func(name, url string) *opsv1alpha1.ResourceAction {
+		return &opsv1alpha1.ResourceAction{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: opsv1alpha1.ResourceActionSpec{
+				Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Events:   []string{"Create"},
+				Actions: []opsv1alpha1.ActionSpec{{
+					Type:      "http",
+					URL:       url,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				}},
+			},
+		}
+	}
+	exec, cl := newTestExecutor(t, newRA("a-broken", failing.URL), newRA("b-healthy", healthy.URL))
+
+	err := exec.Execute(context.Background(), newDeploymentInput("uid-isolated", "demo", "default"))
+	if err == nil || !strings.Contains(err.Error(), "default/a-broken") {
+		t.Fatalf("Execute() error = %v, want error of a-broken", err)
+	}
+	if delivered.Load() != 1 {
+		t.Fatalf("healthy ResourceAction delivered %d requests, want 1", delivered.Load())
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "b-healthy"}, &got); err != nil {
+		t.Fatalf("get ResourceAction: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, "Ready") || len(got.Status.Executions) != 1 {
+		t.Fatalf("healthy ResourceAction status = %+v, want Ready and one execution", got.Status)
+	}
+}
+
+func TestExecute_PriorityOrderAndStopOnMatch(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer srv.Close()
+
+	newRA := func(name string, priority int32, events ...string) *opsv1alpha1.ResourceAction {
+		return &opsv1alpha1.ResourceAction{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: opsv1alpha1.ResourceActionSpec{
+				Selector:        opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Events:          events,
+				Priority:        priority,
+				ExecutionPolicy: "EveryEvent",
+				Actions: []opsv1alpha1.ActionSpec{{
+					Type:      "http",
+					URL:       srv.URL + "/" + name,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				}},
+			},
+		}
+	}
+	stopper := newRA("stopper", 5, "Update")
+	stopper.Spec.StopOnMatch = true
+	exec, _ := newTestExecutor(t,
+		newRA("b-low", 0, "Create", "Update"),
+		newRA("a-low", 0, "Create", "Update"),
+		newRA("high", 10, "Create", "Update"),
+		stopper,
+	)
+
+	input := newDeploymentInput("uid-priority", "demo", "default")
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := []string{"high", "a-low", "b-low"}; !slices.Equal(order, want) {
+		t.Fatalf("Create order = %v, want %v", order, want)
+	}
+
+	// stopper matches Update and holds back the lower priorities.
+	order = nil
+	input.Event = EventUpdate
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := []string{"high", "stopper"}; !slices.Equal(order, want) {
+		t.Fatalf("Update order = %v, want %v", order, want)
+	}
+}
+
+func TestClearHistory_RunsActionsAgain(t *testing.T) {
+	var delivered atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-clear", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-clear", "demo", "default")
+
+	for i := 0; i < 2; i++ {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("execute #%d: %v", i+1, err)
+		}
+	}
+	if delivered.Load() != 1 {
+		t.Fatalf("delivered %d requests before clearing, want 1", delivered.Load())
+	}
+
+	if err := exec.ClearHistory(context.Background(), ra); err != nil {
+		t.Fatalf("ClearHistory() error = %v", err)
+	}
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(ra), &got); err != nil {
+		t.Fatalf("get ResourceAction: %v", err)
+	}
+	if len(got.Status.Executions) != 0 || len(got.Status.ActionStates) != 0 {
+		t.Fatalf("ClearHistory() kept status %+v", got.Status)
+	}
+
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("execute after clearing: %v", err)
+	}
+	if delivered.Load() != 2 {
+		t.Fatalf("delivered %d requests after clearing, want 2", delivered.Load())
+	}
+}
+
+func TestExecute_ActionExecutionHistoryMode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("accepted"))
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-audit",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events:      []string{"Create"},
+			HistoryMode: "ActionExecution",
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       srv.URL,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				},
+			},
+		},
+	}
+
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-audit", "demo-audit", "default")
+
+	for i := 0; i < 2; i++ {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("execute #%d: %v", i+1, err)
+		}
+	}
+
+	var executions opsv1alpha1.ActionExecutionList
+	if err := cl.List(context.Background(), &executions, client.InNamespace("default")); err != nil {
+		t.Fatalf("list action executions: %v", err)
+	}
+	if len(executions.Items) != 1 {
+		t.Fatalf("expected 1 ActionExecution, got %d", len(executions.Items))
+	}
+	ae := executions.Items[0]
+	if ae.Spec.ResourceAction != ra.Name || ae.Spec.Result != "Succeeded" {
+		t.Fatalf("unexpected ActionExecution spec: %+v", ae.Spec)
+	}
+	if len(ae.OwnerReferences) != 1 || ae.OwnerReferences[0].Name != ra.Name {
+		t.Fatalf("expected owner reference to ResourceAction, got %+v", ae.OwnerReferences)
+	}
+	if ae.Spec.Request == nil || ae.Spec.Request.Method != "POST" {
+		t.Fatalf("expected recorded POST request, got %+v", ae.Spec.Request)
+	}
+	if ae.Spec.Response == nil || ae.Spec.Response.StatusCode != http.StatusOK || ae.Spec.Response.Body != "accepted" {
+		t.Fatalf("unexpected recorded response: %+v", ae.Spec.Response)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 0 {
+		t.Fatalf("expected no status execution records, got %d", len(got.Status.Executions))
+	}
+}
+
+func TestResourceActionLabel(t *testing.T) {
+	if got := resourceActionLabel("ra-audit"); got != "ra-audit" {
+		t.Fatalf("resourceActionLabel() = %q, want the name", got)
+	}
+	long := strings.Repeat("a", 60) + ".team-a.example"
+	other := strings.Repeat("a", 60) + ".team-b.example"
+	got := resourceActionLabel(long)
+	if errs := validation.IsValidLabelValue(got); len(errs) > 0 {
+		t.Fatalf("resourceActionLabel() = %q is not a label value: %v", got, errs)
+	}
+	if got == resourceActionLabel(other) || got != resourceActionLabel(long) {
+		t.Fatalf("resourceActionLabel() = %q, want a stable value distinct per name", got)
+	}
+}
+
+func TestPruneExecutions(t *testing.T) {
+	now := time.Now()
+	limit := int32(2)
+	ra := &opsv1alpha1.ResourceAction{
+		Spec: opsv1alpha1.ResourceActionSpec{
+			HistoryLimit: &limit,
+			HistoryTTL:   "1h",
+		},
+		Status: opsv1alpha1.ResourceActionStatus{
+			Executions: []opsv1alpha1.ExecutionRecord{
+				{ResourceUID: "expired", ExecutedAt: metav1.NewTime(now.Add(-2 * time.Hour))},
+				{ResourceUID: "old", ExecutedAt: metav1.NewTime(now.Add(-30 * time.Minute))},
+				{ResourceUID: "recent", ExecutedAt: metav1.NewTime(now.Add(-10 * time.Minute))},
+				{ResourceUID: "latest", ExecutedAt: metav1.NewTime(now)},
+			},
+		},
+	}
+
+	pruneExecutions(ra, now)
+
+	if len(ra.Status.Executions) != 2 {
+		t.Fatalf("expected 2 records after pruning, got %d", len(ra.Status.Executions))
+	}
+	if ra.Status.Executions[0].ResourceUID != "recent" || ra.Status.Executions[1].ResourceUID != "latest" {
+		t.Fatalf("expected most recent records to be kept, got %+v", ra.Status.Executions)
+	}
+}
+
+func TestExecute_SkipsDisabledActionsAndRecordsNames(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	newAction := func(name string) opsv1alpha1.ActionSpec {
+		return opsv1alpha1.ActionSpec{
+			Name:      name,
+			Type:      "http",
+			URL:       srv.URL + "/" + name,
+			URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		}
+	}
+	disabled := newAction("ticket")
+	disabled.Enabled = ptrTo(false)
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-named", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions:  []opsv1alpha1.ActionSpec{newAction("notify"), disabled, newAction("audit")},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-named", "demo", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := []string{"/notify", "/audit"}; !slices.Equal(paths, want) {
+		t.Fatalf("requested paths = %v, want %v", paths, want)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(ra), &got); err != nil {
+		t.Fatalf("get ResourceAction: %v", err)
+	}
+	if len(got.Status.ActionStates) != 2 ||
+		got.Status.ActionStates[0].Name != "notify" ||
+		got.Status.ActionStates[1].ActionIndex != 2 || got.Status.ActionStates[1].Name != "audit" {
+		t.Fatalf("action states = %+v, want notify and audit", got.Status.ActionStates)
+	}
+	if len(got.Status.Executions) != 1 || got.Status.Executions[0].ActionName != "audit" {
+		t.Fatalf("executions = %+v, want one record of audit", got.Status.Executions)
+	}
+}
+
+func TestExecute_BasicAuth(t *testing.T) {
+	var username, password string
+	var ok bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok = r.BasicAuth()
+	}))
+	defer srv.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "receiver-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"user": []byte("operator"), "pass": []byte("s3cr:t")},
+	}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-basic", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Auth: &opsv1alpha1.AuthSpec{Basic: &opsv1alpha1.BasicAuth{
+					Username: opsv1alpha1.ValueFrom{SecretKeyRef: &opsv1alpha1.SecretKeyRef{Name: secret.Name, Key: "user"}},
+					Password: opsv1alpha1.ValueFrom{SecretKeyRef: &opsv1alpha1.SecretKeyRef{Name: secret.Name, Key: "pass"}},
+				}},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra, secret)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-basic", "demo", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !ok || username != "operator" || password != "s3cr:t" {
+		t.Fatalf("basic auth = %q/%q (%v), want operator/s3cr:t", username, password, ok)
+	}
+}
+
+func TestSetActionState(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Actions: []opsv1alpha1.ActionSpec{{Type: "http"}, {Type: "job"}},
+		},
+		Status: opsv1alpha1.ResourceActionStatus{
+			ActionStates: []opsv1alpha1.ActionState{
+				{ActionIndex: 5, State: "Ready"},
+			},
+		},
+	}
+	first := metav1.NewTime(time.Now().Add(-time.Minute))
+	second := metav1.NewTime(time.Now())
+
+	setActionState(ra, 1, errors.New("boom"), first)
+	setActionState(ra, 0, nil, first)
+	setActionState(ra, 1, errors.New("boom again"), second)
+
+	if len(ra.Status.ActionStates) != 2 {
+		t.Fatalf("expected 2 action states, got %+v", ra.Status.ActionStates)
+	}
+	ready, failing := ra.Status.ActionStates[0], ra.Status.ActionStates[1]
+	if ready.ActionIndex != 0 || ready.State != "Ready" || ready.Type != "http" {
+		t.Fatalf("unexpected state for action 0: %+v", ready)
+	}
+	if failing.ActionIndex != 1 || failing.State != "Failing" || failing.Message != "boom again" {
+		t.Fatalf("unexpected state for action 1: %+v", failing)
+	}
+	if failing.ConsecutiveFailures != 2 {
+		t.Fatalf("expected 2 consecutive failures, got %d", failing.ConsecutiveFailures)
+	}
+	if !failing.LastTransitionTime.Equal(&first) || !failing.LastExecutionTime.Equal(&second) {
+		t.Fatalf("unexpected timestamps: transition=%v execution=%v", failing.LastTransitionTime, failing.LastExecutionTime)
+	}
+
+	third := metav1.NewTime(second.Add(time.Minute))
+	setActionState(ra, 1, nil, third)
+	got := ra.Status.ActionStates[1]
+	if got.State != "Ready" || got.ConsecutiveFailures != 0 || got.Message != "" {
+		t.Fatalf("expected action 1 to recover, got %+v", got)
+	}
+	if !got.LastSuccessfulTime.Equal(&third) || !got.LastFailureTime.Equal(&second) {
+		t.Fatalf("unexpected success/failure times: success=%v failure=%v", got.LastSuccessfulTime, got.LastFailureTime)
+	}
+}
+
+func TestExecute_PropagatesCorrelationID(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Correlation-ID")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-correlation",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       srv.URL,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				},
+			},
+		},
+	}
+
+	exec, cl := newTestExecutor(t, ra)
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-correlation", "demo", "default")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 1 {
+		t.Fatalf("expected 1 execution record, got %d", len(got.Status.Executions))
+	}
+	id := got.Status.Executions[0].CorrelationID
+	if id == "" {
+		t.Fatalf("expected correlation ID on execution record")
+	}
+	if header != id {
+		t.Fatalf("expected X-Correlation-ID %q, got %q", id, header)
+	}
+}
+
+func TestExecute_ResponseCapture(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"TICKET-42","links":{"self":"https://tickets.example/42"}}`))
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-capture",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       srv.URL,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					ResponseCapture: &opsv1alpha1.ResponseCaptureSpec{
+						StatusCode: true,
+						Fields: map[string]string{
+							"ticketID": "{.id}",
+							"missing":  "{.nope}",
+						},
+					},
+				},
+				{
+					Type:      "http",
+					URL:       srv.URL,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					ResponseCapture: &opsv1alpha1.ResponseCaptureSpec{
+						Target:        "ConfigMap",
+						ConfigMapName: "tickets",
+						Fields:        map[string]string{"link": "{.links.self}"},
+					},
+				},
+			},
+		},
+	}
+
+	exec, cl := newTestExecutor(t, ra)
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-capture", "demo", "default")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.CapturedResponses) != 1 {
+		t.Fatalf("expected 1 captured response in status, got %+v", got.Status.CapturedResponses)
+	}
+	values := got.Status.CapturedResponses[0].Values
+	if values["ticketID"] != "TICKET-42" || values["statusCode"] != "201" {
+		t.Fatalf("unexpected captured values: %+v", values)
+	}
+	if _, ok := values["missing"]; ok {
+		t.Fatalf("expected missing field to be left out, got %+v", values)
+	}
+
+	var cm corev1.ConfigMap
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: "tickets", Namespace: "default"}, &cm); err != nil {
+		t.Fatalf("get configmap: %v", err)
+	}
+	if cm.Data["link"] != "https://tickets.example/42" {
+		t.Fatalf("unexpected configmap data: %+v", cm.Data)
+	}
+}
+
+func TestExecute_PublishesDeadLetters(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	// The sink is only reachable with the TLS settings of the action.
+	var letter DeadLetter
+	var raw map[string]interface{}
+	sink := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &letter)
+		_ = json.Unmarshal(body, &raw)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	unsafe := &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-dead-letter",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       failing.URL,
+					URLPolicy: unsafe,
+					TLS:       &opsv1alpha1.TLSSpec{InsecureSkipVerify: true},
+					DeadLetter: &opsv1alpha1.DeadLetterSpec{
+						Type:      "HTTP",
+						URL:       sink.URL,
+						URLPolicy: unsafe,
+					},
+				},
+			},
+		},
+	}
+
+	exec, _ := newTestExecutor(t, ra)
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-dl", "demo-dl", "team-a")); err == nil {
+		t.Fatalf("expected execution error, got nil")
+	}
+
+	if letter.ResourceAction != ra.Name || letter.Event != "Create" || letter.ActionIndex != 0 {
+		t.Fatalf("unexpected dead letter: %+v", letter)
+	}
+	if letter.Resource.Name != "demo-dl" || letter.Resource.UID != "uid-dl" || letter.Error == "" {
+		t.Fatalf("unexpected dead letter resource or error: %+v", letter)
+	}
+	if letter.CorrelationID == "" {
+		t.Fatalf("expected correlation ID on dead letter")
+	}
+	if _, ok := raw["object"]; ok {
+		t.Fatalf("dead letter = %v, want only a reference to the object", raw)
+	}
+
+	ra.Spec.Actions[0].DeadLetter = &opsv1alpha1.DeadLetterSpec{Type: "ConfigMap", ConfigMapName: "failed-hooks"}
+	ra.ResourceVersion = ""
+	exec, cl := newTestExecutor(t, ra)
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-dl", "demo-dl", "team-a")); err == nil {
+		t.Fatalf("expected execution error, got nil")
+	}
+	var cm corev1.ConfigMap
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: "failed-hooks", Namespace: "default"}, &cm); err != nil {
+		t.Fatalf("get dead-letter configmap: %v", err)
+	}
+	if len(cm.Data) != 1 {
+		t.Fatalf("expected 1 dead-letter entry, got %d", len(cm.Data))
+	}
+}
+
+func TestExecute_ExecutionPolicy(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-policy",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events:          []string{"Create"},
+			ExecutionPolicy: "OncePerGeneration",
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       srv.URL,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				},
+			},
+		},
+	}
+
+	exec, cl := newTestExecutor(t, ra)
+	ctx := context.Background()
+	input := newDeploymentInput("uid-policy", "demo-policy", "team-a")
+	input.Obj.SetGeneration(1)
+
+	if err := exec.Execute(ctx, input); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	// Wiping the status must not re-fire the action.
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(ctx, types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	got.Status.Executions = nil
+	if err := cl.Status().Update(ctx, &got); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	if err := exec.Execute(ctx, input); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call for the same generation, got %d", calls)
+	}
+
+	input.Obj.SetGeneration(2)
+	if err := exec.Execute(ctx, input); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls after a generation bump, got %d", calls)
+	}
+
+	var cm corev1.ConfigMap
+	if err := cl.Get(ctx, types.NamespacedName{Name: "ra-policy-dedup", Namespace: "default"}, &cm); err != nil {
+		t.Fatalf("get dedup configmap: %v", err)
+	}
+	if cm.Data["uid-policy.create"] != "2" {
+		t.Fatalf("expected recorded generation 2, got %q", cm.Data["uid-policy.create"])
+	}
+}
+
+func TestRecordExecution_PrunesEntriesOfDeletedObjects(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-prune", Namespace: "default", UID: "ra-uid"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Version: "v1", Kind: "ConfigMap"},
+		},
+	}
+	data := map[string]string{
+		"uid-live.create":               "0",
+		"name.create.default.recreated": "uid-gone/2026-01-01T00:00:00Z",
+	}
+	for i := range maxDedupEntries {
+		data[fmt.Sprintf("uid-gone-%d.create", i)] = "0"
+	}
+	store := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ra-prune-dedup", Namespace: "default"}, Data: data}
+	live := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "uid-live"}}
+	recorded := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "recorded", Namespace: "default", UID: "uid-recorded"}}
+	exec, cl := newTestExecutor(t, ra, store, live, recorded)
+	ctx := context.Background()
+
+	input := MatchInput{Event: EventCreate, GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}}
+	input.Obj = &unstructured.Unstructured{}
+	input.Obj.SetUID("uid-recorded")
+	if err := exec.recordExecution(ctx, ra, input); err != nil {
+		t.Fatalf("recordExecution() error = %v", err)
+	}
+
+	var cm corev1.ConfigMap
+	if err := cl.Get(ctx, types.NamespacedName{Name: "ra-prune-dedup", Namespace: "default"}, &cm); err != nil {
+		t.Fatalf("get dedup configmap: %v", err)
+	}
+	want := []string{"name.create.default.recreated", "uid-live.create", "uid-recorded.create"}
+	if got := sortedKeys(cm.Data); !slices.Equal(got, want) {
+		t.Fatalf("dedup keys = %d entries, want %q", len(got), want)
+	}
+}
+
+func TestExecute_PassesOutputsToLaterActions(t *testing.T) {
+	var mu sync.Mutex
+	var notified string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tickets":
+			_, _ = w.Write([]byte(`{"id":"42","links":{"self":"https://tickets.example/42"}}`))
+		case "/notify":
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			notified = string(body)
+			mu.Unlock()
+		}
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-outputs", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       srv.URL + "/tickets",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Outputs:   map[string]string{"ticketURL": "{.links.self}"},
+				},
+				{
+					Type:      "http",
+					URL:       srv.URL + "/notify",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Body: &opsv1alpha1.TemplateSpec{
+						Template: `{"text":"{{ .metadata.name }}: {{ output "ticketURL" }}"}`,
+					},
+				},
+			},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-outputs", "demo", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := `{"text":"demo: https://tickets.example/42"}`; notified != want {
+		t.Fatalf("notify body = %q, want %q", notified, want)
+	}
+}
+
+func TestExecute_ReusesConnectionsAcrossExecutions(t *testing.T) {
+	var connections atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	newRA := func(name string) *opsv1alpha1.ResourceAction {
+		return &opsv1alpha1.ResourceAction{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: opsv1alpha1.ResourceActionSpec{
+				Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Events:   []string{"Create"},
+				Actions: []opsv1alpha1.ActionSpec{{
+					Type:      "http",
+					URL:       srv.URL,
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				}},
+			},
+		}
+	}
+	exec, _ := newTestExecutor(t, newRA("ra-reuse-a"), newRA("ra-reuse-b"))
+
+	for i := 0; i < 3; i++ {
+		if err := exec.Execute(context.Background(), newDeploymentInput(fmt.Sprintf("uid-reuse-%d", i), "demo", "default")); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if got := connections.Load(); got != 1 {
+		t.Fatalf("expected one connection for all executions and ResourceActions, got %d", got)
+	}
+}
+
+func TestExecute_SendsIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-idempotency", Namespace: "default", UID: "ra-uid"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:           "http",
+				URL:            srv.URL,
+				URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Retry:          &opsv1alpha1.RetrySpec{MaxAttempts: 2, Backoff: "1ms", MaxBackoff: "2ms"},
+				IdempotencyKey: &opsv1alpha1.IdempotencyKeySpec{},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	input := newDeploymentInput("uid-idempotency", "demo", "default")
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" {
+		t.Fatalf("expected two requests with a key, got %q", keys)
+	}
+	if keys[0] != keys[1] {
+		t.Fatalf("expected the retry to send the same key, got %q", keys)
+	}
+	if want := idempotencyKey(ra, 0, input); keys[0] != want {
+		t.Fatalf("key = %q, want %q", keys[0], want)
+	}
+}
+
+func TestExecute_ContinueOnError(t *testing.T) {
+	var mu sync.Mutex
+	var called []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		called = append(called, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	newAction := func(path string, continueOnError bool) opsv1alpha1.ActionSpec {
+		return opsv1alpha1.ActionSpec{
+			Type:            "http",
+			URL:             srv.URL + path,
+			URLPolicy:       &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			ContinueOnError: continueOnError,
+		}
+	}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-continue", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				newAction("/broken", true),
+				newAction("/notify", false),
+				newAction("/broken", false),
+				newAction("/skipped", false),
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-continue", "demo", "default")); err == nil {
+		t.Fatalf("expected the failed actions to fail the execution")
+	}
+	if want := []string{"/broken", "/notify", "/broken"}; !slices.Equal(called, want) {
+		t.Fatalf("called %v, want %v", called, want)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	record := got.Status.Executions[0]
+	if record.Result != executionResultFailed || !slices.Equal(record.FailedActions, []int{0, 2}) ||
+		record.ActionCount != 3 || *record.ActionIndex != 2 {
+		t.Fatalf("record = %+v, want failed at action 2 after failing action 0", record)
+	}
+	if len(got.Status.ActionStates) != 3 || got.Status.ActionStates[1].State != actionStateReady {
+		t.Fatalf("action states = %+v, want the state of the action after the failure", got.Status.ActionStates)
+	}
+}
+
+func TestExecute_ContinueOnError_PartiallyFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-partial", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:            "http",
+					URL:             srv.URL + "/broken",
+					URLPolicy:       &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					ContinueOnError: true,
+				},
+				{
+					Type:      "http",
+					URL:       srv.URL + "/notify",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				},
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-partial", "demo", "default")); err == nil {
+		t.Fatalf("expected the failed action to fail the execution")
+	}
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	record := got.Status.Executions[0]
+	if record.Result != executionResultPartiallyFailed || !slices.Equal(record.FailedActions, []int{0}) || record.ActionCount != 2 {
+		t.Fatalf("record = %+v, want PartiallyFailed with action 0 failed", record)
+	}
+}
+
+func TestExecute_RunsHandlers(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string][]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] = append(bodies[r.URL.Path], string(body))
+		mu.Unlock()
+		if r.URL.Path == "/webhook" {
+			http.Error(w, "receiver down", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	newAction := func(name, path, mode string) opsv1alpha1.ActionSpec {
+		return opsv1alpha1.ActionSpec{
+			Name:      name,
+			Type:      "http",
+			Mode:      mode,
+			URL:       srv.URL + path,
+			URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		}
+	}
+	webhook := newAction("webhook", "/webhook", "")
+	webhook.OnSuccess = []string{"audit"}
+	webhook.OnFailure = []string{"slack"}
+	webhook.ContinueOnError = true
+	ping := newAction("", "/ping", "")
+	ping.OnSuccess = []string{"audit"}
+	audit := newAction("audit", "/audit", "handler")
+	audit.Body = &opsv1alpha1.TemplateSpec{Template: `{{ triggeredBy }} succeeded{{ failureReason }}`}
+	slack := newAction("slack", "/slack", "handler")
+	slack.Body = &opsv1alpha1.TemplateSpec{
+		Template: `{{ .metadata.name }}: {{ triggeredBy }} failed: {{ failureReason }}`,
+	}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-handlers", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions:  []opsv1alpha1.ActionSpec{webhook, slack, audit, ping},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-handlers", "demo", "default")); err == nil {
+		t.Fatalf("expected the webhook failure to fail the execution")
+	}
+	// The audit handler runs once, for the action without a name.
+	if got := bodies["/audit"]; !slices.Equal(got, []string{"3 succeeded"}) {
+		t.Fatalf("audit bodies = %q, want only the onSuccess handler of action 3", got)
+	}
+	if got := bodies["/slack"]; len(got) != 1 || !strings.HasPrefix(got[0], "demo: webhook failed: http call failed: status=400") {
+		t.Fatalf("slack bodies = %q, want the failure reason of the webhook", got)
+	}
+}
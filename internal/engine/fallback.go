@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// executeWithFallbacks tries the URL of an action and then its fallback URLs
+// in order, until one succeeds. With hedging, the URL and the first fallback
+// URL are raced first. The metrics add up all attempts; the response is the
+// one of the last URL tried.
+func (h *HTTPExecutor) executeWithFallbacks(
+	ctx context.Context,
+	action opsv1alpha1.ActionSpec,
+	raNamespace string,
+	obj *unstructured.Unstructured,
+	headers map[string]string,
+) (HTTPExecutionMetrics, error) {
+	logger := log.FromContext(ctx)
+	startedAt := time.Now()
+	targets := append([]string{action.URL}, action.FallbackURLs...)
+
+	var metrics HTTPExecutionMetrics
+	var errs []error
+	if action.Hedging != nil {
+		delay := parseDurationDefault(action.Hedging.Delay, 0)
+		m, err := h.executeHedged(ctx, action, targets[0], targets[1], delay, raNamespace, obj, headers)
+		metrics = addMetrics(metrics, m)
+		if err == nil {
+			metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+			return metrics, nil
+		}
+		errs = append(errs, err)
+		targets = targets[2:]
+	}
+
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		if len(errs) > 0 {
+			logger.Info("HTTP action failed, trying fallback URL", "url", target)
+		}
+		m, err := h.executeURL(ctx, withURL(action, target), raNamespace, obj, headers)
+		metrics = addMetrics(metrics, m)
+		if err == nil {
+			metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+			return metrics, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", target, err))
+	}
+	metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+	return metrics, errors.Join(errs...)
+}
+
+// executeHedged sends the request to primary and, after delay unless primary
+// succeeded by then, to secondary. The first success wins and cancels the
+// other request.
+func (h *HTTPExecutor) executeHedged(
+	ctx context.Context,
+	action opsv1alpha1.ActionSpec,
+	primary, secondary string,
+	delay time.Duration,
+	raNamespace string,
+	obj *unstructured.Unstructured,
+	headers map[string]string,
+) (HTTPExecutionMetrics, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		target  string
+		metrics HTTPExecutionMetrics
+		err     error
+	}
+	results := make(chan result, 2)
+	run := func(target string, wait time.Duration, seed int64) {
+		if wait > 0 {
+			if err := sleepContext(ctx, wait); err != nil {
+				results <- result{target: target, err: err}
+				return
+			}
+		}
+		// The requests run concurrently, so each needs its own rng.
+		c := *h
+		c.rng = rand.New(rand.NewSource(seed))
+		m, err := c.executeURL(ctx, withURL(action, target), raNamespace, obj, headers)
+		results <- result{target: target, metrics: m, err: err}
+	}
+	go run(primary, 0, h.rng.Int63())
+	go run(secondary, delay, h.rng.Int63())
+
+	var metrics HTTPExecutionMetrics
+	var errs []error
+	for range 2 {
+		r := <-results
+		metrics = addMetrics(metrics, r.metrics)
+		if r.err == nil {
+			return metrics, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", r.target, r.err))
+	}
+	return metrics, errors.Join(errs...)
+}
+
+func withURL(action opsv1alpha1.ActionSpec, url string) opsv1alpha1.ActionSpec {
+	action.URL = url
+	return action
+}
+
+// addMetrics adds the attempts, retries and backoff of next to total and
+// takes the request and response of next.
+func addMetrics(total, next HTTPExecutionMetrics) HTTPExecutionMetrics {
+	total.Attempts += next.Attempts
+	total.NetworkRetryCount += next.NetworkRetryCount
+	total.StatusRetryCount += next.StatusRetryCount
+	total.BackoffMillis += next.BackoffMillis
+	if next.Request == nil {
+		// Nothing was sent, for example because the hedging delay was cut
+		// short.
+		return total
+	}
+	total.StatusCode = next.StatusCode
+	total.Request = next.Request
+	total.Response = next.Response
+	total.Captured = next.Captured
+	total.Outputs = next.Outputs
+	return total
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHTTPExecutorExecuteWithMetrics_FallbackURLs(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "demo",
+				"namespace": "default",
+			},
+		},
+	}
+	action := opsv1alpha1.ActionSpec{
+		Type:         "http",
+		URL:          failing.URL,
+		FallbackURLs: []string{failing.URL + "/second", healthy.URL},
+		URLPolicy:    &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		Timeout:      "5s",
+		Retry:        &opsv1alpha1.RetrySpec{MaxAttempts: 2, Backoff: "1ms", MaxBackoff: "2ms"},
+	}
+
+	metrics, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if err != nil {
+		t.Fatalf("expected the last fallback URL to succeed, got %v", err)
+	}
+	if metrics.Attempts != 5 || metrics.StatusRetryCount != 2 {
+		t.Fatalf("expected 5 attempts and 2 retries, got %d and %d", metrics.Attempts, metrics.StatusRetryCount)
+	}
+	if metrics.StatusCode != http.StatusOK || metrics.Request.URL != healthy.URL {
+		t.Fatalf("expected the response of %s, got status %d from %s", healthy.URL, metrics.StatusCode, metrics.Request.URL)
+	}
+
+	action.FallbackURLs = []string{failing.URL + "/second"}
+	_, err = exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if err == nil || !strings.Contains(err.Error(), failing.URL+"/second") {
+		t.Fatalf("expected errors of all URLs, got %v", err)
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_Hedging(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	var fastCalls atomic.Int32
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fastCalls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fast.Close()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "demo",
+				"namespace": "default",
+			},
+		},
+	}
+	action := opsv1alpha1.ActionSpec{
+		Type:         "http",
+		URL:          slow.URL,
+		FallbackURLs: []string{fast.URL},
+		URLPolicy:    &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		Timeout:      "5s",
+		Hedging:      &opsv1alpha1.HedgingSpec{Delay: "10ms"},
+	}
+
+	startedAt := time.Now()
+	metrics, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if err != nil {
+		t.Fatalf("expected the hedged request to succeed, got %v", err)
+	}
+	if elapsed := time.Since(startedAt); elapsed > 2*time.Second {
+		t.Fatalf("expected the fast receiver to win, took %s", elapsed)
+	}
+	if metrics.Request.URL != fast.URL {
+		t.Fatalf("expected the response of %s, got %s", fast.URL, metrics.Request.URL)
+	}
+
+	// A primary that answers within the delay is the only request.
+	fastCalls.Store(0)
+	action.URL, action.FallbackURLs = fast.URL, []string{slow.URL}
+	action.Hedging.Delay = "2s"
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err != nil {
+		t.Fatalf("expected the primary to succeed, got %v", err)
+	}
+	if fastCalls.Load() != 1 {
+		t.Fatalf("expected one request to the primary, got %d", fastCalls.Load())
+	}
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// finalState returns the object of a Delete event: the last state the
+// informer saw before the object was deleted. Executions of the event, their
+// retries and handlers all render their templates with it, as the object can
+// no longer be read once it is gone. Other events return nil.
+func finalState(input MatchInput) *unstructured.Unstructured {
+	if input.Event != EventDelete {
+		return nil
+	}
+	return input.Obj
+}
+
+// setFinalState stores obj as spec.finalState of ae. A nil obj is ignored.
+func setFinalState(ae *opsv1alpha1.ActionExecution, obj *unstructured.Unstructured) error {
+	if obj == nil {
+		return nil
+	}
+	raw, err := json.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf("encode final state: %w", err)
+	}
+	ae.Spec.FinalState = &runtime.RawExtension{Raw: raw}
+	return nil
+}
+
+// storedFinalState returns the latest final state of the object with uid that
+// an ActionExecution of ra recorded for its Delete event, or nil when none did.
+func storedFinalState(
+	ctx context.Context,
+	c client.Client,
+	ra *opsv1alpha1.ResourceAction,
+	uid types.UID,
+) (*unstructured.Unstructured, error) {
+	var list opsv1alpha1.ActionExecutionList
+	if err := c.List(ctx, &list,
+		client.InNamespace(ra.Namespace),
+		client.MatchingLabels(actionExecutionLabels(ra.Name, uid, string(EventDelete))),
+	); err != nil {
+		return nil, err
+	}
+	var latest *opsv1alpha1.ActionExecution
+	for i := range list.Items {
+		ae := &list.Items[i]
+		if ae.Spec.FinalState == nil || len(ae.Spec.FinalState.Raw) == 0 {
+			continue
+		}
+		if latest == nil || latest.Spec.ExecutedAt.Before(&ae.Spec.ExecutedAt) {
+			latest = ae
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(latest.Spec.FinalState.Raw); err != nil {
+		return nil, fmt.Errorf("decode final state of %s: %w", latest.Name, err)
+	}
+	return obj, nil
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExecute_DeleteStoresFinalState(t *testing.T) {
+	bodies := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-final", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:    opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:      []string{"Delete"},
+			HistoryMode: historyModeActionExecution,
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:      &opsv1alpha1.TemplateSpec{Template: `{"replicas":{{ .spec.replicas }}}`},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-final", "demo", "default")
+	input.Event = EventDelete
+	if err := unstructured.SetNestedField(input.Obj.Object, int64(3), "spec", "replicas"); err != nil {
+		t.Fatalf("set replicas: %v", err)
+	}
+
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if body := <-bodies; body != `{"replicas":3}` {
+		t.Fatalf("body = %s, want it rendered from the final state", body)
+	}
+
+	var executions opsv1alpha1.ActionExecutionList
+	if err := cl.List(context.Background(), &executions, client.InNamespace("default")); err != nil {
+		t.Fatalf("list action executions: %v", err)
+	}
+	if len(executions.Items) != 1 || executions.Items[0].Spec.FinalState == nil {
+		t.Fatalf("ActionExecutions = %+v, want one with the final state", executions.Items)
+	}
+
+	obj, err := storedFinalState(context.Background(), cl, ra, "uid-final")
+	if err != nil {
+		t.Fatalf("storedFinalState: %v", err)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); obj.GetName() != "demo" || replicas != 3 {
+		t.Fatalf("final state = %v, want the deleted object", obj.Object)
+	}
+	if obj, err := storedFinalState(context.Background(), cl, ra, "uid-other"); err != nil || obj != nil {
+		t.Fatalf("storedFinalState() = %v, %v, want nil for an object without a record", obj, err)
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxForEachItems bounds the requests of a single forEach action.
+const maxForEachItems = 100
+
+// templateFuncs returns the functions available to the templates of h's
+// action.
+func (h *HTTPExecutor) templateFuncs() template.FuncMap {
+	funcs := bodyTemplateFuncs(h.outputs, h.trigger)
+	if h.item != nil {
+		item := *h.item
+		funcs["item"] = func() string { return item }
+	}
+	changes := h.changes
+	funcs["changeTypes"] = func() []string { return changes }
+	funcs["changed"] = func(changeType string) bool { return slices.Contains(changes, changeType) }
+	if group := h.group; group != nil {
+		funcs["groupStatus"] = group.status
+		funcs["groupLabels"] = func() map[string]string { return group.labels }
+		funcs["fingerprint"] = func() string { return group.fingerprint }
+	}
+	if h.lookups != nil {
+		funcs["configMapValue"] = h.lookups.configMapValue
+		funcs["secretValue"] = h.lookups.secretValue
+	}
+	return funcs
+}
+
+// executeForEach sends the request of a forEach action to the URL of every
+// item, one after the other. Every target is called even when an earlier one
+// failed; the action fails if any of them failed.
+func (h *HTTPExecutor) executeForEach(
+	ctx context.Context,
+	action opsv1alpha1.ActionSpec,
+	raNamespace string,
+	obj *unstructured.Unstructured,
+	headers map[string]string,
+) (HTTPExecutionMetrics, error) {
+	items, err := forEachItems(h.compiled, action.ForEach, obj, h.templateFuncs())
+	if err != nil {
+		return HTTPExecutionMetrics{}, err
+	}
+
+	metrics := HTTPExecutionMetrics{Targets: make([]opsv1alpha1.TargetRecord, 0, len(items))}
+	var errs []error
+	for _, item := range items {
+		itemExec := *h
+		itemExec.item = &item
+
+		url, err := renderTemplate(h.compiled, "forEach.url", action.ForEach.URL, obj.Object, itemExec.templateFuncs())
+		record := opsv1alpha1.TargetRecord{Item: item, URL: strings.TrimSpace(url)}
+		var targetMetrics HTTPExecutionMetrics
+		if err == nil {
+			target := action
+			target.ForEach = nil
+			target.URL = record.URL
+			targetMetrics, err = itemExec.ExecuteWithMetrics(ctx, target, raNamespace, obj, forEachHeaders(headers, action.IdempotencyKey, item))
+		}
+
+		metrics.Attempts += targetMetrics.Attempts
+		metrics.NetworkRetryCount += targetMetrics.NetworkRetryCount
+		metrics.StatusRetryCount += targetMetrics.StatusRetryCount
+		metrics.BackoffMillis += targetMetrics.BackoffMillis
+		metrics.DurationMillis += targetMetrics.DurationMillis
+		if targetMetrics.StatusCode > 0 {
+			metrics.StatusCode = targetMetrics.StatusCode
+		}
+		if targetMetrics.Request != nil {
+			metrics.Request = targetMetrics.Request
+			metrics.Response = targetMetrics.Response
+		}
+		record.StatusCode = targetMetrics.StatusCode
+		if err != nil {
+			record.Error = err.Error()
+			errs = append(errs, fmt.Errorf("target %s: %w", item, err))
+		}
+		metrics.Targets = append(metrics.Targets, record)
+	}
+	if len(errs) > 0 {
+		return metrics, fmt.Errorf("%d of %d targets failed: %w", len(errs), len(items), errors.Join(errs...))
+	}
+	return metrics, nil
+}
+
+// forEachItems renders spec.actions[].forEach.items into one item per
+// non-empty line.
+func forEachItems(
+	compiled *compiledSpec,
+	spec *opsv1alpha1.ForEachSpec,
+	obj *unstructured.Unstructured,
+	funcs template.FuncMap,
+) ([]string, error) {
+	text, err := renderTemplate(compiled, "forEach.items", spec.Items, obj.Object, funcs)
+	if err != nil {
+		return nil, err
+	}
+	var items []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	if len(items) > maxForEachItems {
+		return nil, fmt.Errorf("forEach.items yielded %d items, at most %d are allowed", len(items), maxForEachItems)
+	}
+	return items, nil
+}
+
+// forEachHeaders returns the headers of the request to item. Each target gets
+// its own idempotency key, so a receiver behind several targets does not drop
+// them as duplicates.
+func forEachHeaders(headers map[string]string, spec *opsv1alpha1.IdempotencyKeySpec, item string) map[string]string {
+	headers = maps.Clone(headers)
+	if spec != nil {
+		header := idempotencyKeyHeader(spec)
+		sum := sha256.Sum256([]byte(headers[header] + "\x00" + item))
+		headers[header] = hex.EncodeToString(sum[:])
+	}
+	return headers
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestExecute_ForEach(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]string{}
+	keys := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] = string(body)
+		keys[r.Header.Get(defaultIdempotencyKeyHeader)] = true
+		mu.Unlock()
+		if r.URL.Path == "/hooks/sidecar" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-foreach", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type: "http",
+				ForEach: &opsv1alpha1.ForEachSpec{
+					Items: "{{ range .spec.containers }}{{ .name }}\n{{ end }}",
+					URL:   srv.URL + "/hooks/{{ item }}",
+				},
+				URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:           &opsv1alpha1.TemplateSpec{Template: `{"container":"{{ item }}","pod":"{{ .metadata.name }}"}`},
+				IdempotencyKey: &opsv1alpha1.IdempotencyKeySpec{},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-foreach", "demo", "default")
+	input.Obj.Object["spec"] = map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app"},
+			map[string]interface{}{"name": "sidecar"},
+			map[string]interface{}{"name": "proxy"},
+		},
+	}
+
+	err := exec.Execute(context.Background(), input)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 targets failed") || !strings.Contains(err.Error(), "target sidecar") {
+		t.Fatalf("Execute() error = %v, want the failed sidecar target", err)
+	}
+	if len(bodies) != 3 || bodies["/hooks/proxy"] != `{"container":"proxy","pod":"demo"}` {
+		t.Fatalf("bodies = %v, want one request per container", bodies)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("idempotency keys = %v, want one per target", keys)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	record := got.Status.Executions[0]
+	if record.Attempts != 3 || len(record.Targets) != 3 {
+		t.Fatalf("record = %+v, want three attempts and targets", record)
+	}
+	for _, target := range record.Targets {
+		failed := target.Item == "sidecar"
+		if target.URL != srv.URL+"/hooks/"+target.Item || (target.Error != "") != failed {
+			t.Fatalf("target = %+v, want only sidecar to fail", target)
+		}
+	}
+	if record.Targets[1].StatusCode != http.StatusBadRequest {
+		t.Fatalf("sidecar status = %d, want 400", record.Targets[1].StatusCode)
+	}
+}
+
+func TestForEachItems(t *testing.T) {
+	obj := newDeploymentInput("uid-1", "demo", "default").Obj
+	obj.Object["data"] = map[string]interface{}{"endpoints": "a.example.com\n\n  b.example.com  \n"}
+
+	items, err := forEachItems(nil, &opsv1alpha1.ForEachSpec{Items: "{{ .data.endpoints }}"}, obj, bodyTemplateFuncs(nil, nil))
+	if err != nil || strings.Join(items, ",") != "a.example.com,b.example.com" {
+		t.Fatalf("forEachItems() = %v, %v; want both endpoints", items, err)
+	}
+
+	obj.Object["data"] = map[string]interface{}{"endpoints": strings.Repeat("host\n", maxForEachItems+1)}
+	if _, err := forEachItems(nil, &opsv1alpha1.ForEachSpec{Items: "{{ .data.endpoints }}"}, obj, bodyTemplateFuncs(nil, nil)); err == nil {
+		t.Fatalf("expected more than %d items to be rejected", maxForEachItems)
+	}
+
+	if _, err := renderTemplate(nil, "body", "{{ item }}", obj.Object, bodyTemplateFuncs(nil, nil)); err == nil {
+		t.Fatalf("expected item outside of forEach to fail")
+	}
+}
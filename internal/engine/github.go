@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	webhookFormatGitHub = "GitHub"
+
+	gitHubSignatureHeader = "X-Hub-Signature-256"
+	gitHubEventHeader     = "X-GitHub-Event"
+	gitHubDeliveryHeader  = "X-GitHub-Delivery"
+
+	// gitHubField holds the event name and delivery ID of a GitHub event.
+	gitHubField = "github"
+
+	gitHubEventEnv      = "GITHUB_EVENT"
+	gitHubDeliveryEnv   = "GITHUB_DELIVERY"
+	gitHubRepositoryEnv = "GITHUB_REPOSITORY"
+	gitHubRefEnv        = "GITHUB_REF"
+	gitHubSHAEnv        = "GITHUB_SHA"
+)
+
+// validGitHubSignature reports whether signature, the value of
+// X-Hub-Signature-256, is the HMAC-SHA256 of body with secret.
+func validGitHubSignature(secret, body []byte, signature string) bool {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// gitHubEventObject returns the object of the Webhook event of a GitHub
+// delivery: the delivery as .payload and its event name and ID as .github.
+func gitHubEventObject(source *opsv1alpha1.WebhookSource, payload interface{}, event, delivery string) (*unstructured.Unstructured, error) {
+	obj, err := webhookEventObject(source, payload)
+	if err != nil {
+		return nil, err
+	}
+	obj.Object[gitHubField] = map[string]interface{}{
+		"event":    event,
+		"delivery": delivery,
+	}
+	return obj, nil
+}
+
+// gitHubEvent describes the GitHub delivery of an event of a WebhookSource
+// with format GitHub.
+type gitHubEvent struct {
+	event, delivery, repository, ref, sha string
+}
+
+func gitHubEventOf(input MatchInput) (gitHubEvent, bool) {
+	if input.Event != EventWebhook || input.Obj == nil {
+		return gitHubEvent{}, false
+	}
+	meta, ok := input.Obj.Object[gitHubField].(map[string]interface{})
+	if !ok {
+		return gitHubEvent{}, false
+	}
+	var ev gitHubEvent
+	ev.event, _ = meta["event"].(string)
+	ev.delivery, _ = meta["delivery"].(string)
+	if payload, ok := input.Obj.Object["payload"].(map[string]interface{}); ok {
+		ev.repository, _, _ = unstructured.NestedString(payload, "repository", "full_name")
+		ev.ref, _, _ = unstructured.NestedString(payload, "ref")
+		ev.sha, _, _ = unstructured.NestedString(payload, "after")
+	}
+	return ev, true
+}
+
+// matchesGitHub reports whether input is a GitHub event matching filter.
+func matchesGitHub(filter *opsv1alpha1.GitHubFilter, input MatchInput) bool {
+	ev, ok := gitHubEventOf(input)
+	if !ok {
+		return false
+	}
+	if len(filter.Events) > 0 && !slices.Contains(filter.Events, ev.event) {
+		return false
+	}
+	if filter.Repository != "" && ev.repository != filter.Repository {
+		return false
+	}
+	return filter.Ref == "" || ev.ref == filter.Ref
+}
+
+// gitHubEnv passes the GitHub delivery of an event to job actions. GITHUB_SHA
+// is the commit a push moved the ref to.
+func gitHubEnv(input MatchInput) []corev1.EnvVar {
+	ev, ok := gitHubEventOf(input)
+	if !ok {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: gitHubEventEnv, Value: ev.event},
+		{Name: gitHubDeliveryEnv, Value: ev.delivery},
+		{Name: gitHubRepositoryEnv, Value: ev.repository},
+		{Name: gitHubRefEnv, Value: ev.ref},
+		{Name: gitHubSHAEnv, Value: ev.sha},
+	}
+}
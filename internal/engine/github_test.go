@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testGitHubPush = `{"ref":"refs/heads/main","after":"4f2c1e9","repository":{"full_name":"octo-org/shop"}}`
+
+func signGitHub(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func postGitHub(r *WebhookReceiver, event, signature, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/ops/alerts", strings.NewReader(body))
+	req.Header.Set(gitHubSignatureHeader, signature)
+	req.Header.Set(gitHubDeliveryHeader, "delivery-1")
+	if event != "" {
+		req.Header.Set(gitHubEventHeader, event)
+	}
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func gitHubInput(t *testing.T, event, body string) MatchInput {
+	t.Helper()
+	var payload interface{}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("decode delivery: %v", err)
+	}
+	source := &opsv1alpha1.WebhookSource{ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "ops", UID: "uid-source"}}
+	obj, err := gitHubEventObject(source, payload, event, "delivery-1")
+	if err != nil {
+		t.Fatalf("gitHubEventObject() error = %v", err)
+	}
+	return MatchInput{Event: EventWebhook, GVK: webhookSourceGVK, Obj: obj}
+}
+
+func TestWebhookReceiver_GitHubSignature(t *testing.T) {
+	r, e := newWebhookTestReceiver(t, webhookFormatGitHub, "")
+
+	if code := postGitHub(r, "push", signGitHub("s3cret", testGitHubPush), testGitHubPush); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", code, http.StatusAccepted)
+	}
+	item, _ := e.queue.Get()
+	input := e.takeInput(item)
+	e.queue.Done(item)
+	e.finish(item)
+	event, _, _ := unstructured.NestedString(input.Obj.Object, gitHubField, "event")
+	ref, _, _ := unstructured.NestedString(input.Obj.Object, "payload", "ref")
+	if input.Event != EventWebhook || event != "push" || ref != "refs/heads/main" {
+		t.Fatalf("object = %v, want the push delivery", input.Obj.Object)
+	}
+
+	tests := []struct {
+		name      string
+		event     string
+		signature string
+		want      int
+	}{
+		{name: "wrong secret", event: "push", signature: signGitHub("guess", testGitHubPush), want: http.StatusUnauthorized},
+		{name: "other body", event: "push", signature: signGitHub("s3cret", `{}`), want: http.StatusUnauthorized},
+		{name: "sha1 signature", event: "push", signature: "sha1=0123", want: http.StatusUnauthorized},
+		{name: "missing event", signature: signGitHub("s3cret", testGitHubPush), want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := postGitHub(r, tt.event, tt.signature, testGitHubPush); code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+	// The shared secret header does not authenticate GitHub deliveries.
+	if code := postWebhook(r, "/webhooks/ops/alerts", "s3cret", testGitHubPush); code != http.StatusUnauthorized {
+		t.Fatalf("status with the shared secret header = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if e.queue.Len() != 0 {
+		t.Fatalf("queue length = %d, want rejected deliveries not to be queued", e.queue.Len())
+	}
+}
+
+func TestExecute_GitHubFilter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if body, _ := io.ReadAll(r.Body); string(body) != "4f2c1e9" {
+			t.Errorf("body = %s, want the pushed commit", body)
+		}
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-github", Namespace: "ops"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "ops.yusaozdemir.de", Version: "v1alpha1", Kind: "WebhookSource"},
+			Events:   []string{"Webhook"},
+			Filters: &opsv1alpha1.FilterSpec{GitHub: &opsv1alpha1.GitHubFilter{
+				Events:     []string{"push"},
+				Repository: "octo-org/shop",
+				Ref:        "refs/heads/main",
+			}},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:      &opsv1alpha1.TemplateSpec{Template: "{{ .payload.after }}", ContentType: "text/plain"},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	for _, input := range []MatchInput{
+		gitHubInput(t, "push", testGitHubPush),
+		gitHubInput(t, "ping", `{"zen":"Keep it logically awesome.","repository":{"full_name":"octo-org/shop"}}`),
+		gitHubInput(t, "push", `{"ref":"refs/heads/feature","after":"9a8b7c6","repository":{"full_name":"octo-org/shop"}}`),
+		gitHubInput(t, "push", `{"ref":"refs/heads/main","after":"9a8b7c6","repository":{"full_name":"octo-org/docs"}}`),
+	} {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want only the push to main of the repository to run", calls.Load())
+	}
+}
+
+func TestGitHubEnv(t *testing.T) {
+	env := gitHubEnv(gitHubInput(t, "push", testGitHubPush))
+	want := []corev1.EnvVar{
+		{Name: gitHubEventEnv, Value: "push"},
+		{Name: gitHubDeliveryEnv, Value: "delivery-1"},
+		{Name: gitHubRepositoryEnv, Value: "octo-org/shop"},
+		{Name: gitHubRefEnv, Value: "refs/heads/main"},
+		{Name: gitHubSHAEnv, Value: "4f2c1e9"},
+	}
+	if len(env) != len(want) {
+		t.Fatalf("env = %v, want %v", env, want)
+	}
+	for i := range want {
+		if env[i] != want[i] {
+			t.Fatalf("env[%d] = %v, want %v", i, env[i], want[i])
+		}
+	}
+
+	if env := gitHubEnv(alertEventInputs(t)[0]); env != nil {
+		t.Fatalf("env = %v, want none for alerts", env)
+	}
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"maps"
+	"math/rand"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// hasDependencies reports whether an action of ra declares dependsOn, so the
+// event-driven actions run as a graph instead of in list order.
+func hasDependencies(ra *opsv1alpha1.ResourceAction) bool {
+	for _, action := range ra.Spec.Actions {
+		if len(action.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// executeGraph runs the event-driven actions of ra in the order of their
+// dependsOn. An action starts once every action it depends on succeeded or
+// failed with continueOnError; the actions that are ready at the same time
+// run in parallel. The actions depending on a failed action are skipped, the
+// other branches still run. It reports whether an action failed without
+// continueOnError.
+func (e *K8sExecutor) executeGraph(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	input MatchInput,
+	httpExec *HTTPExecutor,
+	jobExec *JobExecutor,
+	progress *executionProgress,
+) bool {
+	logger := log.FromContext(ctx)
+
+	// pending maps the actions still to run to the actions they depend on.
+	// Dependencies on disabled actions count as done.
+	pending := map[int][]int{}
+	for i, action := range ra.Spec.Actions {
+		if action.Mode == "cron" || action.Mode == "schedule" || action.Mode == actionModeHandler || !actionEnabled(action) {
+			continue
+		}
+		pending[i] = nil
+	}
+	for i := range pending {
+		for _, name := range ra.Spec.Actions[i].DependsOn {
+			if j := actionIndexByName(&ra, name); j >= 0 {
+				if _, ok := pending[j]; ok {
+					pending[i] = append(pending[i], j)
+				}
+			}
+		}
+	}
+
+	type result struct {
+		index   int
+		metrics HTTPExecutionMetrics
+		err     error
+	}
+	results := make(chan result)
+	done := map[int]bool{}
+	failed := map[int]bool{}
+	running := 0
+	stopped := false
+
+	for {
+		for changed := true; changed; {
+			changed = false
+			for i := range ra.Spec.Actions {
+				deps, ok := pending[i]
+				if !ok {
+					continue
+				}
+				ready := true
+				for _, j := range deps {
+					if failed[j] {
+						ready = false
+						delete(pending, i)
+						failed[i] = true
+						changed = true
+						logger.Info("Skipping action, a dependency failed",
+							"resourceAction", ra.Name,
+							"actionIndex", i,
+							"action", ra.Spec.Actions[i].Name,
+							"dependency", ra.Spec.Actions[j].Name,
+						)
+						break
+					}
+					ready = ready && done[j]
+				}
+				if !ready || failed[i] {
+					continue
+				}
+
+				delete(pending, i)
+				action := ra.Spec.Actions[i]
+				logger.Info("Executing action",
+					"resourceAction", ra.Name,
+					"actionIndex", i,
+					"action", action.Name,
+					"type", action.Type,
+					"event", input.Event,
+					"name", input.Obj.GetName(),
+				)
+				// The actions run concurrently, so each needs its own rng and
+				// a snapshot of the outputs of the actions it depends on.
+				actionHTTP := *httpExec
+				actionHTTP.rng = rand.New(rand.NewSource(httpExec.rng.Int63()))
+				actionHTTP.outputs = maps.Clone(progress.outputs)
+				running++
+				go func() {
+					metrics, err := e.executeAction(ctx, ra, i, action, input, &actionHTTP, jobExec)
+					results <- result{index: i, metrics: metrics, err: err}
+				}()
+			}
+		}
+		if running == 0 {
+			return stopped
+		}
+
+		r := <-results
+		running--
+		progress.record(r.index, r.metrics, r.err)
+		if r.err != nil && !ra.Spec.Actions[r.index].ContinueOnError {
+			failed[r.index] = true
+			stopped = true
+			continue
+		}
+		done[r.index] = true
+		if r.err != nil {
+			logger.Info("Action failed, continuing with the depending actions",
+				"resourceAction", ra.Name,
+				"actionIndex", r.index,
+				"action", ra.Spec.Actions[r.index].Name,
+				"error", r.err.Error(),
+			)
+			continue
+		}
+		for name, value := range r.metrics.Outputs {
+			progress.outputs[name] = value
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExecute_DependsOnGraph(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	bodies := map[string]string{}
+	// dns and monitoring only answer once both were called, so the test
+	// hangs unless they run in parallel.
+	var branches sync.WaitGroup
+	branches.Add(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		bodies[r.URL.Path] = string(body)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/provision":
+			_, _ = w.Write([]byte(`{"id":"42"}`))
+		case "/dns", "/monitoring":
+			branches.Done()
+			waited := make(chan struct{})
+			go func() { branches.Wait(); close(waited) }()
+			select {
+			case <-waited:
+			case <-time.After(5 * time.Second):
+				w.WriteHeader(http.StatusGatewayTimeout)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	policy := &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-graph", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Name: "notify", Type: "http", URL: srv.URL + "/notify", URLPolicy: policy,
+					DependsOn: []string{"dns", "monitoring"},
+				},
+				{
+					Name: "dns", Type: "http", URL: srv.URL + "/dns", URLPolicy: policy,
+					DependsOn: []string{"provision"},
+					Body:      &opsv1alpha1.TemplateSpec{Template: `{"id":"{{ output "id" }}"}`},
+				},
+				{
+					Name: "monitoring", Type: "http", URL: srv.URL + "/monitoring", URLPolicy: policy,
+					DependsOn: []string{"provision"},
+				},
+				{
+					Name: "provision", Type: "http", URL: srv.URL + "/provision", URLPolicy: policy,
+					Outputs: map[string]string{"id": "{.id}"},
+				},
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-graph", "demo", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(calls) != 4 || calls[0] != "/provision" || calls[3] != "/notify" {
+		t.Fatalf("calls = %v, want provision first and notify last", calls)
+	}
+	if bodies["/dns"] != `{"id":"42"}` {
+		t.Fatalf("dns body = %q, want the output of provision", bodies["/dns"])
+	}
+	got := getResourceAction(t, cl, ra)
+	if record := got.Status.Executions[0]; record.ActionCount != 4 || record.Result != executionResultSucceeded {
+		t.Fatalf("record = %+v, want four succeeded actions", record)
+	}
+}
+
+func TestExecute_DependsOnSkipsAfterFailure(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	policy := &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-graph-failure", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{Name: "a", Type: "http", URL: srv.URL + "/fail-a", URLPolicy: policy},
+				{Name: "b", Type: "http", URL: srv.URL + "/b", URLPolicy: policy, DependsOn: []string{"a"}},
+				{Name: "c", Type: "http", URL: srv.URL + "/c", URLPolicy: policy, DependsOn: []string{"b"}},
+				{Name: "d", Type: "http", URL: srv.URL + "/fail-d", URLPolicy: policy, ContinueOnError: true},
+				{Name: "e", Type: "http", URL: srv.URL + "/e", URLPolicy: policy, DependsOn: []string{"d"}},
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-graph", "demo", "default")); err == nil {
+		t.Fatalf("expected the failed action to fail the execution")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Contains(strings.Join(calls, ","), "/b") || len(calls) != 3 {
+		t.Fatalf("calls = %v, want b and c skipped and e to run after d", calls)
+	}
+	got := getResourceAction(t, cl, ra)
+	record := got.Status.Executions[0]
+	if record.Result != executionResultFailed || len(record.FailedActions) != 2 {
+		t.Fatalf("record = %+v, want a failed execution with two failed actions", record)
+	}
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	actionModeHandler = "handler"
+
+	triggeredByEnv     = "RESOURCE_ACTION_TRIGGERED_BY"
+	failureReasonEnv   = "RESOURCE_ACTION_FAILURE_REASON"
+	failedAttemptsEnv  = "RESOURCE_ACTION_FAILED_ATTEMPTS"
+	failureStatusEnv   = "RESOURCE_ACTION_FAILURE_STATUS"
+	escalationLevelEnv = "RESOURCE_ACTION_ESCALATION_LEVEL"
+)
+
+// actionTrigger describes the action whose outcome runs a handler.
+type actionTrigger struct {
+	// action is the name of the action, or its index when it has none.
+	action string
+	// failureReason is the error of the action; empty for onSuccess.
+	failureReason string
+	// attempts is the number of attempts of the action, and statusCode the
+	// status of its last HTTP response, or 0 without one.
+	attempts   int
+	statusCode int
+	// escalationLevel is the 1-based level of the handler in the escalation
+	// of the action; 0 for onSuccess and onFailure.
+	escalationLevel int
+}
+
+// newActionTrigger describes the outcome of the action at actionIndex for
+// its handlers.
+func newActionTrigger(actionIndex int, action opsv1alpha1.ActionSpec, metrics HTTPExecutionMetrics, execErr error) *actionTrigger {
+	trigger := &actionTrigger{
+		action:     action.Name,
+		attempts:   metrics.Attempts,
+		statusCode: metrics.StatusCode,
+	}
+	if trigger.action == "" {
+		trigger.action = strconv.Itoa(actionIndex)
+	}
+	if execErr != nil {
+		trigger.failureReason = execErr.Error()
+	}
+	return trigger
+}
+
+// runHandlers runs the onSuccess or onFailure handlers of the action at
+// actionIndex with input and the outcome of the action. Handler failures are
+// logged and reported as events; they do not change the outcome.
+func (e *K8sExecutor) runHandlers(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	action opsv1alpha1.ActionSpec,
+	input MatchInput,
+	metrics HTTPExecutionMetrics,
+	execErr error,
+	httpExec *HTTPExecutor,
+	jobExec *JobExecutor,
+) {
+	handlers := action.OnSuccess
+	trigger := newActionTrigger(actionIndex, action, metrics, execErr)
+	if execErr != nil {
+		handlers = action.OnFailure
+		// The action may have failed because ctx expired; the handlers
+		// must still run.
+		ctx = context.WithoutCancel(ctx)
+	}
+	if len(handlers) == 0 {
+		return
+	}
+	logger := log.FromContext(ctx)
+	input.trigger = trigger
+
+	for _, name := range handlers {
+		handlerIndex := actionIndexByName(&ra, name)
+		if handlerIndex < 0 || !actionEnabled(ra.Spec.Actions[handlerIndex]) {
+			continue
+		}
+		logger.Info("Executing handler action",
+			"resourceAction", ra.Name,
+			"actionIndex", actionIndex,
+			"handler", name,
+			"failed", execErr != nil,
+		)
+		if _, err := e.executeAction(ctx, ra, handlerIndex, ra.Spec.Actions[handlerIndex], input, httpExec, jobExec); err != nil {
+			logger.Error(err, "handler action failed", "resourceAction", ra.Name, "handler", name)
+			if e.Recorder != nil {
+				e.Recorder.Eventf(&ra, corev1.EventTypeWarning, "HandlerFailed", "handler %s of action %s failed: %v", name, trigger.action, err)
+			}
+		}
+	}
+}
+
+// actionIndexByName returns the index of the action called name, or -1.
+func actionIndexByName(ra *opsv1alpha1.ResourceAction, name string) int {
+	for i, action := range ra.Spec.Actions {
+		if action.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// triggerEnv returns the environment variables that tell a Job handler which
+// action ran it, why, and at which escalation level.
+func triggerEnv(trigger *actionTrigger) []corev1.EnvVar {
+	if trigger == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: triggeredByEnv, Value: trigger.action},
+		{Name: failureReasonEnv, Value: trigger.failureReason},
+		{Name: failedAttemptsEnv, Value: strconv.Itoa(trigger.attempts)},
+		{Name: failureStatusEnv, Value: strconv.Itoa(trigger.statusCode)},
+		{Name: escalationLevelEnv, Value: strconv.Itoa(trigger.escalationLevel)},
+	}
+}
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildJobForAction_TriggerEnv(t *testing.T) {
+	ra := opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Name: "ra-job-handler", Namespace: "default"}}
+	action := opsv1alpha1.ActionSpec{
+		Name: "cleanup",
+		Type: "job",
+		Mode: actionModeHandler,
+		Job:  &opsv1alpha1.JobSpec{Image: "busybox", Command: []string{"true"}},
+	}
+	input := newDeploymentInput("uid-1", "demo", "default")
+	input.trigger = &actionTrigger{action: "webhook", failureReason: "http call failed: status=503", attempts: 3, statusCode: 503, escalationLevel: 2}
+
+	job, err := buildJobForAction(ra, 0, action, input)
+	if err != nil {
+		t.Fatalf("buildJobForAction() error = %v", err)
+	}
+	env := map[string]string{}
+	for _, v := range job.Spec.Template.Spec.Containers[0].Env {
+		env[v.Name] = v.Value
+	}
+	if env[triggeredByEnv] != "webhook" || env[failureReasonEnv] != "http call failed: status=503" ||
+		env[failedAttemptsEnv] != "3" || env[failureStatusEnv] != "503" || env[escalationLevelEnv] != "2" {
+		t.Fatalf("env = %v, want the trigger of the handler", env)
+	}
+
+	input.trigger = nil
+	job, err = buildJobForAction(ra, 0, action, input)
+	if err != nil {
+		t.Fatalf("buildJobForAction() error = %v", err)
+	}
+	if len(job.Spec.Template.Spec.Containers[0].Env) != 0 {
+		t.Fatalf("expected no trigger env outside handlers, got %v", job.Spec.Template.Spec.Containers[0].Env)
+	}
+}
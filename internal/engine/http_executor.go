@@ -14,11 +14,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http/httpproxy"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -33,6 +36,51 @@ type TemplateContext struct {
 type HTTPExecutor struct {
 	k8s client.Client
 	rng *rand.Rand
+	// breakers short-circuits attempts to hosts that keep failing. Nil
+	// disables it.
+	breakers *circuitBreakers
+	// limiter caps outbound requests; limitKey selects the limiter of
+	// spec.actions[].rateLimit. Nil disables rate limiting.
+	limiter  *outboundLimiter
+	limitKey actionLimitKey
+	// urlPolicy is the operator URL policy. Nil allows every host.
+	urlPolicy *opsv1alpha1.OperatorURLPolicy
+	// secrets and vault read the credentials of TLS and proxy settings.
+	secrets *secretCache
+	vault   *vaultClient
+	// transports reuses transports across requests. Nil builds one per
+	// execution.
+	transports *transportCache
+	// outputs holds the outputs of the earlier actions of an execution for
+	// body templates.
+	outputs map[string]string
+	// trigger is the action that ran a handler action, for body templates.
+	trigger *actionTrigger
+	// item is the current item of a forEach action, for body templates.
+	item *string
+	// changes holds the parts of the object an Update changed, for body
+	// templates.
+	changes []string
+	// lookups reads ConfigMap and Secret values for body templates. Nil
+	// renders placeholders, as in dry runs.
+	lookups *templateLookups
+	// batch holds the objects of an aggregated batch, which are sent
+	// together in one request.
+	batch []*unstructured.Unstructured
+	// group is the group of aggregation.groupBy of the batch, for body
+	// templates.
+	group *eventGroup
+	// specs holds the compiled regular expressions and templates of every
+	// ResourceAction, and compiled those of the ResourceAction of the
+	// action. Nil compiles them for every request.
+	specs    *compiledCache
+	compiled *compiledSpec
+	// deferRetries returns a retryLaterError instead of sleeping before the
+	// next attempt, so the event is requeued and the worker is free in the
+	// meantime.
+	deferRetries bool
+	// firstAttempt is the attempt a deferred retry continues with.
+	firstAttempt int
 }
 
 type HTTPExecutionMetrics struct {
@@ -43,15 +91,34 @@ type HTTPExecutionMetrics struct {
 	BackoffMillis     int64
 	DurationMillis    int64
 	Job               *opsv1alpha1.JobExecutionRecord
+	Request           *opsv1alpha1.HTTPRequestRecord
+	Response          *opsv1alpha1.HTTPResponseRecord
+	// Captured holds the values selected by responseCapture.
+	Captured map[string]string
+	// Outputs holds the values selected by outputs.
+	Outputs map[string]string
+	// Targets holds the result per target of a forEach action.
+	Targets []opsv1alpha1.TargetRecord
 }
 
 func NewHTTPExecutor(k8s client.Client) *HTTPExecutor {
 	return &HTTPExecutor{
-		k8s: k8s,
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		k8s:     k8s,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		secrets: newSecretCache(k8s),
 	}
 }
 
+// forAction returns a copy of h that rate limits requests as the action at
+// actionIndex of ra and uses the compiled regular expressions and templates
+// of ra.
+func (h *HTTPExecutor) forAction(ra *opsv1alpha1.ResourceAction, actionIndex int) *HTTPExecutor {
+	c := *h
+	c.limitKey = actionLimitKey{namespace: ra.Namespace, resourceAction: ra.Name, actionIndex: actionIndex}
+	c.compiled = h.specs.get(ra)
+	return &c
+}
+
 func (h *HTTPExecutor) Execute(
 	ctx context.Context,
 	action opsv1alpha1.ActionSpec,
@@ -63,6 +130,9 @@ func (h *HTTPExecutor) Execute(
 	return err
 }
 
+// ExecuteWithMetrics sends the request of an HTTP action to its URL and, when
+// that failed after all retries, to its fallback URLs, all within the
+// overallTimeout of the action.
 func (h *HTTPExecutor) ExecuteWithMetrics(
 	ctx context.Context,
 	action opsv1alpha1.ActionSpec,
@@ -70,11 +140,55 @@ func (h *HTTPExecutor) ExecuteWithMetrics(
 	obj *unstructured.Unstructured,
 	headers map[string]string,
 ) (HTTPExecutionMetrics, error) {
+	var overallExceeded error
+	if overall := parseDurationDefault(action.OverallTimeout, 0); overall > 0 {
+		overallExceeded = fmt.Errorf("overall timeout of %s exceeded", overall)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, overall, overallExceeded)
+		defer cancel()
+	}
+
+	var metrics HTTPExecutionMetrics
+	var err error
+	if len(action.FallbackURLs) == 0 {
+		metrics, err = h.executeURL(ctx, action, raNamespace, obj, headers)
+	} else {
+		metrics, err = h.executeWithFallbacks(ctx, action, raNamespace, obj, headers)
+	}
+	if err != nil && overallExceeded != nil && context.Cause(ctx) == overallExceeded {
+		err = fmt.Errorf("%w: %w", overallExceeded, err)
+	}
+	return metrics, err
+}
+
+// executeURL sends the request of an HTTP action to action.URL, with retries.
+func (h *HTTPExecutor) executeURL(
+	ctx context.Context,
+	action opsv1alpha1.ActionSpec,
+	raNamespace string,
+	obj *unstructured.Unstructured,
+	headers map[string]string,
+) (metrics HTTPExecutionMetrics, err error) {
+	ctx, span := tracer.Start(ctx, "HTTPExecutor.Execute", trace.WithSpanKind(trace.SpanKindClient))
+	// Errors end up in the status, events and dead letters, so they must not
+	// contain Secret values echoed by the receiver.
+	redact := newRedactor(headers)
+	defer func() {
+		err = redact.redactError(err)
+		span.SetAttributes(
+			attribute.Int("http.attempts", metrics.Attempts),
+			attribute.Int("http.response.status_code", metrics.StatusCode),
+		)
+		endSpan(span, err)
+	}()
 	logger := log.FromContext(ctx)
 	startedAt := time.Now()
-	metrics := HTTPExecutionMetrics{}
 
-	timeout := parseDurationDefault(action.Timeout, 10*time.Second)
+	attemptTimeout := parseDurationDefault(action.AttemptTimeout, parseDurationDefault(action.Timeout, 10*time.Second))
+	maxResponseBytes := action.MaxResponseBytes
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = defaultMaxResponseBytes
+	}
 
 	maxAttempts := 1
 	backoffBase := 500 * time.Millisecond
@@ -100,31 +214,30 @@ func (h *HTTPExecutor) ExecuteWithMetrics(
 		}
 	}
 
-	transport, err := h.buildTransport(ctx, raNamespace, action.TLS)
+	if err := validateProxyURL(h.compiled, action.Proxy, action.URLPolicy, h.urlPolicy); err != nil {
+		return metrics, err
+	}
+	transport, err := h.buildTransport(ctx, raNamespace, action.TLS, action.Proxy)
 	if err != nil {
 		return metrics, err
 	}
 
 	httpClient := &http.Client{
-		Timeout:   timeout,
-		Transport: transport,
+		Timeout:       attemptTimeout,
+		Transport:     transport,
+		CheckRedirect: h.checkRedirect(action, headers),
 	}
 
-	var bodyBytes []byte
-	if action.Body != nil && action.Body.Template != "" {
-		tpl, err := template.New("body").Parse(action.Body.Template)
-		if err != nil {
-			return metrics, err
-		}
-
-		var buf bytes.Buffer
-
-		err = tpl.Execute(&buf, obj.Object)
+	bodyBytes, contentType, err := h.renderBody(action.Body, obj)
+	redact = h.lookups.redactor(redact)
+	if err != nil {
+		return metrics, err
+	}
+	if len(bodyBytes) > 0 && action.Body != nil && action.Body.Compression == "gzip" {
+		bodyBytes, err = gzipBody(bodyBytes)
 		if err != nil {
-			return metrics, err
+			return metrics, fmt.Errorf("compress body: %w", err)
 		}
-
-		bodyBytes = buf.Bytes()
 	}
 
 	method := action.Method
@@ -137,16 +250,39 @@ func (h *HTTPExecutor) ExecuteWithMetrics(
 		pattern = "^2..$"
 	}
 
-	re, err := regexp.Compile(pattern)
+	re, err := h.compiled.compileRegexp("expectedStatus", pattern)
 	if err != nil {
 		return metrics, fmt.Errorf("invalid expectedStatus regex: %w", err)
 	}
-	if err := validateTargetURL(action.URL, action.URLPolicy); err != nil {
+	if err := validateTargetURL(h.compiled, action.URL, action.URLPolicy, h.urlPolicy); err != nil {
 		return metrics, err
 	}
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	metrics.Request = &opsv1alpha1.HTTPRequestRecord{Method: method, URL: action.URL}
+	span.SetAttributes(
+		attribute.String("http.request.method", method),
+		attribute.String("url.full", action.URL),
+	)
+
+	host := circuitHost(action.URL)
+	for attempt := max(h.firstAttempt, 1); attempt <= maxAttempts; attempt++ {
+		if err := h.breakers.allow(host); err != nil {
+			metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+			return metrics, err
+		}
+		waited, err := h.limiter.wait(ctx, h.limitKey, action.RateLimit, host)
+		if err != nil {
+			h.breakers.abort(host)
+			metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+			return metrics, fmt.Errorf("http rate limit wait aborted: %w", err)
+		}
+		if waited > time.Second {
+			logger.Info("HTTP request delayed by rate limit",
+				"url", action.URL,
+				"attempt", attempt,
+				"waited", waited.String(),
+			)
+		}
+		reqCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
 		metrics.Attempts = attempt
 
 		var bodyReader io.Reader
@@ -157,20 +293,33 @@ func (h *HTTPExecutor) ExecuteWithMetrics(
 		req, err := http.NewRequestWithContext(reqCtx, method, action.URL, bodyReader)
 		if err != nil {
 			cancel()
+			h.breakers.abort(host)
 			metrics.DurationMillis = time.Since(startedAt).Milliseconds()
 			return metrics, err
 		}
 
+		if id := correlationIDFrom(ctx); id != "" {
+			req.Header.Set(correlationIDHeader, id)
+		}
 		for k, v := range headers {
 			req.Header.Set(k, v)
 		}
 		if len(bodyBytes) > 0 {
-			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Type", contentType)
+			if action.Body != nil && action.Body.Compression != "" {
+				req.Header.Set("Content-Encoding", action.Body.Compression)
+			}
 		}
+		otel.GetTextMapPropagator().Inject(reqCtx, propagation.HeaderCarrier(req.Header))
 
 		resp, err := httpClient.Do(req)
 		cancel()
 		if err != nil {
+			if ctx.Err() != nil {
+				h.breakers.abort(host)
+			} else {
+				h.breakers.record(host, true)
+			}
 			// network error?
 			if retryOnNetwork && attempt < maxAttempts && isRetryableNetErr(err) {
 				sleep := backoffSleep(h.rng, backoffBase, maxBackoff, attempt)
@@ -180,28 +329,96 @@ func (h *HTTPExecutor) ExecuteWithMetrics(
 					"url", action.URL,
 					"attempt", attempt,
 					"sleep", sleep.String(),
-					"error", err.Error(),
+					"error", redact.redact(err.Error()),
 				)
-				time.Sleep(sleep)
+				if err := h.backoff(ctx, sleep, attempt); err != nil {
+					metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+					return metrics, fmt.Errorf("http retry aborted: %w", err)
+				}
 				continue
 			}
 			metrics.DurationMillis = time.Since(startedAt).Milliseconds()
 			return metrics, err
 		}
 
-		respBody, _ := io.ReadAll(resp.Body)
+		respBody, truncated := readResponseBody(resp.Body, maxResponseBytes)
+		// Closing an unread body drops the connection instead of draining it.
 		_ = resp.Body.Close()
+		if truncated {
+			logger.Info("HTTP response body truncated",
+				"url", action.URL,
+				"maxResponseBytes", maxResponseBytes,
+			)
+		}
+		h.breakers.record(host, circuitFailure(resp.StatusCode))
 		metrics.StatusCode = resp.StatusCode
+		metrics.Response = truncateResponseBody([]byte(redact.redact(string(respBody))))
+		metrics.Response.StatusCode = resp.StatusCode
 
-		logger.Info("HTTP action executed",
+		logValues := []interface{}{
 			"url", action.URL,
 			"status", resp.StatusCode,
 			"attempt", attempt,
-			"response", string(respBody),
-		)
-
-		statusStr := strconv.Itoa(resp.StatusCode)
-		if re.MatchString(statusStr) {
+			"responseBytes", len(respBody),
+		}
+		if action.LogResponseBody {
+			logValues = append(logValues, "response", redact.redactBody(respBody))
+		}
+		logger.Info("HTTP action executed", logValues...)
+
+		statusMatched := re.MatchString(strconv.Itoa(resp.StatusCode))
+		var responseErr error
+		if action.SuccessCondition != "" {
+			// The condition replaces expectedStatus. A 2xx response that
+			// fails it is retried like an unexpected response, any other
+			// like a status that did not match.
+			responseErr = checkSuccessCondition(h.compiled, action.SuccessCondition, resp.StatusCode, resp.Header, respBody)
+			statusMatched = responseErr == nil || resp.StatusCode/100 == 2 && !retryOnStatus[resp.StatusCode]
+		} else if statusMatched {
+			responseErr = checkExpectedResponse(h.compiled, action.ExpectedResponse, respBody)
+		}
+		if statusMatched {
+			if err := responseErr; err != nil {
+				if attempt < maxAttempts {
+					sleep := backoffSleep(h.rng, backoffBase, maxBackoff, attempt)
+					metrics.StatusRetryCount++
+					metrics.BackoffMillis += sleep.Milliseconds()
+					logger.Info("HTTP retry (unexpected response)",
+						"url", action.URL,
+						"status", resp.StatusCode,
+						"attempt", attempt,
+						"reason", redact.redact(err.Error()),
+						"sleep", sleep.String(),
+					)
+					if err := h.backoff(ctx, sleep, attempt); err != nil {
+						metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+						return metrics, fmt.Errorf("http retry aborted: %w", err)
+					}
+					continue
+				}
+				metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+				return metrics, fmt.Errorf("http call failed: status=%d unexpected response: %w", resp.StatusCode, err)
+			}
+			if action.ResponseCapture != nil {
+				captured, err := captureResponse(action.ResponseCapture, resp.StatusCode, respBody)
+				if err != nil {
+					logger.Info("Response capture incomplete",
+						"url", action.URL,
+						"error", err.Error(),
+					)
+				}
+				metrics.Captured = captured
+			}
+			if len(action.Outputs) > 0 {
+				outputs, err := captureResponse(&opsv1alpha1.ResponseCaptureSpec{Fields: action.Outputs}, resp.StatusCode, respBody)
+				if err != nil {
+					logger.Info("Action outputs incomplete",
+						"url", action.URL,
+						"error", err.Error(),
+					)
+				}
+				metrics.Outputs = outputs
+			}
 			metrics.DurationMillis = time.Since(startedAt).Milliseconds()
 			return metrics, nil
 		}
@@ -209,6 +426,10 @@ func (h *HTTPExecutor) ExecuteWithMetrics(
 		// retry on configured status codes
 		if retryOnStatus[resp.StatusCode] && attempt < maxAttempts {
 			sleep := backoffSleep(h.rng, backoffBase, maxBackoff, attempt)
+			// Receivers that rate limit tell how long to wait.
+			if wait, ok := retryAfter(resp.Header, time.Now()); ok {
+				sleep = min(wait, maxBackoff)
+			}
 			metrics.StatusRetryCount++
 			metrics.BackoffMillis += sleep.Milliseconds()
 			logger.Info("HTTP retry (status)",
@@ -217,23 +438,104 @@ func (h *HTTPExecutor) ExecuteWithMetrics(
 				"attempt", attempt,
 				"sleep", sleep.String(),
 			)
-			time.Sleep(sleep)
+			if err := h.backoff(ctx, sleep, attempt); err != nil {
+				metrics.DurationMillis = time.Since(startedAt).Milliseconds()
+				return metrics, fmt.Errorf("http retry aborted: %w", err)
+			}
 			continue
 		}
 
 		// final error
 		metrics.DurationMillis = time.Since(startedAt).Milliseconds()
-		return metrics, fmt.Errorf("http call failed: status=%d body=%s", resp.StatusCode, string(respBody))
+		return metrics, fmt.Errorf("http call failed: status=%d body=%s", resp.StatusCode, redact.redactBody(respBody))
 	}
 
 	metrics.DurationMillis = time.Since(startedAt).Milliseconds()
 	return metrics, fmt.Errorf("http call failed after %d attempts", maxAttempts)
 }
 
-func (h *HTTPExecutor) buildTransport(ctx context.Context, raNamespace string, tlsSpec *opsv1alpha1.TLSSpec) (*http.Transport, error) {
+// buildTransport returns the transport for the TLS and proxy settings of an
+// action. With a transport cache, the transport is reused until the Secrets or
+// Vault values it was built from change.
+func (h *HTTPExecutor) buildTransport(
+	ctx context.Context,
+	raNamespace string,
+	tlsSpec *opsv1alpha1.TLSSpec,
+	proxySpec *opsv1alpha1.ProxySpec,
+) (*http.Transport, error) {
+	material, err := h.transportMaterial(ctx, raNamespace, tlsSpec, proxySpec)
+	if err != nil {
+		return nil, err
+	}
+	return h.transports.get(raNamespace, tlsSpec, proxySpec, material, func() (*http.Transport, error) {
+		return newTransport(material, raNamespace, tlsSpec, proxySpec)
+	})
+}
+
+// transportMaterial reads the certificates and proxy credentials of the TLS
+// and proxy settings.
+func (h *HTTPExecutor) transportMaterial(
+	ctx context.Context,
+	raNamespace string,
+	tlsSpec *opsv1alpha1.TLSSpec,
+	proxySpec *opsv1alpha1.ProxySpec,
+) (transportMaterial, error) {
+	var m transportMaterial
+	if proxySpec != nil {
+		proxyURL, err := h.proxyURL(ctx, raNamespace, proxySpec)
+		if err != nil {
+			return m, err
+		}
+		m.proxyURL = proxyURL
+	}
+	if tlsSpec == nil {
+		return m, nil
+	}
+
+	// CA from secret
+	if tlsSpec.CaSecretRef != nil {
+		sec, err := h.secrets.get(ctx, client.ObjectKey{
+			Name:      tlsSpec.CaSecretRef.Name,
+			Namespace: raNamespace,
+		})
+		if err != nil {
+			return m, err
+		}
+		m.ca = sec.Data[tlsSpec.CaSecretRef.Key]
+		if len(m.ca) == 0 {
+			return m, fmt.Errorf("caSecretRef %s/%s key %q empty", raNamespace, tlsSpec.CaSecretRef.Name, tlsSpec.CaSecretRef.Key)
+		}
+	}
+
+	// mTLS client cert
+	if tlsSpec.ClientCertSecretRef != nil {
+		sec, err := h.secrets.get(ctx, client.ObjectKey{
+			Name:      tlsSpec.ClientCertSecretRef.Name,
+			Namespace: raNamespace,
+		})
+		if err != nil {
+			return m, err
+		}
+		m.certPEM = sec.Data[tlsSpec.ClientCertSecretRef.CertKey]
+		m.keyPEM = sec.Data[tlsSpec.ClientCertSecretRef.KeyKey]
+		if len(m.certPEM) == 0 || len(m.keyPEM) == 0 {
+			return m, fmt.Errorf("clientCertSecretRef %s/%s missing cert/key", raNamespace, tlsSpec.ClientCertSecretRef.Name)
+		}
+	}
+	return m, nil
+}
+
+// newTransport builds a transport from the material read for the TLS and
+// proxy settings.
+func newTransport(
+	m transportMaterial,
+	raNamespace string,
+	tlsSpec *opsv1alpha1.TLSSpec,
+	proxySpec *opsv1alpha1.ProxySpec,
+) (*http.Transport, error) {
 	// base transport (keepalive)
 	tr := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: proxySelector(m.proxyURL, proxySpec),
 		DialContext: (&net.Dialer{
 			Timeout:   5 * time.Second,
 			KeepAlive: 30 * time.Second,
@@ -254,58 +556,134 @@ func (h *HTTPExecutor) buildTransport(ctx context.Context, raNamespace string, t
 		MinVersion:         tls.VersionTLS12,
 		InsecureSkipVerify: tlsSpec.InsecureSkipVerify,
 	}
+	if err := applyTLSVersions(cfg, tlsSpec); err != nil {
+		return nil, err
+	}
 
 	if tlsSpec.ServerName != "" {
 		cfg.ServerName = tlsSpec.ServerName
 	}
 
-	// CA from secret
-	if tlsSpec.CaSecretRef != nil {
-		var sec corev1.Secret
-		if err := h.k8s.Get(ctx, client.ObjectKey{
-			Name:      tlsSpec.CaSecretRef.Name,
-			Namespace: raNamespace,
-		}, &sec); err != nil {
-			return nil, err
-		}
-
-		ca := sec.Data[tlsSpec.CaSecretRef.Key]
-		if len(ca) == 0 {
-			return nil, fmt.Errorf("caSecretRef %s/%s key %q empty", raNamespace, tlsSpec.CaSecretRef.Name, tlsSpec.CaSecretRef.Key)
-		}
-
+	if m.ca != nil {
 		pool := x509.NewCertPool()
-		if ok := pool.AppendCertsFromPEM(ca); !ok {
+		if ok := pool.AppendCertsFromPEM(m.ca); !ok {
 			return nil, fmt.Errorf("failed to parse CA PEM from %s/%s", raNamespace, tlsSpec.CaSecretRef.Name)
 		}
 		cfg.RootCAs = pool
 	}
 
-	// mTLS client cert
-	if tlsSpec.ClientCertSecretRef != nil {
-		var sec corev1.Secret
-		if err := h.k8s.Get(ctx, client.ObjectKey{
-			Name:      tlsSpec.ClientCertSecretRef.Name,
-			Namespace: raNamespace,
-		}, &sec); err != nil {
+	if m.certPEM != nil {
+		cert, err := tls.X509KeyPair(m.certPEM, m.keyPEM)
+		if err != nil {
 			return nil, err
 		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
 
-		certPEM := sec.Data[tlsSpec.ClientCertSecretRef.CertKey]
-		keyPEM := sec.Data[tlsSpec.ClientCertSecretRef.KeyKey]
-		if len(certPEM) == 0 || len(keyPEM) == 0 {
-			return nil, fmt.Errorf("clientCertSecretRef %s/%s missing cert/key", raNamespace, tlsSpec.ClientCertSecretRef.Name)
+	tr.TLSClientConfig = cfg
+	return tr, nil
+}
+
+// applyTLSVersions sets the TLS versions and cipher suites of spec on cfg.
+func applyTLSVersions(cfg *tls.Config, spec *opsv1alpha1.TLSSpec) error {
+	if spec.MinVersion != "" {
+		v, err := parseTLSVersion(spec.MinVersion)
+		if err != nil {
+			return fmt.Errorf("tls.minVersion: %w", err)
+		}
+		cfg.MinVersion = v
+	}
+	if spec.MaxVersion != "" {
+		v, err := parseTLSVersion(spec.MaxVersion)
+		if err != nil {
+			return fmt.Errorf("tls.maxVersion: %w", err)
 		}
+		cfg.MaxVersion = v
+	}
+	for _, name := range spec.CipherSuites {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			return fmt.Errorf("tls.cipherSuites: unsupported cipher suite %q", name)
+		}
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+	return nil
+}
 
-		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+func parseTLSVersion(v string) (uint16, error) {
+	switch v {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q", v)
+	}
+}
+
+// cipherSuiteID resolves the IANA name of a cipher suite without known
+// security issues.
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// validateProxyURL checks the URL of the proxy of spec like the URL of an
+// action, so a proxy cannot reach targets the action could not.
+func validateProxyURL(compiled *compiledSpec, spec *opsv1alpha1.ProxySpec, policy *opsv1alpha1.URLPolicySpec, operator *opsv1alpha1.OperatorURLPolicy) error {
+	if spec == nil {
+		return nil
+	}
+	if err := validateTargetURL(compiled, spec.URL, policy, operator); err != nil {
+		return fmt.Errorf("proxy: %w", err)
+	}
+	return nil
+}
+
+// proxyURL returns the URL of the proxy of spec with its credentials.
+func (h *HTTPExecutor) proxyURL(
+	ctx context.Context,
+	raNamespace string,
+	spec *opsv1alpha1.ProxySpec,
+) (*url.URL, error) {
+	proxyURL, err := url.Parse(spec.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if creds := spec.Credentials; creds != nil {
+		username, err := resolveValueFrom(ctx, h.secrets, h.vault, creds.Username, raNamespace)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("proxy credentials: %w", err)
 		}
-		cfg.Certificates = []tls.Certificate{cert}
+		password, err := resolveValueFrom(ctx, h.secrets, h.vault, creds.Password, raNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("proxy credentials: %w", err)
+		}
+		// The transport sends the user info as Proxy-Authorization header.
+		proxyURL.User = url.UserPassword(username, password)
 	}
+	return proxyURL, nil
+}
 
-	tr.TLSClientConfig = cfg
-	return tr, nil
+// proxySelector selects proxyURL for the targets spec does not exclude, or
+// the proxy from the environment when the action has no proxy.
+func proxySelector(proxyURL *url.URL, spec *opsv1alpha1.ProxySpec) func(*http.Request) (*url.URL, error) {
+	if spec == nil {
+		return http.ProxyFromEnvironment
+	}
+	cfg := httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    strings.Join(spec.NoProxy, ","),
+	}
+	selectProxy := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return selectProxy(req.URL)
+	}
 }
 
 func parseDurationDefault(s string, def time.Duration) time.Duration {
@@ -319,6 +697,14 @@ func parseDurationDefault(s string, def time.Duration) time.Duration {
 	return d
 }
 
+// defaultMaxResponseBytes caps response bodies unless maxResponseBytes is
+// set, so a receiver streaming a huge body cannot exhaust the memory of the
+// operator.
+const defaultMaxResponseBytes = 1 << 20
+
+// maxRetryAfter caps Retry-After delays before maxBackoff applies.
+const maxRetryAfter = 24 * time.Hour
+
 func backoffSleep(rng *rand.Rand, base, max time.Duration, attempt int) time.Duration {
 	// exponential: base * 2^(attempt-1)
 	mult := 1 << (attempt - 1)
@@ -335,6 +721,80 @@ func backoffSleep(rng *rand.Rand, base, max time.Duration, attempt int) time.Dur
 	return sleep
 }
 
+// readResponseBody reads at most limit bytes of body and reports whether the
+// body was longer.
+func readResponseBody(body io.Reader, limit int64) ([]byte, bool) {
+	data, _ := io.ReadAll(io.LimitReader(body, limit+1))
+	if int64(len(data)) > limit {
+		return data[:limit], true
+	}
+	return data, false
+}
+
+// retryAfter returns the delay a Retry-After header asks for, given in
+// seconds or as an HTTP date. It reports false when the header is missing or
+// invalid.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		// Cap before converting, so huge values do not overflow.
+		return time.Duration(min(seconds, int64(maxRetryAfter/time.Second))) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+// retryLaterError is returned by an HTTP action with deferred retries when
+// the next attempt is due after delay.
+type retryLaterError struct {
+	delay time.Duration
+	// attempt is the attempt that failed.
+	attempt int
+}
+
+func (r *retryLaterError) Error() string {
+	return fmt.Sprintf("attempt %d failed, retrying in %s", r.attempt, r.delay)
+}
+
+// backoff waits d before the attempt after attempt, or returns a
+// retryLaterError when retries are deferred.
+func (h *HTTPExecutor) backoff(ctx context.Context, d time.Duration, attempt int) error {
+	if h.deferRetries {
+		return &retryLaterError{delay: d, attempt: attempt}
+	}
+	return sleepContext(ctx, d)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	// Give up right away instead of sleeping into a deadline that leaves no
+	// time for another attempt.
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return fmt.Errorf("backoff of %s would pass the deadline: %w", d, context.DeadlineExceeded)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryableNetErrPattern matches the messages of network errors worth
+// retrying.
+var retryableNetErrPattern = regexp.MustCompile(`(?i)connection reset|broken pipe|EOF|i/o timeout|tls handshake timeout`)
+
 func isRetryableNetErr(err error) bool {
 	// very pragmatic: timeout / connection resets
 	if nerr, ok := err.(net.Error); ok {
@@ -343,12 +803,13 @@ func isRetryableNetErr(err error) bool {
 		}
 	}
 	// match common strings (safe-ish)
-	msg := err.Error()
-	re := regexp.MustCompile(`(?i)connection reset|broken pipe|EOF|i/o timeout|tls handshake timeout`)
-	return re.MatchString(msg)
+	return retryableNetErrPattern.MatchString(err.Error())
 }
 
-func validateTargetURL(rawURL string, policy *opsv1alpha1.URLPolicySpec) error {
+// validateTargetURL checks rawURL against the default safety policy, the
+// urlPolicy of the action and the operator URL policy. The regular
+// expressions of the urlPolicy are compiled once per generation in compiled.
+func validateTargetURL(compiled *compiledSpec, rawURL string, policy *opsv1alpha1.URLPolicySpec, operator *opsv1alpha1.OperatorURLPolicy) error {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return fmt.Errorf("invalid action URL: %w", err)
@@ -358,16 +819,20 @@ func validateTargetURL(rawURL string, policy *opsv1alpha1.URLPolicySpec) error {
 		return fmt.Errorf("invalid action URL: host is empty")
 	}
 
-	if (policy == nil || !policy.AllowUnsafeLocalTargets) && isDefaultBlockedHost(host) {
+	allowUnsafe := policy != nil && policy.AllowUnsafeLocalTargets && operator.AllowsUnsafeLocalTargets()
+	if !allowUnsafe && isDefaultBlockedHost(host) {
 		return fmt.Errorf("action URL host %q is blocked by default safety policy", host)
 	}
+	if err := operator.CheckURL(rawURL); err != nil {
+		return fmt.Errorf("action URL: %w", err)
+	}
 
 	if policy == nil {
 		return nil
 	}
 
 	if len(policy.BlockedHostRegex) > 0 {
-		blocked, err := matchAnyRegex(policy.BlockedHostRegex, host)
+		blocked, err := matchAnyRegex(compiled, "urlPolicy.blockedHostRegex", policy.BlockedHostRegex, host)
 		if err != nil {
 			return err
 		}
@@ -377,7 +842,7 @@ func validateTargetURL(rawURL string, policy *opsv1alpha1.URLPolicySpec) error {
 	}
 
 	if len(policy.AllowedHostRegex) > 0 {
-		allowed, err := matchAnyRegex(policy.AllowedHostRegex, host)
+		allowed, err := matchAnyRegex(compiled, "urlPolicy.allowedHostRegex", policy.AllowedHostRegex, host)
 		if err != nil {
 			return err
 		}
@@ -389,9 +854,9 @@ func validateTargetURL(rawURL string, policy *opsv1alpha1.URLPolicySpec) error {
 	return nil
 }
 
-func matchAnyRegex(patterns []string, value string) (bool, error) {
+func matchAnyRegex(compiled *compiledSpec, field string, patterns []string, value string) (bool, error) {
 	for _, p := range patterns {
-		re, err := regexp.Compile(p)
+		re, err := compiled.compileRegexp(field, p)
 		if err != nil {
 			return false, fmt.Errorf("invalid urlPolicy regex %q: %w", p, err)
 		}
@@ -1,12 +1,24 @@
 package engine
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -73,7 +85,7 @@ func TestHTTPExecutorExecuteWithMetrics_StatusRetry(t *testing.T) {
 }
 
 func TestValidateTargetURL_DefaultBlocked(t *testing.T) {
-	err := validateTargetURL("http://127.0.0.1:8080/hook", nil)
+	err := validateTargetURL(nil, "http://127.0.0.1:8080/hook", nil, nil)
 	if err == nil {
 		t.Fatalf("expected localhost/IP safety policy error, got nil")
 	}
@@ -85,22 +97,575 @@ func TestValidateTargetURL_AllowlistAndBlocklist(t *testing.T) {
 		BlockedHostRegex: []string{`^blocked\.example\.com$`},
 	}
 
-	if err := validateTargetURL("https://api.example.com/hook", policy); err != nil {
+	if err := validateTargetURL(nil, "https://api.example.com/hook", policy, nil); err != nil {
 		t.Fatalf("expected allowed host to pass, got error: %v", err)
 	}
 
-	if err := validateTargetURL("https://blocked.example.com/hook", policy); err == nil {
+	if err := validateTargetURL(nil, "https://blocked.example.com/hook", policy, nil); err == nil {
 		t.Fatalf("expected blocked host to fail, got nil")
 	}
 
-	if err := validateTargetURL("https://other.example.com/hook", policy); err == nil {
+	if err := validateTargetURL(nil, "https://other.example.com/hook", policy, nil); err == nil {
 		t.Fatalf("expected non-allowlisted host to fail, got nil")
 	}
 }
 
 func TestValidateTargetURL_AllowUnsafeLocalTargets(t *testing.T) {
 	policy := &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}
-	if err := validateTargetURL("http://127.0.0.1:8080/hook", policy); err != nil {
+	if err := validateTargetURL(nil, "http://127.0.0.1:8080/hook", policy, nil); err != nil {
 		t.Fatalf("expected localhost to be allowed when explicitly opted in, got error: %v", err)
 	}
 }
+
+func TestHTTPExecutorExecuteWithMetrics_RetryBackoffRespectsContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "retry", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	startedAt := time.Now()
+	_, err := exec.ExecuteWithMetrics(ctx, opsv1alpha1.ActionSpec{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		Timeout:   "2s",
+		Retry: &opsv1alpha1.RetrySpec{
+			MaxAttempts:   5,
+			Backoff:       "10s",
+			MaxBackoff:    "10s",
+			RetryOnStatus: []int{503},
+		},
+	}, "default", obj, nil)
+	if err == nil {
+		t.Fatalf("expected deadline error, got nil")
+	}
+	if elapsed := time.Since(startedAt); elapsed > 2*time.Second {
+		t.Fatalf("expected retry backoff to stop at the context deadline, took %s", elapsed)
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_PropagatesTraceContext(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if _, err := exec.ExecuteWithMetrics(ctx, opsv1alpha1.ActionSpec{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+	}, "default", obj, nil); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if traceparent == "" {
+		t.Fatalf("expected traceparent header on outbound request")
+	}
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Fatalf("expected traceparent %q to carry trace id %s", traceparent, span.SpanContext().TraceID())
+	}
+}
+
+func TestHTTPExecutorBuildTransport_UsesActionProxy(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"username": []byte("egress"), "password": []byte("s3cret")},
+	}
+	h := NewHTTPExecutor(fake.NewClientBuilder().WithObjects(secret).Build())
+	secretRef := func(key string) opsv1alpha1.ValueFrom {
+		return opsv1alpha1.ValueFrom{SecretKeyRef: &opsv1alpha1.SecretKeyRef{Name: "proxy-credentials", Key: key}}
+	}
+
+	tr, err := h.buildTransport(context.Background(), "default", nil, &opsv1alpha1.ProxySpec{
+		URL:         "http://proxy.example.com:3128",
+		NoProxy:     []string{".internal.example.com"},
+		Credentials: &opsv1alpha1.BasicAuth{Username: secretRef("username"), Password: secretRef("password")},
+	})
+	if err != nil {
+		t.Fatalf("buildTransport() error = %v", err)
+	}
+	proxy := tr.Proxy
+
+	req, _ := http.NewRequest(http.MethodPost, "https://hooks.example.com/deploy", nil)
+	proxyURL, err := proxy(req)
+	if err != nil {
+		t.Fatalf("proxy() error = %v", err)
+	}
+	if proxyURL == nil || proxyURL.Host != "proxy.example.com:3128" || proxyURL.User.String() != "egress:s3cret" {
+		t.Fatalf("proxy() = %v, want proxy.example.com:3128 with credentials from the Secret", proxyURL)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "https://api.internal.example.com/deploy", nil)
+	if proxyURL, err := proxy(req); err != nil || proxyURL != nil {
+		t.Fatalf("proxy() = %v, %v, want no proxy for a noProxy host", proxyURL, err)
+	}
+}
+
+func TestValidateTargetURL_OperatorURLPolicy(t *testing.T) {
+	operator, err := opsv1alpha1.NewOperatorURLPolicy([]string{`api\.example\.com`}, nil, true)
+	if err != nil {
+		t.Fatalf("NewOperatorURLPolicy() error = %v", err)
+	}
+	if err := validateTargetURL(nil, "https://api.example.com/hook", nil, operator); err != nil {
+		t.Fatalf("expected allowed host, got %v", err)
+	}
+	if err := validateTargetURL(nil, "https://other.example.com/hook", nil, operator); err == nil {
+		t.Fatalf("expected host outside the operator allowlist to be rejected")
+	}
+	local := &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}
+	if err := validateTargetURL(nil, "http://127.0.0.1:8080/hook", local, operator); err == nil {
+		t.Fatalf("expected allowUnsafeLocalTargets to be ignored")
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_ValidatesProxyURL(t *testing.T) {
+	h := NewHTTPExecutor(nil)
+	action := opsv1alpha1.ActionSpec{
+		Type:  "http",
+		URL:   "https://hooks.example.com/deploy",
+		Proxy: &opsv1alpha1.ProxySpec{URL: "http://169.254.169.254:80"},
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if _, err := h.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err == nil || !strings.Contains(err.Error(), "proxy") {
+		t.Fatalf("ExecuteWithMetrics() error = %v, want the proxy URL to be blocked", err)
+	}
+
+	operator, err := opsv1alpha1.NewOperatorURLPolicy(nil, []string{`proxy\.example\.com`}, false)
+	if err != nil {
+		t.Fatalf("NewOperatorURLPolicy() error = %v", err)
+	}
+	if err := validateProxyURL(nil, &opsv1alpha1.ProxySpec{URL: "http://proxy.example.com:3128"}, nil, operator); err == nil {
+		t.Fatalf("expected a proxy blocked by the operator URL policy to be rejected")
+	}
+	local := &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}
+	if err := validateProxyURL(nil, &opsv1alpha1.ProxySpec{URL: "http://127.0.0.1:3128"}, local, nil); err != nil {
+		t.Fatalf("expected allowUnsafeLocalTargets to allow a local proxy, got %v", err)
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_RedactsEchoedSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("invalid credentials: " + r.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+
+	h := NewHTTPExecutor(nil)
+	action := opsv1alpha1.ActionSpec{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	metrics, err := h.ExecuteWithMetrics(context.Background(), action, "default", obj, map[string]string{"Authorization": "Bearer s3cr3t"})
+	if err == nil {
+		t.Fatalf("expected error for status 401")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") || strings.Contains(metrics.Response.Body, "s3cr3t") {
+		t.Fatalf("secret leaked: error=%q body=%q", err.Error(), metrics.Response.Body)
+	}
+}
+
+func TestHTTPExecutorBuildTransport_TLSVersions(t *testing.T) {
+	h := NewHTTPExecutor(nil)
+	tr, err := h.buildTransport(context.Background(), "default", &opsv1alpha1.TLSSpec{MinVersion: "1.3"}, nil)
+	if err != nil {
+		t.Fatalf("buildTransport() error = %v", err)
+	}
+	if tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("MinVersion = %x, want TLS 1.3", tr.TLSClientConfig.MinVersion)
+	}
+
+	tr, err = h.buildTransport(context.Background(), "default", &opsv1alpha1.TLSSpec{
+		MaxVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("buildTransport() error = %v", err)
+	}
+	cfg := tr.TLSClientConfig
+	if cfg.MinVersion != tls.VersionTLS12 || cfg.MaxVersion != tls.VersionTLS12 ||
+		len(cfg.CipherSuites) != 1 || cfg.CipherSuites[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Fatalf("TLS config = min %x max %x suites %v, want TLS 1.2 with one suite", cfg.MinVersion, cfg.MaxVersion, cfg.CipherSuites)
+	}
+
+	if _, err := h.buildTransport(context.Background(), "default", &opsv1alpha1.TLSSpec{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}, nil); err == nil {
+		t.Fatalf("expected error for an insecure cipher suite")
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_RetriesUnexpectedResponse(t *testing.T) {
+	attempt := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt < 2 {
+			_, _ = w.Write([]byte(`{"status":"error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	ok := "ok"
+	action := opsv1alpha1.ActionSpec{
+		Type:             "http",
+		Method:           "POST",
+		URL:              srv.URL,
+		URLPolicy:        &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		ExpectedStatus:   "^2..$",
+		ExpectedResponse: &opsv1alpha1.ExpectedResponseSpec{JSONPath: "{.status}", Value: &ok},
+		Timeout:          "2s",
+		Retry: &opsv1alpha1.RetrySpec{
+			MaxAttempts: 2,
+			Backoff:     "1ms",
+			MaxBackoff:  "2ms",
+		},
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "demo", "namespace": "default"},
+	}}
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	metrics, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if err != nil {
+		t.Fatalf("expected success after retry, got error: %v", err)
+	}
+	if metrics.Attempts != 2 || metrics.StatusRetryCount != 1 {
+		t.Fatalf("expected 2 attempts and 1 retry, got %d attempts and %d retries", metrics.Attempts, metrics.StatusRetryCount)
+	}
+
+	attempt = 0
+	action.Retry.MaxAttempts = 1
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err == nil ||
+		!strings.Contains(err.Error(), "unexpected response") {
+		t.Fatalf("expected unexpected response error, got %v", err)
+	}
+}
+
+func TestCheckExpectedResponse(t *testing.T) {
+	ok := "ok"
+	tests := []struct {
+		name     string
+		expected *opsv1alpha1.ExpectedResponseSpec
+		body     string
+		wantErr  bool
+	}{
+		{name: "none", body: "anything"},
+		{name: "regex match", expected: &opsv1alpha1.ExpectedResponseSpec{BodyRegex: `"accepted":\s*true`}, body: `{"accepted": true}`},
+		{name: "regex mismatch", expected: &opsv1alpha1.ExpectedResponseSpec{BodyRegex: `"accepted":\s*true`}, body: `{"accepted": false}`, wantErr: true},
+		{name: "value match", expected: &opsv1alpha1.ExpectedResponseSpec{JSONPath: "{.status}", Value: &ok}, body: `{"status":"ok"}`},
+		{name: "value mismatch", expected: &opsv1alpha1.ExpectedResponseSpec{JSONPath: "{.status}", Value: &ok}, body: `{"status":"error"}`, wantErr: true},
+		{name: "path present", expected: &opsv1alpha1.ExpectedResponseSpec{JSONPath: "{.id}"}, body: `{"id":"42"}`},
+		{name: "path missing", expected: &opsv1alpha1.ExpectedResponseSpec{JSONPath: "{.id}"}, body: `{}`, wantErr: true},
+		{name: "not JSON", expected: &opsv1alpha1.ExpectedResponseSpec{JSONPath: "{.id}"}, body: "ok", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkExpectedResponse(nil, tt.expected, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_BodyContentTypeAndCompression(t *testing.T) {
+	var contentType, contentEncoding, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		contentEncoding = r.Header.Get("Content-Encoding")
+		reader := io.Reader(r.Body)
+		if contentEncoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			reader = zr
+		}
+		b, _ := io.ReadAll(reader)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "demo", "namespace": "default"},
+	}}
+	action := opsv1alpha1.ActionSpec{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		Body:      &opsv1alpha1.TemplateSpec{Template: `{"name":"{{ .metadata.name }}"}`},
+	}
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err != nil {
+		t.Fatalf("ExecuteWithMetrics() error = %v", err)
+	}
+	if contentType != "application/json" || contentEncoding != "" || body != `{"name":"demo"}` {
+		t.Fatalf("got Content-Type %q, Content-Encoding %q and body %q", contentType, contentEncoding, body)
+	}
+
+	action.Body = &opsv1alpha1.TemplateSpec{
+		Template:    `name={{ .metadata.name | urlquery }}`,
+		ContentType: "application/x-www-form-urlencoded",
+		Compression: "gzip",
+	}
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err != nil {
+		t.Fatalf("ExecuteWithMetrics() error = %v", err)
+	}
+	if contentType != "application/x-www-form-urlencoded" || contentEncoding != "gzip" || body != "name=demo" {
+		t.Fatalf("got Content-Type %q, Content-Encoding %q and body %q", contentType, contentEncoding, body)
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_HonorsRetryAfter(t *testing.T) {
+	attempt := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "demo", "namespace": "default"},
+	}}
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	metrics, err := exec.ExecuteWithMetrics(context.Background(), opsv1alpha1.ActionSpec{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		Retry: &opsv1alpha1.RetrySpec{
+			MaxAttempts: 2,
+			Backoff:     "1ms",
+			MaxBackoff:  "30ms",
+		},
+	}, "default", obj, nil)
+	if err != nil {
+		t.Fatalf("expected success after retry, got error: %v", err)
+	}
+	// Retry-After asks for 120s; maxBackoff bounds it without jitter.
+	if metrics.BackoffMillis != 30 {
+		t.Fatalf("expected a 30ms backoff bounded by maxBackoff, got %dms", metrics.BackoffMillis)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "5", want: 5 * time.Second, wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: "99999999999999", want: maxRetryAfter, wantOK: true},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.value != "" {
+			header.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_MaxResponseBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok","padding":"`))
+		chunk := []byte(strings.Repeat("x", 64*1024))
+		for i := 0; i < 64; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "demo", "namespace": "default"},
+	}}
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	action := opsv1alpha1.ActionSpec{
+		Type:             "http",
+		URL:              srv.URL,
+		URLPolicy:        &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		MaxResponseBytes: 16,
+		ExpectedResponse: &opsv1alpha1.ExpectedResponseSpec{BodyRegex: `^\{"status":"ok"`},
+	}
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err != nil {
+		t.Fatalf("expected the truncated body to pass the check, got %v", err)
+	}
+
+	action.ExpectedResponse = &opsv1alpha1.ExpectedResponseSpec{BodyRegex: `padding`}
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err == nil {
+		t.Fatalf("expected the check to only see the first 16 bytes")
+	}
+}
+
+func TestReadResponseBody(t *testing.T) {
+	body, truncated := readResponseBody(strings.NewReader("0123456789"), 10)
+	if string(body) != "0123456789" || truncated {
+		t.Fatalf("readResponseBody() = %q, %v, want the whole body", body, truncated)
+	}
+	body, truncated = readResponseBody(strings.NewReader("0123456789"), 4)
+	if string(body) != "0123" || !truncated {
+		t.Fatalf("readResponseBody() = %q, %v, want the first 4 bytes truncated", body, truncated)
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_AttemptAndOverallTimeout(t *testing.T) {
+	var calls atomic.Int32
+	var hangAll atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first request hangs past the attempt timeout.
+		if calls.Add(1) == 1 || hangAll.Load() {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	action := opsv1alpha1.ActionSpec{
+		Type:           "http",
+		URL:            srv.URL,
+		URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		Timeout:        "10s",
+		AttemptTimeout: "50ms",
+		OverallTimeout: "5s",
+		Retry:          &opsv1alpha1.RetrySpec{MaxAttempts: 3, Backoff: "1ms", MaxBackoff: "2ms"},
+	}
+
+	metrics, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if err != nil {
+		t.Fatalf("expected the retry after the attempt timeout to succeed, got %v", err)
+	}
+	if metrics.Attempts != 2 || metrics.NetworkRetryCount != 1 {
+		t.Fatalf("expected 2 attempts and 1 network retry, got %d and %d", metrics.Attempts, metrics.NetworkRetryCount)
+	}
+
+	// Every request hangs now, so the overall timeout ends the retries.
+	hangAll.Store(true)
+	action.OverallTimeout = "120ms"
+	action.Retry = &opsv1alpha1.RetrySpec{MaxAttempts: 20, Backoff: "10ms", MaxBackoff: "10ms"}
+	startedAt := time.Now()
+	metrics, err = exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(startedAt); elapsed > time.Second {
+		t.Fatalf("expected the overall timeout to stop the retries, took %s", elapsed)
+	}
+	if metrics.Attempts >= 20 {
+		t.Fatalf("expected the overall timeout to cut the attempts short, got %d", metrics.Attempts)
+	}
+}
+
+func TestSleepContext_StopsBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	startedAt := time.Now()
+	if err := sleepContext(ctx, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(startedAt); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected sleepContext to return right away, took %s", elapsed)
+	}
+}
+
+func TestHTTPExecutorExecuteWithMetrics_SuccessCondition(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/exists" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if calls.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"result":"pending"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	}))
+	defer srv.Close()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	action := opsv1alpha1.ActionSpec{
+		Type:             "http",
+		URL:              srv.URL,
+		URLPolicy:        &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		SuccessCondition: `status == 200 && body.result == "ok"`,
+		Retry:            &opsv1alpha1.RetrySpec{MaxAttempts: 3, Backoff: "1ms", MaxBackoff: "2ms"},
+	}
+
+	metrics, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if err != nil {
+		t.Fatalf("expected the second response to meet the condition, got %v", err)
+	}
+	if metrics.Attempts != 2 || metrics.StatusRetryCount != 1 {
+		t.Fatalf("expected 2 attempts and 1 retry, got %d and %d", metrics.Attempts, metrics.StatusRetryCount)
+	}
+
+	// A status that expectedStatus would reject can succeed.
+	action.URL = srv.URL + "/exists"
+	action.SuccessCondition = `status in [200, 409]`
+	if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil); err != nil {
+		t.Fatalf("expected 409 to meet the condition, got %v", err)
+	}
+
+	action.SuccessCondition = `status == 200`
+	metrics, err = exec.ExecuteWithMetrics(context.Background(), action, "default", obj, nil)
+	if err == nil || metrics.Attempts != 1 {
+		t.Fatalf("expected 409 to fail without retries, got %v after %d attempts", err, metrics.Attempts)
+	}
+}
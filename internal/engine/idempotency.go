@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+const defaultIdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKey derives the idempotency key of the action at actionIndex for
+// input. It only depends on values that survive an operator restart, so a
+// redelivered event gets the key of the first delivery.
+func idempotencyKey(ra *opsv1alpha1.ResourceAction, actionIndex int, input MatchInput) string {
+	h := sha256.New()
+	if input.batch != nil {
+		// An aggregated batch is identified by the events it delivers.
+		for _, event := range input.batch {
+			h.Write([]byte(idempotencyKey(ra, actionIndex, event)))
+		}
+		if input.group.status() == groupStatusResolved {
+			// The resolution delivers the last batch of its group again.
+			h.Write([]byte(groupStatusResolved))
+		}
+		return hex.EncodeToString(h.Sum(nil))
+	}
+	for _, part := range []string{
+		string(ra.UID),
+		strconv.Itoa(actionIndex),
+		string(input.Obj.GetUID()),
+		string(input.Event),
+		strconv.FormatInt(input.Obj.GetGeneration(), 10),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if !input.ScheduledAt.IsZero() {
+		h.Write([]byte(input.ScheduledAt.UTC().Format(time.RFC3339)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// addIdempotencyKey sets the idempotency key header configured by spec.
+func addIdempotencyKey(
+	headers map[string]string,
+	spec *opsv1alpha1.IdempotencyKeySpec,
+	ra *opsv1alpha1.ResourceAction,
+	actionIndex int,
+	input MatchInput,
+) {
+	if spec == nil {
+		return
+	}
+	headers[idempotencyKeyHeader(spec)] = idempotencyKey(ra, actionIndex, input)
+}
+
+func idempotencyKeyHeader(spec *opsv1alpha1.IdempotencyKeySpec) string {
+	if spec.Header == "" {
+		return defaultIdempotencyKeyHeader
+	}
+	return spec.Header
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{UID: "ra-uid"}}
+	base := newDeploymentInput("uid-1", "demo", "default")
+	key := idempotencyKey(ra, 0, base)
+
+	if again := idempotencyKey(ra, 0, newDeploymentInput("uid-1", "demo", "default")); again != key {
+		t.Fatalf("expected the same input to get the same key, got %q and %q", key, again)
+	}
+
+	update := newDeploymentInput("uid-1", "demo", "default")
+	update.Event = EventUpdate
+	nextGeneration := newDeploymentInput("uid-1", "demo", "default")
+	nextGeneration.Obj.SetGeneration(2)
+	scheduled := newDeploymentInput("uid-1", "demo", "default")
+	scheduled.ScheduledAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	otherRA := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{UID: "other-uid"}}
+
+	for name, other := range map[string]string{
+		"event":          idempotencyKey(ra, 0, update),
+		"generation":     idempotencyKey(ra, 0, nextGeneration),
+		"object":         idempotencyKey(ra, 0, newDeploymentInput("uid-2", "demo", "default")),
+		"action":         idempotencyKey(ra, 1, base),
+		"tick":           idempotencyKey(ra, 0, scheduled),
+		"resourceAction": idempotencyKey(otherRA, 0, base),
+	} {
+		if other == key {
+			t.Errorf("expected a different %s to change the key", name)
+		}
+	}
+}
+
+func TestAddIdempotencyKey(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{UID: "ra-uid"}}
+	input := newDeploymentInput("uid-1", "demo", "default")
+
+	headers := map[string]string{}
+	addIdempotencyKey(headers, nil, ra, 0, input)
+	if len(headers) != 0 {
+		t.Fatalf("expected no header without idempotencyKey, got %v", headers)
+	}
+	addIdempotencyKey(headers, &opsv1alpha1.IdempotencyKeySpec{Header: "X-Request-Id"}, ra, 0, input)
+	if headers["X-Request-Id"] != idempotencyKey(ra, 0, input) {
+		t.Fatalf("expected the key in X-Request-Id, got %v", headers)
+	}
+}
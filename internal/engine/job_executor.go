@@ -34,12 +34,15 @@ const (
 )
 
 type JobExecutor struct {
-	k8s       client.Client
+	k8s client.Client
+	// target is the cluster Jobs are created in. It differs from k8s, which
+	// receives status updates, for ResourceActions with spec.clusterRef.
+	target    client.Client
 	clientset kubernetes.Interface
 }
 
 func NewJobExecutor(k8s client.Client, clientset kubernetes.Interface) *JobExecutor {
-	return &JobExecutor{k8s: k8s, clientset: clientset}
+	return &JobExecutor{k8s: k8s, target: k8s, clientset: clientset}
 }
 
 func (e *JobExecutor) Execute(
@@ -60,8 +63,12 @@ func (e *JobExecutor) Execute(
 	if err != nil {
 		return metrics, err
 	}
+	if id := correlationIDFrom(ctx); id != "" {
+		jobObj.Annotations = map[string]string{correlationIDAnnotation: id}
+		jobObj.Spec.Template.Annotations = map[string]string{correlationIDAnnotation: id}
+	}
 
-	if err := e.k8s.Create(ctx, jobObj); err != nil {
+	if err := e.target.Create(ctx, jobObj); err != nil {
 		return metrics, err
 	}
 
@@ -104,6 +111,9 @@ func buildJobForAction(
 		}
 		envVars = append(envVars, envVar)
 	}
+	envVars = append(envVars, triggerEnv(input.trigger)...)
+	envVars = append(envVars, alertEnv(input)...)
+	envVars = append(envVars, gitHubEnv(input)...)
 
 	volumes := make([]corev1.Volume, 0, len(job.Volumes))
 	for _, item := range job.Volumes {
@@ -197,7 +207,7 @@ func buildJobForAction(
 			GenerateName: jobGenerateName(ra.Name, actionIndex),
 			Labels: map[string]string{
 				"app.kubernetes.io/managed-by":                  "resource-action-operator",
-				"resource-action-operator.yusaozdemir.de/name":  ra.Name,
+				"resource-action-operator.yusaozdemir.de/name":  resourceActionLabel(ra.Name),
 				"resource-action-operator.yusaozdemir.de/type":  action.Type,
 				"resource-action-operator.yusaozdemir.de/event": strings.ToLower(string(input.Event)),
 			},
@@ -209,7 +219,7 @@ func buildJobForAction(
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
-						"resource-action-operator.yusaozdemir.de/name": ra.Name,
+						"resource-action-operator.yusaozdemir.de/name": resourceActionLabel(ra.Name),
 					},
 				},
 				Spec: podSpec,
@@ -245,7 +255,7 @@ func (e *JobExecutor) trackJobExecution(
 			return
 		case <-ticker.C:
 			var current batchv1.Job
-			if err := e.k8s.Get(watchCtx, client.ObjectKeyFromObject(jobObj), &current); err != nil {
+			if err := e.target.Get(watchCtx, client.ObjectKeyFromObject(jobObj), &current); err != nil {
 				return
 			}
 
@@ -311,7 +321,7 @@ func deriveJobStatus(job batchv1.Job) string {
 
 func (e *JobExecutor) findJobPodDetails(ctx context.Context, job batchv1.Job) (string, *int32) {
 	var podList corev1.PodList
-	if err := e.k8s.List(ctx, &podList, &client.ListOptions{
+	if err := e.target.List(ctx, &podList, &client.ListOptions{
 		Namespace:     job.Namespace,
 		LabelSelector: labels.SelectorFromSet(map[string]string{"job-name": job.Name}),
 	}); err != nil || len(podList.Items) == 0 {
@@ -369,6 +379,27 @@ func (e *JobExecutor) updateJobExecutionRecord(
 	input MatchInput,
 	jobRecord opsv1alpha1.JobExecutionRecord,
 ) {
+	if usesActionExecutions(&ra) {
+		_ = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			updated, err := updateActionExecutionJob(ctx, e.k8s, ra, input, jobRecord)
+			if err != nil || updated {
+				return err
+			}
+			record := opsv1alpha1.ExecutionRecord{
+				ResourceUID:        string(input.Obj.GetUID()),
+				Event:              string(input.Event),
+				ExecutedAt:         metav1.Now(),
+				ResourceName:       input.Obj.GetName(),
+				ResourceNamespace:  input.Obj.GetNamespace(),
+				ResourceAPIVersion: input.GVK.GroupVersion().String(),
+				ResourceKind:       input.GVK.Kind,
+				Job:                &jobRecord,
+			}
+			return createActionExecution(ctx, e.k8s, &ra, record, nil, nil, finalState(input))
+		})
+		return
+	}
+
 	_ = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var latest opsv1alpha1.ResourceAction
 		if err := e.k8s.Get(ctx, client.ObjectKey{Name: ra.Name, Namespace: ra.Namespace}, &latest); err != nil {
@@ -388,11 +419,16 @@ func (e *JobExecutor) updateJobExecutionRecord(
 		}
 
 		latest.Status.Executions = append(latest.Status.Executions, opsv1alpha1.ExecutionRecord{
-			ResourceUID: string(input.Obj.GetUID()),
-			Event:       string(input.Event),
-			ExecutedAt:  metav1.Now(),
-			Job:         &jobRecord,
+			ResourceUID:        string(input.Obj.GetUID()),
+			Event:              string(input.Event),
+			ExecutedAt:         metav1.Now(),
+			ResourceName:       input.Obj.GetName(),
+			ResourceNamespace:  input.Obj.GetNamespace(),
+			ResourceAPIVersion: input.GVK.GroupVersion().String(),
+			ResourceKind:       input.GVK.Kind,
+			Job:                &jobRecord,
 		})
+		pruneExecutions(&latest, time.Now())
 		return e.k8s.Status().Update(ctx, &latest)
 	})
 }
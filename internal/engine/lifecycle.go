@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"context"
+)
+
+// Start implements manager.Runnable. It runs the informers registered so far,
+// the event workers and the cron engine with ctx and blocks until ctx is done.
+// The queued events are drained afterwards.
+func (e *Engine) Start(ctx context.Context) error {
+	e.mu.Lock()
+	e.runCtx = ctx
+	e.started = true
+	// Workers outlive ctx so Shutdown can drain the queue; Shutdown cancels
+	// them once its grace period expired.
+	workCtx, stopWorkers := context.WithCancel(context.WithoutCancel(ctx))
+	e.stopWorkers = stopWorkers
+	e.startWorkers(workCtx)
+	for key, inf := range e.informers {
+		e.runInformerLocked(key, inf)
+	}
+	e.mu.Unlock()
+	if exec, ok := e.executor.(*K8sExecutor); ok && e.meta != nil {
+		exec.secrets.enable(ctx, e.meta, e.watchNamespaces)
+	}
+	e.cronEngine.Start(ctx)
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.shutdownGracePeriod)
+	defer cancel()
+	e.Shutdown(shutdownCtx)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Only the
+// leader watches resources and runs actions, unless the events are sharded
+// across replicas.
+func (e *Engine) NeedLeaderElection() bool {
+	return !e.shard.sharded()
+}
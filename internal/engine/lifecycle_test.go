@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestStart_RunsInformersRegisteredBefore(t *testing.T) {
+	e := newWatchTestEngine(t)
+	e.dyn = fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+	}, newConfigMap("existing", "uid-1"))
+	exec := &blockingExecutor{}
+	_, cl := newTestExecutor(t)
+	e.executor = exec
+	e.cronEngine = NewCronEngine(cl, exec)
+
+	ra := newTestResourceAction("lifecycle", "ConfigMap")
+	owner := types.NamespacedName{Namespace: "default", Name: "lifecycle"}
+	if err := e.EnsureWatchingFor(context.Background(), ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if !isWatching(e, "configmaps", "") {
+		t.Fatalf("expected informer to be registered before Start")
+	}
+	if len(e.stops) != 0 {
+		t.Fatalf("informer runs before Start")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Start(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !e.WatchHealth(owner).Synced && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !e.WatchHealth(owner).Synced {
+		t.Fatalf("informer did not sync after Start")
+	}
+	for time.Now().Before(deadline) {
+		exec.mu.Lock()
+		delivered := len(exec.delivered)
+		exec.mu.Unlock()
+		if delivered == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Start() did not return after the context was cancelled")
+	}
+	if len(exec.delivered) != 1 || exec.delivered[0] != "existing" {
+		t.Fatalf("delivered = %v, want [existing]", exec.delivered)
+	}
+	if len(e.stops) != 0 {
+		t.Fatalf("informers still running after Start returned")
+	}
+}
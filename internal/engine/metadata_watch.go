@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"text/template"
+	"text/template/parse"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// needsFullObject reports whether ra reads more of the watched objects than
+// their metadata. Filters only use metadata, except for the Spec and Status
+// change types, so this mostly depends on the HTTP body, forEach, wait
+// duration, waitForCondition and result annotation templates.
+func needsFullObject(ra *opsv1alpha1.ResourceAction) bool {
+	if filter := ra.Spec.Filters; filter != nil &&
+		(filter.ChangeType == changeSpec || filter.ChangeType == changeStatus) {
+		return true
+	}
+	if aggregation := ra.Spec.Aggregation; aggregation != nil {
+		for _, text := range aggregation.GroupBy {
+			if !templateUsesOnlyMetadata(text) {
+				return true
+			}
+		}
+	}
+	for _, action := range ra.Spec.Actions {
+		templates := bodyTemplates(action.Body)
+		if action.Wait != nil {
+			templates = append(templates, action.Wait.Duration)
+		}
+		if action.WaitForCondition != nil {
+			templates = append(templates, action.WaitForCondition.Name, action.WaitForCondition.Namespace)
+		}
+		if action.ForEach != nil {
+			templates = append(templates, action.ForEach.Items, action.ForEach.URL)
+		}
+		for _, text := range action.ResultAnnotations {
+			templates = append(templates, text)
+		}
+		for _, text := range templates {
+			if !templateUsesOnlyMetadata(text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// templateUsesOnlyMetadata reports whether a body template only references
+// .apiVersion, .kind and .metadata of the object. Anything it cannot prove,
+// such as a bare dot or a changed dot inside with or range, counts as using
+// the full object.
+func templateUsesOnlyMetadata(text string) bool {
+	tpl, err := template.New("body").Funcs(bodyTemplateFuncs(nil, nil)).Parse(text)
+	if err != nil {
+		// Let execution report the error against the full object.
+		return false
+	}
+	for _, t := range tpl.Templates() {
+		if t.Tree != nil && !metadataOnlyNode(t.Tree.Root) {
+			return false
+		}
+	}
+	return true
+}
+
+func metadataOnlyNode(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, child := range n.Nodes {
+			if !metadataOnlyNode(child) {
+				return false
+			}
+		}
+		return true
+	case *parse.ActionNode:
+		return metadataOnlyNode(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return true
+		}
+		for _, cmd := range n.Cmds {
+			if !metadataOnlyNode(cmd) {
+				return false
+			}
+		}
+		return true
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if !metadataOnlyNode(arg) {
+				return false
+			}
+		}
+		return true
+	case *parse.IfNode:
+		return metadataOnlyBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return metadataOnlyBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return metadataOnlyBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return metadataOnlyNode(n.Pipe)
+	case *parse.FieldNode:
+		return isMetadataField(n.Ident[0])
+	case *parse.IdentifierNode:
+		// The change types compare the spec and status of the object.
+		return n.Ident != "changeTypes" && n.Ident != "changed"
+	case *parse.VariableNode:
+		// $ is the object itself; other variables were assigned from
+		// pipelines that are checked on their own.
+		if n.Ident[0] != "$" {
+			return true
+		}
+		return len(n.Ident) > 1 && isMetadataField(n.Ident[1])
+	case *parse.DotNode, *parse.ChainNode:
+		return false
+	default:
+		return true
+	}
+}
+
+func metadataOnlyBranch(n *parse.BranchNode) bool {
+	return metadataOnlyNode(n.Pipe) && metadataOnlyNode(n.List) && metadataOnlyNode(n.ElseList)
+}
+
+func isMetadataField(name string) bool {
+	return name == "metadata" || name == "apiVersion" || name == "kind"
+}
+
+// metadataTransform converts the PartialObjectMetadata of a metadata informer
+// into the Unstructured objects the rest of the engine works with, stripped
+// like the objects of full informers. Objects that were already converted are
+// returned as is.
+func metadataTransform(gvk schema.GroupVersionKind) cache.TransformFunc {
+	return func(obj interface{}) (interface{}, error) {
+		meta, ok := obj.(*metav1.PartialObjectMetadata)
+		if !ok {
+			return obj, nil
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&meta.ObjectMeta)
+		if err != nil {
+			return nil, err
+		}
+		u := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": content}}
+		u.SetGroupVersionKind(gvk)
+		stripObject(u, false)
+		return u, nil
+	}
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakemetadata "k8s.io/client-go/metadata/fake"
+)
+
+func TestTemplateUsesOnlyMetadata(t *testing.T) {
+	tests := []struct {
+		template string
+		want     bool
+	}{
+		{template: `{"name":"{{ .metadata.name }}","kind":"{{ .kind }}"}`, want: true},
+		{template: `{{ range $k, $v := .metadata.labels }}{{ $k }}={{ $v }}{{ end }}`, want: true},
+		{template: `{{ if .metadata.annotations }}{{ $.metadata.namespace }}{{ end }}`, want: true},
+		{template: `{{ .metadata.name }}: {{ output "ticketURL" }}`, want: true},
+		{template: `{{ .spec.replicas }}`, want: false},
+		{template: `{{ $.status.phase }}`, want: false},
+		{template: `{{ with .metadata }}{{ .name }}{{ end }}`, want: false},
+		{template: `{{ toJson . }}`, want: false},
+		{template: `{{ .metadata.name`, want: false},
+		{template: `{{ .metadata.name }} {{ changeTypes }}`, want: false},
+	}
+	for _, tt := range tests {
+		if got := templateUsesOnlyMetadata(tt.template); got != tt.want {
+			t.Errorf("templateUsesOnlyMetadata(%q) = %v, want %v", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsFullObject(t *testing.T) {
+	ra := newTestResourceAction("meta", "ConfigMap")
+	ra.Spec.Actions = []opsv1alpha1.ActionSpec{
+		{Type: "http", Body: &opsv1alpha1.TemplateSpec{Template: `{{ .metadata.name }}`}},
+		{Type: "job"},
+	}
+	if needsFullObject(ra) {
+		t.Fatalf("expected metadata-only ResourceAction")
+	}
+
+	ra.Spec.Actions[0].Body.Form = map[string]string{"replicas": `{{ .spec.replicas }}`}
+	if !needsFullObject(ra) {
+		t.Fatalf("expected form fields reading the spec to need the full object")
+	}
+	ra.Spec.Actions[0].Body.Form = nil
+
+	ra.Spec.Actions = append(ra.Spec.Actions, opsv1alpha1.ActionSpec{
+		Type: "wait",
+		Wait: &opsv1alpha1.WaitSpec{Duration: `{{ .spec.indexDelay }}`},
+	})
+	if !needsFullObject(ra) {
+		t.Fatalf("expected wait durations reading the spec to need the full object")
+	}
+	ra.Spec.Actions = ra.Spec.Actions[:2]
+
+	ra.Spec.Actions[0].ResultAnnotations = map[string]string{"tickets.ops.yusaozdemir.de/at": `{{ executedAt }}`}
+	if needsFullObject(ra) {
+		t.Fatalf("expected result annotations without object fields to need only the metadata")
+	}
+	ra.Spec.Actions[0].ResultAnnotations["tickets.ops.yusaozdemir.de/url"] = `{{ .spec.url }}`
+	if !needsFullObject(ra) {
+		t.Fatalf("expected result annotations reading the spec to need the full object")
+	}
+	ra.Spec.Actions[0].ResultAnnotations = nil
+
+	ra.Spec.Actions[1].DeadLetter = &opsv1alpha1.DeadLetterSpec{Type: "ConfigMap", ConfigMapName: "failed"}
+	if needsFullObject(ra) {
+		t.Fatalf("expected dead letters to need only the metadata")
+	}
+	ra.Spec.Actions[1].DeadLetter = nil
+
+	ra.Spec.Filters = &opsv1alpha1.FilterSpec{ChangeType: "Labels"}
+	if needsFullObject(ra) {
+		t.Fatalf("expected label changes to need only the metadata")
+	}
+	ra.Spec.Filters.ChangeType = "Status"
+	if !needsFullObject(ra) {
+		t.Fatalf("expected status changes to need the full object")
+	}
+}
+
+func TestEnsureWatchingFor_MetadataOnlyInformer(t *testing.T) {
+	e := newWatchTestEngine(t)
+	existing := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "settings",
+			Namespace:     "default",
+			UID:           "uid-1",
+			Labels:        map[string]string{"team": "a"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+	}
+	scheme := fakemetadata.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatalf("add meta scheme: %v", err)
+	}
+	e.meta = fakemetadata.NewSimpleMetadataClient(scheme, existing)
+	// Run the informers without event workers to inspect the queued events.
+	e.started = true
+
+	ctx := context.Background()
+	ra := newTestResourceAction("meta", "ConfigMap")
+	owner := types.NamespacedName{Namespace: "default", Name: "meta"}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if keys := e.owners[owner]; len(keys) != 1 || !keys[0].metadataOnly {
+		t.Fatalf("expected a metadata-only informer, got %v", keys)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !e.WatchHealth(owner).Synced && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	objects, err := e.ListCached(owner)
+	if err != nil {
+		t.Fatalf("ListCached() error = %v", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("ListCached() returned %d objects, want 1", len(objects))
+	}
+	obj := objects[0]
+	if obj.GetKind() != "ConfigMap" || obj.GetAPIVersion() != "v1" || obj.GetName() != "settings" {
+		t.Fatalf("unexpected cached object %v", obj.Object)
+	}
+	if obj.GetLabels()["team"] != "a" || len(obj.GetManagedFields()) != 0 {
+		t.Fatalf("expected labels without managed fields, got %v", obj.Object["metadata"])
+	}
+
+	// A body template that reads the spec switches to a full informer.
+	ra.Spec.Actions = []opsv1alpha1.ActionSpec{
+		{Type: "http", Body: &opsv1alpha1.TemplateSpec{Template: `{{ .data.key }}`}},
+	}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	if !isWatching(e, "configmaps", "") {
+		t.Fatalf("expected a full informer for templates that read the object")
+	}
+	if len(e.informers) != 1 {
+		t.Fatalf("metadata-only informer still running, informers = %d", len(e.informers))
+	}
+}
@@ -2,8 +2,11 @@ package engine
 
 import (
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
 	"github.com/prometheus/client_golang/prometheus"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -88,6 +91,117 @@ var (
 			Help: "Total number of persisted job log tail lines.",
 		},
 	)
+
+	actionLastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "resource_action_operator_action_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful execution per action.",
+		},
+		[]string{"namespace", "resource_action", "action_index", "action"},
+	)
+
+	actionLastFailureTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "resource_action_operator_action_last_failure_timestamp_seconds",
+			Help: "Unix time of the last failed execution per action.",
+		},
+		[]string{"namespace", "resource_action", "action_index", "action"},
+	)
+
+	actionConsecutiveFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "resource_action_operator_action_consecutive_failures",
+			Help: "Number of failed executions since the last success per action.",
+		},
+		[]string{"namespace", "resource_action", "action_index", "action"},
+	)
+
+	deadLettersTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_dead_letters_total",
+			Help: "Total number of dead letters published by destination type and result.",
+		},
+		[]string{"type", "result"},
+	)
+
+	cloudEventsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_cloudevents_total",
+			Help: "Total number of matched events emitted as CloudEvents by result.",
+		},
+		[]string{"result"},
+	)
+
+	auditRecordsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_audit_records_total",
+			Help: "Total number of execution records exported to the audit sink by result.",
+		},
+		[]string{"result"},
+	)
+
+	eventsReceivedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_events_received_total",
+			Help: "Total number of events received from informers and WebhookSources by kind and event.",
+		},
+		[]string{"group", "version", "kind", "event"},
+	)
+
+	eventsMatchedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_events_matched_total",
+			Help: "Total number of events matched per ResourceAction and event.",
+		},
+		[]string{"namespace", "resource_action", "event"},
+	)
+
+	eventsFilteredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_events_filtered_total",
+			Help: "Total number of events of the selected kind and event that spec.filters dropped per ResourceAction.",
+		},
+		[]string{"namespace", "resource_action"},
+	)
+
+	eventsSilencedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_events_silenced_total",
+			Help: "Total number of events whose actions a Silence muted per ResourceAction and Silence.",
+		},
+		[]string{"namespace", "resource_action", "silence"},
+	)
+
+	escalationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_escalations_total",
+			Help: "Total number of escalation levels run for failed actions per ResourceAction, action, level and result.",
+		},
+		[]string{"namespace", "resource_action", "action", "level", "result"},
+	)
+
+	circuitBreakerOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "resource_action_operator_circuit_breaker_open",
+			Help: "Whether the circuit breaker of an HTTP target host is open (1) or closed (0).",
+		},
+		[]string{"host"},
+	)
+
+	circuitBreakerShortCircuitsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_circuit_breaker_short_circuits_total",
+			Help: "Total number of HTTP attempts skipped because the circuit of the host was open.",
+		},
+		[]string{"host"},
+	)
+
+	httpRateLimitWaitSecondsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "resource_action_operator_http_rate_limit_wait_seconds_total",
+			Help: "Total time HTTP attempts waited for the outbound rate limits.",
+		},
+	)
 )
 
 func initEngineMetrics() {
@@ -103,6 +217,20 @@ func initEngineMetrics() {
 			jobRunsTotal,
 			jobDurationSeconds,
 			jobLogTailLinesTotal,
+			actionLastSuccessTimestamp,
+			actionLastFailureTimestamp,
+			actionConsecutiveFailures,
+			deadLettersTotal,
+			cloudEventsTotal,
+			auditRecordsTotal,
+			eventsReceivedTotal,
+			eventsMatchedTotal,
+			eventsFilteredTotal,
+			eventsSilencedTotal,
+			escalationsTotal,
+			circuitBreakerOpen,
+			circuitBreakerShortCircuitsTotal,
+			httpRateLimitWaitSecondsTotal,
 		)
 	})
 }
@@ -145,3 +273,122 @@ func observeJobExecution(result string, durationMillis int64, logTailLines int)
 	jobDurationSeconds.WithLabelValues(result).Observe(float64(durationMillis) / 1000.0)
 	jobLogTailLinesTotal.Add(float64(logTailLines))
 }
+
+func observeDeadLetter(destination, result string) {
+	initEngineMetrics()
+	deadLettersTotal.WithLabelValues(destination, result).Inc()
+}
+
+func observeCloudEvent(result string) {
+	initEngineMetrics()
+	cloudEventsTotal.WithLabelValues(result).Inc()
+}
+
+func observeAuditRecords(result string, records int) {
+	initEngineMetrics()
+	auditRecordsTotal.WithLabelValues(result).Add(float64(records))
+}
+
+func observeEventReceived(input MatchInput) {
+	initEngineMetrics()
+	eventsReceivedTotal.WithLabelValues(input.GVK.Group, input.GVK.Version, input.GVK.Kind, string(input.Event)).Inc()
+}
+
+func observeEventMatched(ra *opsv1alpha1.ResourceAction, input MatchInput) {
+	initEngineMetrics()
+	eventsMatchedTotal.WithLabelValues(ra.Namespace, ra.Name, string(input.Event)).Inc()
+}
+
+func observeEventFiltered(ra *opsv1alpha1.ResourceAction) {
+	initEngineMetrics()
+	eventsFilteredTotal.WithLabelValues(ra.Namespace, ra.Name).Inc()
+}
+
+func observeEventSilenced(ra *opsv1alpha1.ResourceAction, silence string) {
+	initEngineMetrics()
+	eventsSilencedTotal.WithLabelValues(ra.Namespace, ra.Name, silence).Inc()
+}
+
+var (
+	informersDesc = prometheus.NewDesc(
+		"resource_action_operator_informers",
+		"Number of running informers.",
+		nil, nil,
+	)
+	informerCachedObjectsDesc = prometheus.NewDesc(
+		"resource_action_operator_informer_cached_objects",
+		"Number of objects cached by the informers per resource and cluster. The cluster is empty for the local cluster.",
+		[]string{"group", "version", "resource", "cluster"}, nil,
+	)
+)
+
+// informerCollector reports the informers of an engine and the size of their
+// caches when metrics are scraped.
+type informerCollector struct {
+	engine *Engine
+}
+
+func (c informerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- informersDesc
+	ch <- informerCachedObjectsDesc
+}
+
+func (c informerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.engine.mu.Lock()
+	running := len(c.engine.informers)
+	objects := map[watchKey]int{}
+	for key, inf := range c.engine.informers {
+		// Informers of the same resource with other namespaces, selectors
+		// or caching are summed up.
+		key.namespace, key.labelSelector, key.metadataOnly, key.keepStatus = "", "", false, false
+		key.cluster.resourceVersion = ""
+		objects[key] += len(inf.GetStore().ListKeys())
+	}
+	c.engine.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(informersDesc, prometheus.GaugeValue, float64(running))
+	for key, count := range objects {
+		cluster := ""
+		if key.cluster != (clusterID{}) {
+			cluster = key.cluster.String()
+		}
+		ch <- prometheus.MustNewConstMetric(informerCachedObjectsDesc, prometheus.GaugeValue, float64(count),
+			key.gvr.Group, key.gvr.Version, key.gvr.Resource, cluster)
+	}
+}
+
+func observeActionState(namespace, resourceAction string, state opsv1alpha1.ActionState) {
+	initEngineMetrics()
+	labels := []string{namespace, resourceAction, strconv.Itoa(state.ActionIndex), state.Name}
+	if state.LastSuccessfulTime != nil {
+		actionLastSuccessTimestamp.WithLabelValues(labels...).Set(float64(state.LastSuccessfulTime.Unix()))
+	}
+	if state.LastFailureTime != nil {
+		actionLastFailureTimestamp.WithLabelValues(labels...).Set(float64(state.LastFailureTime.Unix()))
+	}
+	actionConsecutiveFailures.WithLabelValues(labels...).Set(float64(state.ConsecutiveFailures))
+}
+
+func observeCircuitOpen(host string, open bool) {
+	initEngineMetrics()
+	value := 0.0
+	if open {
+		value = 1
+	}
+	circuitBreakerOpen.WithLabelValues(host).Set(value)
+}
+
+func observeCircuitShortCircuit(host string) {
+	initEngineMetrics()
+	circuitBreakerShortCircuitsTotal.WithLabelValues(host).Inc()
+}
+
+func observeRateLimitWait(waited time.Duration) {
+	initEngineMetrics()
+	httpRateLimitWaitSecondsTotal.Add(waited.Seconds())
+}
+
+func observeEscalation(ra *opsv1alpha1.ResourceAction, action string, level int, result string) {
+	initEngineMetrics()
+	escalationsTotal.WithLabelValues(ra.Namespace, ra.Name, action, strconv.Itoa(level), result).Inc()
+}
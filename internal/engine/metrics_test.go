@@ -1,6 +1,18 @@
 package engine
 
-import "testing"
+import (
+	"context"
+	"strings"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
 
 func TestStatusClass(t *testing.T) {
 	tests := []struct {
@@ -21,3 +33,74 @@ func TestStatusClass(t *testing.T) {
 		}
 	}
 }
+
+func newCachedInformer(t *testing.T, names ...string) cache.SharedIndexInformer {
+	t.Helper()
+	inf := cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+	for _, name := range names {
+		obj := &unstructured.Unstructured{}
+		obj.SetNamespace("shop")
+		obj.SetName(name)
+		if err := inf.GetStore().Add(obj); err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+	}
+	return inf
+}
+
+func TestInformerCollector(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	e := NewEngine(nil)
+	e.informers[watchKey{gvr: deployments}] = newCachedInformer(t, "cart", "checkout")
+	e.informers[watchKey{gvr: deployments, namespace: "ops", labelSelector: "team=ops"}] = newCachedInformer(t, "alerts")
+	remote := clusterID{secret: types.NamespacedName{Namespace: "ops", Name: "edge-1"}, resourceVersion: "7"}
+	e.informers[watchKey{gvr: deployments, cluster: remote}] = newCachedInformer(t, "edge")
+
+	want := `
+# HELP resource_action_operator_informer_cached_objects Number of objects cached by the informers per resource and cluster. The cluster is empty for the local cluster.
+# TYPE resource_action_operator_informer_cached_objects gauge
+resource_action_operator_informer_cached_objects{cluster="",group="apps",resource="deployments",version="v1"} 3
+resource_action_operator_informer_cached_objects{cluster="ops/edge-1",group="apps",resource="deployments",version="v1"} 1
+# HELP resource_action_operator_informers Number of running informers.
+# TYPE resource_action_operator_informers gauge
+resource_action_operator_informers 3
+`
+	if err := testutil.CollectAndCompare(informerCollector{engine: e}, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecute_CountsMatchedAndFilteredEvents(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "count-events", Namespace: "ops"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Filters:  &opsv1alpha1.FilterSpec{NameRegex: "^cart$"},
+			DryRun:   true,
+			Actions:  []opsv1alpha1.ActionSpec{{Type: "http", URL: "https://hooks.example.com"}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+
+	deleted := newDeploymentInput("uid-3", "cart", "shop")
+	deleted.Event = EventDelete
+	for _, input := range []MatchInput{
+		newDeploymentInput("uid-1", "cart", "shop"),
+		newDeploymentInput("uid-2", "checkout", "shop"),
+		deleted,
+	} {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	if got := testutil.ToFloat64(eventsMatchedTotal.WithLabelValues("ops", "count-events", "Create")); got != 1 {
+		t.Fatalf("matched = %v, want 1", got)
+	}
+	// The Delete is not selected by spec.events, so it is not counted as
+	// filtered.
+	if got := testutil.ToFloat64(eventsFilteredTotal.WithLabelValues("ops", "count-events")); got != 1 {
+		t.Fatalf("filtered = %v, want 1", got)
+	}
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	nameReusePolicySuppress = "Suppress"
+	defaultNameReuseWindow  = 10 * time.Minute
+
+	nameKeyPrefix = "name."
+)
+
+// With spec.nameReuse.policy Suppress the dedup store also holds a
+// fingerprint per namespace, name and event. The key is
+// "name.<event>.<namespace>.<name>" and the value "<uid>/<time>" names the
+// object the actions last ran for and when. Fingerprints outlive the object,
+// so a re-created object with a new UID can be recognised; they are pruned
+// once their window has passed.
+
+// nameReuseWindow returns how long events of re-created objects are
+// suppressed, or 0 when they are treated as new objects.
+func nameReuseWindow(ra *opsv1alpha1.ResourceAction) time.Duration {
+	nameReuse := ra.Spec.NameReuse
+	if nameReuse == nil || nameReuse.Policy != nameReusePolicySuppress {
+		return 0
+	}
+	window, err := time.ParseDuration(nameReuse.Window)
+	if err != nil || window <= 0 {
+		return defaultNameReuseWindow
+	}
+	return window
+}
+
+func nameKey(input MatchInput) string {
+	return nameKeyPrefix + strings.ToLower(string(input.Event)) + "." +
+		input.Obj.GetNamespace() + "." + input.Obj.GetName()
+}
+
+// fingerprints reports whether recordExecution stores a fingerprint for
+// input. Webhook events have no object to re-create.
+func fingerprints(ra *opsv1alpha1.ResourceAction, input MatchInput) bool {
+	return nameReuseWindow(ra) > 0 && input.Event != EventWebhook
+}
+
+// nameReused reports whether input belongs to an object that re-uses the name
+// of an object the actions ran for within spec.nameReuse.window, so its
+// event is suppressed. Retriggered events are never suppressed.
+func (e *K8sExecutor) nameReused(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	input MatchInput,
+) (bool, error) {
+	window := nameReuseWindow(ra)
+	if window == 0 || input.retrigger || input.Event == EventWebhook {
+		return false, nil
+	}
+
+	var cm corev1.ConfigMap
+	err := e.Client.Get(ctx, client.ObjectKey{Name: dedupConfigMapName(ra), Namespace: ra.Namespace}, &cm)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	uid, at, ok := parseFingerprint(cm.Data[nameKey(input)])
+	if !ok || uid == input.Obj.GetUID() {
+		return false, nil
+	}
+	return time.Since(at) < window, nil
+}
+
+// recordName stores the fingerprint of input in data and drops the
+// fingerprints whose window has passed.
+func recordName(data map[string]string, input MatchInput, window time.Duration, now time.Time) {
+	for key, value := range data {
+		if !strings.HasPrefix(key, nameKeyPrefix) {
+			continue
+		}
+		if _, at, ok := parseFingerprint(value); !ok || now.Sub(at) >= window {
+			delete(data, key)
+		}
+	}
+	data[nameKey(input)] = string(input.Obj.GetUID()) + "/" + now.UTC().Format(time.RFC3339)
+}
+
+func parseFingerprint(value string) (types.UID, time.Time, bool) {
+	uid, at, ok := strings.Cut(value, "/")
+	if !ok {
+		return "", time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return types.UID(uid), t, true
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExecute_NameReuseSuppress(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-reuse", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:  opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:    []string{"Create"},
+			NameReuse: &opsv1alpha1.NameReuseSpec{Policy: "Suppress", Window: "10m"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	ctx := context.Background()
+
+	if err := exec.Execute(ctx, newDeploymentInput("uid-1", "demo", "team-a")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if err := exec.forgetObject(ctx, ra, "uid-1"); err != nil {
+		t.Fatalf("forgetObject: %v", err)
+	}
+
+	// The re-created object is suppressed, other names are not.
+	if err := exec.Execute(ctx, newDeploymentInput("uid-2", "demo", "team-a")); err != nil {
+		t.Fatalf("execute re-created: %v", err)
+	}
+	if err := exec.Execute(ctx, newDeploymentInput("uid-3", "other", "team-a")); err != nil {
+		t.Fatalf("execute other: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want the re-created object to be suppressed", calls)
+	}
+
+	// Once the window has passed the name counts as new again.
+	var cm corev1.ConfigMap
+	key := types.NamespacedName{Name: dedupConfigMapName(ra), Namespace: ra.Namespace}
+	if err := cl.Get(ctx, key, &cm); err != nil {
+		t.Fatalf("get dedup configmap: %v", err)
+	}
+	input := newDeploymentInput("uid-4", "demo", "team-a")
+	cm.Data[nameKey(input)] = "uid-1/" + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	if err := cl.Update(ctx, &cm); err != nil {
+		t.Fatalf("update dedup configmap: %v", err)
+	}
+	if err := exec.Execute(ctx, input); err != nil {
+		t.Fatalf("execute after window: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want the action to run after the window", calls)
+	}
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(&cm), &cm); err != nil {
+		t.Fatalf("get dedup configmap: %v", err)
+	}
+	if uid, _, _ := parseFingerprint(cm.Data[nameKey(input)]); uid != "uid-4" {
+		t.Fatalf("fingerprint = %q, want the latest object", cm.Data[nameKey(input)])
+	}
+}
+
+func TestNameReuseWindow(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{}
+	if got := nameReuseWindow(ra); got != 0 {
+		t.Fatalf("nameReuseWindow() = %v without nameReuse, want 0", got)
+	}
+	ra.Spec.NameReuse = &opsv1alpha1.NameReuseSpec{Policy: "New", Window: "5m"}
+	if got := nameReuseWindow(ra); got != 0 {
+		t.Fatalf("nameReuseWindow() = %v for policy New, want 0", got)
+	}
+	ra.Spec.NameReuse.Policy = "Suppress"
+	if got := nameReuseWindow(ra); got != 5*time.Minute {
+		t.Fatalf("nameReuseWindow() = %v, want 5m", got)
+	}
+}
@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const pendingConfigMapSuffix = "-pending"
+
+// The pending store is a ConfigMap owned by the ResourceAction that holds the
+// events whose actions are being retried through the event queue. Each key is
+// "<uid>.<event>" and holds a reference to the object of the event as JSON;
+// the object itself is not stored, so the ConfigMap stays small and does not
+// copy Secret data. An entry is removed once the execution finished; entries
+// left behind by a restart are delivered again with the object read anew.
+
+// PendingDeliveries is implemented by executors that persist the events
+// being delivered, so they survive a restart.
+type PendingDeliveries interface {
+	PendingDeliveries(ctx context.Context, ra *opsv1alpha1.ResourceAction) ([]MatchInput, error)
+}
+
+// pendingDelivery is the stored form of an event.
+type pendingDelivery struct {
+	Event           EventType `json:"event"`
+	Group           string    `json:"group"`
+	Version         string    `json:"version"`
+	Kind            string    `json:"kind"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name"`
+	UID             types.UID `json:"uid"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+}
+
+func (d pendingDelivery) gvk() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: d.Group, Version: d.Version, Kind: d.Kind}
+}
+
+// reference returns an object with only the identity of the stored object.
+func (d pendingDelivery) reference() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(d.gvk())
+	obj.SetNamespace(d.Namespace)
+	obj.SetName(d.Name)
+	obj.SetUID(d.UID)
+	obj.SetResourceVersion(d.ResourceVersion)
+	return obj
+}
+
+func pendingConfigMapName(ra *opsv1alpha1.ResourceAction) string {
+	return ra.Name + pendingConfigMapSuffix
+}
+
+// SetDeferredRetries makes HTTP actions retry through the event queue instead
+// of sleeping between attempts. The events being retried are stored in the
+// ConfigMap <resourceaction>-pending and delivered again after a restart.
+func (e *K8sExecutor) SetDeferredRetries(enabled bool) {
+	e.deferRetries = enabled
+}
+
+// SetDurableDeliveries stores every event in the ConfigMap
+// <resourceaction>-pending before its actions run, not only those whose
+// retries are deferred, so a crash during an execution does not lose the
+// event. It costs two ConfigMap writes per execution.
+func (e *K8sExecutor) SetDurableDeliveries(enabled bool) {
+	e.durableDeliveries = enabled
+}
+
+// persistPending stores input as a pending delivery of ra.
+func (e *K8sExecutor) persistPending(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput) error {
+	value, err := json.Marshal(pendingDelivery{
+		Event:           input.Event,
+		Group:           input.GVK.Group,
+		Version:         input.GVK.Version,
+		Kind:            input.GVK.Kind,
+		Namespace:       input.Obj.GetNamespace(),
+		Name:            input.Obj.GetName(),
+		UID:             input.Obj.GetUID(),
+		ResourceVersion: input.Obj.GetResourceVersion(),
+	})
+	if err != nil {
+		return err
+	}
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pendingConfigMapName(ra),
+				Namespace: ra.Namespace,
+			},
+		}
+		_, err := controllerutil.CreateOrUpdate(ctx, e.Client, cm, func() error {
+			if cm.Labels == nil {
+				cm.Labels = map[string]string{}
+			}
+			cm.Labels[labelResourceActionName] = resourceActionLabel(ra.Name)
+			if cm.Data == nil {
+				cm.Data = map[string]string{}
+			}
+			cm.Data[dedupKey(input.Obj.GetUID(), input.Event)] = string(value)
+			return controllerutil.SetOwnerReference(ra, cm, e.Client.Scheme())
+		})
+		return err
+	})
+}
+
+// clearPending removes the pending delivery of input from ra.
+func (e *K8sExecutor) clearPending(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput) error {
+	key := dedupKey(input.Obj.GetUID(), input.Event)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cm corev1.ConfigMap
+		if err := e.Client.Get(ctx, client.ObjectKey{Name: pendingConfigMapName(ra), Namespace: ra.Namespace}, &cm); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if _, ok := cm.Data[key]; !ok {
+			return nil
+		}
+		delete(cm.Data, key)
+		return e.Client.Update(ctx, &cm)
+	})
+}
+
+// PendingDeliveries returns the stored pending deliveries of ra, or nil when
+// neither retries are deferred nor deliveries durable. The objects are read
+// again: Delete events get a reference to the deleted object, and Create and
+// Update events of objects that are gone are dropped. Entries that cannot be
+// read are skipped.
+func (e *K8sExecutor) PendingDeliveries(ctx context.Context, ra *opsv1alpha1.ResourceAction) ([]MatchInput, error) {
+	if !e.deferRetries && !e.durableDeliveries {
+		return nil, nil
+	}
+	var cm corev1.ConfigMap
+	if err := e.Client.Get(ctx, client.ObjectKey{Name: pendingConfigMapName(ra), Namespace: ra.Namespace}, &cm); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	target := e.Client
+	cluster, err := e.clusters.clusterFor(ctx, ra)
+	if err != nil {
+		return nil, err
+	}
+	if cluster != nil {
+		target = cluster.client
+	}
+	logger := log.FromContext(ctx)
+	owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	inputs := make([]MatchInput, 0, len(cm.Data))
+	for key, value := range cm.Data {
+		var delivery pendingDelivery
+		if err := json.Unmarshal([]byte(value), &delivery); err != nil || delivery.UID == "" {
+			logger.Info("Skipping unreadable pending delivery",
+				"resourceAction", ra.Name,
+				"key", key,
+			)
+			continue
+		}
+		obj := delivery.reference()
+		if delivery.Event != EventDelete {
+			current := &unstructured.Unstructured{}
+			current.SetGroupVersionKind(delivery.gvk())
+			err := target.Get(ctx, client.ObjectKey{Namespace: delivery.Namespace, Name: delivery.Name}, current)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			if err != nil || current.GetUID() != delivery.UID {
+				logger.Info("Dropping pending delivery of deleted object",
+					"resourceAction", ra.Name,
+					"event", delivery.Event,
+					"name", delivery.Name,
+				)
+				if err := e.clearPending(ctx, ra, MatchInput{Event: delivery.Event, Obj: obj}); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			obj = current
+		}
+		inputs = append(inputs, MatchInput{
+			Event:   delivery.Event,
+			GVK:     delivery.gvk(),
+			Obj:     obj,
+			owners:  map[types.NamespacedName]struct{}{owner: {}},
+			pending: true,
+		})
+	}
+	return inputs, nil
+}
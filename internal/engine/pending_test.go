@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExecute_DeferredRetry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-deferred", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Retry:     &opsv1alpha1.RetrySpec{MaxAttempts: 3, Backoff: "1m", MaxBackoff: "1m"},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	exec.SetDeferredRetries(true)
+	ctx := context.Background()
+	input := newDeploymentInput("uid-deferred", "demo", "default")
+	input.Obj.Object["spec"] = map[string]interface{}{"replicas": int64(1)}
+
+	// The first attempt fails and the retry is handed back to the queue
+	// instead of sleeping for the backoff.
+	wait := executeUntilPaused(t, exec, input)
+	if calls != 1 || wait.progress.next != 0 || wait.progress.retryAttempt != 1 {
+		t.Fatalf("calls = %d, progress = %+v, want a deferred retry after attempt 1", calls, wait.progress)
+	}
+	var cm corev1.ConfigMap
+	key := types.NamespacedName{Name: pendingConfigMapName(ra), Namespace: ra.Namespace}
+	if err := cl.Get(ctx, key, &cm); err != nil {
+		t.Fatalf("get pending configmap: %v", err)
+	}
+	value, ok := cm.Data[dedupKey("uid-deferred", EventCreate)]
+	if !ok {
+		t.Fatalf("pending deliveries = %v, want the event", cm.Data)
+	}
+	if strings.Contains(value, "spec") {
+		t.Fatalf("pending delivery = %s, want only a reference to the object", value)
+	}
+	// The object is read again when the delivery is replayed.
+	if err := cl.Create(ctx, input.Obj.DeepCopy()); err != nil {
+		t.Fatalf("create deployment: %v", err)
+	}
+	pending, err := exec.PendingDeliveries(ctx, ra)
+	if err != nil || len(pending) != 1 || pending[0].Obj.GetUID() != "uid-deferred" || !pending[0].pending {
+		t.Fatalf("PendingDeliveries() = %+v, %v, want the stored event", pending, err)
+	}
+
+	input.resume = &wait.progress
+	if err := exec.Execute(ctx, input); err != nil {
+		t.Fatalf("execute resumed: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want the second attempt", calls)
+	}
+	if err := cl.Get(ctx, key, &cm); err != nil {
+		t.Fatalf("get pending configmap: %v", err)
+	}
+	if len(cm.Data) != 0 {
+		t.Fatalf("pending deliveries = %v, want the entry removed", cm.Data)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(ctx, types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 1 {
+		t.Fatalf("executions = %+v, want one", got.Status.Executions)
+	}
+	if record := got.Status.Executions[0]; record.Attempts != 2 || record.StatusRetryCount != 1 || record.LastHTTPStatus != http.StatusOK {
+		t.Fatalf("record = %+v, want two attempts and one status retry", record)
+	}
+}
+
+func TestExecute_DurableDelivery(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-durable", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+		},
+	}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: pendingConfigMapName(ra), Namespace: ra.Namespace}
+
+	// The event is stored before the action runs.
+	var stored bool
+	var cl client.Client
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cm corev1.ConfigMap
+		if err := cl.Get(ctx, key, &cm); err == nil {
+			_, stored = cm.Data[dedupKey("uid-durable", EventCreate)]
+		}
+	}))
+	defer srv.Close()
+	ra.Spec.Actions = []opsv1alpha1.ActionSpec{{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+	}}
+	exec, c := newTestExecutor(t, ra)
+	cl = c
+	exec.SetDurableDeliveries(true)
+
+	if err := exec.Execute(ctx, newDeploymentInput("uid-durable", "demo", "default")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !stored {
+		t.Fatalf("the event was not stored while the action ran")
+	}
+	var cm corev1.ConfigMap
+	if err := cl.Get(ctx, key, &cm); err != nil {
+		t.Fatalf("get pending configmap: %v", err)
+	}
+	if len(cm.Data) != 0 {
+		t.Fatalf("pending deliveries = %v, want the entry removed", cm.Data)
+	}
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	conditionThrottled = "Throttled"
+
+	reasonMaxExecutionsPerObject = "MaxExecutionsPerObject"
+	reasonMaxExecutionsPerHour   = "MaxExecutionsPerHour"
+	reasonWithinQuota            = "WithinQuota"
+
+	// quotaPeriod is the sliding window of the execution quotas.
+	quotaPeriod = time.Hour
+)
+
+// executionQuotas counts the executions of every ResourceAction with
+// spec.maxExecutionsPerObject or spec.maxExecutionsPerHour within the last
+// quotaPeriod. The counts are kept in memory by each replica.
+type executionQuotas struct {
+	mu     sync.Mutex
+	owners map[types.NamespacedName]*quotaUsage
+}
+
+type quotaUsage struct {
+	executions []time.Time
+	objects    map[types.UID][]time.Time
+	// throttled is when the last event was throttled.
+	throttled time.Time
+	// swept is when objects was last cleared of expired executions.
+	swept time.Time
+}
+
+func newExecutionQuotas() *executionQuotas {
+	return &executionQuotas{owners: map[types.NamespacedName]*quotaUsage{}}
+}
+
+func hasQuotas(ra *opsv1alpha1.ResourceAction) bool {
+	return ra.Spec.MaxExecutionsPerObject != nil || ra.Spec.MaxExecutionsPerHour != nil
+}
+
+// allow takes an execution of ra for the object uid from the quotas at now.
+// It returns the reason of the exceeded quota when the execution is
+// throttled, or "" when it may run.
+func (q *executionQuotas) allow(ra *opsv1alpha1.ResourceAction, uid types.UID, now time.Time) string {
+	if !hasQuotas(ra) {
+		return ""
+	}
+	key := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	since := now.Add(-quotaPeriod)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.owners[key]
+	if usage == nil {
+		usage = &quotaUsage{objects: map[types.UID][]time.Time{}, swept: now}
+		q.owners[key] = usage
+	}
+	if now.Sub(usage.swept) >= time.Minute {
+		for object, times := range usage.objects {
+			if times = expireQuota(times, since); len(times) == 0 {
+				delete(usage.objects, object)
+			} else {
+				usage.objects[object] = times
+			}
+		}
+		usage.swept = now
+	}
+	usage.executions = expireQuota(usage.executions, since)
+	objectExecutions := expireQuota(usage.objects[uid], since)
+
+	reason := ""
+	switch {
+	case ra.Spec.MaxExecutionsPerObject != nil && len(objectExecutions) >= int(*ra.Spec.MaxExecutionsPerObject):
+		reason = reasonMaxExecutionsPerObject
+	case ra.Spec.MaxExecutionsPerHour != nil && len(usage.executions) >= int(*ra.Spec.MaxExecutionsPerHour):
+		reason = reasonMaxExecutionsPerHour
+	}
+	if reason != "" {
+		usage.throttled = now
+		if len(objectExecutions) > 0 {
+			usage.objects[uid] = objectExecutions
+		}
+		return reason
+	}
+	usage.executions = append(usage.executions, now)
+	usage.objects[uid] = append(objectExecutions, now)
+	return ""
+}
+
+// throttledWithin reports whether an event of ra was throttled since the
+// start of the current quota period.
+func (q *executionQuotas) throttledWithin(ra *opsv1alpha1.ResourceAction, now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.owners[types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}]
+	return usage != nil && now.Sub(usage.throttled) < quotaPeriod
+}
+
+// expireQuota drops the executions before since from times, which is sorted.
+func expireQuota(times []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(since) {
+		i++
+	}
+	return times[i:]
+}
+
+// setThrottled sets the Throttled condition of ra after an event exceeded the
+// quota reason. The status is only written when the condition changes.
+func (e *K8sExecutor) setThrottled(ctx context.Context, ra *opsv1alpha1.ResourceAction, reason string) error {
+	if cond := meta.FindStatusCondition(ra.Status.Conditions, conditionThrottled); cond != nil &&
+		cond.Status == metav1.ConditionTrue && cond.Reason == reason {
+		return nil
+	}
+	var message string
+	if reason == reasonMaxExecutionsPerObject {
+		message = fmt.Sprintf("An object reached spec.maxExecutionsPerObject of %d within an hour", *ra.Spec.MaxExecutionsPerObject)
+	} else {
+		message = fmt.Sprintf("spec.maxExecutionsPerHour of %d reached", *ra.Spec.MaxExecutionsPerHour)
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := e.Client.Get(ctx, client.ObjectKeyFromObject(ra), &latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		setCondition(&latest, metav1.Condition{
+			Type:    conditionThrottled,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: message,
+		})
+		return e.Client.Status().Update(ctx, &latest)
+	})
+}
+
+// setThrottledCondition updates the Throttled condition of ra after an
+// execution. The condition is cleared once no event was throttled for a quota
+// period and removed when ra has no quotas.
+func setThrottledCondition(ra *opsv1alpha1.ResourceAction, throttled bool) {
+	if !hasQuotas(ra) {
+		meta.RemoveStatusCondition(&ra.Status.Conditions, conditionThrottled)
+		return
+	}
+	if throttled {
+		return
+	}
+	setCondition(ra, metav1.Condition{
+		Type:    conditionThrottled,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonWithinQuota,
+		Message: "No events were throttled within the last hour",
+	})
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestExecutionQuotas_Allow(t *testing.T) {
+	perObject, perHour := int32(2), int32(3)
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-quota", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			MaxExecutionsPerObject: &perObject,
+			MaxExecutionsPerHour:   &perHour,
+		},
+	}
+	q := newExecutionQuotas()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	for i, tc := range []struct {
+		uid  types.UID
+		at   time.Duration
+		want string
+	}{
+		{"a", 0, ""},
+		{"a", time.Minute, ""},
+		{"a", 2 * time.Minute, reasonMaxExecutionsPerObject},
+		{"b", 3 * time.Minute, ""},
+		{"c", 4 * time.Minute, reasonMaxExecutionsPerHour},
+		// The executions of a left the sliding hour.
+		{"a", 61 * time.Minute, ""},
+		{"a", 62 * time.Minute, ""},
+		{"a", 63 * time.Minute, reasonMaxExecutionsPerObject},
+	} {
+		if got := q.allow(ra, tc.uid, now.Add(tc.at)); got != tc.want {
+			t.Fatalf("event %d: allow(%s) = %q, want %q", i, tc.uid, got, tc.want)
+		}
+	}
+	if !q.throttledWithin(ra, now.Add(2*time.Hour)) {
+		t.Fatalf("throttledWithin() = false, want the throttled event of the last hour")
+	}
+	if q.throttledWithin(ra, now.Add(3*time.Hour)) {
+		t.Fatalf("throttledWithin() = true after an hour without throttling")
+	}
+
+	if got := q.allow(&opsv1alpha1.ResourceAction{}, "a", now); got != "" {
+		t.Fatalf("allow() = %q without quotas, want \"\"", got)
+	}
+}
+
+func TestExecute_ThrottledCondition(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	perObject := int32(1)
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-throttled", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:               opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:                 []string{"Update"},
+			ExecutionPolicy:        "EveryEvent",
+			MaxExecutionsPerObject: &perObject,
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}
+	throttled := func() *metav1.Condition {
+		t.Helper()
+		var got opsv1alpha1.ResourceAction
+		if err := cl.Get(ctx, key, &got); err != nil {
+			t.Fatalf("get resourceaction: %v", err)
+		}
+		return meta.FindStatusCondition(got.Status.Conditions, conditionThrottled)
+	}
+
+	input := newDeploymentInput("uid-loop", "crashloop", "team-a")
+	input.Event = EventUpdate
+	if err := exec.Execute(ctx, input); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if cond := throttled(); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Fatalf("Throttled = %+v, want False", cond)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := exec.Execute(ctx, input); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want the object throttled after one execution", calls)
+	}
+	if cond := throttled(); cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != reasonMaxExecutionsPerObject {
+		t.Fatalf("Throttled = %+v, want True for the object quota", cond)
+	}
+
+	// Other objects run, and the condition stays until an hour passed
+	// without throttling.
+	other := newDeploymentInput("uid-other", "other", "team-a")
+	other.Event = EventUpdate
+	if err := exec.Execute(ctx, other); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if cond := throttled(); calls != 2 || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("calls = %d, Throttled = %+v, want the other object to run while throttled", calls, cond)
+	}
+}
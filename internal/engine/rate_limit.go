@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"golang.org/x/time/rate"
+)
+
+// outboundLimiter caps outbound HTTP requests globally, per destination host
+// and per action. Every attempt, including retries, takes a token from each
+// applicable limiter.
+type outboundLimiter struct {
+	mu sync.Mutex
+	// global is nil when the global rate is unlimited.
+	global *rate.Limiter
+	// hostRate is the per-host rate; 0 means unlimited.
+	hostRate int
+	hosts    map[string]*rate.Limiter
+	actions  map[actionLimitKey]*rate.Limiter
+}
+
+// actionLimitKey identifies an action for spec.actions[].rateLimit.
+type actionLimitKey struct {
+	namespace      string
+	resourceAction string
+	actionIndex    int
+}
+
+func newOutboundLimiter() *outboundLimiter {
+	return &outboundLimiter{
+		hosts:   make(map[string]*rate.Limiter),
+		actions: make(map[actionLimitKey]*rate.Limiter),
+	}
+}
+
+// SetHTTPRateLimits caps outbound HTTP requests per second across all actions
+// and per destination host. 0 disables a limit.
+func (e *K8sExecutor) SetHTTPRateLimits(global, perHost int) {
+	e.limiter.mu.Lock()
+	defer e.limiter.mu.Unlock()
+	e.limiter.global = nil
+	if global > 0 {
+		e.limiter.global = rate.NewLimiter(rate.Limit(global), global)
+	}
+	e.limiter.hostRate = perHost
+	e.limiter.hosts = make(map[string]*rate.Limiter)
+}
+
+// wait blocks until an attempt of the action may be sent to host, or ctx is
+// done. It returns how long it waited.
+func (l *outboundLimiter) wait(
+	ctx context.Context,
+	key actionLimitKey,
+	spec *opsv1alpha1.RateLimitSpec,
+	host string,
+) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	limiters := l.limitersFor(key, spec, host)
+	if len(limiters) == 0 {
+		return 0, nil
+	}
+	started := time.Now()
+	for _, limiter := range limiters {
+		if err := limiter.Wait(ctx); err != nil {
+			waited := time.Since(started)
+			observeRateLimitWait(waited)
+			return waited, err
+		}
+	}
+	waited := time.Since(started)
+	observeRateLimitWait(waited)
+	return waited, nil
+}
+
+// limitersFor returns the limiters an attempt has to pass, narrowest first.
+func (l *outboundLimiter) limitersFor(
+	key actionLimitKey,
+	spec *opsv1alpha1.RateLimitSpec,
+	host string,
+) []*rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var limiters []*rate.Limiter
+	if spec != nil && spec.RequestsPerSecond > 0 {
+		burst := spec.Burst
+		if burst <= 0 {
+			burst = spec.RequestsPerSecond
+		}
+		limiter, ok := l.actions[key]
+		if !ok || limiter.Limit() != rate.Limit(spec.RequestsPerSecond) || limiter.Burst() != burst {
+			limiter = rate.NewLimiter(rate.Limit(spec.RequestsPerSecond), burst)
+			l.actions[key] = limiter
+		}
+		limiters = append(limiters, limiter)
+	} else {
+		delete(l.actions, key)
+	}
+	if l.hostRate > 0 && host != "" {
+		limiter, ok := l.hosts[host]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Limit(l.hostRate), l.hostRate)
+			l.hosts[host] = limiter
+		}
+		limiters = append(limiters, limiter)
+	}
+	if l.global != nil {
+		limiters = append(limiters, l.global)
+	}
+	return limiters
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOutboundLimiter_ActionLimiterFollowsSpec(t *testing.T) {
+	l := newOutboundLimiter()
+	key := actionLimitKey{namespace: "default", resourceAction: "demo"}
+
+	first := l.limitersFor(key, &opsv1alpha1.RateLimitSpec{RequestsPerSecond: 5}, "receiver.example")
+	if len(first) != 1 || first[0].Burst() != 5 {
+		t.Fatalf("limitersFor() = %d limiters, want one with burst 5", len(first))
+	}
+	if again := l.limitersFor(key, &opsv1alpha1.RateLimitSpec{RequestsPerSecond: 5}, "receiver.example"); again[0] != first[0] {
+		t.Fatalf("limitersFor() recreated the limiter of an unchanged spec")
+	}
+	if changed := l.limitersFor(key, &opsv1alpha1.RateLimitSpec{RequestsPerSecond: 5, Burst: 2}, "receiver.example"); changed[0] == first[0] || changed[0].Burst() != 2 {
+		t.Fatalf("limitersFor() kept the limiter of a changed spec")
+	}
+	if got := l.limitersFor(key, nil, "receiver.example"); len(got) != 0 {
+		t.Fatalf("limitersFor() without limits = %d limiters, want 0", len(got))
+	}
+	if _, ok := l.actions[key]; ok {
+		t.Fatalf("limiter of a removed rateLimit was kept")
+	}
+}
+
+func TestOutboundLimiter_GlobalAndPerHost(t *testing.T) {
+	exec := &K8sExecutor{limiter: newOutboundLimiter()}
+	exec.SetHTTPRateLimits(10, 2)
+	l := exec.limiter
+
+	a := l.limitersFor(actionLimitKey{}, nil, "a.example")
+	b := l.limitersFor(actionLimitKey{}, nil, "b.example")
+	if len(a) != 2 || len(b) != 2 {
+		t.Fatalf("limitersFor() = %d and %d limiters, want 2", len(a), len(b))
+	}
+	if a[0] == b[0] {
+		t.Fatalf("hosts share a per-host limiter")
+	}
+	if a[1] != b[1] || a[1].Burst() != 10 {
+		t.Fatalf("hosts do not share the global limiter")
+	}
+}
+
+func TestHTTPExecutor_RateLimitDelaysAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	exec.limiter = newOutboundLimiter()
+	action := opsv1alpha1.ActionSpec{
+		Type:      "http",
+		URL:       srv.URL,
+		URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+		RateLimit: &opsv1alpha1.RateLimitSpec{RequestsPerSecond: 20, Burst: 1},
+	}
+
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := exec.ExecuteWithMetrics(context.Background(), action, "default", newConfigMap("demo", "uid-1"), nil); err != nil {
+			t.Fatalf("ExecuteWithMetrics() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < 90*time.Millisecond {
+		t.Fatalf("3 requests at 20/s with burst 1 took %s, want >= 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := exec.ExecuteWithMetrics(ctx, action, "default", newConfigMap("demo", "uid-1"), nil); err == nil {
+		t.Fatalf("ExecuteWithMetrics() with cancelled context waiting for the rate limit error = nil")
+	}
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"context"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+// SetRecordMode makes every ResourceAction behave as if spec.record was set:
+// matching events are rendered and stored as simulated ActionExecutions, but
+// no requests are sent and no Jobs are created.
+func (e *K8sExecutor) SetRecordMode(enabled bool) {
+	e.recordMode = enabled
+}
+
+// recording reports whether the executions of ra are simulated and stored as
+// ActionExecutions.
+func (e *K8sExecutor) recording(ra *opsv1alpha1.ResourceAction) bool {
+	return e.recordMode || ra.Spec.Record
+}
+
+// storeSimulation stores record of a simulated execution as an ActionExecution
+// and returns its request, the last rendered one.
+func (e *K8sExecutor) storeSimulation(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	record opsv1alpha1.ExecutionRecord,
+) (*opsv1alpha1.HTTPRequestRecord, error) {
+	var request *opsv1alpha1.HTTPRequestRecord
+	if n := len(record.RenderedRequests); n > 0 {
+		last := record.RenderedRequests[n-1]
+		request = &opsv1alpha1.HTTPRequestRecord{Method: last.Method, URL: last.URL}
+	}
+	if err := createActionExecution(ctx, e.Client, ra, record, request, nil, nil); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
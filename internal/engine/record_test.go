@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExecute_RecordModeStoresSimulatedExecutions(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-record", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Name:      "notify",
+					Type:      "http",
+					URL:       srv.URL + "/hooks",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Body:      &opsv1alpha1.TemplateSpec{Template: `{"name":"{{ .metadata.name }}"}`},
+				},
+				{
+					Type: "job",
+					Job:  &opsv1alpha1.JobSpec{Image: "bash:5.2", Command: []string{"true"}},
+				},
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	exec.SetRecordMode(true)
+	input := newDeploymentInput("uid-record", "demo", "default")
+
+	for i := 0; i < 2; i++ {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("execute #%d: %v", i+1, err)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want no request in record mode", calls.Load())
+	}
+
+	var executions opsv1alpha1.ActionExecutionList
+	if err := cl.List(context.Background(), &executions, client.InNamespace("default")); err != nil {
+		t.Fatalf("list action executions: %v", err)
+	}
+	if len(executions.Items) != 2 {
+		t.Fatalf("ActionExecutions = %d, want one per event", len(executions.Items))
+	}
+	ae := executions.Items[0].Spec
+	if !ae.Simulated || ae.DryRun || ae.Result != executionResultSucceeded || len(ae.RenderedRequests) != 1 {
+		t.Fatalf("ActionExecution = %+v, want a succeeded simulated execution of the HTTP action", ae)
+	}
+	if ae.Request == nil || ae.Request.URL != srv.URL+"/hooks" || ae.RenderedRequests[0].Body != `{"name":"demo"}` {
+		t.Fatalf("ActionExecution = %+v, want the rendered request", ae)
+	}
+	if got := getResourceAction(t, cl, ra); len(got.Status.Executions) != 0 {
+		t.Fatalf("status.executions = %+v, want simulated executions outside of the status", got.Status.Executions)
+	}
+
+	// Simulated executions do not count once record mode is turned off.
+	exec.SetRecordMode(false)
+	var latest opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), client.ObjectKeyFromObject(ra), &latest); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	latest.Spec.HistoryMode = historyModeActionExecution
+	latest.Spec.Actions = latest.Spec.Actions[:1]
+	if err := cl.Update(context.Background(), &latest); err != nil {
+		t.Fatalf("update resourceaction: %v", err)
+	}
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want the event to run after record mode", calls.Load())
+	}
+}
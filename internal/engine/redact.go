@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	redactedValue = "[REDACTED]"
+	// maxLoggedResponseBody caps response bodies in logs and error messages.
+	maxLoggedResponseBody = 1024
+)
+
+// credentialFieldPattern matches the values of JSON fields, query parameters
+// and form fields whose names suggest credentials, for example a token a
+// receiver echoes back.
+var credentialFieldPattern = regexp.MustCompile(
+	`(?i)("?(?:access_?token|refresh_?token|token|password|passwd|secret|client_?secret|api_?key|authorization)"?\s*[:=]\s*"?)([^"&,\s}]+)`,
+)
+
+// redactor removes the resolved Secret values of an action from text before
+// it is logged or stored in a status, an ActionExecution or a dead letter.
+type redactor struct {
+	values []string
+}
+
+// newRedactor redacts the values of headers, which are all read from
+// Secrets. For Authorization values the credentials after the scheme are
+// redacted on their own too, since receivers often echo only those.
+func newRedactor(headers map[string]string) redactor {
+	var values []string
+	for name, value := range headers {
+		if value == "" {
+			continue
+		}
+		values = append(values, value)
+		if strings.EqualFold(name, "Authorization") {
+			if _, credentials, ok := strings.Cut(value, " "); ok && credentials != "" {
+				values = append(values, credentials)
+			}
+		}
+	}
+	return redactor{}.with(values...)
+}
+
+// with returns a redactor that redacts values as well.
+func (r redactor) with(values ...string) redactor {
+	all := slices.Clone(r.values)
+	for _, value := range values {
+		if value != "" {
+			all = append(all, value)
+		}
+	}
+	// Replace longer values first, so a value containing another one is
+	// not left partially visible.
+	sort.Slice(all, func(i, j int) bool { return len(all[i]) > len(all[j]) })
+	return redactor{values: all}
+}
+
+// redact replaces the Secret values and credential fields in s.
+func (r redactor) redact(s string) string {
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, redactedValue)
+	}
+	return credentialFieldPattern.ReplaceAllString(s, "${1}"+redactedValue)
+}
+
+// redactBody redacts a response body and caps it at maxLoggedResponseBody.
+func (r redactor) redactBody(body []byte) string {
+	s := r.redact(string(body))
+	if len(s) > maxLoggedResponseBody {
+		s = s[:maxLoggedResponseBody] + "...(truncated)"
+	}
+	return s
+}
+
+// redactError returns err with a redacted message. errors.Is and errors.As
+// still see the original error.
+func (r redactor) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := r.redact(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
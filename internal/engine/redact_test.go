@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_RedactsSecretValuesAndCredentialFields(t *testing.T) {
+	r := newRedactor(map[string]string{
+		"Authorization": "Bearer abc123token",
+		"X-Api-Key":     "key-4711",
+	})
+
+	got := r.redact(`unauthorized: abc123token, key key-4711, {"password": "hunter2", "name": "demo"} ?access_token=xyz&page=2`)
+	for _, leaked := range []string{"abc123token", "key-4711", "hunter2", "xyz"} {
+		if strings.Contains(got, leaked) {
+			t.Fatalf("redact() = %q, still contains %q", got, leaked)
+		}
+	}
+	if !strings.Contains(got, `"name": "demo"`) || !strings.Contains(got, "page=2") {
+		t.Fatalf("redact() = %q, redacted more than the credentials", got)
+	}
+
+	if body := r.redactBody([]byte(strings.Repeat("a", 2*maxLoggedResponseBody))); len(body) > maxLoggedResponseBody+len("...(truncated)") {
+		t.Fatalf("redactBody() length = %d, want capped at %d", len(body), maxLoggedResponseBody)
+	}
+}
+
+func TestRedactor_RedactErrorKeepsChain(t *testing.T) {
+	r := newRedactor(map[string]string{"Authorization": "Basic dXNlcjpwYXNz"})
+
+	err := r.redactError(fmt.Errorf("receiver echoed dXNlcjpwYXNz: %w", context.DeadlineExceeded))
+	if strings.Contains(err.Error(), "dXNlcjpwYXNz") {
+		t.Fatalf("redactError() = %q, still contains the credentials", err.Error())
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("redactError() lost the wrapped error")
+	}
+}
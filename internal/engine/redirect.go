@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+const defaultMaxRedirects = 10
+
+// checkRedirect returns the CheckRedirect function of the HTTP client of an
+// action. Redirect targets are checked against the URL policies like the
+// action URL. headers are the resolved headers of the action, which are
+// removed from redirects to another origin unless crossOriginRedirects keeps
+// them.
+func (h *HTTPExecutor) checkRedirect(
+	action opsv1alpha1.ActionSpec,
+	headers map[string]string,
+) func(*http.Request, []*http.Request) error {
+	if action.FollowRedirects != nil && !*action.FollowRedirects {
+		return func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	maxRedirects := action.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	crossOrigin := opsv1alpha1.CrossOriginRedirectSpec{}
+	if action.CrossOriginRedirects != nil {
+		crossOrigin = *action.CrossOriginRedirects
+	}
+
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if err := validateTargetURL(h.compiled, req.URL.String(), action.URLPolicy, h.urlPolicy); err != nil {
+			return fmt.Errorf("redirect to %s: %w", req.URL.Redacted(), err)
+		}
+		if sameOrigin(req.URL, via[0].URL) {
+			return nil
+		}
+		if req.ContentLength != 0 && !crossOrigin.ResendBody {
+			return fmt.Errorf("redirect to %s would re-send the body to another origin", req.URL.Redacted())
+		}
+		// The client already drops Authorization and cookies for other
+		// domains, but not custom headers such as signatures or API keys.
+		for name, value := range headers {
+			if crossOrigin.KeepHeaders {
+				req.Header.Set(name, value)
+			} else {
+				req.Header.Del(name)
+			}
+		}
+		return nil
+	}
+}
+
+func sameOrigin(a, b *url.URL) bool {
+	return a.Scheme == b.Scheme && a.Host == b.Host
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHTTPExecutorExecuteWithMetrics_Redirects(t *testing.T) {
+	var gotAPIKey, gotBody string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAPIKey = r.Header.Get("X-Api-Key")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			w.WriteHeader(http.StatusOK)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/other":
+			http.Redirect(w, r, other.URL, http.StatusFound)
+		case "/other-307":
+			http.Redirect(w, r, other.URL, http.StatusTemporaryRedirect)
+		case "/blocked":
+			http.Redirect(w, r, "http://blocked.example/hook", http.StatusFound)
+		default:
+			http.Redirect(w, r, "/same", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "demo", "namespace": "default"},
+	}}
+	newAction := func(path string) opsv1alpha1.ActionSpec {
+		return opsv1alpha1.ActionSpec{
+			Type:      "http",
+			Method:    "POST",
+			URL:       srv.URL + path,
+			URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			Timeout:   "2s",
+		}
+	}
+	headers := map[string]string{"X-Api-Key": "k1"}
+	exec := NewHTTPExecutor(fake.NewClientBuilder().Build())
+	run := func(action opsv1alpha1.ActionSpec) (HTTPExecutionMetrics, error) {
+		gotAPIKey, gotBody = "", ""
+		return exec.ExecuteWithMetrics(context.Background(), action, "default", obj, headers)
+	}
+
+	if _, err := run(newAction("/start")); err != nil {
+		t.Fatalf("expected redirect to be followed, got %v", err)
+	}
+
+	noFollow := newAction("/start")
+	noFollow.FollowRedirects = ptrTo(false)
+	if metrics, err := run(noFollow); err == nil || metrics.StatusCode != http.StatusFound {
+		t.Fatalf("expected 302 to fail expectedStatus, got status %d and error %v", metrics.StatusCode, err)
+	}
+	noFollow.ExpectedStatus = "^302$"
+	if _, err := run(noFollow); err != nil {
+		t.Fatalf("expected 302 to match expectedStatus, got %v", err)
+	}
+
+	loop := newAction("/loop")
+	loop.MaxRedirects = 2
+	if _, err := run(loop); err == nil || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+		t.Fatalf("expected redirect limit error, got %v", err)
+	}
+
+	blocked := newAction("/blocked")
+	blocked.URLPolicy.BlockedHostRegex = []string{`^blocked\.example$`}
+	if _, err := run(blocked); err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Fatalf("expected blocked redirect target, got %v", err)
+	}
+
+	if _, err := run(newAction("/other")); err != nil {
+		t.Fatalf("expected cross-origin redirect to be followed, got %v", err)
+	}
+	if gotAPIKey != "" {
+		t.Fatalf("expected X-Api-Key to be removed on cross-origin redirect, got %q", gotAPIKey)
+	}
+	keep := newAction("/other")
+	keep.CrossOriginRedirects = &opsv1alpha1.CrossOriginRedirectSpec{KeepHeaders: true}
+	if _, err := run(keep); err != nil || gotAPIKey != "k1" {
+		t.Fatalf("expected X-Api-Key to be kept, got %q and error %v", gotAPIKey, err)
+	}
+
+	withBody := newAction("/other-307")
+	withBody.Body = &opsv1alpha1.TemplateSpec{Template: `{"name":"{{ .metadata.name }}"}`}
+	if _, err := run(withBody); err == nil || !strings.Contains(err.Error(), "re-send the body") {
+		t.Fatalf("expected cross-origin 307 with body to be refused, got %v", err)
+	}
+	withBody.CrossOriginRedirects = &opsv1alpha1.CrossOriginRedirectSpec{ResendBody: true}
+	if _, err := run(withBody); err != nil || gotBody != `{"name":"demo"}` {
+		t.Fatalf("expected body to be re-sent, got %q and error %v", gotBody, err)
+	}
+}
@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultKubeconfigKey = "kubeconfig"
+
+// remoteCluster holds the clients built from the kubeconfig Secret of a
+// spec.clusterRef.
+type remoteCluster struct {
+	id        clusterID
+	dyn       dynamic.Interface
+	meta      metadata.Interface
+	kinds     *kindResolver
+	client    client.Client
+	clientset kubernetes.Interface
+}
+
+// clusterID identifies a remote cluster by its kubeconfig Secret. The
+// resourceVersion is part of the identity so that informers of a rotated
+// kubeconfig are replaced. The zero value is the local cluster.
+type clusterID struct {
+	secret          types.NamespacedName
+	resourceVersion string
+}
+
+func (id clusterID) String() string {
+	return id.secret.String()
+}
+
+// clusterRegistry caches the clients of remote clusters. The Secrets are read
+// through the local client.
+type clusterRegistry struct {
+	client  client.Client
+	secrets *secretCache
+
+	mu       sync.Mutex
+	clusters map[types.NamespacedName]*remoteCluster
+	// newClients builds the clients of a cluster; tests replace it.
+	newClients func(cfg *rest.Config, c *remoteCluster) error
+}
+
+func newClusterRegistry(c client.Client) *clusterRegistry {
+	r := &clusterRegistry{
+		client:   c,
+		secrets:  newSecretCache(c),
+		clusters: make(map[types.NamespacedName]*remoteCluster),
+	}
+	r.newClients = r.buildClients
+	return r
+}
+
+// clusterFor returns the remote cluster ra is bound to, or nil for the local
+// cluster.
+func (r *clusterRegistry) clusterFor(ctx context.Context, ra *opsv1alpha1.ResourceAction) (*remoteCluster, error) {
+	ref := ra.Spec.ClusterRef
+	if ref == nil {
+		return nil, nil
+	}
+	if r == nil || r.client == nil {
+		return nil, fmt.Errorf("remote clusters are not configured")
+	}
+
+	key := types.NamespacedName{Namespace: ra.Namespace, Name: ref.SecretName}
+	secret, err := r.secrets.get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get kubeconfig secret %s: %w", key.String(), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.clusters[key]; ok && cached.id.resourceVersion == secret.ResourceVersion {
+		return cached, nil
+	}
+
+	dataKey := ref.Key
+	if dataKey == "" {
+		dataKey = defaultKubeconfigKey
+	}
+	kubeconfig, ok := secret.Data[dataKey]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %s has no key %q", key.String(), dataKey)
+	}
+	cfg, err := restConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("parse kubeconfig from secret %s: %w", key.String(), err)
+	}
+
+	cluster := &remoteCluster{id: clusterID{secret: key, resourceVersion: secret.ResourceVersion}}
+	if err := r.newClients(cfg, cluster); err != nil {
+		return nil, fmt.Errorf("create clients for cluster %s: %w", key.String(), err)
+	}
+	r.clusters[key] = cluster
+	return cluster, nil
+}
+
+// restConfigFromKubeconfig builds the client config of the current context of
+// kubeconfig from its inline server, CA, token and client certificate only.
+// The kubeconfig comes from a tenant Secret, so credential plugins and file
+// references, which would run commands in or read files of the operator pod,
+// are rejected.
+func restConfigFromKubeconfig(kubeconfig []byte) (*rest.Config, error) {
+	raw, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	kubeContext, ok := raw.Contexts[raw.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("current context %q is not defined", raw.CurrentContext)
+	}
+	cluster, ok := raw.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("cluster %q is not defined", kubeContext.Cluster)
+	}
+	if cluster.Server == "" {
+		return nil, fmt.Errorf("cluster %q has no server", kubeContext.Cluster)
+	}
+	if cluster.CertificateAuthority != "" {
+		return nil, fmt.Errorf("cluster %q: certificate-authority files are not allowed, use certificate-authority-data", kubeContext.Cluster)
+	}
+	user := clientcmdapi.NewAuthInfo()
+	if kubeContext.AuthInfo != "" {
+		if user, ok = raw.AuthInfos[kubeContext.AuthInfo]; !ok {
+			return nil, fmt.Errorf("user %q is not defined", kubeContext.AuthInfo)
+		}
+	}
+	switch {
+	case user.Exec != nil:
+		return nil, fmt.Errorf("user %q: exec credential plugins are not allowed", kubeContext.AuthInfo)
+	case user.AuthProvider != nil:
+		return nil, fmt.Errorf("user %q: auth providers are not allowed", kubeContext.AuthInfo)
+	case user.TokenFile != "" || user.ClientCertificate != "" || user.ClientKey != "":
+		return nil, fmt.Errorf("user %q: tokenFile, client-certificate and client-key files are not allowed, use inline data", kubeContext.AuthInfo)
+	}
+	return &rest.Config{
+		Host:        cluster.Server,
+		BearerToken: user.Token,
+		Username:    user.Username,
+		Password:    user.Password,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure:   cluster.InsecureSkipTLSVerify,
+			ServerName: cluster.TLSServerName,
+			CAData:     cluster.CertificateAuthorityData,
+			CertData:   user.ClientCertificateData,
+			KeyData:    user.ClientKeyData,
+		},
+	}, nil
+}
+
+func (r *clusterRegistry) buildClients(cfg *rest.Config, c *remoteCluster) error {
+	var err error
+	if c.dyn, err = dynamic.NewForConfig(cfg); err != nil {
+		return err
+	}
+	if c.meta, err = metadata.NewForConfig(cfg); err != nil {
+		return err
+	}
+	mapper, err := NewRESTMapper(cfg)
+	if err != nil {
+		return err
+	}
+	c.kinds = newKindResolver(mapper)
+	if c.clientset, err = kubernetes.NewForConfig(cfg); err != nil {
+		return err
+	}
+	c.client, err = client.New(cfg, client.Options{Scheme: r.client.Scheme()})
+	return err
+}
+
+// cached returns the clients last built for the kubeconfig Secret, or nil.
+func (r *clusterRegistry) cached(secret types.NamespacedName) *remoteCluster {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clusters[secret]
+}
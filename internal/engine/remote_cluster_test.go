@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+users:
+- name: remote
+  user:
+    token: secret-token
+`
+
+func newKubeconfigSecret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       map[string][]byte{"kubeconfig": []byte(testKubeconfig)},
+	}
+}
+
+func TestClusterRegistry_ClusterFor(t *testing.T) {
+	_, cl := newTestExecutor(t, newKubeconfigSecret("remote"))
+	registry := newClusterRegistry(cl)
+	var hosts []string
+	registry.newClients = func(cfg *rest.Config, _ *remoteCluster) error {
+		hosts = append(hosts, cfg.Host)
+		return nil
+	}
+	ctx := context.Background()
+
+	local := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "default"}}
+	if cluster, err := registry.clusterFor(ctx, local); err != nil || cluster != nil {
+		t.Fatalf("clusterFor() without clusterRef = %v, %v, want nil, nil", cluster, err)
+	}
+
+	ra := local.DeepCopy()
+	ra.Spec.ClusterRef = &opsv1alpha1.ClusterRefSpec{SecretName: "remote"}
+	first, err := registry.clusterFor(ctx, ra)
+	if err != nil {
+		t.Fatalf("clusterFor() error = %v", err)
+	}
+	if _, err := registry.clusterFor(ctx, ra); err != nil {
+		t.Fatalf("clusterFor() error = %v", err)
+	}
+	if len(hosts) != 1 || hosts[0] != "https://remote.example.com" {
+		t.Fatalf("clients built for %v, want once for https://remote.example.com", hosts)
+	}
+
+	// Rotating the kubeconfig builds new clients with a new identity.
+	var secret corev1.Secret
+	if err := cl.Get(ctx, types.NamespacedName{Name: "remote", Namespace: "default"}, &secret); err != nil {
+		t.Fatalf("get secret: %v", err)
+	}
+	secret.Labels = map[string]string{"rotated": "true"}
+	if err := cl.Update(ctx, &secret); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	second, err := registry.clusterFor(ctx, ra)
+	if err != nil {
+		t.Fatalf("clusterFor() error = %v", err)
+	}
+	if len(hosts) != 2 || second.id == first.id || second.id.secret != first.id.secret {
+		t.Fatalf("expected rebuilt clients after rotation, got ids %v and %v", first.id, second.id)
+	}
+
+	ra.Spec.ClusterRef = &opsv1alpha1.ClusterRefSpec{SecretName: "remote", Key: "missing"}
+	registry.clusters = map[types.NamespacedName]*remoteCluster{}
+	if _, err := registry.clusterFor(ctx, ra); err == nil {
+		t.Fatalf("clusterFor() with missing key error = nil, want error")
+	}
+}
+
+func TestEnsureWatchingFor_RemoteCluster(t *testing.T) {
+	_, cl := newTestExecutor(t, newKubeconfigSecret("remote"))
+	e := newWatchTestEngine(t)
+	e.clusters = newClusterRegistry(cl)
+	e.clusters.newClients = func(_ *rest.Config, c *remoteCluster) error {
+		c.kinds = newKindResolver(newDiscoveryRESTMapper(&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+			},
+		}}}))
+		c.dyn = fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+		})
+		return nil
+	}
+	// Remote clusters are not restricted by --watch-namespaces.
+	e.SetWatchNamespaces([]string{"team-a"})
+
+	ctx := context.Background()
+	ra := newTestResourceAction("remote", "ConfigMap", "team-b")
+	ra.Spec.ClusterRef = &opsv1alpha1.ClusterRefSpec{SecretName: "remote"}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+
+	keys := e.owners[types.NamespacedName{Namespace: "default", Name: "remote"}]
+	if len(keys) != 1 || keys[0].namespace != "team-b" {
+		t.Fatalf("unexpected watch keys %v", keys)
+	}
+	if keys[0].cluster.secret != (types.NamespacedName{Namespace: "default", Name: "remote"}) {
+		t.Fatalf("watch key not bound to the remote cluster: %v", keys[0])
+	}
+	if isWatching(e, "configmaps", "team-b") {
+		t.Fatalf("remote informer registered as local informer")
+	}
+
+	e.ReleaseWatch(ctx, types.NamespacedName{Namespace: "default", Name: "remote"})
+	if len(e.informers) != 0 {
+		t.Fatalf("remote informer still running after release")
+	}
+}
+
+func TestRestConfigFromKubeconfig(t *testing.T) {
+	cfg, err := restConfigFromKubeconfig([]byte(testKubeconfig))
+	if err != nil {
+		t.Fatalf("restConfigFromKubeconfig() error = %v", err)
+	}
+	if cfg.Host != "https://remote.example.com" || cfg.BearerToken != "secret-token" {
+		t.Fatalf("config = %s with token %q, want the inline server and token", cfg.Host, cfg.BearerToken)
+	}
+
+	for name, user := range map[string]string{
+		"exec":          "exec:\n      apiVersion: client.authentication.k8s.io/v1\n      command: /bin/sh",
+		"auth provider": "auth-provider:\n      name: oidc",
+		"token file":    "tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token",
+		"client key":    "client-certificate: /etc/tls/tls.crt\n    client-key: /etc/tls/tls.key",
+	} {
+		kubeconfig := strings.Replace(testKubeconfig, "token: secret-token", user, 1)
+		if _, err := restConfigFromKubeconfig([]byte(kubeconfig)); err == nil {
+			t.Fatalf("%s: restConfigFromKubeconfig() error = nil, want the kubeconfig to be rejected", name)
+		}
+	}
+	kubeconfig := strings.Replace(testKubeconfig, "server: https://remote.example.com", "server: https://remote.example.com\n    certificate-authority: /etc/ca.crt", 1)
+	if _, err := restConfigFromKubeconfig([]byte(kubeconfig)); err == nil {
+		t.Fatalf("restConfigFromKubeconfig() with a CA file error = nil, want the kubeconfig to be rejected")
+	}
+}
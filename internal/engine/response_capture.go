@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const capturedStatusCodeKey = "statusCode"
+
+// captureResponse extracts the values selected by capture from an HTTP
+// response. Fields that cannot be evaluated are left out and reported in the
+// returned error.
+func captureResponse(
+	capture *opsv1alpha1.ResponseCaptureSpec,
+	statusCode int,
+	body []byte,
+) (map[string]string, error) {
+	values := map[string]string{}
+	if capture.StatusCode {
+		values[capturedStatusCodeKey] = strconv.Itoa(statusCode)
+	}
+	if len(capture.Fields) == 0 {
+		return values, nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return values, fmt.Errorf("response body is not JSON: %w", err)
+	}
+
+	keys := make([]string, 0, len(capture.Fields))
+	for key := range capture.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var firstErr error
+	for _, key := range keys {
+		jp := jsonpath.New(key)
+		if err := jp.Parse(capture.Fields[key]); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("field %q: %w", key, err)
+			}
+			continue
+		}
+		var buf bytes.Buffer
+		if err := jp.Execute(&buf, data); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("field %q: %w", key, err)
+			}
+			continue
+		}
+		values[key] = buf.String()
+	}
+	return values, firstErr
+}
+
+// checkExpectedResponse returns why body fails the checks of expected, or nil
+// when it passes them.
+func checkExpectedResponse(compiled *compiledSpec, expected *opsv1alpha1.ExpectedResponseSpec, body []byte) error {
+	if expected == nil {
+		return nil
+	}
+	if expected.BodyRegex != "" {
+		re, err := compiled.compileRegexp("expectedResponse.bodyRegex", expected.BodyRegex)
+		if err != nil {
+			return fmt.Errorf("bodyRegex: %w", err)
+		}
+		if !re.Match(body) {
+			return fmt.Errorf("body does not match %q", expected.BodyRegex)
+		}
+	}
+	if expected.JSONPath == "" {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("response body is not JSON: %w", err)
+	}
+	jp := jsonpath.New("expectedResponse")
+	if err := jp.Parse(expected.JSONPath); err != nil {
+		return fmt.Errorf("jsonPath: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, data); err != nil {
+		return fmt.Errorf("jsonPath %s: %w", expected.JSONPath, err)
+	}
+	if expected.Value != nil && buf.String() != *expected.Value {
+		return fmt.Errorf("jsonPath %s is %q, expected %q", expected.JSONPath, buf.String(), *expected.Value)
+	}
+	return nil
+}
+
+// storeCapturedResponse writes captured values to the target configured on
+// the action.
+func (e *K8sExecutor) storeCapturedResponse(
+	ctx context.Context,
+	ra opsv1alpha1.ResourceAction,
+	actionIndex int,
+	input MatchInput,
+	values map[string]string,
+) error {
+	capture := ra.Spec.Actions[actionIndex].ResponseCapture
+	if capture.Target == "ConfigMap" {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      capture.ConfigMapName,
+				Namespace: ra.Namespace,
+			},
+		}
+		_, err := controllerutil.CreateOrUpdate(ctx, e.Client, cm, func() error {
+			if cm.Data == nil {
+				cm.Data = map[string]string{}
+			}
+			for key, value := range values {
+				cm.Data[key] = value
+			}
+			return nil
+		})
+		return err
+	}
+
+	captured := opsv1alpha1.CapturedResponse{
+		ActionIndex:  actionIndex,
+		ActionName:   actionName(&ra, actionIndex),
+		ResourceUID:  string(input.Obj.GetUID()),
+		ResourceName: input.Obj.GetName(),
+		CapturedAt:   metav1.Now(),
+		Values:       values,
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := e.Client.Get(ctx, client.ObjectKey{Name: ra.Name, Namespace: ra.Namespace}, &latest); err != nil {
+			return err
+		}
+		for i := range latest.Status.CapturedResponses {
+			if latest.Status.CapturedResponses[i].ActionIndex == actionIndex {
+				latest.Status.CapturedResponses[i] = captured
+				return e.Client.Status().Update(ctx, &latest)
+			}
+		}
+		latest.Status.CapturedResponses = append(latest.Status.CapturedResponses, captured)
+		return e.Client.Status().Update(ctx, &latest)
+	})
+}
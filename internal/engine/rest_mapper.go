@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// kindResetInterval bounds how often a kind that is not found invalidates the
+// discovery cache, so ResourceActions waiting for a CRD do not each hit
+// discovery on every reconcile.
+const kindResetInterval = 5 * time.Second
+
+// KindNotServedError is returned when the API server does not serve the
+// selected kind, usually because its CRD is not installed yet.
+type KindNotServedError struct {
+	GVK schema.GroupVersionKind
+}
+
+func (e *KindNotServedError) Error() string {
+	return fmt.Sprintf("kind %q not found in %s", e.GVK.Kind, e.GVK.GroupVersion().String())
+}
+
+// NewRESTMapper returns a RESTMapper for cfg that discovers API groups on first
+// use and caches them. Pass it to the manager and to Engine.SetRESTMapper so
+// both share one discovery cache.
+func NewRESTMapper(cfg *rest.Config) (meta.ResettableRESTMapper, error) {
+	disco, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newDiscoveryRESTMapper(disco), nil
+}
+
+func newDiscoveryRESTMapper(d discovery.DiscoveryInterface) meta.ResettableRESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(d))
+}
+
+// kindResolver maps kinds to resources through a cached RESTMapper. A kind
+// that is not found invalidates the cache, at most once per
+// kindResetInterval, so newly installed CRDs are picked up.
+type kindResolver struct {
+	mapper meta.ResettableRESTMapper
+
+	mu        sync.Mutex
+	lastReset time.Time
+	now       func() time.Time
+}
+
+func newKindResolver(mapper meta.ResettableRESTMapper) *kindResolver {
+	return &kindResolver{mapper: mapper, now: time.Now}
+}
+
+// resolve returns the resource of gvk and whether it is namespaced.
+func (r *kindResolver) resolve(gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error) {
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) && r.invalidate() {
+		mapping, err = r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if meta.IsNoMatchError(err) {
+		return schema.GroupVersionResource{}, false, &KindNotServedError{GVK: gvk}
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+	return mapping.Resource, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// invalidate drops the cached discovery data unless that happened less than
+// kindResetInterval ago. It reports whether the cache was dropped.
+func (r *kindResolver) invalidate() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if !r.lastReset.IsZero() && now.Sub(r.lastReset) < kindResetInterval {
+		return false
+	}
+	r.lastReset = now
+	r.mapper.Reset()
+	return true
+}
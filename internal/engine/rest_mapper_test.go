@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestKindResolver_PicksUpNewKinds(t *testing.T) {
+	fake := &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+		},
+	}}
+	now := time.Unix(0, 0)
+	r := newKindResolver(newDiscoveryRESTMapper(&fakediscovery.FakeDiscovery{Fake: fake}))
+	r.now = func() time.Time { return now }
+
+	gvr, namespaced, err := r.resolve(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	if err != nil || gvr.Resource != "configmaps" || !namespaced {
+		t.Fatalf("resolve(ConfigMap) = %v, %v, %v", gvr, namespaced, err)
+	}
+
+	widget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	var notServed *KindNotServedError
+	if _, _, err := r.resolve(widget); !errors.As(err, &notServed) {
+		t.Fatalf("resolve(Widget) error = %v, want KindNotServedError", err)
+	}
+
+	fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget"}},
+	})
+	// The cache was just invalidated; the new kind shows up after the interval.
+	if _, _, err := r.resolve(widget); !errors.As(err, &notServed) {
+		t.Fatalf("resolve(Widget) within the reset interval error = %v, want KindNotServedError", err)
+	}
+	now = now.Add(kindResetInterval)
+	gvr, namespaced, err = r.resolve(widget)
+	if err != nil || gvr.Resource != "widgets" || namespaced {
+		t.Fatalf("resolve(Widget) after the CRD was installed = %v, %v, %v", gvr, namespaced, err)
+	}
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	recordResultOnObject = "object"
+
+	// defaultResultAnnotationSuffix follows the name of the ResourceAction in
+	// the annotation written when resultAnnotations is not set.
+	defaultResultAnnotationSuffix = ".ops.yusaozdemir.de/notified-at"
+)
+
+// resultAnnotations returns the annotation templates action writes back.
+func resultAnnotations(ra *opsv1alpha1.ResourceAction, action opsv1alpha1.ActionSpec) map[string]string {
+	if len(action.ResultAnnotations) > 0 {
+		return action.ResultAnnotations
+	}
+	return map[string]string{ra.Name + defaultResultAnnotationSuffix: "{{ executedAt }}"}
+}
+
+// recordResult patches the result annotations of action onto the objects of
+// input after the action succeeded. The templates see the outputs of the
+// earlier actions and of action itself.
+func (e *K8sExecutor) recordResult(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	action opsv1alpha1.ActionSpec,
+	input MatchInput,
+	outputs map[string]string,
+) error {
+	if action.RecordResultOn != recordResultOnObject || (input.Event != EventCreate && input.Event != EventUpdate) {
+		return nil
+	}
+	target := e.Client
+	cluster, err := e.clusters.clusterFor(ctx, ra)
+	if err != nil {
+		return err
+	}
+	if cluster != nil {
+		target = cluster.client
+	}
+
+	executedAt := time.Now().UTC().Format(time.RFC3339)
+	funcs := bodyTemplateFuncs(outputs, input.trigger)
+	funcs["executedAt"] = func() string { return executedAt }
+	templates := resultAnnotations(ra, action)
+	var errs []error
+	for _, event := range input.events() {
+		annotations := make(map[string]string, len(templates))
+		for key, text := range templates {
+			value, err := renderTemplate(e.compiled.get(ra), "resultAnnotations."+key, text, event.Obj.Object, funcs)
+			if err != nil {
+				return fmt.Errorf("resultAnnotations[%s]: %w", key, err)
+			}
+			annotations[key] = value
+		}
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"annotations": annotations},
+		})
+		if err != nil {
+			return err
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(event.GVK)
+		obj.SetNamespace(event.Obj.GetNamespace())
+		obj.SetName(event.Obj.GetName())
+		// An object deleted in the meantime has nothing left to annotate.
+		if err := target.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch)); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("annotate %s: %w", event.Obj.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resultWriteBack reports whether an Update only changed result annotations,
+// so that writing them does not run ra or any other ResourceAction again.
+func resultWriteBack(input MatchInput) bool {
+	if input.Event != EventUpdate || input.OldObj == nil {
+		return false
+	}
+	oldAnnotations, newAnnotations := input.OldObj.GetAnnotations(), input.Obj.GetAnnotations()
+	written := false
+	for _, annotations := range []map[string]string{oldAnnotations, newAnnotations} {
+		for key := range annotations {
+			if opsv1alpha1.IsResultAnnotation(key) && oldAnnotations[key] != newAnnotations[key] {
+				written = true
+			}
+		}
+	}
+	if !written {
+		return false
+	}
+	old, obj := input.OldObj.DeepCopy(), input.Obj.DeepCopy()
+	for _, o := range []*unstructured.Unstructured{old, obj} {
+		annotations := o.GetAnnotations()
+		maps.DeleteFunc(annotations, func(key, _ string) bool {
+			return opsv1alpha1.IsResultAnnotation(key)
+		})
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		o.SetAnnotations(annotations)
+	}
+	return changeTypesOf(old, obj) == nil
+}
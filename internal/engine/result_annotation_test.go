@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestExecute_RecordResultOnObject(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"url":"https://tickets.example.com/T-1"}`))
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-ticket", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:        opsv1alpha1.ResourceSelector{Version: "v1", Kind: "ConfigMap"},
+			Events:          []string{"Create", "Update"},
+			ExecutionPolicy: "EveryEvent",
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:           "http",
+				URL:            srv.URL,
+				URLPolicy:      &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Outputs:        map[string]string{"ticketURL": "{.url}"},
+				RecordResultOn: "object",
+				ResultAnnotations: map[string]string{
+					"tickets.ops.yusaozdemir.de/url": `{{ output "ticketURL" }}`,
+				},
+			}},
+		},
+	}
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default", UID: "uid-settings"},
+		Data:       map[string]string{"mode": "a"},
+	}
+	exec, cl := newTestExecutor(t, ra, settings)
+	ctx := context.Background()
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	current := func() *unstructured.Unstructured {
+		t.Helper()
+		var cm corev1.ConfigMap
+		if err := cl.Get(ctx, types.NamespacedName{Name: "settings", Namespace: "default"}, &cm); err != nil {
+			t.Fatalf("get configmap: %v", err)
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cm)
+		if err != nil {
+			t.Fatalf("convert configmap: %v", err)
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		u.SetGroupVersionKind(gvk)
+		return u
+	}
+
+	before := current()
+	if err := exec.Execute(ctx, MatchInput{Event: EventCreate, GVK: gvk, Obj: before}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	after := current()
+	if got := after.GetAnnotations()["tickets.ops.yusaozdemir.de/url"]; got != "https://tickets.example.com/T-1" {
+		t.Fatalf("annotations = %v, want the ticket URL", after.GetAnnotations())
+	}
+
+	// The Update caused by the write-back does not run the action again.
+	writeBack := MatchInput{Event: EventUpdate, GVK: gvk, Obj: after, OldObj: before}
+	if matchesFilters(nil, ra, writeBack) {
+		t.Fatalf("matchesFilters() = true for the write-back of the result")
+	}
+	// Nor does it run other ResourceActions that watch the object.
+	other := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-audit", Namespace: "default"},
+		Spec:       opsv1alpha1.ResourceActionSpec{Events: []string{"Update"}},
+	}
+	if matchesFilters(nil, other, writeBack) {
+		t.Fatalf("matchesFilters() = true for the write-back of another ResourceAction")
+	}
+	changed := after.DeepCopy()
+	_ = unstructured.SetNestedField(changed.Object, "b", "data", "mode")
+	if !matchesFilters(nil, ra, MatchInput{Event: EventUpdate, GVK: gvk, Obj: changed, OldObj: before}) {
+		t.Fatalf("matchesFilters() = false for an Update that also changed the data")
+	}
+}
+
+func TestResultAnnotations_Default(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Name: "notify"}}
+	got := resultAnnotations(ra, opsv1alpha1.ActionSpec{RecordResultOn: "object"})
+	if len(got) != 1 || got["notify.ops.yusaozdemir.de/notified-at"] != "{{ executedAt }}" {
+		t.Fatalf("resultAnnotations() = %v, want the notified-at annotation", got)
+	}
+}
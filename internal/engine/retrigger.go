@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Retrigger queues event for the cached object with uid and delivers it to ra
+// only. The event runs even when spec.executionPolicy recorded it as already
+// executed, so failed or missed executions can be replayed. Delete events of
+// objects that are gone replay the final state an ActionExecution recorded.
+func (e *Engine) Retrigger(ctx context.Context, ra *opsv1alpha1.ResourceAction, uid types.UID, event EventType) error {
+	owner := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	gvk := schema.GroupVersionKind{
+		Group:   ra.Spec.Selector.Group,
+		Version: ra.Spec.Selector.Version,
+		Kind:    ra.Spec.Selector.Kind,
+	}
+
+	if health := e.WatchHealth(owner); !health.Watching || !health.Synced {
+		return fmt.Errorf("informers of %s have not synced", owner.String())
+	}
+	objects, err := e.ListCached(owner)
+	if err != nil {
+		return err
+	}
+	var obj *unstructured.Unstructured
+	for _, cached := range objects {
+		if cached.GetUID() == uid {
+			obj = cached
+			break
+		}
+	}
+	if obj == nil && event == EventDelete {
+		if obj, err = storedFinalState(ctx, e.client, ra, uid); err != nil {
+			return err
+		}
+	}
+	if obj == nil {
+		return fmt.Errorf("object %s is not in the informer cache", uid)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.draining {
+		return errShutdown
+	}
+	e.addLocked(&eventItem{
+		input: MatchInput{
+			Event:     event,
+			GVK:       gvk,
+			Obj:       obj,
+			owners:    map[types.NamespacedName]struct{}{owner: {}},
+			retrigger: true,
+		},
+		key: objectKey{owner: owner, uid: uid},
+	})
+	log.FromContext(ctx).Info("Queued retriggered event",
+		"resourceAction", ra.Name,
+		"event", event,
+		"name", obj.GetName(),
+	)
+	return nil
+}
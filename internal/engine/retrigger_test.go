@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestRetrigger_QueuesCachedObjectForOwner(t *testing.T) {
+	e := newWatchTestEngine(t)
+	e.dyn = fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+	}, newConfigMap("first", "uid-1"), newConfigMap("second", "uid-2"))
+	// Run the informers without event workers to inspect the queued events.
+	e.started = true
+
+	ctx := context.Background()
+	ra := newTestResourceAction("retrigger", "ConfigMap")
+	owner := types.NamespacedName{Namespace: "default", Name: "retrigger"}
+
+	if err := e.Retrigger(ctx, ra, "uid-2", EventUpdate); err == nil {
+		t.Fatalf("Retrigger() without informer error = nil, want error")
+	}
+	if err := e.EnsureWatchingFor(ctx, ra); err != nil {
+		t.Fatalf("EnsureWatchingFor() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !e.WatchHealth(owner).Synced && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Drop the Add events of the initial list.
+	for e.queue.Len() > 0 {
+		item, _ := e.queue.Get()
+		e.queue.Done(item)
+		e.queue.Forget(item)
+		e.finish(item)
+	}
+
+	if err := e.Retrigger(ctx, ra, "uid-missing", EventUpdate); err == nil {
+		t.Fatalf("Retrigger() of an unknown object error = nil, want error")
+	}
+	if err := e.Retrigger(ctx, ra, "uid-2", EventUpdate); err != nil {
+		t.Fatalf("Retrigger() error = %v", err)
+	}
+	if e.queue.Len() != 1 {
+		t.Fatalf("queue length = %d, want 1", e.queue.Len())
+	}
+	item, _ := e.queue.Get()
+	input := e.takeInput(item)
+	other := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}}
+	if input.Event != EventUpdate || input.Obj.GetName() != "second" || !input.retrigger ||
+		!input.targets(ra) || input.targets(other) {
+		t.Fatalf("unexpected retriggered event %s of %s for owners %v", input.Event, input.Obj.GetName(), input.owners)
+	}
+	e.queue.Done(item)
+}
+
+func TestExecute_RetriggerBypassesExecutionPolicy(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-retrigger", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-retrigger", "demo", "default")
+
+	for i := 0; i < 2; i++ {
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want the second event to be skipped", calls.Load())
+	}
+
+	input.retrigger = true
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("retriggered Execute() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want the retriggered event to run again", calls.Load())
+	}
+}
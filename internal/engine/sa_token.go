@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultTokenServiceAccount    = "default"
+	defaultTokenExpirationSeconds = int64(3600)
+)
+
+// serviceAccountTokens requests bound ServiceAccount tokens through the
+// TokenRequest API and caches them until 80% of their lifetime passed, so a
+// cached token is never close to expiry when it is sent.
+type serviceAccountTokens struct {
+	client client.Client
+
+	mu     sync.Mutex
+	tokens map[serviceAccountTokenKey]cachedToken
+	now    func() time.Time
+}
+
+type serviceAccountTokenKey struct {
+	namespace         string
+	serviceAccount    string
+	audience          string
+	expirationSeconds int64
+}
+
+type cachedToken struct {
+	token     string
+	refreshAt time.Time
+}
+
+func newServiceAccountTokens(c client.Client) *serviceAccountTokens {
+	return &serviceAccountTokens{
+		client: c,
+		tokens: make(map[serviceAccountTokenKey]cachedToken),
+		now:    time.Now,
+	}
+}
+
+// token returns a token for auth, issued for a ServiceAccount in namespace.
+func (t *serviceAccountTokens) token(ctx context.Context, namespace string, auth *opsv1alpha1.ServiceAccountTokenAuth) (string, error) {
+	key := serviceAccountTokenKey{
+		namespace:         namespace,
+		serviceAccount:    auth.ServiceAccountName,
+		audience:          auth.Audience,
+		expirationSeconds: defaultTokenExpirationSeconds,
+	}
+	if key.serviceAccount == "" {
+		key.serviceAccount = defaultTokenServiceAccount
+	}
+	if auth.ExpirationSeconds != nil {
+		key.expirationSeconds = *auth.ExpirationSeconds
+	}
+
+	t.mu.Lock()
+	cached, ok := t.tokens[key]
+	t.mu.Unlock()
+	if ok && t.now().Before(cached.refreshAt) {
+		return cached.token, nil
+	}
+
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: key.serviceAccount, Namespace: namespace}}
+	request := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         []string{key.audience},
+			ExpirationSeconds: ptrTo(key.expirationSeconds),
+		},
+	}
+	issuedAt := t.now()
+	if err := t.client.SubResource("token").Create(ctx, sa, request); err != nil {
+		return "", fmt.Errorf("request token for serviceaccount %s/%s: %w", namespace, key.serviceAccount, err)
+	}
+
+	lifetime := request.Status.ExpirationTimestamp.Sub(issuedAt)
+	cached = cachedToken{
+		token:     request.Status.Token,
+		refreshAt: issuedAt.Add(lifetime * 4 / 5),
+	}
+	t.mu.Lock()
+	t.tokens[key] = cached
+	t.mu.Unlock()
+	return cached.token, nil
+}
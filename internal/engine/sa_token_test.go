@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestServiceAccountTokens_CachesUntilRefresh(t *testing.T) {
+	now := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	var requests []authenticationv1.TokenRequestSpec
+	cl := fake.NewClientBuilder().
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceCreate: func(_ context.Context, _ client.Client, subResource string, obj, sub client.Object, _ ...client.SubResourceCreateOption) error {
+				if subResource != "token" || obj.GetName() != "notifier" || obj.GetNamespace() != "team-a" {
+					t.Fatalf("unexpected token request for %s %s/%s", subResource, obj.GetNamespace(), obj.GetName())
+				}
+				request := sub.(*authenticationv1.TokenRequest)
+				requests = append(requests, request.Spec)
+				request.Status.Token = fmt.Sprintf("token-%d", len(requests))
+				request.Status.ExpirationTimestamp = metav1.NewTime(now.Add(time.Duration(*request.Spec.ExpirationSeconds) * time.Second))
+				return nil
+			},
+		}).
+		Build()
+	tokens := newServiceAccountTokens(cl)
+	tokens.now = func() time.Time { return now }
+	auth := &opsv1alpha1.ServiceAccountTokenAuth{ServiceAccountName: "notifier", Audience: "receiver"}
+
+	for range 2 {
+		token, err := tokens.token(context.Background(), "team-a", auth)
+		if err != nil {
+			t.Fatalf("token() error = %v", err)
+		}
+		if token != "token-1" {
+			t.Fatalf("token() = %q, want cached token-1", token)
+		}
+	}
+	if len(requests) != 1 || requests[0].Audiences[0] != "receiver" || *requests[0].ExpirationSeconds != 3600 {
+		t.Fatalf("token requests = %+v, want one for audience receiver with 3600s", requests)
+	}
+
+	// After 80% of the lifetime a new token is requested.
+	now = now.Add(49 * time.Minute)
+	if token, err := tokens.token(context.Background(), "team-a", auth); err != nil || token != "token-2" {
+		t.Fatalf("token() = %q, %v, want refreshed token-2", token, err)
+	}
+}
+
+func TestExecute_ServiceAccountTokenAuth(t *testing.T) {
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	scheme := runtime.NewScheme()
+	if err := opsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-auth", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Auth: &opsv1alpha1.AuthSpec{
+					ServiceAccountToken: &opsv1alpha1.ServiceAccountTokenAuth{Audience: "receiver"},
+				},
+			}},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&opsv1alpha1.ResourceAction{}).
+		WithObjects(ra, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}}).
+		Build()
+	exec := NewK8sExecutor(cl, nil)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-auth", "demo", "default")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if authorization != "Bearer fake-token" {
+		t.Fatalf("Authorization = %q, want bearer token of the default ServiceAccount", authorization)
+	}
+}
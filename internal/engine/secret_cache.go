@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// secretCache caches the Secrets read by actions, so events and retries do not
+// read them from the API server again. Metadata informers on Secrets track
+// their current resourceVersion: a cached Secret is only returned while its
+// resourceVersion is current, and deleted Secrets are dropped. Until the
+// informer of its namespace has synced, and without one, every lookup reads
+// the Secret.
+type secretCache struct {
+	reader client.Reader
+
+	mu      sync.Mutex
+	secrets map[types.NamespacedName]*corev1.Secret
+	// versions holds the stores of the informers that have synced by
+	// namespace; "" is the store of the informer for all namespaces.
+	versions map[string]cache.Store
+
+	// meta and runCtx start the informers with the first lookup, so the
+	// operator only watches Secrets when an action reads one. namespaces
+	// restricts them to these namespaces when set.
+	meta       metadata.Interface
+	runCtx     context.Context
+	namespaces []string
+	startOnce  sync.Once
+}
+
+func newSecretCache(r client.Reader) *secretCache {
+	return &secretCache{
+		reader:   r,
+		secrets:  make(map[types.NamespacedName]*corev1.Secret),
+		versions: make(map[string]cache.Store),
+	}
+}
+
+// enable lets the cache watch Secrets with meta until ctx is done, in the
+// given namespaces or in all namespaces when there are none.
+func (s *secretCache) enable(ctx context.Context, meta metadata.Interface, namespaces []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta = meta
+	s.runCtx = ctx
+	s.namespaces = namespaces
+}
+
+// versionsLocked returns the store that tracks the Secrets of namespace, or
+// nil when none has synced.
+func (s *secretCache) versionsLocked(namespace string) cache.Store {
+	if versions, ok := s.versions[namespace]; ok {
+		return versions
+	}
+	return s.versions[""]
+}
+
+// get returns the Secret key. The returned Secret is shared and must not be
+// modified.
+func (s *secretCache) get(ctx context.Context, key types.NamespacedName) (*corev1.Secret, error) {
+	s.startInformer()
+
+	s.mu.Lock()
+	cached, versions := s.secrets[key], s.versionsLocked(key.Namespace)
+	s.mu.Unlock()
+	if cached != nil && versions != nil {
+		if obj, ok, _ := versions.GetByKey(key.String()); ok && obj.(client.Object).GetResourceVersion() == cached.ResourceVersion {
+			return cached, nil
+		}
+	}
+
+	var secret corev1.Secret
+	if err := s.reader.Get(ctx, key, &secret); err != nil {
+		return nil, err
+	}
+	if versions != nil {
+		s.mu.Lock()
+		s.secrets[key] = &secret
+		s.mu.Unlock()
+	}
+	return &secret, nil
+}
+
+func (s *secretCache) startInformer() {
+	s.mu.Lock()
+	meta, ctx, namespaces := s.meta, s.runCtx, s.namespaces
+	s.mu.Unlock()
+	if meta == nil {
+		return
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	s.startOnce.Do(func() {
+		for _, namespace := range namespaces {
+			s.runInformer(ctx, meta, namespace)
+		}
+	})
+}
+
+// runInformer watches the Secrets of namespace and publishes its store once
+// it has synced.
+func (s *secretCache) runInformer(ctx context.Context, meta metadata.Interface, namespace string) {
+	inf := metadatainformer.NewFilteredMetadataInformer(meta, secretsGVR, namespace, 0, cache.Indexers{}, nil).Informer()
+	if _, err := inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: s.forget,
+	}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to watch Secrets; Secrets are not cached", "namespace", namespace)
+		return
+	}
+	go inf.RunWithContext(ctx)
+	go func() {
+		if !cache.WaitForCacheSync(ctx.Done(), inf.HasSynced) {
+			return
+		}
+		s.mu.Lock()
+		s.versions[namespace] = inf.GetStore()
+		s.mu.Unlock()
+	}()
+}
+
+// forget drops a deleted Secret from the cache.
+func (s *secretCache) forget(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	delete(s.secrets, types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()})
+	s.mu.Unlock()
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakemetadata "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestSecretCache_ReadsAgainOnceResourceVersionChanges(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook-token", Namespace: "team-a"},
+		Data:       map[string][]byte{"token": []byte("v1")},
+	}
+	gets := 0
+	cl := fake.NewClientBuilder().
+		WithObjects(secret).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				gets++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+	secrets := newSecretCache(cl)
+	key := types.NamespacedName{Namespace: "team-a", Name: "hook-token"}
+	ctx := context.Background()
+
+	// Without the informer every lookup reads the Secret.
+	for range 2 {
+		if _, err := secrets.get(ctx, key); err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+	}
+	if gets != 2 {
+		t.Fatalf("Get calls = %d, want 2 without informer", gets)
+	}
+
+	versions := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	secrets.versions[""] = versions
+	current, err := secrets.get(ctx, key)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if err := versions.Add(&metav1.PartialObjectMetadata{ObjectMeta: current.ObjectMeta}); err != nil {
+		t.Fatalf("add version: %v", err)
+	}
+	gets = 0
+	for range 3 {
+		if _, err := secrets.get(ctx, key); err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+	}
+	if gets != 0 {
+		t.Fatalf("Get calls = %d, want cached Secret while the resourceVersion is current", gets)
+	}
+
+	updated := current.DeepCopy()
+	updated.Data = map[string][]byte{"token": []byte("v2")}
+	if err := cl.Update(ctx, updated); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	if err := versions.Update(&metav1.PartialObjectMetadata{ObjectMeta: updated.ObjectMeta}); err != nil {
+		t.Fatalf("update version: %v", err)
+	}
+	got, err := secrets.get(ctx, key)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if string(got.Data["token"]) != "v2" || gets != 1 {
+		t.Fatalf("get() = %q after %d reads, want v2 read once", got.Data["token"], gets)
+	}
+
+	secrets.forget(cache.DeletedFinalStateUnknown{Key: key.String(), Obj: &metav1.PartialObjectMetadata{ObjectMeta: updated.ObjectMeta}})
+	if _, ok := secrets.secrets[key]; ok {
+		t.Fatalf("deleted Secret is still cached")
+	}
+}
+
+func TestSecretCache_WatchesOnlyGivenNamespaces(t *testing.T) {
+	scheme := fakemetadata.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatalf("add meta scheme: %v", err)
+	}
+	meta := fakemetadata.NewSimpleMetadataClient(scheme)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	secrets := newSecretCache(fake.NewClientBuilder().Build())
+	secrets.enable(ctx, meta, []string{"team-a", "team-b"})
+	secrets.startInformer()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		secrets.mu.Lock()
+		synced := len(secrets.versions)
+		secrets.mu.Unlock()
+		if synced == 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	if secrets.versions["team-a"] == nil || secrets.versions["team-b"] == nil {
+		t.Fatalf("versions = %v, want stores for team-a and team-b", secrets.versions)
+	}
+	if secrets.versionsLocked("team-c") != nil {
+		t.Fatalf("expected no store for Secrets outside the watched namespaces")
+	}
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// shard is the part of the informer events a replica handles when event
+// processing is sharded across replicas. The zero value handles every event.
+type shard struct {
+	index int
+	count int
+}
+
+// SetShard makes the engine handle only the informer events of objects whose
+// UID hashes to shard index of count. Sharded engines run on every replica
+// instead of the leader only; the ResourceAction controller, standalone
+// schedules, backfills and retriggers stay with the leader. It must be called
+// before the engine is added to the manager.
+func (e *Engine) SetShard(index, count int) error {
+	if count < 1 || index < 0 || index >= count {
+		return fmt.Errorf("shard index %d is out of range for %d shards", index, count)
+	}
+	e.shard = shard{index: index, count: count}
+	return nil
+}
+
+// sharded reports whether the events are spread across several replicas.
+func (s shard) sharded() bool {
+	return s.count > 1
+}
+
+// owns reports whether the replica handles the events of obj.
+func (s shard) owns(obj *unstructured.Unstructured) bool {
+	if !s.sharded() {
+		return true
+	}
+	return shardOf(obj.GetUID(), s.count) == s.index
+}
+
+// shardOf hashes uid to one of count shards.
+func shardOf(uid types.UID, count int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(uid))
+	return int(h.Sum32() % uint32(count))
+}
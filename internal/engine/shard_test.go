@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSetShard(t *testing.T) {
+	e := NewEngine(nil)
+	if !e.NeedLeaderElection() {
+		t.Fatalf("NeedLeaderElection() = false, want an unsharded engine to run on the leader only")
+	}
+	for _, tc := range []struct{ index, count int }{{-1, 3}, {3, 3}, {0, 0}} {
+		if err := e.SetShard(tc.index, tc.count); err == nil {
+			t.Fatalf("SetShard(%d, %d) = nil, want an error", tc.index, tc.count)
+		}
+	}
+	if err := e.SetShard(1, 3); err != nil {
+		t.Fatalf("SetShard(1, 3) = %v", err)
+	}
+	if e.NeedLeaderElection() {
+		t.Fatalf("NeedLeaderElection() = true, want a sharded engine on every replica")
+	}
+}
+
+func TestShard_OwnsEveryObjectOnce(t *testing.T) {
+	const count = 3
+	shards := make([]shard, count)
+	for i := range shards {
+		shards[i] = shard{index: i, count: count}
+	}
+
+	perShard := make([]int, count)
+	for i := 0; i < 300; i++ {
+		obj := &unstructured.Unstructured{}
+		obj.SetUID(types.UID(fmt.Sprintf("uid-%d", i)))
+		owners := 0
+		for j, s := range shards {
+			if s.owns(obj) {
+				owners++
+				perShard[j]++
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("object %s owned by %d shards, want 1", obj.GetUID(), owners)
+		}
+	}
+	for i, n := range perShard {
+		if n < 50 {
+			t.Fatalf("shard %d owns %d of 300 objects, want them spread across the shards", i, n)
+		}
+	}
+
+	if !(shard{}).owns(&unstructured.Unstructured{}) {
+		t.Fatalf("unsharded engine does not own an object")
+	}
+}
+
+func TestReadyCheck_ShardedStandby(t *testing.T) {
+	e := NewEngine(nil)
+	if err := e.SetShard(1, 2); err != nil {
+		t.Fatalf("SetShard: %v", err)
+	}
+	key := watchKey{gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}}
+	e.informers[key] = cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	check := e.ReadyCheck(make(chan struct{}))
+
+	if err := check(req); err == nil {
+		t.Fatalf("check() before Start = nil, want an error")
+	}
+	// A standby neither restores schedules nor waits for reconciles.
+	e.started = true
+	if err := check(req); err == nil || !strings.Contains(err.Error(), "informers not synced") {
+		t.Fatalf("check() = %v, want the informer that has not synced", err)
+	}
+	delete(e.informers, key)
+	if err := check(req); err != nil {
+		t.Fatalf("check() = %v, want nil", err)
+	}
+}
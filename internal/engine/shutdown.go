@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultShutdownGracePeriod = 25 * time.Second
+	// workerStopTimeout bounds the wait for workers after their context was
+	// cancelled at the end of the grace period.
+	workerStopTimeout = 5 * time.Second
+	// undeliveredRecordTimeout bounds recording undelivered events in status.
+	undeliveredRecordTimeout = 10 * time.Second
+)
+
+// errShutdown is recorded for events the operator could not deliver before it
+// shut down.
+var errShutdown = errors.New("operator shut down before the event was delivered")
+
+// UndeliveredRecorder is implemented by executors that can record events which
+// were not delivered before shutdown, or whose paused executions were
+// cancelled by the deletion of their ResourceAction, as failed executions.
+type UndeliveredRecorder interface {
+	RecordUndelivered(ctx context.Context, input MatchInput, reason error) error
+}
+
+// SetShutdownGracePeriod sets how long shutdown waits for queued and running
+// events before the remaining ones are recorded as undelivered.
+func (e *Engine) SetShutdownGracePeriod(d time.Duration) {
+	e.shutdownGracePeriod = d
+}
+
+// Shutdown stops accepting events, stops the informers and processes the
+// queued events until ctx is done. Debounced Updates are processed right away
+// and the batches collected for spec.aggregation are delivered after that.
+// Events that were not delivered by then are recorded as failures in the
+// status of their ResourceActions.
+func (e *Engine) Shutdown(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	e.mu.Lock()
+	if e.draining {
+		e.mu.Unlock()
+		return
+	}
+	e.draining = true
+	for key, stop := range e.stops {
+		stop()
+		delete(e.stops, key)
+		delete(e.informers, key)
+	}
+	// Deliver debounced Updates right away. Executions paused by a wait
+	// action are not resumed early; they are recorded as undelivered.
+	for key, chain := range e.chains {
+		if chain[0].input.resume != nil {
+			delete(e.chains, key)
+			continue
+		}
+		e.queue.Add(chain[0])
+	}
+	started := e.started
+	drained := make(chan struct{})
+	e.drained = drained
+	e.notifyDrainedLocked()
+	e.mu.Unlock()
+	if !started {
+		return
+	}
+
+	logger.Info("Draining event queue", "queued", e.queue.Len())
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		logger.Info("Shutdown grace period expired, cancelling running actions")
+	}
+	e.stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(workerStopTimeout):
+	}
+
+	if flusher, ok := e.executor.(AggregationFlusher); ok {
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), aggregationFlushTimeout)
+		flusher.FlushAggregations(flushCtx)
+		cancel()
+	}
+	if flusher, ok := e.executor.(StatusFlusher); ok {
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusFlushTimeout)
+		flusher.FlushStatus(flushCtx)
+		cancel()
+	}
+
+	e.mu.Lock()
+	undelivered := make([]*eventItem, 0, len(e.tracked))
+	for item := range e.tracked {
+		undelivered = append(undelivered, item)
+	}
+	e.mu.Unlock()
+	if len(undelivered) == 0 {
+		logger.Info("Event queue drained")
+		return
+	}
+
+	logger.Info("Recording undelivered events", "count", len(undelivered))
+	recorder, ok := e.executor.(UndeliveredRecorder)
+	if !ok {
+		return
+	}
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), undeliveredRecordTimeout)
+	defer cancel()
+	for _, item := range undelivered {
+		if err := recorder.RecordUndelivered(recordCtx, item.input, errShutdown); err != nil {
+			logger.Error(err, "failed to record undelivered event",
+				"event", item.input.Event,
+				"gvk", item.input.GVK.String(),
+				"name", item.input.Obj.GetName(),
+			)
+		}
+	}
+}
+
+// RecordUndelivered appends a failed execution record for input to every
+// ResourceAction the event was meant for.
+func (e *K8sExecutor) RecordUndelivered(ctx context.Context, input MatchInput, reason error) error {
+	if input.resume != nil && input.resume.persisted && errors.Is(reason, errShutdown) {
+		// A pending delivery, which is delivered again after the restart.
+		return nil
+	}
+	var list opsv1alpha1.ResourceActionList
+	if err := e.Client.List(ctx, &list); err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range list.Items {
+		ra := &list.Items[i]
+		if !matchesSelector(ra.Spec.Selector, input.GVK) ||
+			!input.targets(ra) || !watchesNamespace(ra.Spec.WatchNamespaces, input.Obj) ||
+			!containsEvent(ra.Spec.Events, string(input.Event)) ||
+			!matchesFilters(e.compiled.get(ra), ra, input) || ra.Spec.Suspend {
+			continue
+		}
+
+		record := opsv1alpha1.ExecutionRecord{
+			ResourceUID: string(input.Obj.GetUID()),
+			Event:       string(input.Event),
+			ExecutedAt:  metav1.Now(),
+		}
+		fillExecutionRecord(&record, input, -1, reason)
+
+		if usesActionExecutions(ra) {
+			if err := createActionExecution(ctx, e.Client, ra, record, nil, nil, finalState(input)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ra.Name, err))
+			}
+		}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			var latest opsv1alpha1.ResourceAction
+			if err := e.Client.Get(ctx, client.ObjectKeyFromObject(ra), &latest); err != nil {
+				return err
+			}
+			if !usesActionExecutions(&latest) {
+				latest.Status.Executions = append(latest.Status.Executions, record)
+				pruneExecutions(&latest, time.Now())
+			}
+			latest.Status.LastError = reason.Error()
+			return e.Client.Status().Update(ctx, &latest)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ra.Name, err))
+		}
+		e.exportRecord(ctx, ra, record, nil)
+	}
+	return errors.Join(errs...)
+}
+
+// notifyDrainedLocked closes drained once every object either has no events
+// left or stalled.
+func (e *Engine) notifyDrainedLocked() {
+	if e.drained != nil && len(e.chains) == 0 {
+		close(e.drained)
+		e.drained = nil
+	}
+}
+
+func (e *Engine) isDraining() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.draining
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// blockingExecutor blocks every event with the name "stuck" until its context
+// is cancelled and records undelivered events.
+type blockingExecutor struct {
+	mu          sync.Mutex
+	delivered   []string
+	undelivered []string
+}
+
+func (b *blockingExecutor) Execute(ctx context.Context, input MatchInput) error {
+	if input.Obj.GetName() == "stuck" {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.delivered = append(b.delivered, input.Obj.GetName())
+	return nil
+}
+
+func (b *blockingExecutor) RecordUndelivered(_ context.Context, input MatchInput, _ error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.undelivered = append(b.undelivered, input.Obj.GetName())
+	return nil
+}
+
+func TestShutdown_DrainsQueuedEvents(t *testing.T) {
+	exec := &blockingExecutor{}
+	_, cl := newTestExecutor(t)
+	e := NewEngine(cl)
+	e.executor = exec
+	e.runCtx = context.Background()
+	workCtx, stopWorkers := context.WithCancel(e.runCtx)
+	e.stopWorkers = stopWorkers
+	e.started = true
+	e.startWorkers(workCtx)
+	debounced := types.NamespacedName{Namespace: "default", Name: "debounced"}
+	e.debounces[debounced] = time.Hour
+
+	e.enqueue(newDeploymentInput("uid-1", "first", "default"))
+	e.enqueue(newDeploymentInput("uid-2", "second", "default"))
+	update := newUpdateInput("uid-3", "1", "2", debounced)
+	update.Obj.SetName("debounced")
+	e.enqueue(update)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	e.Shutdown(ctx)
+
+	if len(exec.delivered) != 3 {
+		t.Fatalf("delivered = %v, want 3 events including the debounced Update", exec.delivered)
+	}
+	if len(exec.undelivered) != 0 {
+		t.Fatalf("undelivered = %v, want none", exec.undelivered)
+	}
+
+	e.enqueue(newDeploymentInput("uid-4", "late", "default"))
+	if e.queue.Len() != 0 || len(e.tracked) != 0 {
+		t.Fatalf("event accepted after shutdown")
+	}
+}
+
+func TestShutdown_RecordsUndeliveredEvents(t *testing.T) {
+	exec := &blockingExecutor{}
+	_, cl := newTestExecutor(t)
+	e := NewEngine(cl)
+	e.executor = exec
+	e.runCtx = context.Background()
+	workCtx, stopWorkers := context.WithCancel(e.runCtx)
+	e.stopWorkers = stopWorkers
+	e.started = true
+	e.startWorkers(workCtx)
+
+	e.enqueue(newDeploymentInput("uid-1", "stuck", "default"))
+	deadline := time.Now().Add(5 * time.Second)
+	for e.queue.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	e.Shutdown(ctx)
+
+	if len(exec.undelivered) != 1 || exec.undelivered[0] != "stuck" {
+		t.Fatalf("undelivered = %v, want [stuck]", exec.undelivered)
+	}
+}
+
+func TestRecordUndelivered_AppendsFailedExecution(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra-undelivered",
+			Namespace: "default",
+		},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Events: []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{Type: "http", URL: "http://example.invalid"},
+			},
+		},
+	}
+	other := ra.DeepCopy()
+	other.Name = "ra-other-kind"
+	other.Spec.Selector.Kind = "StatefulSet"
+
+	exec, cl := newTestExecutor(t, ra, other)
+	input := newDeploymentInput("uid-undelivered", "demo", "default")
+	if err := exec.RecordUndelivered(context.Background(), input, errShutdown); err != nil {
+		t.Fatalf("RecordUndelivered() error = %v", err)
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 1 {
+		t.Fatalf("expected 1 execution record, got %d", len(got.Status.Executions))
+	}
+	record := got.Status.Executions[0]
+	if record.Result != "Failed" || record.Error != errShutdown.Error() || record.ResourceUID != "uid-undelivered" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if got.Status.LastError != errShutdown.Error() {
+		t.Fatalf("lastError = %q, want %q", got.Status.LastError, errShutdown.Error())
+	}
+
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: other.Name, Namespace: other.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 0 {
+		t.Fatalf("expected no record for a non-matching ResourceAction, got %+v", got.Status.Executions)
+	}
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// activeSilence returns the name of the first Silence that mutes input for ra
+// at now, or "" when none does. Silences that cannot be read do not block
+// executions: the error is logged and the event is not silenced.
+func (e *K8sExecutor) activeSilence(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput, now time.Time) string {
+	var silences opsv1alpha1.SilenceList
+	if err := e.Client.List(ctx, &silences); err != nil {
+		if !meta.IsNoMatchError(err) {
+			log.FromContext(ctx).Error(err, "failed to list silences, executing unsilenced",
+				"resourceAction", ra.Name,
+			)
+		}
+		return ""
+	}
+	for i := range silences.Items {
+		silence := &silences.Items[i]
+		if silenceActive(silence, now) && silenceMatches(silence, ra, input) {
+			return silence.Name
+		}
+	}
+	return ""
+}
+
+// silenceActive reports whether silence is active at now. It starts at
+// spec.startsAt, or at its creation, and ends at spec.endsAt.
+func silenceActive(silence *opsv1alpha1.Silence, now time.Time) bool {
+	start := silence.CreationTimestamp.Time
+	if silence.Spec.StartsAt != nil {
+		start = silence.Spec.StartsAt.Time
+	}
+	return !now.Before(start) && now.Before(silence.Spec.EndsAt.Time)
+}
+
+// silenceMatches reports whether any matcher of silence matches input of ra.
+func silenceMatches(silence *opsv1alpha1.Silence, ra *opsv1alpha1.ResourceAction, input MatchInput) bool {
+	return slices.ContainsFunc(silence.Spec.Matchers, func(m opsv1alpha1.SilenceMatcher) bool {
+		return matchesSilenceMatcher(m, ra, input)
+	})
+}
+
+func matchesSilenceMatcher(m opsv1alpha1.SilenceMatcher, ra *opsv1alpha1.ResourceAction, input MatchInput) bool {
+	if (m.Group != "" && m.Group != input.GVK.Group) ||
+		(m.Version != "" && m.Version != input.GVK.Version) ||
+		(m.Kind != "" && m.Kind != input.GVK.Kind) {
+		return false
+	}
+	if len(m.Namespaces) > 0 && !slices.Contains(m.Namespaces, input.Obj.GetNamespace()) {
+		return false
+	}
+	labels := input.Obj.GetLabels()
+	for key, value := range m.Labels {
+		if got, ok := labels[key]; !ok || got != value {
+			return false
+		}
+	}
+	return len(m.ResourceActions) == 0 || slices.Contains(m.ResourceActions, ra.Namespace+"/"+ra.Name)
+}
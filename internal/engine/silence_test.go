@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSilenceMatches(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "ops"}}
+	input := newDeploymentInput("uid-1", "web", "shop")
+	input.Obj.SetLabels(map[string]string{"app": "web", "tier": "frontend"})
+
+	tests := []struct {
+		name    string
+		matcher opsv1alpha1.SilenceMatcher
+		want    bool
+	}{
+		{name: "empty", matcher: opsv1alpha1.SilenceMatcher{}, want: true},
+		{name: "kind", matcher: opsv1alpha1.SilenceMatcher{Group: "apps", Kind: "Deployment"}, want: true},
+		{name: "other kind", matcher: opsv1alpha1.SilenceMatcher{Kind: "StatefulSet"}},
+		{name: "other version", matcher: opsv1alpha1.SilenceMatcher{Version: "v1beta1"}},
+		{name: "namespace", matcher: opsv1alpha1.SilenceMatcher{Namespaces: []string{"billing", "shop"}}, want: true},
+		{name: "other namespace", matcher: opsv1alpha1.SilenceMatcher{Namespaces: []string{"billing"}}},
+		{name: "labels", matcher: opsv1alpha1.SilenceMatcher{Labels: map[string]string{"app": "web"}}, want: true},
+		{name: "other labels", matcher: opsv1alpha1.SilenceMatcher{Labels: map[string]string{"app": "web", "tier": "backend"}}},
+		{name: "resource action", matcher: opsv1alpha1.SilenceMatcher{ResourceActions: []string{"ops/notify"}}, want: true},
+		{name: "other resource action", matcher: opsv1alpha1.SilenceMatcher{ResourceActions: []string{"shop/notify"}}},
+	}
+	for _, tt := range tests {
+		silence := &opsv1alpha1.Silence{Spec: opsv1alpha1.SilenceSpec{Matchers: []opsv1alpha1.SilenceMatcher{tt.matcher}}}
+		if got := silenceMatches(silence, ra, input); got != tt.want {
+			t.Fatalf("%s: silenceMatches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSilenceActive(t *testing.T) {
+	now := time.Now()
+	created := metav1.NewTime(now.Add(-time.Hour))
+	silence := &opsv1alpha1.Silence{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+		Spec:       opsv1alpha1.SilenceSpec{EndsAt: metav1.NewTime(now.Add(time.Hour))},
+	}
+	if !silenceActive(silence, now) {
+		t.Fatalf("silence without startsAt should be active since its creation")
+	}
+	startsAt := metav1.NewTime(now.Add(time.Minute))
+	silence.Spec.StartsAt = &startsAt
+	if silenceActive(silence, now) {
+		t.Fatalf("silence should not be active before startsAt")
+	}
+	if silenceActive(silence, now.Add(2*time.Hour)) {
+		t.Fatalf("silence should not be active after endsAt")
+	}
+}
+
+func TestExecute_Silenced(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-silenced", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	silence := &opsv1alpha1.Silence{
+		ObjectMeta: metav1.ObjectMeta{Name: "incident-42"},
+		Spec: opsv1alpha1.SilenceSpec{
+			Matchers:  []opsv1alpha1.SilenceMatcher{{Kind: "Deployment", Namespaces: []string{"shop"}}},
+			EndsAt:    metav1.NewTime(time.Now().Add(time.Hour)),
+			CreatedBy: "on-call",
+		},
+	}
+	exec, _ := newTestExecutor(t, ra, silence)
+	ctx := context.Background()
+
+	if err := exec.Execute(ctx, newDeploymentInput("uid-silenced", "web", "shop")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want the silenced event to be skipped", calls.Load())
+	}
+	if err := exec.Execute(ctx, newDeploymentInput("uid-other", "web", "billing")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want the event in another namespace to run", calls.Load())
+	}
+}
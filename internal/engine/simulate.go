@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+// Simulation is the outcome of a ResourceAction for an event, evaluated
+// without a cluster by the kubectl resource-action plugin.
+type Simulation struct {
+	// Matched reports whether the selector, the events and the filters
+	// match the event. Reason explains why they do not.
+	Matched bool
+	Reason  string
+	// Requests holds the requests of the event-driven HTTP actions, rendered
+	// like in a dry run.
+	Requests []opsv1alpha1.RenderedRequest
+	// Replays holds the result per action when the requests were replayed.
+	Replays []Replay
+}
+
+// Replay is the result of sending the request of an HTTP action to a mock.
+type Replay struct {
+	ActionIndex int
+	ActionName  string
+	Request     *opsv1alpha1.HTTPRequestRecord
+	Response    *opsv1alpha1.HTTPResponseRecord
+	// Captured and Outputs hold the values selected by responseCapture and
+	// outputs from the response.
+	Captured map[string]string
+	Outputs  map[string]string
+	Err      error
+}
+
+// Simulate evaluates ra for input and renders the requests of its
+// event-driven HTTP actions. With replayURL set, the requests are also sent
+// to that URL, keeping their path and query, so expectedResponse,
+// successCondition, responseCapture and outputs are checked against the
+// response of a mock. Headers read from Secrets or Vault and the
+// Authorization header are masked in both cases, and replayed requests are
+// not retried.
+func Simulate(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput, replayURL string) (Simulation, error) {
+	if input.Event == EventUpdate && input.OldObj != nil {
+		input.changes = changeTypesOf(input.OldObj, input.Obj)
+	}
+	var sim Simulation
+	switch {
+	case !matchesSelector(ra.Spec.Selector, input.GVK):
+		sim.Reason = fmt.Sprintf("spec.selector does not select %s", input.GVK.String())
+	case !containsEvent(ra.Spec.Events, string(input.Event)):
+		sim.Reason = fmt.Sprintf("spec.events does not contain %s", input.Event)
+	case !watchesNamespace(ra.Spec.WatchNamespaces, input.Obj):
+		sim.Reason = fmt.Sprintf("spec.watchNamespaces does not contain %s", input.Obj.GetNamespace())
+	case !matchesFilters(nil, ra, input):
+		sim.Reason = "spec.filters do not match the object"
+	default:
+		sim.Matched = true
+	}
+	if !sim.Matched {
+		return sim, nil
+	}
+
+	ctx, correlationID := withCorrelationID(ctx)
+	requests, _, _, err := NewHTTPExecutor(nil).renderActions(ra, input, correlationID)
+	sim.Requests = requests
+	if err != nil || replayURL == "" {
+		return sim, err
+	}
+	mock, err := url.Parse(replayURL)
+	if err != nil {
+		return sim, fmt.Errorf("parse replay URL: %w", err)
+	}
+
+	httpExec := NewHTTPExecutor(nil)
+	outputs := map[string]string{}
+	httpExec.outputs = outputs
+	var errs []error
+	for i, action := range ra.Spec.Actions {
+		if !renderable(action) {
+			continue
+		}
+		replay := Replay{ActionIndex: i, ActionName: action.Name}
+		if action.ForEach != nil {
+			replay.Err = errors.New("forEach actions are rendered but not replayed")
+			sim.Replays = append(sim.Replays, replay)
+			continue
+		}
+		target, err := replayTarget(action.URL, mock)
+		if err != nil {
+			replay.Err = err
+			sim.Replays = append(sim.Replays, replay)
+			continue
+		}
+		headers := map[string]string{}
+		for _, request := range requests {
+			if request.ActionIndex == i {
+				headers = request.Headers
+			}
+		}
+		action.URL = target
+		action.FallbackURLs = nil
+		action.Retry = nil
+		action.Headers = nil
+		action.Auth = nil
+		action.TLS = nil
+		action.Proxy = nil
+		action.URLPolicy = &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}
+		metrics, err := httpExec.forAction(ra, i).ExecuteWithMetrics(ctx, action, ra.Namespace, input.Obj, headers)
+		replay.Request = metrics.Request
+		replay.Response = metrics.Response
+		replay.Captured = metrics.Captured
+		replay.Outputs = metrics.Outputs
+		replay.Err = err
+		sim.Replays = append(sim.Replays, replay)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("action %s: %w", actionName(ra, i), err))
+		}
+		for name, value := range metrics.Outputs {
+			outputs[name] = value
+		}
+	}
+	return sim, errors.Join(errs...)
+}
+
+// replayTarget returns target with the scheme and host of mock, keeping the
+// path and query of target below the path of mock.
+func replayTarget(target string, mock *url.URL) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %w", err)
+	}
+	replayed := *mock
+	replayed.Path = mock.Path + u.Path
+	replayed.RawPath = ""
+	replayed.RawQuery = u.RawQuery
+	return replayed.String(), nil
+}
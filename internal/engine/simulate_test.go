@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSimulate(t *testing.T) {
+	var path, body, token string
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		path, body, token = r.URL.RequestURI(), string(raw), r.Header.Get("X-Token")
+		_, _ = io.WriteString(w, `{"id":"42"}`)
+	}))
+	defer mock.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "ops"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Filters:  &opsv1alpha1.FilterSpec{NamespaceRegex: "^shop$"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Name: "create",
+					Type: "http",
+					URL:  "https://tickets.example.com/api/tickets?source=operator",
+					Headers: map[string]opsv1alpha1.ValueFrom{
+						"X-Token": {SecretKeyRef: &opsv1alpha1.SecretKeyRef{Name: "tickets", Key: "token"}},
+					},
+					Body:    &opsv1alpha1.TemplateSpec{Template: `{"name":"{{ .metadata.name }}"}`},
+					Outputs: map[string]string{"ticket": "{.id}"},
+				},
+				{
+					Name: "comment",
+					Type: "http",
+					URL:  "https://tickets.example.com/api/comments",
+					Body: &opsv1alpha1.TemplateSpec{Template: `{"ticket":"{{ output "ticket" }}"}`},
+				},
+				{Name: "cleanup", Type: "job", Job: &opsv1alpha1.JobSpec{Image: "busybox"}},
+			},
+		},
+	}
+
+	sim, err := Simulate(context.Background(), ra, newDeploymentInput("uid-1", "cart", "shop"), "")
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if !sim.Matched || len(sim.Requests) != 2 || len(sim.Replays) != 0 {
+		t.Fatalf("simulation = %+v, want the two HTTP requests rendered", sim)
+	}
+	if sim.Requests[0].Body != `{"name":"cart"}` || sim.Requests[0].Headers["X-Token"] != redactedValue {
+		t.Fatalf("request = %+v", sim.Requests[0])
+	}
+	if sim.Requests[1].Body != `{"ticket":"<output ticket>"}` {
+		t.Fatalf("request = %+v, want a placeholder for the output", sim.Requests[1])
+	}
+
+	sim, err = Simulate(context.Background(), ra, newDeploymentInput("uid-1", "cart", "shop"), mock.URL+"/mock")
+	if err != nil {
+		t.Fatalf("Simulate() with replay error = %v", err)
+	}
+	if len(sim.Replays) != 2 || sim.Replays[0].Outputs["ticket"] != "42" {
+		t.Fatalf("replays = %+v, want the output of the mock response", sim.Replays)
+	}
+	if path != "/mock/api/comments" || body != `{"ticket":"42"}` {
+		t.Fatalf("mock received %s %s, want the second request with the output", path, body)
+	}
+	if token != "" {
+		t.Fatalf("X-Token = %q, want Secret headers left out of the replay", token)
+	}
+
+	sim, err = Simulate(context.Background(), ra, newDeploymentInput("uid-1", "cart", "default"), mock.URL)
+	if err != nil || sim.Matched || !strings.Contains(sim.Reason, "filters") {
+		t.Fatalf("simulation = %+v, %v, want the filters to reject the object", sim, err)
+	}
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HistoryClearer is implemented by executors that can drop the execution
+// history of a ResourceAction.
+type HistoryClearer interface {
+	ClearHistory(ctx context.Context, ra *opsv1alpha1.ResourceAction) error
+}
+
+// ResyncSchedules restarts the schedules of ra after its spec changed.
+func (e *Engine) ResyncSchedules(ctx context.Context, ra opsv1alpha1.ResourceAction) error {
+	return e.cronEngine.Resync(ctx, ra)
+}
+
+// ClearHistory drops the execution history and deduplication state of ra.
+func (e *Engine) ClearHistory(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	clearer, ok := e.executor.(HistoryClearer)
+	if !ok {
+		return fmt.Errorf("executor does not support clearing the execution history")
+	}
+	return clearer.ClearHistory(ctx, ra)
+}
+
+// ClearHistory drops the dedup entries, the ActionExecution records and the
+// status.executions and status.actionStates of ra, so its actions run again
+// for objects that were already handled.
+func (e *K8sExecutor) ClearHistory(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cm corev1.ConfigMap
+		if err := e.Client.Get(ctx, client.ObjectKey{Name: dedupConfigMapName(ra), Namespace: ra.Namespace}, &cm); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if len(cm.Data) == 0 {
+			return nil
+		}
+		cm.Data = nil
+		return e.Client.Update(ctx, &cm)
+	})
+	if err != nil {
+		return fmt.Errorf("clear dedup entries: %w", err)
+	}
+
+	var executions opsv1alpha1.ActionExecutionList
+	if err := e.Client.List(ctx, &executions,
+		client.InNamespace(ra.Namespace),
+		client.MatchingLabels{labelResourceActionName: resourceActionLabel(ra.Name)},
+	); err != nil {
+		return err
+	}
+	for i := range executions.Items {
+		if err := e.Client.Delete(ctx, &executions.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := e.Client.Get(ctx, client.ObjectKeyFromObject(ra), &latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		latest.Status.Executions = nil
+		latest.Status.ActionStates = nil
+		latest.Status.LastError = ""
+		return e.Client.Status().Update(ctx, &latest)
+	})
+}
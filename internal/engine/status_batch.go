@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// statusFieldOwner is the field manager of the status written by
+	// server-side apply.
+	statusFieldOwner = "resource-action-operator"
+	// statusFlushTimeout bounds writing the pending status updates on
+	// shutdown.
+	statusFlushTimeout = 10 * time.Second
+)
+
+// StatusFlusher is implemented by executors that batch status updates and
+// can write the pending ones right away.
+type StatusFlusher interface {
+	FlushStatus(ctx context.Context)
+}
+
+// statusUpdate is the change an execution makes to the status of its
+// ResourceAction.
+type statusUpdate struct {
+	record   opsv1alpha1.ExecutionRecord
+	outcomes []actionOutcome
+	err      error
+	// throttled is set when an event of the ResourceAction was throttled
+	// within the last quota period.
+	throttled bool
+}
+
+// apply applies u to the status of ra. The Degraded condition is left to the
+// caller, which sets it once after all updates.
+func (u statusUpdate) apply(ra *opsv1alpha1.ResourceAction) {
+	if !usesActionExecutions(ra) {
+		ra.Status.Executions = append(ra.Status.Executions, u.record)
+		pruneExecutions(ra, time.Now())
+	}
+	for _, outcome := range u.outcomes {
+		setActionState(ra, outcome.index, outcome.err, u.record.ExecutedAt)
+	}
+	ra.Status.LastExecutionTime = ptrTo(u.record.ExecutedAt)
+	setThrottledCondition(ra, u.throttled)
+
+	if u.err != nil {
+		ra.Status.LastError = u.err.Error()
+		setCondition(ra, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  failureReason(u.err),
+			Message: u.err.Error(),
+		})
+		return
+	}
+	ra.Status.LastError = ""
+	setCondition(ra, metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionTrue,
+		Reason:  "ActionSucceeded",
+		Message: "All actions executed successfully",
+	})
+}
+
+// ready is the status of the Ready condition u sets.
+func (u statusUpdate) ready() metav1.ConditionStatus {
+	if u.err != nil {
+		return metav1.ConditionFalse
+	}
+	return metav1.ConditionTrue
+}
+
+// statusBatcher collects the status updates of executions and writes those of
+// a ResourceAction together once per interval, so a burst of events does not
+// cost a status write each. A zero interval writes every update right away.
+type statusBatcher struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[types.NamespacedName]*pendingStatus
+}
+
+type pendingStatus struct {
+	updates []statusUpdate
+	// ctx carries the logger of the first update and is not cancelled with
+	// it, as the updates are written later.
+	ctx   context.Context
+	timer *time.Timer
+}
+
+func newStatusBatcher() *statusBatcher {
+	return &statusBatcher{pending: map[types.NamespacedName]*pendingStatus{}}
+}
+
+// SetStatusFlushInterval batches the status updates of executions and writes
+// those of a ResourceAction at most once per interval with server-side apply.
+// Updates that change the Ready condition are written right away. 0 writes
+// every update right away.
+func (e *K8sExecutor) SetStatusFlushInterval(interval time.Duration) {
+	e.statusBatches.interval = interval
+}
+
+// updateStatus records u in the status of ra: right away when batching is
+// disabled or u changes the Ready condition, otherwise with the next flush of
+// ra.
+func (e *K8sExecutor) updateStatus(ctx context.Context, ra *opsv1alpha1.ResourceAction, u statusUpdate) error {
+	b := e.statusBatches
+	if b.interval <= 0 {
+		return e.writeStatus(ctx, client.ObjectKeyFromObject(ra), []statusUpdate{u}, false)
+	}
+	key := client.ObjectKeyFromObject(ra)
+
+	b.mu.Lock()
+	batch := b.pending[key]
+	ready := metav1.ConditionUnknown
+	if batch != nil {
+		ready = batch.updates[len(batch.updates)-1].ready()
+	} else if cond := meta.FindStatusCondition(ra.Status.Conditions, "Ready"); cond != nil {
+		ready = cond.Status
+	}
+	if batch == nil {
+		batch = &pendingStatus{ctx: context.WithoutCancel(ctx)}
+		b.pending[key] = batch
+		batch.timer = time.AfterFunc(b.interval, func() { e.flushPending(key, batch) })
+	}
+	batch.updates = append(batch.updates, u)
+	b.mu.Unlock()
+
+	if u.ready() == ready {
+		return nil
+	}
+	// A changed Ready condition is written right away with the updates
+	// collected so far.
+	if !b.take(key, batch) {
+		return nil
+	}
+	return e.writeStatus(ctx, key, batch.updates, true)
+}
+
+// take removes batch of key from the pending updates. It reports false when
+// the batch was already taken by a flush.
+func (b *statusBatcher) take(key types.NamespacedName, batch *pendingStatus) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending[key] != batch {
+		return false
+	}
+	delete(b.pending, key)
+	batch.timer.Stop()
+	return true
+}
+
+// flushPending writes batch of key when its interval has passed.
+func (e *K8sExecutor) flushPending(key types.NamespacedName, batch *pendingStatus) {
+	if !e.statusBatches.take(key, batch) {
+		return
+	}
+	if err := e.writeStatus(batch.ctx, key, batch.updates, true); err != nil {
+		log.FromContext(batch.ctx).Error(err, "failed to update status",
+			"resourceAction", key.Name,
+			"updates", len(batch.updates),
+		)
+	}
+}
+
+// FlushStatus writes every pending status update right away.
+func (e *K8sExecutor) FlushStatus(ctx context.Context) {
+	b := e.statusBatches
+	b.mu.Lock()
+	pending := make(map[types.NamespacedName]*pendingStatus, len(b.pending))
+	for key, batch := range b.pending {
+		pending[key] = batch
+	}
+	b.mu.Unlock()
+
+	for key, batch := range pending {
+		if !b.take(key, batch) {
+			continue
+		}
+		if err := e.writeStatus(ctx, key, batch.updates, true); err != nil {
+			log.FromContext(ctx).Error(err, "failed to update status",
+				"resourceAction", key.Name,
+				"updates", len(batch.updates),
+			)
+		}
+	}
+}
+
+// writeStatus applies updates to the latest status of the ResourceAction
+// key. Batched updates are written with server-side apply; the
+// resourceVersion makes the apply fail with a conflict when the status
+// changed since it was read, as its lists are replaced as a whole. Updates
+// of a deleted ResourceAction are dropped.
+func (e *K8sExecutor) writeStatus(ctx context.Context, key types.NamespacedName, updates []statusUpdate, apply bool) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest opsv1alpha1.ResourceAction
+		if err := e.Client.Get(ctx, key, &latest); err != nil {
+			return err
+		}
+		for _, u := range updates {
+			u.apply(&latest)
+		}
+		SetDegradedCondition(&latest, time.Now())
+		if !apply {
+			return e.Client.Status().Update(ctx, &latest)
+		}
+		return e.applyStatus(ctx, &latest)
+	})
+	if apply && apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// applyStatus writes the status of ra with server-side apply.
+func (e *K8sExecutor) applyStatus(ctx context.Context, ra *opsv1alpha1.ResourceAction) error {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&ra.Status)
+	if err != nil {
+		return err
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+	obj.SetGroupVersionKind(opsv1alpha1.GroupVersion.WithKind("ResourceAction"))
+	obj.SetNamespace(ra.Namespace)
+	obj.SetName(ra.Name)
+	obj.SetResourceVersion(ra.ResourceVersion)
+	return e.Client.Status().Patch(ctx, obj, client.Apply, client.FieldOwner(statusFieldOwner), client.ForceOwnership)
+}
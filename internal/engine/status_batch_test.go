@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// applyAsUpdate turns status applies into updates, since the fake client does
+// not support server-side apply, and counts the status writes.
+func applyAsUpdate(writes *int) interceptor.Funcs {
+	return interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, sub string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			*writes++
+			return c.SubResource(sub).Update(ctx, obj, opts...)
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, sub string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			if patch.Type() != types.ApplyPatchType {
+				return fmt.Errorf("patch type = %s, want apply", patch.Type())
+			}
+			applied, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return fmt.Errorf("applied %T, want unstructured", obj)
+			}
+			var ra opsv1alpha1.ResourceAction
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, &ra); err != nil {
+				return err
+			}
+			*writes++
+			return c.SubResource(sub).Update(ctx, &ra)
+		},
+	}
+}
+
+func TestExecute_StatusUpdatesAreBatched(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-batched", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector:        opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:          []string{"Create"},
+			ExecutionPolicy: executionPolicyEveryEvent,
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	var writes int
+	scheme := runtime.NewScheme()
+	if err := opsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&opsv1alpha1.ResourceAction{}).
+		WithObjects(ra).
+		WithInterceptorFuncs(applyAsUpdate(&writes)).
+		Build()
+	exec := NewK8sExecutor(cl, nil)
+	exec.SetStatusFlushInterval(time.Hour)
+
+	// The first execution sets the Ready condition and is written right away.
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-1", "one", "default")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	got := getResourceAction(t, cl, ra)
+	if writes != 1 || len(got.Status.Executions) != 1 || !meta.IsStatusConditionTrue(got.Status.Conditions, "Ready") {
+		t.Fatalf("writes = %d, status = %+v, want the first execution written right away", writes, got.Status)
+	}
+
+	for i := 2; i <= 4; i++ {
+		input := newDeploymentInput(fmt.Sprintf("uid-%d", i), fmt.Sprintf("demo-%d", i), "default")
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("execute #%d: %v", i, err)
+		}
+	}
+	if got := getResourceAction(t, cl, ra); writes != 1 || len(got.Status.Executions) != 1 {
+		t.Fatalf("writes = %d, executions = %d, want the later executions pending", writes, len(got.Status.Executions))
+	}
+
+	exec.FlushStatus(context.Background())
+	got = getResourceAction(t, cl, ra)
+	if writes != 2 || len(got.Status.Executions) != 4 {
+		t.Fatalf("writes = %d, executions = %d, want the pending executions written at once", writes, len(got.Status.Executions))
+	}
+	if got.Status.Executions[3].ResourceUID != "uid-4" {
+		t.Fatalf("last execution = %+v, want the executions in order", got.Status.Executions[3])
+	}
+}
+
+func TestUpdateStatus_ReadyChangeFlushesPending(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-ready", Namespace: "default"},
+		Status: opsv1alpha1.ResourceActionStatus{
+			Conditions: []metav1.Condition{{
+				Type:               "Ready",
+				Status:             metav1.ConditionTrue,
+				Reason:             "ActionSucceeded",
+				LastTransitionTime: metav1.Now(),
+			}},
+		},
+	}
+	var writes int
+	scheme := runtime.NewScheme()
+	if err := opsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&opsv1alpha1.ResourceAction{}).
+		WithObjects(ra).
+		WithInterceptorFuncs(applyAsUpdate(&writes)).
+		Build()
+	exec := NewK8sExecutor(cl, nil)
+	exec.SetStatusFlushInterval(time.Hour)
+	ctx := context.Background()
+
+	succeeded := statusUpdate{record: opsv1alpha1.ExecutionRecord{ResourceUID: "uid-1", ExecutedAt: metav1.Now()}}
+	if err := exec.updateStatus(ctx, ra, succeeded); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	if writes != 0 {
+		t.Fatalf("writes = %d, want an unchanged Ready condition batched", writes)
+	}
+
+	failed := statusUpdate{
+		record: opsv1alpha1.ExecutionRecord{ResourceUID: "uid-2", ExecutedAt: metav1.Now()},
+		err:    fmt.Errorf("boom"),
+	}
+	if err := exec.updateStatus(ctx, ra, failed); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	got := getResourceAction(t, cl, ra)
+	if writes != 1 || len(got.Status.Executions) != 2 || got.Status.LastError != "boom" {
+		t.Fatalf("writes = %d, status = %+v, want both executions written with the failure", writes, got.Status)
+	}
+	if meta.IsStatusConditionTrue(got.Status.Conditions, "Ready") {
+		t.Fatalf("conditions = %+v, want Ready false", got.Status.Conditions)
+	}
+
+	// Updates of a deleted ResourceAction are dropped.
+	if err := exec.updateStatus(ctx, ra, succeeded); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	if err := cl.Delete(ctx, ra); err != nil {
+		t.Fatalf("delete resourceaction: %v", err)
+	}
+	exec.FlushStatus(ctx)
+	if writes != 1 {
+		t.Fatalf("writes = %d, want no write for a deleted ResourceAction", writes)
+	}
+}
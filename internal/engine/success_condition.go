@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var errSuccessConditionNotMet = errors.New("successCondition not met")
+
+// checkSuccessCondition evaluates the CEL expression of
+// spec.actions[].successCondition against a response.
+func checkSuccessCondition(compiled *compiledSpec, expr string, status int, header http.Header, body []byte) error {
+	program, err := compiled.compileSuccessCondition(expr)
+	if err != nil {
+		return fmt.Errorf("invalid successCondition: %w", err)
+	}
+
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		parsed = string(body)
+	}
+
+	out, _, err := program.Eval(map[string]interface{}{
+		"status":  status,
+		"headers": headers,
+		"body":    parsed,
+	})
+	if err != nil {
+		return fmt.Errorf("successCondition: %w", err)
+	}
+	ok, isBool := out.Value().(bool)
+	if !isBool {
+		return fmt.Errorf("successCondition returned %s, not a bool", out.Type().TypeName())
+	}
+	if !ok {
+		return errSuccessConditionNotMet
+	}
+	return nil
+}
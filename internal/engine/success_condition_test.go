@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckSuccessCondition(t *testing.T) {
+	header := http.Header{"X-Job-State": []string{"done"}}
+
+	tests := []struct {
+		name    string
+		expr    string
+		status  int
+		body    string
+		wantErr error
+		fail    bool
+	}{
+		{name: "json body", expr: `status == 200 && body.result == "ok"`, status: 200, body: `{"result":"ok"}`},
+		{name: "json number", expr: `body.count == 3`, status: 200, body: `{"count":3}`},
+		{name: "header", expr: `headers["x-job-state"] == "done"`, status: 202},
+		{name: "text body", expr: `body.contains("accepted")`, status: 200, body: "request accepted"},
+		{name: "status only", expr: `status == 409`, status: 409, body: `{"error":"exists"}`},
+		{name: "not met", expr: `body.result == "ok"`, status: 200, body: `{"result":"pending"}`, wantErr: errSuccessConditionNotMet},
+		{name: "missing field", expr: `body.result == "ok"`, status: 200, body: `{}`, fail: true},
+		{name: "not a bool", expr: `body.result`, status: 200, body: `{"result":"ok"}`, fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSuccessCondition(nil, tt.expr, tt.status, header, []byte(tt.body))
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("checkSuccessCondition() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.fail:
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+			case err != nil:
+				t.Fatalf("checkSuccessCondition() error = %v", err)
+			}
+		})
+	}
+}
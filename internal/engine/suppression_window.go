@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	suppressionModeQueue = "Queue"
+
+	// maxSuppressedEvents bounds the events queued per ResourceAction while a
+	// suppression window is active. The oldest event is dropped beyond it.
+	maxSuppressedEvents = 1000
+)
+
+// activeSuppressionWindow returns the end of the first window of
+// spec.suppressionWindows that is active at now and whether its events are
+// queued. ok is false outside of every window.
+func activeSuppressionWindow(ra *opsv1alpha1.ResourceAction, now time.Time) (end time.Time, queue bool, ok bool) {
+	for _, window := range ra.Spec.SuppressionWindows {
+		schedule, err := opsv1alpha1.ParseCronSchedule(window.Schedule)
+		if err != nil {
+			continue
+		}
+		duration, err := time.ParseDuration(window.Duration)
+		if err != nil || duration <= 0 {
+			continue
+		}
+		loc := time.UTC
+		if window.TimeZone != "" {
+			if loc, err = time.LoadLocation(window.TimeZone); err != nil {
+				continue
+			}
+		}
+		// The latest start within duration before now decides, as it ends
+		// last.
+		latest := now.In(loc).Truncate(time.Minute)
+		for back := time.Duration(0); back < duration; back += time.Minute {
+			start := latest.Add(-back)
+			if !schedule.Matches(start) {
+				continue
+			}
+			if end := start.Add(duration); end.After(now) {
+				return end, window.Mode == suppressionModeQueue, true
+			}
+			break
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// suppressionQueue holds the events queued by suppression windows in Queue
+// mode, one queue per ResourceAction.
+type suppressionQueue struct {
+	mu     sync.Mutex
+	queues map[types.NamespacedName]*suppressedEvents
+}
+
+type suppressedEvents struct {
+	inputs []MatchInput
+	// ctx carries the logger of the first event and is not cancelled with
+	// it, as the events run later.
+	ctx   context.Context
+	timer *time.Timer
+}
+
+func newSuppressionQueue() *suppressionQueue {
+	return &suppressionQueue{queues: map[types.NamespacedName]*suppressedEvents{}}
+}
+
+// suppress queues input until end, when the suppression window of ra is
+// over. A newer event of an object replaces the queued one unless every
+// event is executed.
+func (e *K8sExecutor) suppress(ctx context.Context, ra *opsv1alpha1.ResourceAction, input MatchInput, end time.Time) {
+	key := types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}
+	q := e.suppressed
+	input.queued = true
+
+	q.mu.Lock()
+	events := q.queues[key]
+	if events == nil {
+		events = &suppressedEvents{ctx: context.WithoutCancel(ctx)}
+		q.queues[key] = events
+		events.timer = time.AfterFunc(time.Until(end), func() { e.releaseSuppressed(key, events) })
+	}
+	replaced := false
+	if executionPolicy(ra) != executionPolicyEveryEvent {
+		for i, queued := range events.inputs {
+			if queued.Event == input.Event && queued.Obj.GetUID() == input.Obj.GetUID() {
+				events.inputs[i] = input
+				replaced = true
+				break
+			}
+		}
+	}
+	dropped := false
+	if !replaced {
+		if len(events.inputs) >= maxSuppressedEvents {
+			events.inputs = events.inputs[1:]
+			dropped = true
+		}
+		events.inputs = append(events.inputs, input)
+	}
+	size := len(events.inputs)
+	q.mu.Unlock()
+
+	logger := log.FromContext(ctx)
+	if dropped {
+		logger.Info("Dropping oldest queued event, the suppression queue is full",
+			"resourceAction", ra.Name,
+			"limit", maxSuppressedEvents,
+		)
+	}
+	logger.Info("Queued event until the suppression window ends",
+		"resourceAction", ra.Name,
+		"event", input.Event,
+		"name", input.Obj.GetName(),
+		"windowEnd", end,
+		"queued", size,
+	)
+}
+
+// releaseSuppressed runs the events queued for the ResourceAction key once
+// its suppression window ended. Events that fall into another window are
+// queued again.
+func (e *K8sExecutor) releaseSuppressed(key types.NamespacedName, events *suppressedEvents) {
+	q := e.suppressed
+	q.mu.Lock()
+	if q.queues[key] != events {
+		q.mu.Unlock()
+		return
+	}
+	delete(q.queues, key)
+	q.mu.Unlock()
+
+	ctx := events.ctx
+	logger := log.FromContext(ctx)
+	for _, input := range events.inputs {
+		if err := e.executeQueued(ctx, key, input); err != nil {
+			logger.Error(err, "queued execution failed",
+				"resourceAction", key.Name,
+				"event", input.Event,
+				"name", input.Obj.GetName(),
+			)
+		}
+	}
+}
+
+func (e *K8sExecutor) executeQueued(ctx context.Context, key types.NamespacedName, input MatchInput) error {
+	var ra opsv1alpha1.ResourceAction
+	if err := e.Client.Get(ctx, key, &ra); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return e.executeFor(ctx, ra, input)
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestActiveSuppressionWindow(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		Spec: opsv1alpha1.ResourceActionSpec{
+			SuppressionWindows: []opsv1alpha1.SuppressionWindowSpec{
+				{Schedule: "0 22 * * 6", Duration: "2h", TimeZone: "Europe/Berlin", Mode: "Queue"},
+			},
+		},
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	wantEnd := time.Date(2026, 10, 18, 0, 0, 0, 0, berlin)
+
+	for _, tc := range []struct {
+		at     time.Time
+		active bool
+	}{
+		{time.Date(2026, 10, 17, 21, 59, 0, 0, berlin), false},
+		{time.Date(2026, 10, 17, 22, 0, 0, 0, berlin), true},
+		{time.Date(2026, 10, 17, 23, 59, 30, 0, berlin), true},
+		{time.Date(2026, 10, 18, 0, 0, 0, 0, berlin), false},
+		{time.Date(2026, 10, 16, 22, 30, 0, 0, berlin), false}, // a Friday
+	} {
+		end, queue, ok := activeSuppressionWindow(ra, tc.at.UTC())
+		if ok != tc.active {
+			t.Fatalf("activeSuppressionWindow(%s) active = %v, want %v", tc.at, ok, tc.active)
+		}
+		if ok && (!end.Equal(wantEnd) || !queue) {
+			t.Fatalf("activeSuppressionWindow(%s) = %s, %v, want %s and queued", tc.at, end, queue, wantEnd)
+		}
+	}
+}
+
+func TestExecute_SuppressionWindow(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-quiet", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			// Every minute starts a window, so one is always active.
+			SuppressionWindows: []opsv1alpha1.SuppressionWindowSpec{
+				{Schedule: "* * * * *", Duration: "1h", Mode: "Drop"},
+			},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+			}},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}
+
+	if err := exec.Execute(ctx, newDeploymentInput("uid-drop", "dropped", "team-a")); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if calls.Load() != 0 || len(exec.suppressed.queues) != 0 {
+		t.Fatalf("calls = %d, queues = %d, want the event dropped", calls.Load(), len(exec.suppressed.queues))
+	}
+
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(ctx, key, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	got.Spec.SuppressionWindows[0].Mode = "Queue"
+	if err := cl.Update(ctx, &got); err != nil {
+		t.Fatalf("update resourceaction: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := exec.Execute(ctx, newDeploymentInput("uid-queue", "queued", "team-a")); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	}
+	events := exec.suppressed.queues[key]
+	if calls.Load() != 0 || events == nil || len(events.inputs) != 1 {
+		t.Fatalf("calls = %d, queued = %+v, want one queued event", calls.Load(), events)
+	}
+	events.timer.Stop()
+
+	// The window is over once it is removed from the spec.
+	got.Spec.SuppressionWindows = nil
+	if err := cl.Update(ctx, &got); err != nil {
+		t.Fatalf("update resourceaction: %v", err)
+	}
+	exec.releaseSuppressed(key, events)
+	if calls.Load() != 1 || len(exec.suppressed.queues) != 0 {
+		t.Fatalf("calls = %d, queues = %d, want the queued event to run", calls.Load(), len(exec.suppressed.queues))
+	}
+}
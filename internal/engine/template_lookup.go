@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateLookups reads the values of the configMapValue and secretValue
+// template functions for the requests of one action. The Secret values it
+// read are redacted like the values of Secret-backed headers.
+type templateLookups struct {
+	ctx     context.Context
+	k8s     client.Client
+	secrets *secretCache
+	// namespace is the namespace of the ResourceAction. Secrets are only
+	// read from it, and ConfigMaps from it and from shared.
+	namespace string
+	shared    []string
+
+	mu           sync.Mutex
+	secretValues []string
+}
+
+// SetConfigMapLookupNamespaces sets the namespaces whose ConfigMaps the
+// configMapValue template function may read for ResourceActions in any
+// namespace. Without them, templates only read ConfigMaps in the namespace of
+// their ResourceAction.
+func (e *K8sExecutor) SetConfigMapLookupNamespaces(namespaces []string) {
+	e.configMapLookupNamespaces = normalizeNamespaces(namespaces)
+}
+
+// templateLookups returns the lookups of an action of a ResourceAction in
+// namespace.
+func (e *K8sExecutor) templateLookups(ctx context.Context, namespace string) *templateLookups {
+	return &templateLookups{
+		ctx:       ctx,
+		k8s:       e.Client,
+		secrets:   e.secrets,
+		namespace: namespace,
+		shared:    e.configMapLookupNamespaces,
+	}
+}
+
+// configMapValue returns the value of key in the ConfigMap namespace/name.
+// An empty namespace is the namespace of the ResourceAction.
+func (l *templateLookups) configMapValue(namespace, name, key string) (string, error) {
+	if namespace == "" {
+		namespace = l.namespace
+	}
+	if namespace != l.namespace && !slices.Contains(l.shared, namespace) {
+		return "", fmt.Errorf("configMapValue: namespace %q is not the namespace of the ResourceAction and not one of the ConfigMap lookup namespaces", namespace)
+	}
+	var cm corev1.ConfigMap
+	if err := l.k8s.Get(l.ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cm); err != nil {
+		return "", fmt.Errorf("configMapValue: %w", err)
+	}
+	value, ok := cm.Data[key]
+	if !ok {
+		return "", fmt.Errorf("configMapValue: key %q not found in ConfigMap %s/%s", key, namespace, name)
+	}
+	return value, nil
+}
+
+// secretValue returns the value of key in the Secret name in the namespace
+// of the ResourceAction and remembers it for redaction.
+func (l *templateLookups) secretValue(name, key string) (string, error) {
+	secret, err := l.secrets.get(l.ctx, types.NamespacedName{Namespace: l.namespace, Name: name})
+	if err != nil {
+		return "", fmt.Errorf("secretValue: %w", err)
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secretValue: key %q not found in Secret %s/%s", key, l.namespace, name)
+	}
+	l.mu.Lock()
+	l.secretValues = append(l.secretValues, string(value))
+	l.mu.Unlock()
+	return string(value), nil
+}
+
+// redactor returns base extended with the Secret values read so far.
+func (l *templateLookups) redactor(base redactor) redactor {
+	if l == nil {
+		return base
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return base.with(l.secretValues...)
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestExecute_TemplateLookups(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		// Echo the body, as some receivers do in their errors.
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-lookup", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body: &opsv1alpha1.TemplateSpec{
+					Template: `{{ configMapValue "platform" "cluster-info" "region" }} {{ secretValue "receiver" "token" }}`,
+				},
+			}},
+		},
+	}
+	clusterInfo := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-info", Namespace: "platform"},
+		Data:       map[string]string{"region": "eu-central-1"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "receiver", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t-t0ken")},
+	}
+	exec, cl := newTestExecutor(t, ra, clusterInfo, secret)
+	exec.SetConfigMapLookupNamespaces([]string{"platform"})
+	ctx := context.Background()
+
+	err := exec.Execute(ctx, newDeploymentInput("uid-lookup", "demo", "default"))
+	if err == nil || strings.Contains(err.Error(), "s3cr3t-t0ken") {
+		t.Fatalf("execute = %v, want the failure with the Secret value redacted", err)
+	}
+	if body != "eu-central-1 s3cr3t-t0ken" {
+		t.Fatalf("body = %q, want the ConfigMap and Secret values", body)
+	}
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(ctx, types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 1 {
+		t.Fatalf("executions = %+v, want one", got.Status.Executions)
+	}
+	if msg := got.Status.Executions[0].Error; strings.Contains(msg, "s3cr3t-t0ken") || !strings.Contains(msg, redactedValue) {
+		t.Fatalf("error = %q, want the Secret value redacted", msg)
+	}
+
+	// ConfigMaps of other namespaces are out of reach unless the operator
+	// allows them.
+	exec.SetConfigMapLookupNamespaces(nil)
+	body = ""
+	err = exec.Execute(ctx, newDeploymentInput("uid-other", "other", "default"))
+	if err == nil || !strings.Contains(err.Error(), "lookup namespaces") || body != "" {
+		t.Fatalf("execute = %v, body = %q, want no request for a ConfigMap of another namespace", err, body)
+	}
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "de.yusaozdemir.resource-action-operator/engine"
+
+// tracer resolves lazily against the global provider, so spans are no-ops
+// until SetupTracing installed an exporter.
+var tracer = otel.Tracer(tracerName)
+
+// TracingOptions configures OTLP trace export.
+type TracingOptions struct {
+	// Endpoint is the OTLP gRPC endpoint (host:port). Tracing is disabled when empty.
+	Endpoint string
+	// Insecure disables TLS towards the collector.
+	Insecure bool
+	// SampleRatio is the fraction of root spans that are sampled.
+	SampleRatio float64
+}
+
+// SetupTracing installs a global OTLP tracer provider and the W3C trace
+// context propagator. The returned function flushes and stops the exporter.
+func SetupTracing(ctx context.Context, opts TracingOptions) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("resource-action-operator"),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+func inputAttributes(input MatchInput) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("k8s.resource.gvk", input.GVK.String()),
+		attribute.String("resourceaction.event", string(input.Event)),
+	}
+	if input.Obj != nil {
+		attrs = append(attrs,
+			attribute.String("k8s.resource.name", input.Obj.GetName()),
+			attribute.String("k8s.resource.namespace", input.Obj.GetNamespace()),
+		)
+	}
+	return attrs
+}
+
+// endSpan records err on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+// transportMaterial holds what a transport is built from besides the spec:
+// the certificates and the proxy URL with its credentials.
+type transportMaterial struct {
+	ca       []byte
+	certPEM  []byte
+	keyPEM   []byte
+	proxyURL *url.URL
+}
+
+func (m transportMaterial) fingerprint() [sha256.Size]byte {
+	h := sha256.New()
+	for _, b := range [][]byte{m.ca, m.certPEM, m.keyPEM} {
+		_, _ = fmt.Fprintf(h, "%d:", len(b))
+		_, _ = h.Write(b)
+	}
+	if m.proxyURL != nil {
+		_, _ = h.Write([]byte(m.proxyURL.String()))
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// transportCache reuses the transports of HTTP actions, so requests keep
+// their connections instead of a TLS handshake per request. A transport is
+// replaced once the material it was built from changed, for example after
+// cert-manager rotated a client certificate. The Secrets are read through
+// the secret cache, whose watch keeps them current.
+type transportCache struct {
+	mu         sync.Mutex
+	transports map[string]cachedTransport
+}
+
+type cachedTransport struct {
+	transport   *http.Transport
+	fingerprint [sha256.Size]byte
+}
+
+func newTransportCache() *transportCache {
+	return &transportCache{transports: make(map[string]cachedTransport)}
+}
+
+// get returns the cached transport for the settings while it was built from
+// material, and otherwise builds a new one. A nil cache always builds.
+func (c *transportCache) get(
+	namespace string,
+	tlsSpec *opsv1alpha1.TLSSpec,
+	proxySpec *opsv1alpha1.ProxySpec,
+	material transportMaterial,
+	build func() (*http.Transport, error),
+) (*http.Transport, error) {
+	if c == nil {
+		return build()
+	}
+	spec, err := json.Marshal(struct {
+		TLS   *opsv1alpha1.TLSSpec   `json:"tls"`
+		Proxy *opsv1alpha1.ProxySpec `json:"proxy"`
+	}{tlsSpec, proxySpec})
+	if err != nil {
+		return nil, err
+	}
+	key := namespace + "/" + string(spec)
+	fingerprint := material.fingerprint()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.transports[key]
+	if ok && cached.fingerprint == fingerprint {
+		return cached.transport, nil
+	}
+	tr, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		// Requests in flight finish on the old transport.
+		cached.transport.CloseIdleConnections()
+	}
+	c.transports[key] = cachedTransport{transport: tr, fingerprint: fingerprint}
+	return tr, nil
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTransportCache_RebuildsAfterSecretRotation(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "receiver-ca", Namespace: "default"},
+		Data:       map[string][]byte{"ca.crt": caPEM},
+	}
+	cl := fake.NewClientBuilder().WithObjects(secret).Build()
+	h := NewHTTPExecutor(cl)
+	h.transports = newTransportCache()
+	tlsSpec := &opsv1alpha1.TLSSpec{CaSecretRef: &opsv1alpha1.SecretKeyRef{Name: "receiver-ca", Key: "ca.crt"}}
+
+	first, err := h.buildTransport(context.Background(), "default", tlsSpec, nil)
+	if err != nil {
+		t.Fatalf("buildTransport() error = %v", err)
+	}
+	again, err := h.buildTransport(context.Background(), "default", tlsSpec, nil)
+	if err != nil {
+		t.Fatalf("buildTransport() error = %v", err)
+	}
+	if again != first {
+		t.Fatalf("expected the transport to be reused while the Secret is unchanged")
+	}
+
+	// Rotate the CA bundle, for example to add the next CA.
+	secret.Data["ca.crt"] = append(append([]byte{}, caPEM...), caPEM...)
+	if err := cl.Update(context.Background(), secret); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	rotated, err := h.buildTransport(context.Background(), "default", tlsSpec, nil)
+	if err != nil {
+		t.Fatalf("buildTransport() error = %v", err)
+	}
+	if rotated == first {
+		t.Fatalf("expected a new transport after the Secret was rotated")
+	}
+}
@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+)
+
+const (
+	defaultVaultAuthMount = "kubernetes"
+	defaultVaultAudience  = "vault"
+	vaultRequestTimeout   = 10 * time.Second
+)
+
+// vaultClient reads values of vaultRef from HashiCorp Vault. It logs in with
+// the Kubernetes auth method using ServiceAccount tokens of the namespace of
+// the ResourceAction, so a ResourceAction can only read what the Vault roles
+// bound to its namespace allow. Vault tokens are cached until 80% of their
+// lease passed; the values themselves are read on every use.
+type vaultClient struct {
+	address    string
+	httpClient *http.Client
+	tokens     *serviceAccountTokens
+
+	mu     sync.Mutex
+	logins map[vaultLoginKey]cachedToken
+	now    func() time.Time
+}
+
+type vaultLoginKey struct {
+	namespace      string
+	serviceAccount string
+	audience       string
+	authMount      string
+	role           string
+}
+
+func newVaultClient(address string, tokens *serviceAccountTokens) *vaultClient {
+	return &vaultClient{
+		address:    strings.TrimSuffix(address, "/"),
+		httpClient: &http.Client{Timeout: vaultRequestTimeout},
+		tokens:     tokens,
+		logins:     make(map[vaultLoginKey]cachedToken),
+		now:        time.Now,
+	}
+}
+
+// SetVaultAddress enables vaultRef values, read from the Vault server at
+// address. An empty address disables them.
+func (e *K8sExecutor) SetVaultAddress(address string) {
+	e.vault = nil
+	if address != "" {
+		e.vault = newVaultClient(address, e.tokens)
+	}
+}
+
+// read returns the value ref selects, logging in with a ServiceAccount of
+// namespace.
+func (v *vaultClient) read(ctx context.Context, namespace string, ref *opsv1alpha1.VaultRef) (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("vaultRef %s: Vault is not configured", ref.Path)
+	}
+	token, err := v.login(ctx, namespace, ref)
+	if err != nil {
+		return "", err
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	path := "/v1/" + strings.TrimPrefix(ref.Path, "/")
+	if err := v.do(ctx, http.MethodGet, path, token, nil, &secret); err != nil {
+		return "", fmt.Errorf("read vault secret %s: %w", ref.Path, err)
+	}
+	data := secret.Data
+	// KV version 2 nests the values in data.data next to data.metadata.
+	if nested, ok := data["data"].(map[string]interface{}); ok && data["metadata"] != nil {
+		data = nested
+	}
+	value, ok := data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %q", ref.Path, ref.Key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// login returns a Vault token for ref, from the cache while it is fresh.
+func (v *vaultClient) login(ctx context.Context, namespace string, ref *opsv1alpha1.VaultRef) (string, error) {
+	key := vaultLoginKey{
+		namespace:      namespace,
+		serviceAccount: ref.ServiceAccountName,
+		audience:       ref.Audience,
+		authMount:      strings.Trim(ref.AuthMount, "/"),
+		role:           ref.Role,
+	}
+	if key.serviceAccount == "" {
+		key.serviceAccount = defaultTokenServiceAccount
+	}
+	if key.audience == "" {
+		key.audience = defaultVaultAudience
+	}
+	if key.authMount == "" {
+		key.authMount = defaultVaultAuthMount
+	}
+
+	v.mu.Lock()
+	cached, ok := v.logins[key]
+	v.mu.Unlock()
+	if ok && v.now().Before(cached.refreshAt) {
+		return cached.token, nil
+	}
+
+	jwt, err := v.tokens.token(ctx, namespace, &opsv1alpha1.ServiceAccountTokenAuth{
+		ServiceAccountName: key.serviceAccount,
+		Audience:           key.audience,
+	})
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]string{"role": key.role, "jwt": jwt})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	issuedAt := v.now()
+	if err := v.do(ctx, http.MethodPost, "/v1/auth/"+key.authMount+"/login", "", body, &resp); err != nil {
+		return "", fmt.Errorf("vault login with role %q as serviceaccount %s/%s: %w", key.role, namespace, key.serviceAccount, err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login with role %q returned no token", key.role)
+	}
+
+	lifetime := time.Duration(resp.Auth.LeaseDuration) * time.Second
+	cached = cachedToken{
+		token:     resp.Auth.ClientToken,
+		refreshAt: issuedAt.Add(lifetime * 4 / 5),
+	}
+	v.mu.Lock()
+	v.logins[key] = cached
+	v.mu.Unlock()
+	return cached.token, nil
+}
+
+// do sends a request to the Vault API and decodes the JSON response into out.
+func (v *vaultClient) do(ctx context.Context, method, path, token string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, v.address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(respBody, &vaultErr)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestVault serves the Kubernetes auth login and a KV version 2 secret.
+func newTestVault(t *testing.T, logins, reads *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var login map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&login)
+			if login["role"] != "team-a" || login["jwt"] != "fake-token" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			*logins++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"vault-token","lease_duration":3600}}`))
+		case "/v1/secret/data/team-a/webhook":
+			if r.Header.Get("X-Vault-Token") != "vault-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			*reads++
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"Bearer from-vault"},"metadata":{"version":3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestVaultClient_ReadsFreshValuesWithCachedLogin(t *testing.T) {
+	logins, reads := 0, 0
+	vault := newTestVault(t, &logins, &reads)
+	defer vault.Close()
+
+	cl := fake.NewClientBuilder().
+		WithObjects(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "team-a"}}).
+		Build()
+	v := newVaultClient(vault.URL, newServiceAccountTokens(cl))
+	ref := &opsv1alpha1.VaultRef{Path: "secret/data/team-a/webhook", Key: "token", Role: "team-a"}
+
+	for range 2 {
+		value, err := v.read(context.Background(), "team-a", ref)
+		if err != nil {
+			t.Fatalf("read() error = %v", err)
+		}
+		if value != "Bearer from-vault" {
+			t.Fatalf("read() = %q, want the value of data.data.token", value)
+		}
+	}
+	if logins != 1 || reads != 2 {
+		t.Fatalf("logins = %d, reads = %d, want one login and a read per use", logins, reads)
+	}
+
+	ref.Role = "team-b"
+	if _, err := v.read(context.Background(), "team-a", ref); err == nil {
+		t.Fatalf("expected login error for a role the ServiceAccount is not bound to")
+	}
+}
+
+func TestExecute_VaultHeader(t *testing.T) {
+	logins, reads := 0, 0
+	vault := newTestVault(t, &logins, &reads)
+	defer vault.Close()
+
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	scheme := runtime.NewScheme()
+	if err := opsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-vault", Namespace: "team-a"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Headers: map[string]opsv1alpha1.ValueFrom{
+					"Authorization": {VaultRef: &opsv1alpha1.VaultRef{Path: "secret/data/team-a/webhook", Key: "token", Role: "team-a"}},
+				},
+			}},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&opsv1alpha1.ResourceAction{}).
+		WithObjects(ra, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "team-a"}}).
+		Build()
+	exec := NewK8sExecutor(cl, nil)
+	exec.SetVaultAddress(vault.URL)
+
+	if err := exec.Execute(context.Background(), newDeploymentInput("uid-vault", "demo", "team-a")); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if authorization != "Bearer from-vault" {
+		t.Fatalf("Authorization = %q, want the value from Vault", authorization)
+	}
+}
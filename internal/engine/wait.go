@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const actionTypeWait = "wait"
+
+// executionProgress is the state of an execution of the event-driven actions
+// of a ResourceAction. A wait action or a pending approval pauses the
+// execution; the event is requeued with the progress and the execution
+// resumes at next.
+type executionProgress struct {
+	owner types.NamespacedName
+	// generation is the generation of the ResourceAction when the execution
+	// started. The execution is abandoned if the spec changes while it waits.
+	generation    int64
+	next          int
+	correlationID string
+	outputs       map[string]string
+	// approvalRequestedAt is set while the action at next waits for its
+	// approval.
+	approvalRequestedAt time.Time
+	// conditionWaitSince is set while the waitForCondition action at next
+	// polls its resource.
+	conditionWaitSince time.Time
+	// jobs holds the Jobs created by the job actions so far by action index.
+	jobs map[int]types.NamespacedName
+
+	// retryAttempt is the last failed attempt of the action at next when
+	// its retry was deferred.
+	retryAttempt int
+	// persisted is set once the event was stored as a pending delivery.
+	persisted bool
+
+	executedActions int
+	lastActionIndex int
+	outcomes        []actionOutcome
+	errs            []error
+	failedActions   []int
+	totals          HTTPExecutionMetrics
+}
+
+func newExecutionProgress(ra *opsv1alpha1.ResourceAction) executionProgress {
+	return executionProgress{
+		owner:           types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name},
+		generation:      ra.Generation,
+		outputs:         map[string]string{},
+		lastActionIndex: -1,
+	}
+}
+
+// clone returns a copy of p that can be resumed without changing p, so a
+// failed resumed execution can be retried from the same wait.
+func (p executionProgress) clone() executionProgress {
+	p.outputs = maps.Clone(p.outputs)
+	p.jobs = maps.Clone(p.jobs)
+	p.outcomes = slices.Clone(p.outcomes)
+	p.errs = slices.Clone(p.errs)
+	p.failedActions = slices.Clone(p.failedActions)
+	return p
+}
+
+// record adds the outcome of the action at actionIndex.
+func (p *executionProgress) record(actionIndex int, metrics HTTPExecutionMetrics, err error) {
+	p.totals.Attempts += metrics.Attempts
+	p.totals.NetworkRetryCount += metrics.NetworkRetryCount
+	p.totals.StatusRetryCount += metrics.StatusRetryCount
+	p.totals.BackoffMillis += metrics.BackoffMillis
+	p.totals.DurationMillis += metrics.DurationMillis
+	if metrics.StatusCode > 0 {
+		p.totals.StatusCode = metrics.StatusCode
+	}
+	if metrics.Job != nil {
+		p.totals.Job = metrics.Job.DeepCopy()
+		if p.jobs == nil {
+			p.jobs = map[int]types.NamespacedName{}
+		}
+		p.jobs[actionIndex] = types.NamespacedName{Namespace: metrics.Job.Namespace, Name: metrics.Job.Name}
+	}
+	if metrics.Request != nil {
+		p.totals.Request = metrics.Request
+		p.totals.Response = metrics.Response
+	}
+	if metrics.Targets != nil {
+		p.totals.Targets = metrics.Targets
+	}
+	p.executedActions++
+	p.lastActionIndex = actionIndex
+	p.outcomes = append(p.outcomes, actionOutcome{index: actionIndex, err: err})
+	if err != nil {
+		p.errs = append(p.errs, err)
+		p.failedActions = append(p.failedActions, actionIndex)
+	}
+}
+
+// deferRetry records that the action at actionIndex failed attempt and
+// retries later. Its metrics are added to the totals, except the attempts,
+// which the final run of the action counts.
+func (p *executionProgress) deferRetry(actionIndex int, metrics HTTPExecutionMetrics, attempt int) {
+	p.totals.NetworkRetryCount += metrics.NetworkRetryCount
+	p.totals.StatusRetryCount += metrics.StatusRetryCount
+	p.totals.BackoffMillis += metrics.BackoffMillis
+	p.totals.DurationMillis += metrics.DurationMillis
+	p.next = actionIndex
+	p.retryAttempt = attempt
+}
+
+// takeRetryAttempt returns the attempt the action at actionIndex continues
+// with, or 0 when it starts over.
+func (p *executionProgress) takeRetryAttempt(actionIndex int) int {
+	if p.retryAttempt == 0 || p.next != actionIndex {
+		return 0
+	}
+	attempt := p.retryAttempt + 1
+	p.retryAttempt = 0
+	return attempt
+}
+
+// waitError is returned by an execution that a wait action, a pending
+// approval or a waitForCondition action paused. The event is requeued after delay and resumes with
+// progress.
+type waitError struct {
+	progress executionProgress
+	delay    time.Duration
+}
+
+func (w *waitError) Error() string {
+	return fmt.Sprintf("waiting %s before action %d", w.delay, w.progress.next)
+}
+
+// renderWaitDuration renders spec.actions[].wait.duration against obj and the
+// outputs of the earlier actions.
+func renderWaitDuration(
+	compiled *compiledSpec,
+	spec *opsv1alpha1.WaitSpec,
+	obj *unstructured.Unstructured,
+	outputs map[string]string,
+) (time.Duration, error) {
+	if spec == nil {
+		return 0, fmt.Errorf("wait is not set")
+	}
+	text, err := renderTemplate(compiled, "wait.duration", spec.Duration, obj.Object, bodyTemplateFuncs(outputs, nil))
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %w", err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %s", d)
+	}
+	return d, nil
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const actionTypeWaitForCondition = "waitForCondition"
+
+const (
+	defaultConditionPollInterval = 10 * time.Second
+	defaultConditionTimeout      = 10 * time.Minute
+)
+
+// awaitCondition polls the resource of the waitForCondition action at
+// actionIndex. It returns zero once the resource reached the state, the delay
+// until the next poll while it has not, or an error when the resource failed
+// or the action timed out. A missing resource has not reached the state yet,
+// as the Job of an earlier action may not be visible right away.
+func (e *K8sExecutor) awaitCondition(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	action opsv1alpha1.ActionSpec,
+	input MatchInput,
+	target client.Client,
+	progress *executionProgress,
+) (time.Duration, error) {
+	spec := action.WaitForCondition
+	if spec == nil {
+		return 0, fmt.Errorf("waitForCondition is not set")
+	}
+	if progress.conditionWaitSince.IsZero() {
+		progress.conditionWaitSince = time.Now()
+	}
+	obj, err := e.conditionTarget(ctx, ra, spec, input, target, progress)
+	if err == nil {
+		var reached bool
+		reached, err = conditionReached(spec, obj)
+		if reached {
+			progress.conditionWaitSince = time.Time{}
+			return 0, nil
+		}
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		progress.conditionWaitSince = time.Time{}
+		return 0, err
+	}
+
+	timeout := parseDurationDefault(spec.Timeout, defaultConditionTimeout)
+	remaining := timeout - time.Since(progress.conditionWaitSince)
+	if remaining <= 0 {
+		progress.conditionWaitSince = time.Time{}
+		return 0, fmt.Errorf("timed out after %s", timeout)
+	}
+	return min(parseDurationDefault(spec.PollInterval, defaultConditionPollInterval), remaining), nil
+}
+
+// conditionTarget reads the resource spec selects: the Job of an earlier job
+// action, the resource of kind, or the object of the event.
+func (e *K8sExecutor) conditionTarget(
+	ctx context.Context,
+	ra *opsv1alpha1.ResourceAction,
+	spec *opsv1alpha1.WaitForConditionSpec,
+	input MatchInput,
+	target client.Client,
+	progress *executionProgress,
+) (*unstructured.Unstructured, error) {
+	var gvk schema.GroupVersionKind
+	var key types.NamespacedName
+	switch {
+	case spec.Job != "":
+		job, ok := progress.jobs[actionIndexByName(ra, spec.Job)]
+		if !ok {
+			return nil, fmt.Errorf("job action %q did not create a Job", spec.Job)
+		}
+		gvk, key = schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, job
+	case spec.Kind != "":
+		gv, err := schema.ParseGroupVersion(spec.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("apiVersion: %w", err)
+		}
+		gvk = gv.WithKind(spec.Kind)
+		funcs := bodyTemplateFuncs(progress.outputs, input.trigger)
+		if key.Name, err = renderTemplate(e.compiled.get(ra), "waitForCondition.name", spec.Name, input.Obj.Object, funcs); err != nil {
+			return nil, fmt.Errorf("name: %w", err)
+		}
+		if key.Namespace, err = renderTemplate(e.compiled.get(ra), "waitForCondition.namespace", spec.Namespace, input.Obj.Object, funcs); err != nil {
+			return nil, fmt.Errorf("namespace: %w", err)
+		}
+		if key.Name == "" {
+			return nil, errors.New("name rendered empty")
+		}
+		if key.Namespace == "" {
+			key.Namespace = input.Obj.GetNamespace()
+		}
+	default:
+		gvk = input.GVK
+		key = types.NamespacedName{Namespace: input.Obj.GetNamespace(), Name: input.Obj.GetName()}
+	}
+	if e.confinement.confines(ra) && key.Namespace != ra.Namespace {
+		return nil, &NamespaceConfinementError{
+			Reason: fmt.Sprintf("waitForCondition may only poll resources in the namespace %q of the ResourceAction", ra.Namespace),
+		}
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := target.Get(ctx, key, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// conditionReached reports whether obj reached the state of spec. It fails
+// once the failure condition of spec is True.
+func conditionReached(spec *opsv1alpha1.WaitForConditionSpec, obj *unstructured.Unstructured) (bool, error) {
+	if spec.FailureCondition != "" && conditionStatus(obj, spec.FailureCondition) == "True" {
+		return false, fmt.Errorf("%s %s has condition %s", obj.GetKind(), obj.GetName(), spec.FailureCondition)
+	}
+	if spec.Condition != "" {
+		want := spec.Status
+		if want == "" {
+			want = "True"
+		}
+		if conditionStatus(obj, spec.Condition) != want {
+			return false, nil
+		}
+	}
+	if spec.JSONPath == "" {
+		return true, nil
+	}
+	jp := jsonpath.New("waitForCondition").AllowMissingKeys(true)
+	if err := jp.Parse(spec.JSONPath); err != nil {
+		return false, fmt.Errorf("jsonPath: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj.Object); err != nil {
+		return false, fmt.Errorf("jsonPath %s: %w", spec.JSONPath, err)
+	}
+	if spec.Value != "" {
+		return buf.String() == spec.Value, nil
+	}
+	return buf.String() != "" && buf.String() != "false", nil
+}
+
+// conditionStatus returns the status of the status condition conditionType of
+// obj, or "" when obj has no such condition.
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType {
+			status, _ := condition["status"].(string)
+			return status
+		}
+	}
+	return ""
+}
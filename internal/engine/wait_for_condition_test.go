@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConditionReached(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Job",
+		"metadata": map[string]interface{}{"name": "demo"},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"ready": true,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Complete", "status": "True"},
+				map[string]interface{}{"type": "Suspended", "status": "False"},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		spec    opsv1alpha1.WaitForConditionSpec
+		want    bool
+		wantErr bool
+	}{
+		{name: "condition true", spec: opsv1alpha1.WaitForConditionSpec{Condition: "Complete"}, want: true},
+		{name: "condition status", spec: opsv1alpha1.WaitForConditionSpec{Condition: "Suspended", Status: "False"}, want: true},
+		{name: "condition missing", spec: opsv1alpha1.WaitForConditionSpec{Condition: "Available"}},
+		{name: "failure condition", spec: opsv1alpha1.WaitForConditionSpec{Condition: "Available", FailureCondition: "Complete"}, wantErr: true},
+		{name: "json path value", spec: opsv1alpha1.WaitForConditionSpec{JSONPath: "{.status.phase}", Value: "Running"}, want: true},
+		{name: "json path other value", spec: opsv1alpha1.WaitForConditionSpec{JSONPath: "{.status.phase}", Value: "Succeeded"}},
+		{name: "json path truthy", spec: opsv1alpha1.WaitForConditionSpec{JSONPath: "{.status.ready}"}, want: true},
+		{name: "json path missing", spec: opsv1alpha1.WaitForConditionSpec{JSONPath: "{.status.succeeded}"}},
+		{name: "condition and json path", spec: opsv1alpha1.WaitForConditionSpec{Condition: "Complete", JSONPath: "{.status.phase}", Value: "Failed"}},
+	}
+	for _, tt := range tests {
+		got, err := conditionReached(&tt.spec, obj)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Fatalf("%s: conditionReached() = %v, %v; want %v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestExecute_WaitForJobCondition(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-wait-job", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{Name: "migrate", Type: "job", Job: &opsv1alpha1.JobSpec{Image: "bash:5.2", Script: "echo migrate"}},
+				{
+					Type: "waitForCondition",
+					WaitForCondition: &opsv1alpha1.WaitForConditionSpec{
+						Job:              "migrate",
+						Condition:        "Complete",
+						FailureCondition: "Failed",
+						PollInterval:     "5s",
+					},
+				},
+				{Type: "http", URL: srv.URL, URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true}},
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	ctx := context.Background()
+	input := newDeploymentInput("uid-wait-job", "demo", "default")
+
+	wait := executeUntilPaused(t, exec, input)
+	if wait.progress.next != 1 || wait.delay != 5*time.Second || calls.Load() != 0 {
+		t.Fatalf("progress = %+v, delay = %s, calls = %d; want a poll of the job after 5s", wait.progress, wait.delay, calls.Load())
+	}
+
+	// The Job has not completed yet.
+	input.resume = &wait.progress
+	wait = executeUntilPaused(t, exec, input)
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want none before the job completed", calls.Load())
+	}
+
+	var jobs batchv1.JobList
+	if err := cl.List(ctx, &jobs); err != nil || len(jobs.Items) != 1 {
+		t.Fatalf("list jobs = %d, %v; want one", len(jobs.Items), err)
+	}
+	job := jobs.Items[0]
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	if err := cl.Status().Update(ctx, &job); err != nil {
+		t.Fatalf("complete job: %v", err)
+	}
+
+	input.resume = &wait.progress
+	if err := exec.Execute(ctx, input); err != nil {
+		t.Fatalf("execute resumed: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want the notification after the job completed", calls.Load())
+	}
+}
+
+func TestExecute_WaitForConditionTimeout(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-wait-timeout", Namespace: "default"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type: "waitForCondition",
+				WaitForCondition: &opsv1alpha1.WaitForConditionSpec{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       "{{ .metadata.name }}-ready",
+					JSONPath:   "{.data.ready}",
+					Timeout:    "1m",
+				},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-wait-timeout", "demo", "default")
+
+	// The ConfigMap does not exist yet, which is not an error.
+	wait := executeUntilPaused(t, exec, input)
+	if wait.delay != defaultConditionPollInterval {
+		t.Fatalf("delay = %s, want the default poll interval", wait.delay)
+	}
+
+	wait.progress.conditionWaitSince = time.Now().Add(-2 * time.Minute)
+	input.resume = &wait.progress
+	err := exec.Execute(context.Background(), input)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 1m0s") {
+		t.Fatalf("Execute() error = %v, want a timeout", err)
+	}
+}
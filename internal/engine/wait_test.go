@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestRenderWaitDuration(t *testing.T) {
+	obj := newDeploymentInput("uid-1", "demo", "default").Obj
+	obj.SetAnnotations(map[string]string{"example.com/index-delay": "45s"})
+	outputs := map[string]string{"delay": "2m"}
+
+	tests := []struct {
+		duration string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{duration: "30s", want: 30 * time.Second},
+		{duration: `{{ index .metadata.annotations "example.com/index-delay" }}`, want: 45 * time.Second},
+		{duration: `{{ output "delay" }}`, want: 2 * time.Minute},
+		{duration: `{{ output "missing" }}`, wantErr: true},
+		{duration: "soon", wantErr: true},
+		{duration: "-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := renderWaitDuration(nil, &opsv1alpha1.WaitSpec{Duration: tt.duration}, obj, outputs)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("renderWaitDuration(%q) = %s, %v; want %s, error %v", tt.duration, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestExecute_WaitActionPausesAndResumes(t *testing.T) {
+	var mu sync.Mutex
+	var called []string
+	var verified string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		called = append(called, r.URL.Path)
+		if r.URL.Path == "/create" {
+			_, _ = w.Write([]byte(`{"id":"42"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		verified = string(body)
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-wait", Namespace: "default", Generation: 1},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Events:   []string{"Create"},
+			Actions: []opsv1alpha1.ActionSpec{
+				{
+					Type:      "http",
+					URL:       srv.URL + "/create",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Outputs:   map[string]string{"id": "{.id}"},
+				},
+				{Type: "wait", Wait: &opsv1alpha1.WaitSpec{Duration: "1m"}},
+				{
+					Type:      "http",
+					URL:       srv.URL + "/verify",
+					URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+					Body:      &opsv1alpha1.TemplateSpec{Template: `{{ output "id" }}`},
+				},
+			},
+		},
+	}
+	exec, cl := newTestExecutor(t, ra)
+	input := newDeploymentInput("uid-wait", "demo", "default")
+
+	err := exec.Execute(context.Background(), input)
+	var wait *waitError
+	if !errors.As(err, &wait) {
+		t.Fatalf("Execute() error = %v, want a wait", err)
+	}
+	if wait.delay != time.Minute || wait.progress.next != 2 {
+		t.Fatalf("wait = %s before action %d, want 1m before action 2", wait.delay, wait.progress.next)
+	}
+	if !slices.Equal(called, []string{"/create"}) {
+		t.Fatalf("called %v before the wait, want only /create", called)
+	}
+	var got opsv1alpha1.ResourceAction
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	if len(got.Status.Executions) != 0 {
+		t.Fatalf("executions = %+v, want none while waiting", got.Status.Executions)
+	}
+
+	input.resume = &wait.progress
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("resumed Execute() error = %v", err)
+	}
+	if !slices.Equal(called, []string{"/create", "/verify"}) || verified != "42" {
+		t.Fatalf("called %v with body %q, want /verify with the output of /create", called, verified)
+	}
+	if err := cl.Get(context.Background(), types.NamespacedName{Name: ra.Name, Namespace: ra.Namespace}, &got); err != nil {
+		t.Fatalf("get resourceaction: %v", err)
+	}
+	record := got.Status.Executions[0]
+	if record.ActionCount != 3 || record.Attempts != 2 || record.CorrelationID != wait.progress.correlationID {
+		t.Fatalf("record = %+v, want one execution of all three actions", record)
+	}
+
+	// A resumed execution is abandoned once the spec changed.
+	stale := wait.progress
+	stale.generation = 0
+	input.resume = &stale
+	if err := exec.Execute(context.Background(), input); err != nil {
+		t.Fatalf("stale Execute() error = %v", err)
+	}
+	if len(called) != 2 {
+		t.Fatalf("called %v, want no request after a spec change", called)
+	}
+}
+
+// waitingExecutor pauses the Create of every object once and records the
+// delivered events.
+type waitingExecutor struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (w *waitingExecutor) Execute(_ context.Context, input MatchInput) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	event := string(input.Event)
+	if input.resume != nil {
+		event += "/resumed"
+	}
+	w.events = append(w.events, event)
+	if input.Event == EventCreate && input.resume == nil {
+		return &waitError{
+			progress: executionProgress{owner: types.NamespacedName{Namespace: "default", Name: "direct"}, next: 1},
+			delay:    50 * time.Millisecond,
+		}
+	}
+	return nil
+}
+
+func (w *waitingExecutor) delivered() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.events...)
+}
+
+func TestEventWorkers_ResumeAfterWait(t *testing.T) {
+	_, cl := newTestExecutor(t)
+	exec := &waitingExecutor{}
+	e := NewEngine(cl)
+	e.executor = exec
+	e.queue = workqueue.NewTypedRateLimitingQueue(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[*eventItem](time.Millisecond, 10*time.Millisecond),
+	)
+	owner := types.NamespacedName{Namespace: "default", Name: "direct"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e.startWorkers(ctx)
+
+	create := newDeploymentInput("uid-1", "demo", "default")
+	create.owners = map[types.NamespacedName]struct{}{owner: {}}
+	e.enqueue(create)
+	// The Update waits for the paused Create of the same object.
+	e.enqueue(newUpdateInput("uid-1", "1", "2", owner))
+
+	want := []string{"Create", "Create/resumed", "Update"}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(exec.delivered()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := exec.delivered(); !slices.Equal(got, want) {
+		t.Fatalf("delivered %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchFailure is the last list/watch error of an informer together with the
+// resource version the informer had synced when the error happened. A later
+// successful relist changes the resource version and clears the failure.
+type watchFailure struct {
+	err             error
+	resourceVersion string
+}
+
+// WatchHealth describes the state of the informer for a resource type.
+type WatchHealth struct {
+	// Watching is false when no informer was registered for the type.
+	Watching bool
+	Synced   bool
+	// Err is the last list/watch error that was not followed by a successful
+	// relist, for example RBAC denied or a deleted CRD.
+	Err error
+}
+
+// Healthy reports whether the informer is synced and has no pending error.
+func (h WatchHealth) Healthy() bool {
+	return h.Watching && h.Synced && h.Err == nil
+}
+
+// watchErrorHandler records list/watch failures of the informer for key.
+// Expired resource versions and closed watch streams are part of normal
+// operation and are not recorded.
+func (e *Engine) watchErrorHandler(key watchKey) cache.WatchErrorHandlerWithContext {
+	return func(ctx context.Context, r *cache.Reflector, err error) {
+		cache.DefaultWatchErrorHandler(ctx, r, err)
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) ||
+			errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return
+		}
+
+		if apierrors.IsNotFound(err) {
+			// The resource was removed, e.g. with its CRD; resolve it
+			// again on the next reconcile.
+			e.kindsForKey(key).invalidate()
+		}
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		failure := watchFailure{err: err}
+		if inf, ok := e.informers[key]; ok {
+			failure.resourceVersion = inf.LastSyncResourceVersion()
+		}
+		e.watchFailures[key] = failure
+	}
+}
+
+// WatchHealth returns the combined state of the informers owner needs.
+func (e *Engine) WatchHealth(owner types.NamespacedName) WatchHealth {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	keys := e.owners[owner]
+	if len(keys) == 0 {
+		return WatchHealth{}
+	}
+	combined := WatchHealth{Watching: true, Synced: true}
+	for _, key := range keys {
+		health := e.watchHealthLocked(key)
+		combined.Watching = combined.Watching && health.Watching
+		combined.Synced = combined.Synced && health.Synced
+		if combined.Err == nil && health.Err != nil {
+			combined.Err = fmt.Errorf("%s: %w", key.String(), health.Err)
+		}
+	}
+	return combined
+}
+
+func (e *Engine) watchHealthLocked(key watchKey) WatchHealth {
+	inf, ok := e.informers[key]
+	if !ok {
+		return WatchHealth{}
+	}
+	health := WatchHealth{Watching: true, Synced: inf.HasSynced()}
+	if failure, ok := e.watchFailures[key]; ok {
+		// The informer listed or watched since the failure: its
+		// resourceVersion moved, or it synced after a failure that was
+		// recorded before its first list.
+		if inf.LastSyncResourceVersion() != failure.resourceVersion ||
+			(failure.resourceVersion == "" && inf.HasSynced()) {
+			delete(e.watchFailures, key)
+		} else {
+			health.Err = failure.err
+		}
+	}
+	return health
+}
+
+// InformersHealthy is a readiness check that fails while any registered
+// informer cannot list or watch its resource.
+func (e *Engine) InformersHealthy(_ *http.Request) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var failing []string
+	for key := range e.informers {
+		if health := e.watchHealthLocked(key); health.Err != nil {
+			failing = append(failing, fmt.Sprintf("%s: %v", key.String(), health.Err))
+		}
+	}
+	if len(failing) == 0 {
+		return nil
+	}
+	sort.Strings(failing)
+	return fmt.Errorf("informers failing: %s", strings.Join(failing, "; "))
+}
+
+// Reconciled records that the controller reconciled owner. Readiness waits
+// until every ResourceAction was reconciled once, so their informers and
+// schedules are registered.
+func (e *Engine) Reconciled(owner types.NamespacedName) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.restored {
+		return
+	}
+	if e.reconciled == nil {
+		e.reconciled = make(map[types.NamespacedName]struct{})
+	}
+	e.reconciled[owner] = struct{}{}
+}
+
+// ReadyCheck returns a readiness check that fails until the engine is warm:
+// it has started, every ResourceAction was reconciled, the cron engine
+// restored its schedules and all registered informers have synced. Until
+// elected is closed the replica is a standby that does not watch anything,
+// and the check passes so rollouts are not held up by it. Standbys of a
+// sharded engine watch their shard; they are ready once it has started and
+// its informers have synced.
+func (e *Engine) ReadyCheck(elected <-chan struct{}) func(*http.Request) error {
+	return func(req *http.Request) error {
+		select {
+		case <-elected:
+			return e.ready(req.Context(), true)
+		default:
+			if !e.shard.sharded() {
+				return nil
+			}
+			return e.ready(req.Context(), false)
+		}
+	}
+}
+
+func (e *Engine) ready(ctx context.Context, leader bool) error {
+	e.mu.Lock()
+	started := e.started
+	e.mu.Unlock()
+	if !started {
+		return errors.New("engine has not started")
+	}
+	if leader {
+		if !e.cronEngine.isStarted() {
+			return errors.New("cron engine has not restored its schedules")
+		}
+		if err := e.checkRestored(ctx); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var syncing []string
+	for key, inf := range e.informers {
+		if !inf.HasSynced() {
+			syncing = append(syncing, key.String())
+		}
+	}
+	if len(syncing) == 0 {
+		return nil
+	}
+	sort.Strings(syncing)
+	return fmt.Errorf("informers not synced: %s", strings.Join(syncing, ", "))
+}
+
+// checkRestored fails while a ResourceAction was not reconciled since the
+// process started.
+func (e *Engine) checkRestored(ctx context.Context) error {
+	e.mu.Lock()
+	restored := e.restored
+	e.mu.Unlock()
+	if restored {
+		return nil
+	}
+
+	var list opsv1alpha1.ResourceActionList
+	if err := e.cronEngine.client.List(ctx, &list); err != nil {
+		return fmt.Errorf("list ResourceActions: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var pending []string
+	for _, ra := range list.Items {
+		if _, ok := e.reconciled[types.NamespacedName{Namespace: ra.Namespace, Name: ra.Name}]; !ok {
+			pending = append(pending, ra.Namespace+"/"+ra.Name)
+		}
+	}
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		return fmt.Errorf("ResourceActions not reconciled yet: %s", strings.Join(pending, ", "))
+	}
+	e.restored = true
+	e.reconciled = nil
+	return nil
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWatchErrorHandler_TracksFailures(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	key := watchKey{gvr: gvr}
+	e := NewEngine(nil)
+	e.informers[key] = cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+
+	if err := e.InformersHealthy(nil); err != nil {
+		t.Fatalf("InformersHealthy() = %v, want nil", err)
+	}
+
+	handler := e.watchErrorHandler(key)
+	r := cache.NewReflector(&cache.ListWatch{}, &unstructured.Unstructured{}, cache.NewStore(cache.MetaNamespaceKeyFunc), 0)
+	gr := gvr.GroupResource()
+	handler(context.Background(), r, apierrors.NewResourceExpired("too old resource version"))
+	if health := e.watchHealthLocked(key); health.Err != nil {
+		t.Fatalf("expired resource version recorded as failure: %v", health.Err)
+	}
+
+	handler(context.Background(), r, apierrors.NewForbidden(gr, "", nil))
+	health := e.watchHealthLocked(key)
+	if !health.Watching || health.Synced || health.Healthy() {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+	if !apierrors.IsForbidden(health.Err) {
+		t.Fatalf("health.Err = %v, want forbidden", health.Err)
+	}
+	if err := e.InformersHealthy(nil); err == nil {
+		t.Fatalf("InformersHealthy() = nil, want error")
+	}
+
+	if health := e.watchHealthLocked(watchKey{gvr: schema.GroupVersionResource{Version: "v1", Resource: "pods"}}); health.Watching {
+		t.Fatalf("unregistered resource reported as watching")
+	}
+}
+
+// fakeSyncInformer reports a fixed sync state and resourceVersion.
+type fakeSyncInformer struct {
+	cache.SharedIndexInformer
+	synced          bool
+	resourceVersion string
+}
+
+func (f *fakeSyncInformer) HasSynced() bool { return f.synced }
+
+func (f *fakeSyncInformer) LastSyncResourceVersion() string { return f.resourceVersion }
+
+func TestWatchHealth_ClearsFailureAfterSync(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	key := watchKey{gvr: gvr}
+	gr := gvr.GroupResource()
+	e := NewEngine(nil)
+	inf := &fakeSyncInformer{}
+	e.informers[key] = inf
+	handler := e.watchErrorHandler(key)
+	r := cache.NewReflector(&cache.ListWatch{}, &unstructured.Unstructured{}, cache.NewStore(cache.MetaNamespaceKeyFunc), 0)
+
+	// The first list failed before the informer ever synced.
+	handler(context.Background(), r, apierrors.NewForbidden(gr, "", nil))
+	if err := e.InformersHealthy(nil); err == nil {
+		t.Fatalf("InformersHealthy() = nil, want the list failure")
+	}
+	inf.synced = true
+	if err := e.InformersHealthy(nil); err != nil {
+		t.Fatalf("InformersHealthy() after the first sync = %v, want nil", err)
+	}
+
+	// A later watch failure is cleared once the resourceVersion moves on.
+	inf.resourceVersion = "10"
+	handler(context.Background(), r, apierrors.NewForbidden(gr, "", nil))
+	if err := e.InformersHealthy(nil); err == nil {
+		t.Fatalf("InformersHealthy() = nil, want the watch failure")
+	}
+	inf.resourceVersion = "11"
+	if err := e.InformersHealthy(nil); err != nil {
+		t.Fatalf("InformersHealthy() after the watch recovered = %v, want nil", err)
+	}
+}
+
+func TestReadyCheck(t *testing.T) {
+	ra := &opsv1alpha1.ResourceAction{ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "ops"}}
+	_, cl := newTestExecutor(t, ra)
+	e := NewEngine(cl)
+	key := watchKey{gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}}
+	e.informers[key] = cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	elected := make(chan struct{})
+	check := e.ReadyCheck(elected)
+	if err := check(req); err != nil {
+		t.Fatalf("check() on a standby = %v, want nil", err)
+	}
+	close(elected)
+	if err := check(req); err == nil {
+		t.Fatalf("check() before Start = nil, want an error")
+	}
+
+	e.started = true
+	e.cronEngine.Start(context.Background())
+	if err := check(req); err == nil || !strings.Contains(err.Error(), "ops/notify") {
+		t.Fatalf("check() = %v, want the ResourceAction that was not reconciled", err)
+	}
+	e.Reconciled(types.NamespacedName{Namespace: "ops", Name: "notify"})
+	if err := check(req); err == nil || !strings.Contains(err.Error(), "informers not synced") {
+		t.Fatalf("check() = %v, want the informer that has not synced", err)
+	}
+
+	delete(e.informers, key)
+	if err := check(req); err != nil {
+		t.Fatalf("check() = %v, want nil", err)
+	}
+	if !e.restored || e.reconciled != nil {
+		t.Fatalf("restored = %v, want the reconciled set released", e.restored)
+	}
+}
@@ -0,0 +1,233 @@
+package engine
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultWebhookSecretHeader = "X-Webhook-Secret"
+	// maxWebhookPayload bounds the request bodies the receiver accepts.
+	maxWebhookPayload = 1 << 20
+	// webhookShutdownTimeout bounds waiting for open requests on shutdown.
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+var webhookSourceGVK = opsv1alpha1.GroupVersion.WithKind("WebhookSource")
+
+// WebhookReceiver serves the endpoints of WebhookSources at
+// POST /webhooks/<namespace>/<name>. A request that carries the shared secret
+// of its WebhookSource is queued as a Webhook event of the WebhookSource, with
+// the JSON request body as .payload, and runs through the same matching and
+// actions as informer events. Alertmanager notifications are queued as one
+// event per alert; GitHub deliveries are authenticated by their signature.
+type WebhookReceiver struct {
+	addr    string
+	engine  *Engine
+	client  client.Reader
+	secrets *secretCache
+}
+
+// NewWebhookReceiver returns a receiver listening on addr that queues events
+// with eng and reads WebhookSources with c.
+func NewWebhookReceiver(addr string, eng *Engine, c client.Reader) *WebhookReceiver {
+	secrets := newSecretCache(c)
+	if exec, ok := eng.executor.(*K8sExecutor); ok {
+		secrets = exec.secrets
+	}
+	return &WebhookReceiver{addr: addr, engine: eng, client: c, secrets: secrets}
+}
+
+// Start implements manager.Runnable. It serves until ctx is done.
+func (r *WebhookReceiver) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              r.addr,
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe() }()
+	log.FromContext(ctx).Info("Serving webhook sources", "address", r.addr)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Only the
+// leader runs actions, so only it accepts events.
+func (r *WebhookReceiver) NeedLeaderElection() bool {
+	return true
+}
+
+// Handler returns the HTTP handler of the receiver.
+func (r *WebhookReceiver) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhooks/{namespace}/{name}", r.receive)
+	return mux
+}
+
+func (r *WebhookReceiver) receive(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	key := types.NamespacedName{Namespace: req.PathValue("namespace"), Name: req.PathValue("name")}
+	logger := log.FromContext(ctx).WithValues("webhookSource", key.String())
+
+	if len(r.engine.watchNamespaces) > 0 && !slices.Contains(r.engine.watchNamespaces, key.Namespace) {
+		http.Error(w, "webhook source not found", http.StatusNotFound)
+		return
+	}
+	var source opsv1alpha1.WebhookSource
+	if err := r.client.Get(ctx, key, &source); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "webhook source not found", http.StatusNotFound)
+			return
+		}
+		logger.Error(err, "failed to get webhook source")
+		http.Error(w, "failed to get webhook source", http.StatusInternalServerError)
+		return
+	}
+
+	secret, err := r.secrets.get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: source.Spec.SecretRef.Name})
+	if err != nil {
+		logger.Error(err, "failed to read the shared secret of webhook source")
+		http.Error(w, "failed to read the shared secret", http.StatusInternalServerError)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxWebhookPayload))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if !authenticated(&source, secret.Data[source.Spec.SecretRef.Key], req, body) {
+		http.Error(w, "invalid shared secret", http.StatusUnauthorized)
+		return
+	}
+	var payload interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, "request body must be JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var objects []*unstructured.Unstructured
+	switch source.Spec.Format {
+	case webhookFormatAlertmanager:
+		objects, err = alertEventObjects(&source, payload)
+		if errors.Is(err, errNotAlertmanagerNotification) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case webhookFormatGitHub:
+		event := req.Header.Get(gitHubEventHeader)
+		if event == "" {
+			http.Error(w, gitHubEventHeader+" is required", http.StatusBadRequest)
+			return
+		}
+		var obj *unstructured.Unstructured
+		obj, err = gitHubEventObject(&source, payload, event, req.Header.Get(gitHubDeliveryHeader))
+		objects = append(objects, obj)
+	default:
+		var obj *unstructured.Unstructured
+		obj, err = webhookEventObject(&source, payload)
+		objects = append(objects, obj)
+	}
+	if err != nil {
+		logger.Error(err, "failed to convert webhook source")
+		http.Error(w, "failed to convert webhook source", http.StatusInternalServerError)
+		return
+	}
+	if err := r.engine.queueWebhookEvents(objects...); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	logger.Info("Queued webhook events", "events", len(objects))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// authenticated reports whether req carries secret. GitHub deliveries are
+// signed with it, other requests send it in the header of source. An empty
+// secret is a misconfiguration and never accepts a request.
+func authenticated(source *opsv1alpha1.WebhookSource, secret []byte, req *http.Request, body []byte) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	if source.Spec.Format == webhookFormatGitHub {
+		return validGitHubSignature(secret, body, req.Header.Get(gitHubSignatureHeader))
+	}
+
+	header := source.Spec.Header
+	if header == "" {
+		header = defaultWebhookSecretHeader
+	}
+	got := req.Header.Get(header)
+	if strings.EqualFold(header, "Authorization") {
+		// Alertmanager and most other senders only set bearer tokens.
+		if token, ok := strings.CutPrefix(got, "Bearer "); ok {
+			got = token
+		}
+	}
+	return subtle.ConstantTimeCompare([]byte(got), secret) == 1
+}
+
+// webhookEventObject returns source as the object of a Webhook event, with
+// payload added as .payload.
+func webhookEventObject(source *opsv1alpha1.WebhookSource, payload interface{}) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(source)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(webhookSourceGVK)
+	obj.Object["payload"] = payload
+	return obj, nil
+}
+
+// queueWebhookEvents queues a Webhook event of each of objects for every
+// ResourceAction, in order.
+func (e *Engine) queueWebhookEvents(objects ...*unstructured.Unstructured) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.draining {
+		return errShutdown
+	}
+	for _, obj := range objects {
+		input := MatchInput{Event: EventWebhook, GVK: webhookSourceGVK, Obj: obj}
+		observeEventReceived(input)
+		e.addLocked(&eventItem{input: input, key: objectKey{owner: allResourceActions, uid: obj.GetUID()}})
+	}
+	return nil
+}
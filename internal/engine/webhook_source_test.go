@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	opsv1alpha1 "de.yusaozdemir.resource-action-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newWebhookTestReceiver(t *testing.T, format, header string) (*WebhookReceiver, *Engine) {
+	t.Helper()
+	source := &opsv1alpha1.WebhookSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "alerts", Namespace: "ops", UID: "uid-source"},
+		Spec: opsv1alpha1.WebhookSourceSpec{
+			SecretRef: opsv1alpha1.SecretKeyRef{Name: "alerts-webhook", Key: "token"},
+			Header:    header,
+			Format:    format,
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "alerts-webhook", Namespace: "ops"},
+		Data:       map[string][]byte{"token": []byte("s3cret")},
+	}
+	_, cl := newTestExecutor(t, source, secret)
+	e := NewEngine(cl)
+	return NewWebhookReceiver("", e, cl), e
+}
+
+func postWebhook(r *WebhookReceiver, path, secret, body string) int {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if secret != "" {
+		req.Header.Set(defaultWebhookSecretHeader, secret)
+	}
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWebhookReceiver_QueuesEventWithPayload(t *testing.T) {
+	r, e := newWebhookTestReceiver(t, "", "")
+
+	if code := postWebhook(r, "/webhooks/ops/alerts", "s3cret", `{"alert":"DiskFull","count":2}`); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", code, http.StatusAccepted)
+	}
+	if e.queue.Len() != 1 {
+		t.Fatalf("queue length = %d, want 1", e.queue.Len())
+	}
+	item, _ := e.queue.Get()
+	defer e.queue.Done(item)
+	input := e.takeInput(item)
+	if input.Event != EventWebhook || input.GVK != webhookSourceGVK || input.owners != nil {
+		t.Fatalf("unexpected event %s of %s for owners %v", input.Event, input.GVK, input.owners)
+	}
+	if input.Obj.GetName() != "alerts" || input.Obj.GetUID() != "uid-source" || input.Obj.GetKind() != "WebhookSource" {
+		t.Fatalf("object = %v, want the webhook source", input.Obj.Object)
+	}
+	alert, _, _ := unstructured.NestedString(input.Obj.Object, "payload", "alert")
+	if alert != "DiskFull" {
+		t.Fatalf("payload = %v, want the request body", input.Obj.Object["payload"])
+	}
+}
+
+func TestWebhookReceiver_RejectsRequests(t *testing.T) {
+	r, e := newWebhookTestReceiver(t, "", "")
+
+	tests := []struct {
+		name   string
+		path   string
+		secret string
+		body   string
+		want   int
+	}{
+		{name: "missing secret", path: "/webhooks/ops/alerts", body: `{}`, want: http.StatusUnauthorized},
+		{name: "wrong secret", path: "/webhooks/ops/alerts", secret: "guess", body: `{}`, want: http.StatusUnauthorized},
+		{name: "unknown source", path: "/webhooks/ops/missing", secret: "s3cret", body: `{}`, want: http.StatusNotFound},
+		{name: "invalid JSON", path: "/webhooks/ops/alerts", secret: "s3cret", body: `alert`, want: http.StatusBadRequest},
+		{
+			name: "too large", path: "/webhooks/ops/alerts", secret: "s3cret",
+			body: `"` + strings.Repeat("x", maxWebhookPayload) + `"`, want: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := postWebhook(r, tt.path, tt.secret, tt.body); code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+	if e.queue.Len() != 0 {
+		t.Fatalf("queue length = %d, want rejected requests not to be queued", e.queue.Len())
+	}
+
+	e.draining = true
+	if code := postWebhook(r, "/webhooks/ops/alerts", "s3cret", `{}`); code != http.StatusServiceUnavailable {
+		t.Fatalf("status while draining = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestExecute_WebhookEventsIgnoreExecutionPolicy(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer srv.Close()
+
+	ra := &opsv1alpha1.ResourceAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "ra-webhook", Namespace: "ops"},
+		Spec: opsv1alpha1.ResourceActionSpec{
+			Selector: opsv1alpha1.ResourceSelector{Group: "ops.yusaozdemir.de", Version: "v1alpha1", Kind: "WebhookSource"},
+			Events:   []string{"Webhook"},
+			Actions: []opsv1alpha1.ActionSpec{{
+				Type:      "http",
+				URL:       srv.URL,
+				URLPolicy: &opsv1alpha1.URLPolicySpec{AllowUnsafeLocalTargets: true},
+				Body:      &opsv1alpha1.TemplateSpec{Template: `{{ .payload.alert }}`, ContentType: "text/plain"},
+			}},
+		},
+	}
+	exec, _ := newTestExecutor(t, ra)
+	source := &opsv1alpha1.WebhookSource{ObjectMeta: metav1.ObjectMeta{Name: "alerts", Namespace: "ops", UID: "uid-source"}}
+
+	for _, alert := range []string{"DiskFull", "NodeDown"} {
+		obj, err := webhookEventObject(source, map[string]interface{}{"alert": alert})
+		if err != nil {
+			t.Fatalf("webhookEventObject() error = %v", err)
+		}
+		input := MatchInput{Event: EventWebhook, GVK: webhookSourceGVK, Obj: obj}
+		if err := exec.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if calls.Load() != 2 || bodies[0] != "DiskFull" || bodies[1] != "NodeDown" {
+		t.Fatalf("bodies = %v, want both webhook events to run", bodies)
+	}
+}